@@ -0,0 +1,54 @@
+// Command fakesmtp menjalankan server SMTP lokal untuk development dan test. Email tidak diteruskan
+// ke mana pun, hanya ditulis sebagai file .eml. Jalankan server dengan MAILER_DRIVER=smtp,
+// SMTP_HOST=127.0.0.1, SMTP_PORT=2525 dan SMTP_TLS=none.
+//
+// Usage:
+//
+//	go run ./cmd/fakesmtp [-addr 127.0.0.1:2525] [-dir ./data/fakesmtp] [-reject a@x.com,b@y.com] [-tempfail c@z.com] [-v]
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"backend/internal/mailer/fakesmtp"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:2525", "alamat yang didengarkan")
+	dir := flag.String("dir", "./data/fakesmtp", "folder untuk menyimpan email yang diterima")
+	reject := flag.String("reject", "", "alamat penerima yang ditolak permanen (550), pisahkan dengan koma")
+	tempFail := flag.String("tempfail", "", "alamat penerima yang ditolak sementara (451), pisahkan dengan koma")
+	verbose := flag.Bool("v", false, "catat percakapan SMTP")
+	flag.Parse()
+
+	server := &fakesmtp.Server{Dir: *dir, Reject: addressSet(*reject), TempFail: addressSet(*tempFail)}
+	if *verbose {
+		server.Logf = log.Printf
+	}
+	if err := server.Start(*addr); err != nil {
+		log.Fatalf("failed to listen: %v", err)
+	}
+	log.Printf("📮 Fake SMTP listening on %s, writing messages to %s", server.Addr(), *dir)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+	_ = server.Close()
+	log.Printf("Fake SMTP stopped, %d messages received", len(server.Messages()))
+}
+
+// addressSet mengubah daftar alamat dipisah koma menjadi set lowercase
+func addressSet(list string) map[string]bool {
+	set := map[string]bool{}
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.ToLower(strings.TrimSpace(addr)); addr != "" {
+			set[addr] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,82 @@
+// Command normalize-phones menormalisasi nomor HP participant yang sudah ada ke format E.164.
+// Nomor asli disimpan di kolom phone_input; baris dengan nomor tidak valid dilewati dan dicatat di log.
+//
+// Usage:
+//
+//	go run ./cmd/normalize-phones [-dry-run]
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/joho/godotenv"
+
+	"backend/internal/db"
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "tampilkan perubahan tanpa menyimpan ke database")
+	flag.Parse()
+
+	// Sama seperti server: coba .env di parent directory lalu current dir
+	if err := godotenv.Load("../.env"); err != nil {
+		_ = godotenv.Load()
+	}
+
+	if err := db.Connect(db.URLFromEnv()); err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	database := db.Instance()
+
+	// Pastikan kolom phone_input & phone_key sudah ada
+	if err := database.AutoMigrate(&models.Participant{}); err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+
+	var participants []models.Participant
+	if err := database.Select("id", "name", "phone", "phone_input").Find(&participants).Error; err != nil {
+		log.Fatalf("failed to load participants: %v", err)
+	}
+
+	var updated, unchanged, invalid int
+	for _, p := range participants {
+		original := p.PhoneInput
+		if original == "" {
+			original = p.Phone
+		}
+
+		normalized, err := helpers.NormalizeIndonesianPhone(p.Phone)
+		if err != nil {
+			invalid++
+			log.Printf("SKIP %s (%s): %q tidak valid: %v", p.ID, p.Name, p.Phone, err)
+			continue
+		}
+		if normalized == p.Phone && original == p.PhoneInput {
+			unchanged++
+			continue
+		}
+
+		log.Printf("UPDATE %s (%s): %q -> %q", p.ID, p.Name, p.Phone, normalized)
+		updated++
+		if *dryRun {
+			continue
+		}
+		// UpdateColumns melewati hook & updated_at: ini migrasi data, bukan edit oleh admin
+		if err := database.Model(&models.Participant{}).Where("id = ?", p.ID).UpdateColumns(map[string]interface{}{
+			"phone":       normalized,
+			"phone_input": original,
+			"phone_key":   helpers.PhoneKey(normalized),
+		}).Error; err != nil {
+			log.Fatalf("failed to update participant %s: %v", p.ID, err)
+		}
+	}
+
+	if *dryRun {
+		log.Printf("Dry run: %d akan diupdate, %d sudah normal, %d tidak valid", updated, unchanged, invalid)
+		return
+	}
+	log.Printf("Selesai: %d diupdate, %d sudah normal, %d tidak valid", updated, unchanged, invalid)
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
+	_ "time/tzdata" // zona waktu TZ (mis. Asia/Jakarta) tetap bisa dipakai di image tanpa tzdata
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -14,9 +19,13 @@ import (
 	"gorm.io/gorm"
 
 	"backend/internal/db"
+	"backend/internal/duplicates"
+	"backend/internal/helpers"
 	"backend/internal/httpapi"
 	"backend/internal/models"
+	"backend/internal/search"
 	"backend/internal/seeders"
+	"backend/internal/tickets"
 )
 
 func getEnvOrDefault(key string, fallback string) string {
@@ -59,40 +68,52 @@ func main() {
 		_ = godotenv.Load()
 	}
 
-	port := getEnvOrDefault("PORT", "8001")
-
-	// Cek env MySQL
-	dbHost := getEnvOrDefault("DB_HOST", "")
-	dbPort := getEnvOrDefault("DB_PORT", "")
-	dbName := getEnvOrDefault("DB_NAME", "")
-	dbUser := getEnvOrDefault("DB_USER", "")
-	dbPassword := getEnvOrDefault("DB_PASSWORD", "")
-
-	var databaseURL string
-	if dbHost != "" && dbPort != "" && dbName != "" && dbUser != "" {
-		// Format DSN MySQL: username:password@tcp(host:port)/dbname?parseTime=true
-		databaseURL = dbUser + ":" + dbPassword + "@tcp(" + dbHost + ":" + dbPort + ")/" + dbName + "?parseTime=true"
-		log.Printf("Connecting to MySQL: %s", databaseURL)
-	} else {
-		// Prefer DATABASE_URL (can be Postgres or MySQL); fallback to SQLite path via DB_PATH
-		databaseURL = getEnvOrDefault("DATABASE_URL", "")
-		if databaseURL == "" {
-			databaseURL = getEnvOrDefault("DB_PATH", filepath.Join(".", "data", "app.db"))
+	// TZ dari .env baru terbaca setelah proses berjalan, jadi zona waktu lokal di-set ulang
+	if tz := os.Getenv("TZ"); tz != "" {
+		if loc, err := time.LoadLocation(tz); err != nil {
+			log.Printf("Warning: invalid TZ %q: %v", tz, err)
+		} else {
+			time.Local = loc
 		}
-		log.Printf("Using database: %s", databaseURL)
 	}
 
+	port := getEnvOrDefault("PORT", "8001")
+
+	// MySQL (DB_HOST dkk), DATABASE_URL, atau SQLite via DB_PATH
+	databaseURL := db.URLFromEnv()
+	log.Printf("Using database: %s", databaseURL)
+
 	database := mustConnectDatabase(databaseURL)
 	log.Printf("Database connected successfully")
 
 	// Auto migrate models
 	log.Printf("Running auto migration...")
-	if err := database.AutoMigrate(&models.User{}, &models.Participant{}, &models.BlacklistedToken{}, &models.RefreshToken{}); err != nil {
+	if err := database.AutoMigrate(&models.User{}, &models.Participant{}, &models.BlacklistedToken{}, &models.RefreshToken{}, &models.ParticipantMerge{}, &models.EmailVerification{}, &models.ParticipantStatusHistory{}, &models.Event{}, &models.RegistrationQuestion{}, &models.ParticipantAnswer{}, &models.ParticipantFile{}, &models.CheckIn{}, &models.Session{}, &models.SessionAttendance{}, &models.CertificateTemplate{}, &models.Certificate{}, &models.CertificateBatch{}, &models.Campus{}, &models.StudyProgram{}, &models.ParticipantMagicLink{}, &models.ParticipantSession{}, &models.IdempotencyKey{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.WebhookAttempt{}, &models.OutboundMessage{}, &models.MessageTemplate{}, &models.MessageOptOut{}, &models.OutboundEmail{}, &models.EmailTemplate{}, &models.ScheduledJob{}, &models.JobRun{}); err != nil {
 		log.Printf("Migration error: %v", err)
 	} else {
 		log.Printf("Migration completed successfully")
 	}
 
+	// Index full-text search participant (FULLTEXT / GIN / FTS5 + trigger), dibuat setelah tabelnya ada
+	log.Printf("Running search index migration...")
+	if err := search.Migrate(database); err != nil {
+		log.Printf("Warning: Search index migration failed, search falls back to LIKE: %v", err)
+	} else {
+		log.Printf("Search index migration completed successfully")
+	}
+
+	// Data lama yang dibuat sebelum kolom phone_key / registration_code ada; sekali saat startup, bukan per controller
+	if n, err := duplicates.NewDetector(database).BackfillPhoneKeys(); err != nil {
+		log.Printf("Warning: Failed to backfill phone keys: %v", err)
+	} else if n > 0 {
+		log.Printf("Backfilled phone keys for %d participants", n)
+	}
+	if n, err := tickets.BackfillCodes(database); err != nil {
+		log.Printf("Warning: Failed to backfill registration codes: %v", err)
+	} else if n > 0 {
+		log.Printf("Backfilled registration codes for %d participants", n)
+	}
+
 	// Run seeders
 	log.Printf("Running user seeder...")
 	if err := seeders.SeedUsers(database); err != nil {
@@ -101,12 +122,30 @@ func main() {
 		log.Printf("User seeder completed successfully")
 	}
 
+	log.Printf("Running master data seeder...")
+	if err := seeders.SeedMasterData(database); err != nil {
+		log.Printf("Warning: Failed to seed master data: %v", err)
+	}
+
 	// Set Gin mode from environment
 	ginMode := getEnvOrDefault("GIN_MODE", "debug")
 	gin.SetMode(ginMode)
 
 	engine := gin.Default()
 
+	// IP client (dipakai rate limit & log) hanya dibaca dari X-Forwarded-For / X-Real-IP jika request datang
+	// dari proxy di TRUSTED_PROXIES. Default kosong: header itu diabaikan dan IP koneksi langsung yang dipakai.
+	var trustedProxies []string
+	for _, proxy := range strings.Split(getEnvOrDefault("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	log.Printf("Trusted proxies: %v", trustedProxies)
+
 	// Setup CORS
 	allowOriginsRaw := getEnvOrDefault("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173,http://10.255.209.77:3000,http://10.255.209.77:5173")
 	allowOrigins := strings.Split(allowOriginsRaw, ",")
@@ -126,7 +165,7 @@ func main() {
 	engine.Use(cors.New(corsConfig))
 
 	// Setup API routes
-	httpapi.SetupRouter(engine, database)
+	workers := httpapi.SetupRouter(engine, database)
 
 	// Health check endpoint
 	engine.GET("/healthz", func(c *gin.Context) {
@@ -148,13 +187,75 @@ func main() {
 					"logout": "POST /api/logout (protected)",
 				},
 				"participants": gin.H{
-					"create": "POST /api/participants",
-					"list":   "GET /api/participants (protected)",
-					"get":    "GET /api/participants/:id (protected)",
-					"update": "PUT /api/participants/:id (protected)",
-					"delete": "DELETE /api/participants/:id (protected)",
+					"create":     "POST /api/participants",
+					"list":       "GET /api/participants (protected)",
+					"get":        "GET /api/participants/:id (protected)",
+					"update":     "PUT /api/participants/:id (protected)",
+					"delete":     "DELETE /api/participants/:id (protected)",
+					"duplicates": "GET /api/participants/duplicates (protected)",
+					"merge":      "POST /api/participants/duplicates/merge (protected)",
+					"verify":     "GET /api/participants/verify-email?token=",
+					"resend":     "POST /api/participants/:id/resend-verification (protected)",
+					"status":     "POST /api/participants/:id/status (protected)",
+					"history":    "GET /api/participants/:id/status-history (protected)",
+					"export":     "GET /api/participants/export (protected, CSV)",
+					"files":      "GET|POST /api/participants/:id/files, DELETE /api/participants/:id/files/:fileId (protected)",
+					"upload":     "POST /api/participants/:id/uploads (upload_token dari registrasi)",
+					"download":   "GET /api/files/:id?expires=&signature= (signed URL)",
+					"ticket":     "GET /api/tickets/:code?signature=&format=pdf|png|svg, GET /api/participants/:id/ticket (protected)",
+				},
+				"events": gin.H{
+					"list":               "GET /api/events",
+					"get":                "GET /api/events/:slug",
+					"register":           "POST /api/events/:slug/participants",
+					"create":             "POST /api/events (protected)",
+					"update":             "PUT /api/events/:slug (protected)",
+					"delete":             "DELETE /api/events/:slug (protected)",
+					"scoped":             "/api/events/:slug/participants/... (protected, same as /api/participants)",
+					"questions":          "GET /api/events/:slug/questions, POST|PUT|DELETE /api/events/:slug/questions[/:id] (protected)",
+					"check_in":           "POST /api/events/:slug/check-ins, POST /api/events/:slug/check-ins/:id/undo (protected)",
+					"attendance":         "GET /api/events/:slug/attendance, GET /api/events/:slug/no-shows, GET /api/events/:slug/check-ins (protected)",
+					"sessions":           "GET /api/events/:slug/sessions, POST|PUT|DELETE /api/events/:slug/sessions[/:id] (protected)",
+					"session_attendance": "GET|POST /api/events/:slug/sessions/:id/attendance, GET /api/events/:slug/sessions/report, GET /api/events/:slug/attendance/participants (protected)",
+					"certificates":       "GET|POST|PUT|DELETE /api/events/:slug/certificate-templates[/:id], POST /api/events/:slug/certificates/generate, GET /api/events/:slug/certificates (protected)",
+					"eligibility":        "POST /api/events/:slug/eligibility/evaluate (protected)",
+				},
+				"master_data": gin.H{
+					"autocomplete": "GET /api/campuses?q=, GET /api/study-programs?q=",
+					"manage":       "POST|PUT|DELETE /api/campuses[/:id], POST|PUT|DELETE /api/study-programs[/:id] (protected)",
+					"mapping":      "GET /api/master-data/unmapped/:field, POST /api/master-data/map, POST /api/master-data/auto-map (protected)",
+				},
+				"registration_challenge": "GET /api/registration-challenge (form_token, PoW & captcha untuk registrasi publik)",
+				"registration_status":    "POST /api/registrations/status (kode registrasi + 4 digit terakhir HP, rate limited)",
+				"portal": gin.H{
+					"magic_link": "POST /api/portal/magic-link, POST /api/portal/session",
+					"me":         "GET|PUT /api/portal/me, POST /api/portal/me/withdraw, GET /api/portal/me/ticket, POST /api/portal/logout (sesi portal)",
+				},
+				"webhooks": gin.H{
+					"manage":     "GET|POST /api/webhooks, GET|PUT|DELETE /api/webhooks/:id, POST /api/webhooks/:id/rotate-secret, POST /api/webhooks/:id/ping (protected)",
+					"deliveries": "GET /api/webhooks/:id/deliveries[/:deliveryId], POST /api/webhooks/:id/deliveries/:deliveryId/redeliver (protected)",
 				},
-				"health": "GET /healthz",
+				"jobs": gin.H{
+					"manage":    "GET|POST /api/jobs, GET|PUT|DELETE /api/jobs/:id, POST /api/jobs/:id/pause|resume|trigger (protected)",
+					"runs":      "GET /api/jobs/:id/runs (protected)",
+					"per_event": "GET|POST /api/events/:slug/jobs (protected)",
+				},
+				"emails": gin.H{
+					"templates": "GET /api/email-templates, PUT|DELETE /api/email-templates/:key/:lang, POST /api/email-templates/:key/:lang/preview (protected)",
+					"outbox":    "GET /api/emails[/:id], POST /api/emails/:id/retry, GET /api/participants/:id/emails (protected)",
+					"bounces":   "POST /api/mail/bounces (header X-Mail-Bounce-Token)",
+				},
+				"messaging": gin.H{
+					"templates": "GET /api/message-templates, PUT|DELETE /api/message-templates/:key, POST /api/message-templates/:key/preview (protected)",
+					"reminders": "POST /api/events/:slug/reminders (protected)",
+					"delivery":  "GET /api/participants/:id/messages, POST|DELETE /api/participants/:id/opt-out (protected)",
+					"portal":    "PUT /api/portal/me/notifications",
+					"whatsapp":  "GET|POST /api/messaging/whatsapp/webhook (callback Meta)",
+				},
+				"idempotency":        "Header Idempotency-Key pada endpoint POST pembuatan data (response pertama di-replay)",
+				"stats":              "GET /api/stats/summary, GET /api/stats/breakdown/:dimension, GET /api/stats/registrations (protected, juga /api/events/:slug/stats/...)",
+				"verify_certificate": "GET /verify/:serial",
+				"health":             "GET /healthz",
 			},
 		})
 	})
@@ -163,7 +264,27 @@ func main() {
 	log.Printf("📖 API Documentation: http://localhost:%s/", port)
 	log.Printf("❤️  Health Check: http://localhost:%s/healthz", port)
 
-	if err := engine.Run("0.0.0.0:" + port); err != nil {
-		log.Fatalf("server failed to start: %v", err)
+	srv := &http.Server{Addr: "0.0.0.0:" + port, Handler: engine}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server failed to start: %v", err)
+		}
+	}()
+	workers.Jobs.Start()
+
+	// Graceful shutdown: selesaikan request & job yang sedang berjalan sebelum keluar
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Printf("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), helpers.GetEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second))
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown error: %v", err)
+	}
+	if err := workers.Stop(ctx); err != nil {
+		log.Printf("Worker shutdown error: %v", err)
 	}
+	log.Printf("Server stopped")
 }
@@ -0,0 +1,240 @@
+package antispam
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/helpers"
+)
+
+// Alasan penolakan pendaftaran
+var (
+	ErrHoneypot         = errors.New("honeypot field diisi")
+	ErrFormTokenMissing = errors.New("form_token wajib diisi, ambil dari GET /api/registration-challenge")
+	ErrFormTokenInvalid = errors.New("form_token tidak valid atau sudah dipakai, muat ulang form")
+	ErrFormTokenExpired = errors.New("form_token sudah kedaluwarsa, muat ulang form")
+	ErrTooFast          = errors.New("form dikirim terlalu cepat, coba lagi beberapa detik lagi")
+	ErrProofOfWork      = errors.New("pow_nonce tidak valid")
+)
+
+// Config mengatur proteksi yang aktif; semua bisa diatur per environment
+type Config struct {
+	// Honeypot menolak request yang mengisi field tersembunyi "website"
+	Honeypot bool
+	// RequireFormToken mewajibkan form_token dari endpoint challenge (otomatis aktif jika MinFillTime
+	// atau PoW aktif)
+	RequireFormToken bool
+	// MinFillTime adalah waktu minimal antara form_token dibuat dan form dikirim. Hanya bisa dicek dari
+	// form_token, jadi nilai > 0 mewajibkan form_token; set 0 untuk menerima registrasi tanpa token.
+	MinFillTime time.Duration
+	// FormTokenTTL adalah umur maksimal form_token
+	FormTokenTTL time.Duration
+	// PowDifficulty adalah jumlah bit nol di awal sha256(form_token + ":" + pow_nonce), 0 = nonaktif
+	PowDifficulty int
+	// CaptchaProvider & CaptchaSiteKey diteruskan ke frontend untuk merender widget captcha
+	CaptchaProvider string
+	CaptchaSiteKey  string
+}
+
+// ConfigFromEnv membaca konfigurasi proteksi spam dari environment
+func ConfigFromEnv() Config {
+	return Config{
+		Honeypot:         helpers.GetEnvBool("ANTISPAM_HONEYPOT", true),
+		RequireFormToken: helpers.GetEnvBool("ANTISPAM_FORM_TOKEN", false),
+		MinFillTime:      minFillTimeFromEnv(),
+		FormTokenTTL:     helpers.GetEnvDuration("ANTISPAM_FORM_TOKEN_TTL", 2*time.Hour),
+		PowDifficulty:    helpers.GetEnvInt("ANTISPAM_POW_DIFFICULTY", 0),
+		CaptchaProvider:  strings.ToLower(helpers.GetEnv("CAPTCHA_PROVIDER", "none")),
+		CaptchaSiteKey:   helpers.GetEnv("CAPTCHA_SITE_KEY", ""),
+	}
+}
+
+// minFillTimeFromEnv membaca ANTISPAM_MIN_FILL_TIME. GetEnvDuration menganggap 0 sebagai tidak diisi,
+// padahal "0" dipakai untuk mematikan cek waktu isi (dan kewajiban form_token yang menyertainya).
+func minFillTimeFromEnv() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(os.Getenv("ANTISPAM_MIN_FILL_TIME"))); err == nil && d == 0 {
+		return 0
+	}
+	return helpers.GetEnvDuration("ANTISPAM_MIN_FILL_TIME", 3*time.Second)
+}
+
+// Submission adalah field proteksi spam yang dikirim bersama form registrasi
+type Submission struct {
+	Honeypot     string
+	FormToken    string
+	PowNonce     string
+	CaptchaToken string
+	RemoteIP     string
+}
+
+// Challenge dikirim ke frontend sebelum form diisi
+type Challenge struct {
+	FormToken       string `json:"form_token,omitempty"`
+	MinFillSeconds  int    `json:"min_fill_seconds"`
+	PowDifficulty   int    `json:"pow_difficulty"`
+	PowAlgorithm    string `json:"pow_algorithm,omitempty"`
+	CaptchaProvider string `json:"captcha_provider"`
+	CaptchaSiteKey  string `json:"captcha_site_key,omitempty"`
+	HoneypotField   string `json:"honeypot_field,omitempty"`
+}
+
+// Guard menjalankan semua pemeriksaan spam untuk registrasi publik
+type Guard struct {
+	Config   Config
+	Secret   []byte
+	Verifier Verifier // nil = tanpa captcha
+
+	mu   sync.Mutex
+	used map[string]time.Time // form_token yang sudah dipakai / sedang dipesan -> kedaluwarsa
+}
+
+// NewGuard membuat guard; secret dipakai untuk menandatangani form_token
+func NewGuard(config Config, secret []byte, verifier Verifier) *Guard {
+	return &Guard{Config: config, Secret: secret, Verifier: verifier, used: map[string]time.Time{}}
+}
+
+// formTokenRequired menentukan apakah form_token wajib dikirim. Waktu isi minimal, sekali pakai dan
+// proof-of-work hanya bisa dicek dari form_token; tanpa kewajiban ini bot cukup tidak mengirim token.
+func (g *Guard) formTokenRequired() bool {
+	return g.Config.RequireFormToken || g.Config.MinFillTime > 0 || g.Config.PowDifficulty > 0
+}
+
+// NewChallenge membuat form_token baru beserta konfigurasi yang perlu diketahui frontend
+func (g *Guard) NewChallenge(now time.Time) (Challenge, error) {
+	challenge := Challenge{
+		MinFillSeconds:  int(g.Config.MinFillTime / time.Second),
+		PowDifficulty:   g.Config.PowDifficulty,
+		CaptchaProvider: g.Config.CaptchaProvider,
+		CaptchaSiteKey:  g.Config.CaptchaSiteKey,
+	}
+	if g.Config.Honeypot {
+		challenge.HoneypotField = "website"
+	}
+	if g.Config.PowDifficulty > 0 {
+		challenge.PowAlgorithm = "sha256(form_token + \":\" + pow_nonce) diawali pow_difficulty bit nol"
+	}
+	nonce, err := helpers.GenerateRandomToken(12)
+	if err != nil {
+		return challenge, err
+	}
+	issued := strconv.FormatInt(now.Unix(), 10)
+	challenge.FormToken = issued + "." + nonce + "." + g.mac(issued, nonce)
+	return challenge, nil
+}
+
+func (g *Guard) mac(parts ...string) string {
+	m := hmac.New(sha256.New, g.Secret)
+	m.Write([]byte("form\n" + strings.Join(parts, "\n")))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Check menjalankan pemeriksaan sesuai konfigurasi: honeypot, form_token (waktu isi minimal & sekali pakai),
+// proof-of-work lalu captcha. form_token (beserta solusi proof-of-work-nya) langsung dipesan di sini
+// supaya request bersamaan dengan token yang sama ditolak; panggil Release jika registrasi akhirnya gagal.
+func (g *Guard) Check(ctx context.Context, sub Submission, now time.Time) error {
+	if g.Config.Honeypot && strings.TrimSpace(sub.Honeypot) != "" {
+		return ErrHoneypot
+	}
+
+	reserved := false
+	if sub.FormToken != "" || g.formTokenRequired() {
+		if sub.FormToken == "" {
+			return ErrFormTokenMissing
+		}
+		if err := g.checkFormToken(sub.FormToken, now); err != nil {
+			return err
+		}
+		if g.Config.PowDifficulty > 0 && !VerifyProof(sub.FormToken, sub.PowNonce, g.Config.PowDifficulty) {
+			return ErrProofOfWork
+		}
+		if !g.reserve(sub.FormToken, now) {
+			return ErrFormTokenInvalid
+		}
+		reserved = true
+	}
+
+	if g.Verifier != nil {
+		if err := g.Verifier.Verify(ctx, sub.CaptchaToken, sub.RemoteIP); err != nil {
+			if reserved {
+				g.Release(sub.FormToken)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFormToken memvalidasi tanda tangan dan umur form_token (tanpa memesannya)
+func (g *Guard) checkFormToken(token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || !hmac.Equal([]byte(parts[2]), []byte(g.mac(parts[0], parts[1]))) {
+		return ErrFormTokenInvalid
+	}
+	issuedUnix, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrFormTokenInvalid
+	}
+	issued := time.Unix(issuedUnix, 0)
+	if now.Sub(issued) < g.Config.MinFillTime {
+		return ErrTooFast
+	}
+	if now.Sub(issued) > g.Config.FormTokenTTL {
+		return ErrFormTokenExpired
+	}
+	return nil
+}
+
+// reserve menandai form_token sudah dipakai; false jika token sudah dipakai request lain.
+// Cek dan tandai dilakukan di bawah satu lock sehingga hanya satu request yang berhasil.
+func (g *Guard) reserve(token string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for t, expires := range g.used {
+		if now.After(expires) {
+			delete(g.used, t)
+		}
+	}
+	if _, used := g.used[token]; used {
+		return false
+	}
+	g.used[token] = now.Add(g.Config.FormTokenTTL)
+	return true
+}
+
+// Release melepas form_token yang dipesan Check. Dipanggil jika registrasi gagal (mis. data tidak valid)
+// supaya pendaftar yang salah isi form tidak perlu mengambil token baru.
+func (g *Guard) Release(token string) {
+	if token == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.used, token)
+}
+
+// VerifyProof mengecek sha256(challenge + ":" + nonce) diawali minimal difficulty bit nol
+func VerifyProof(challenge, nonce string, difficulty int) bool {
+	if nonce == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(challenge + ":" + nonce))
+	zeros := 0
+	for _, b := range sum {
+		if b == 0 {
+			zeros += 8
+			continue
+		}
+		zeros += bits.LeadingZeros8(b)
+		break
+	}
+	return zeros >= difficulty
+}
@@ -0,0 +1,193 @@
+package antispam
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestGuard(pow int, verifier Verifier) *Guard {
+	return NewGuard(Config{
+		Honeypot:         true,
+		RequireFormToken: true,
+		MinFillTime:      3 * time.Second,
+		FormTokenTTL:     time.Hour,
+		PowDifficulty:    pow,
+	}, []byte("test-secret"), verifier)
+}
+
+func challenge(t *testing.T, g *Guard, issued time.Time) string {
+	t.Helper()
+	c, err := g.NewChallenge(issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.FormToken
+}
+
+// solve mencari pow_nonce untuk form_token (difficulty kecil supaya test cepat)
+func solve(token string, difficulty int) string {
+	for i := 0; ; i++ {
+		nonce := strconv.Itoa(i)
+		if VerifyProof(token, nonce, difficulty) {
+			return nonce
+		}
+	}
+}
+
+func TestGuardFormTokenChecks(t *testing.T) {
+	g := newTestGuard(0, nil)
+	issued := time.Now().Add(-time.Minute)
+	token := challenge(t, g, issued)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		sub  Submission
+		now  time.Time
+		want error
+	}{
+		{"honeypot", Submission{Honeypot: "http://spam", FormToken: token}, time.Now(), ErrHoneypot},
+		{"missing", Submission{}, time.Now(), ErrFormTokenMissing},
+		{"tampered", Submission{FormToken: token + "0"}, time.Now(), ErrFormTokenInvalid},
+		{"too fast", Submission{FormToken: token}, issued.Add(time.Second), ErrTooFast},
+		{"expired", Submission{FormToken: token}, issued.Add(2 * time.Hour), ErrFormTokenExpired},
+	}
+	for _, tt := range tests {
+		if err := g.Check(ctx, tt.sub, tt.now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Check = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	// Penolakan di atas tidak memesan token
+	if err := g.Check(ctx, Submission{FormToken: token}, time.Now()); err != nil {
+		t.Fatalf("valid token: Check = %v", err)
+	}
+}
+
+func TestGuardFormTokenSingleUse(t *testing.T) {
+	g := newTestGuard(0, nil)
+	token := challenge(t, g, time.Now().Add(-time.Minute))
+	ctx := context.Background()
+
+	if err := g.Check(ctx, Submission{FormToken: token}, time.Now()); err != nil {
+		t.Fatalf("first Check = %v", err)
+	}
+	if err := g.Check(ctx, Submission{FormToken: token}, time.Now()); !errors.Is(err, ErrFormTokenInvalid) {
+		t.Fatalf("reused token: Check = %v, want ErrFormTokenInvalid", err)
+	}
+
+	// Registrasi gagal: token dilepas dan bisa dipakai lagi
+	g.Release(token)
+	if err := g.Check(ctx, Submission{FormToken: token}, time.Now()); err != nil {
+		t.Fatalf("after Release: Check = %v", err)
+	}
+}
+
+func TestGuardConcurrentSubmissionsReserveOnce(t *testing.T) {
+	g := newTestGuard(4, StaticVerifier{Token: "test-pass"})
+	token := challenge(t, g, time.Now().Add(-time.Minute))
+	sub := Submission{FormToken: token, PowNonce: solve(token, 4), CaptchaToken: "test-pass"}
+
+	var passed, rejected atomic.Int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			switch err := g.Check(context.Background(), sub, time.Now()); {
+			case err == nil:
+				passed.Add(1)
+			case errors.Is(err, ErrFormTokenInvalid):
+				rejected.Add(1)
+			default:
+				t.Errorf("Check = %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if passed.Load() != 1 || rejected.Load() != 49 {
+		t.Fatalf("passed %d, rejected %d; want exactly one submission to pass", passed.Load(), rejected.Load())
+	}
+}
+
+func TestGuardProofOfWorkAndCaptcha(t *testing.T) {
+	g := newTestGuard(8, StaticVerifier{Token: "test-pass"})
+	token := challenge(t, g, time.Now().Add(-time.Minute))
+	nonce := solve(token, 8)
+	ctx := context.Background()
+
+	if err := g.Check(ctx, Submission{FormToken: token, PowNonce: "", CaptchaToken: "test-pass"}, time.Now()); !errors.Is(err, ErrProofOfWork) {
+		t.Fatalf("missing nonce: Check = %v, want ErrProofOfWork", err)
+	}
+	// Captcha ditolak: token dilepas lagi sehingga pendaftar bisa mengulang captcha
+	if err := g.Check(ctx, Submission{FormToken: token, PowNonce: nonce, CaptchaToken: "wrong"}, time.Now()); !errors.Is(err, ErrChallengeFailed) {
+		t.Fatalf("wrong captcha: Check = %v, want ErrChallengeFailed", err)
+	}
+	if err := g.Check(ctx, Submission{FormToken: token, PowNonce: nonce, CaptchaToken: "test-pass"}, time.Now()); err != nil {
+		t.Fatalf("valid submission: Check = %v", err)
+	}
+	// Solusi proof-of-work yang sama tidak bisa dipakai ulang
+	if err := g.Check(ctx, Submission{FormToken: token, PowNonce: nonce, CaptchaToken: "test-pass"}, time.Now()); !errors.Is(err, ErrFormTokenInvalid) {
+		t.Fatalf("replayed proof: Check = %v, want ErrFormTokenInvalid", err)
+	}
+}
+
+func TestGuardWithoutFormTokenOnlyChecksCaptcha(t *testing.T) {
+	g := NewGuard(Config{Honeypot: true}, []byte("test-secret"), StaticVerifier{Token: "test-pass"})
+	if err := g.Check(context.Background(), Submission{CaptchaToken: "test-pass"}, time.Now()); err != nil {
+		t.Fatalf("Check = %v", err)
+	}
+	if err := g.Check(context.Background(), Submission{CaptchaToken: "nope"}, time.Now()); !errors.Is(err, ErrChallengeFailed) {
+		t.Fatalf("Check = %v, want ErrChallengeFailed", err)
+	}
+}
+
+func TestGuardMinFillTimeRequiresFormToken(t *testing.T) {
+	// Tanpa kewajiban token, bot bisa melewati cek waktu isi cukup dengan tidak mengirim form_token
+	g := NewGuard(Config{MinFillTime: 3 * time.Second, FormTokenTTL: time.Hour}, []byte("test-secret"), nil)
+	if err := g.Check(context.Background(), Submission{}, time.Now()); !errors.Is(err, ErrFormTokenMissing) {
+		t.Fatalf("Check without token = %v, want ErrFormTokenMissing", err)
+	}
+	token := challenge(t, g, time.Now())
+	if err := g.Check(context.Background(), Submission{FormToken: token}, time.Now()); !errors.Is(err, ErrTooFast) {
+		t.Fatalf("Check too fast = %v, want ErrTooFast", err)
+	}
+}
+
+func TestConfigFromEnvMinFillTime(t *testing.T) {
+	for value, want := range map[string]time.Duration{"": 3 * time.Second, "5s": 5 * time.Second, "0": 0, "0s": 0, "-1s": 3 * time.Second} {
+		t.Setenv("ANTISPAM_MIN_FILL_TIME", value)
+		if got := ConfigFromEnv().MinFillTime; got != want {
+			t.Errorf("ANTISPAM_MIN_FILL_TIME=%q: MinFillTime = %v, want %v", value, got, want)
+		}
+	}
+	// MIN_FILL_TIME=0 tanpa FORM_TOKEN / PoW: registrasi tanpa form_token diterima
+	t.Setenv("ANTISPAM_MIN_FILL_TIME", "0")
+	g := NewGuard(ConfigFromEnv(), []byte("test-secret"), nil)
+	if err := g.Check(context.Background(), Submission{}, time.Now()); err != nil {
+		t.Fatalf("Check without token = %v", err)
+	}
+}
+
+func TestVerifyProof(t *testing.T) {
+	if VerifyProof("token", "", 0) {
+		t.Error("empty nonce accepted")
+	}
+	nonce := solve("token", 12)
+	if !VerifyProof("token", nonce, 12) {
+		t.Error("solved nonce rejected")
+	}
+	if !VerifyProof("other-token", "x", 0) {
+		t.Error("difficulty 0 rejected a non-empty nonce")
+	}
+	if VerifyProof("token", nonce, 256+1) {
+		t.Error("difficulty above hash length accepted")
+	}
+}
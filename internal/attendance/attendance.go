@@ -0,0 +1,132 @@
+package attendance
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend/internal/models"
+)
+
+// ErrAlreadyRecorded dikembalikan jika participant sudah tercatat hadir di sesi tersebut
+var ErrAlreadyRecorded = errors.New("attendance already recorded for this session")
+
+// ExpectedStatuses adalah status participant yang diharapkan hadir (dasar perhitungan persentase)
+var ExpectedStatuses = []string{models.StatusApproved, models.StatusAttended}
+
+// Record mencatat participant hadir di sesi. Unique index (session, participant) menjaga
+// scan ganda / bulk yang bersamaan tetap menghasilkan satu baris.
+func Record(db *gorm.DB, sessionID uint, participantID, method, actor string) (*models.SessionAttendance, error) {
+	record := models.SessionAttendance{
+		SessionID:     sessionID,
+		ParticipantID: participantID,
+		Method:        method,
+		RecordedBy:    actor,
+		CreatedAt:     time.Now(),
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var existing models.SessionAttendance
+		if err := db.Where("session_id = ? AND participant_id = ?", sessionID, participantID).First(&existing).Error; err != nil {
+			return nil, err
+		}
+		return &existing, ErrAlreadyRecorded
+	}
+	return &record, nil
+}
+
+// attendedExpr menghitung jumlah sesi event yang dihadiri participant (subquery berkorelasi, portable antar driver)
+const attendedExpr = "(SELECT COUNT(*) FROM session_attendances sa JOIN sessions s ON s.id = sa.session_id " +
+	"WHERE sa.participant_id = participants.id AND s.event_id = participants.event_id)"
+
+// Summary adalah rekap kehadiran satu participant di seluruh sesi event
+type Summary struct {
+	ParticipantID    string  `json:"participant_id"`
+	Name             string  `json:"name"`
+	RegistrationCode *string `json:"registration_code"`
+	Status           string  `json:"status"`
+	Attended         int64   `json:"attended"`
+	TotalSessions    int64   `json:"total_sessions"`
+	Percentage       float64 `json:"percentage"`
+}
+
+// TotalSessions menghitung jumlah sesi event
+func TotalSessions(db *gorm.DB, eventID uint) (int64, error) {
+	var total int64
+	err := db.Model(&models.Session{}).Where("event_id = ?", eventID).Count(&total).Error
+	return total, err
+}
+
+// SummaryQuery membuat query rekap kehadiran participant yang diharapkan hadir di event.
+// minPercentage > 0 hanya mengambil participant dengan kehadiran minimal sekian persen dari totalSessions.
+func SummaryQuery(db *gorm.DB, eventID uint, totalSessions int64, minPercentage float64) *gorm.DB {
+	query := db.Model(&models.Participant{}).
+		Select("participants.id AS participant_id, participants.name, participants.registration_code, participants.status, "+
+			attendedExpr+" AS attended").
+		Where("participants.event_id = ? AND participants.status IN ?", eventID, ExpectedStatuses)
+	return AttendedAtLeast(query, totalSessions, minPercentage)
+}
+
+// AttendedAtLeast membatasi query participant ke yang kehadiran sesinya minimal minPercentage persen.
+// minPercentage 0 tidak menambah filter.
+func AttendedAtLeast(query *gorm.DB, totalSessions int64, minPercentage float64) *gorm.DB {
+	if minPercentage <= 0 {
+		return query
+	}
+	return query.Where(attendedExpr+" >= ?", RequiredSessions(totalSessions, minPercentage))
+}
+
+// RequiredSessions menghitung jumlah sesi minimal untuk mencapai persentase kehadiran
+func RequiredSessions(totalSessions int64, minPercentage float64) int64 {
+	return int64(math.Ceil(float64(totalSessions) * minPercentage / 100))
+}
+
+// Fill menghitung persentase kehadiran dari hasil SummaryQuery
+func Fill(summaries []Summary, totalSessions int64) {
+	for i := range summaries {
+		summaries[i].TotalSessions = totalSessions
+		summaries[i].Percentage = Percentage(summaries[i].Attended, totalSessions)
+	}
+}
+
+// Percentage menghitung persentase dengan satu angka di belakang koma
+func Percentage(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*1000) / 10
+}
+
+// Turnout adalah rekap kehadiran satu sesi
+type Turnout struct {
+	models.Session
+	Attended int64   `json:"attended"`
+	Expected int64   `json:"expected"`
+	Turnout  float64 `json:"turnout"` // persen dari participant yang diharapkan hadir
+}
+
+// SessionTurnout menghitung jumlah hadir per sesi event, urut sesuai jadwal
+func SessionTurnout(db *gorm.DB, eventID uint) ([]Turnout, error) {
+	var expected int64
+	if err := db.Model(&models.Participant{}).
+		Where("event_id = ? AND status IN ?", eventID, ExpectedStatuses).Count(&expected).Error; err != nil {
+		return nil, err
+	}
+	var rows []Turnout
+	if err := db.Model(&models.Session{}).
+		Select("sessions.*, (SELECT COUNT(*) FROM session_attendances sa WHERE sa.session_id = sessions.id) AS attended").
+		Where("event_id = ?", eventID).Order("starts_at asc, id asc").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Expected = expected
+		rows[i].Turnout = Percentage(rows[i].Attended, expected)
+	}
+	return rows, nil
+}
@@ -0,0 +1,353 @@
+package certificates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/attendance"
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/outbox"
+	"backend/internal/storage"
+)
+
+const (
+	// batchSize adalah jumlah sertifikat yang diambil worker per putaran
+	batchSize = 20
+	// leaseDuration adalah batas waktu render satu sertifikat sebelum boleh diambil instance lain
+	leaseDuration = 5 * time.Minute
+	// pollInterval adalah jeda worker mengecek sertifikat pending / lease yang habis jika tidak di-Kick
+	pollInterval = time.Minute
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// CheckTemplate memvalidasi desain dan aturan sertifikat
+func CheckTemplate(tpl models.CertificateTemplate) error {
+	for i, f := range tpl.Fields {
+		if f.Color != "" && !colorPattern.MatchString(f.Color) {
+			return fmt.Errorf("fields[%d].color harus format #RRGGBB", i)
+		}
+		if f.X < 0 || f.Y < 0 || f.Width < 0 || f.FontSize < 0 || f.FontSize > 200 {
+			return fmt.Errorf("fields[%d] posisi / ukuran tidak valid", i)
+		}
+	}
+	if tpl.QRX < 0 || tpl.QRY < 0 || tpl.QRSize < 0 {
+		return errors.New("posisi / ukuran QR tidak valid")
+	}
+	for _, s := range tpl.Rules.Statuses {
+		if !models.IsValidParticipantStatus(s) {
+			return fmt.Errorf("rules.statuses: status %q tidak dikenal", s)
+		}
+	}
+	if tpl.Rules.MinAttendance < 0 || tpl.Rules.MinAttendance > 100 {
+		return errors.New("rules.min_attendance harus 0-100")
+	}
+	return nil
+}
+
+// eligibleStatuses mengembalikan status yang berhak sertifikat, default hanya yang hadir
+func eligibleStatuses(rules models.CertificateRules) []string {
+	if len(rules.Statuses) == 0 {
+		return []string{models.StatusAttended}
+	}
+	return rules.Statuses
+}
+
+// EligibleQuery membuat query participant event yang memenuhi aturan template
+func EligibleQuery(db *gorm.DB, tpl models.CertificateTemplate) (*gorm.DB, error) {
+	query := db.Model(&models.Participant{}).
+		Where("participants.event_id = ? AND participants.status IN ?", tpl.EventID, eligibleStatuses(tpl.Rules))
+	if tpl.Rules.MinAttendance > 0 {
+		total, err := attendance.TotalSessions(db, tpl.EventID)
+		if err != nil {
+			return nil, err
+		}
+		query = attendance.AttendedAtLeast(query, total, tpl.Rules.MinAttendance)
+	}
+	return query, nil
+}
+
+// generateSerial membuat nomor seri unik, mis. "YC-2026-K7QM-3XPA"
+func generateSerial(tx *gorm.DB, year int) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := helpers.GenerateRegistrationCode()
+		if err != nil {
+			return "", err
+		}
+		serial := "YC-" + strconv.Itoa(year) + "-" + code
+		var count int64
+		if err := tx.Model(&models.Certificate{}).Where("serial = ?", serial).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return serial, nil
+		}
+	}
+	return "", errors.New("gagal membuat nomor seri sertifikat unik")
+}
+
+// Enqueue membuat sertifikat pending untuk participant yang berhak dan belum punya sertifikat
+// dari template ini. Rendering dilakukan Worker di background.
+func Enqueue(db *gorm.DB, tpl models.CertificateTemplate, event models.Event, actor string) (*models.CertificateBatch, error) {
+	batch := models.CertificateBatch{EventID: tpl.EventID, TemplateID: tpl.ID, CreatedBy: actor, CreatedAt: time.Now()}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query, err := EligibleQuery(tx, tpl)
+		if err != nil {
+			return err
+		}
+		var ids []string
+		if err := query.Where("NOT EXISTS (SELECT 1 FROM certificates c WHERE c.participant_id = participants.id AND c.template_id = ?)", tpl.ID).
+			Order("participants.name asc").Pluck("participants.id", &ids).Error; err != nil {
+			return err
+		}
+		batch.Total = len(ids)
+		if len(ids) == 0 {
+			now := time.Now()
+			batch.FinishedAt = &now
+		}
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, id := range ids {
+			serial, err := generateSerial(tx, event.StartDate.Year())
+			if err != nil {
+				return err
+			}
+			cert := models.Certificate{
+				Serial:        serial,
+				EventID:       tpl.EventID,
+				TemplateID:    tpl.ID,
+				ParticipantID: id,
+				BatchID:       &batch.ID,
+				Status:        models.CertificatePending,
+				IssuedAt:      now,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+			if err := tx.Create(&cert).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// Placeholders adalah nilai placeholder teks sertifikat
+func Placeholders(p models.Participant, e models.Event, c models.Certificate) map[string]string {
+	values := map[string]string{
+		"name":           p.Name,
+		"place":          p.Place,
+		"kampus":         p.Kampus,
+		"jurusan":        p.Jurusan,
+		"angkatan":       p.Angkatan,
+		"event_name":     e.Name,
+		"event_location": e.Location,
+		"event_date":     e.StartDate.Format("02 January 2006"),
+		"serial":         c.Serial,
+		"issued_date":    c.IssuedAt.Format("02 January 2006"),
+	}
+	if !e.EndDate.IsZero() && !e.EndDate.Equal(e.StartDate) {
+		values["event_date"] += " - " + e.EndDate.Format("02 January 2006")
+	}
+	if p.RegistrationCode != nil {
+		values["registration_code"] = *p.RegistrationCode
+	}
+	return values
+}
+
+// Worker merender sertifikat pending menjadi PDF di storage di background. Sertifikat diambil
+// dengan update bersyarat (pending -> rendering + lease) sehingga aman dijalankan di beberapa
+// instance sekaligus; jika proses mati di tengah render, sertifikat diambil lagi setelah lease habis.
+type Worker struct {
+	*outbox.Loop
+	DB      *gorm.DB
+	Storage storage.Storage
+	// VerifyURL membuat link verifikasi publik yang dimasukkan ke QR sertifikat
+	VerifyURL func(serial string) string
+	// OnGenerated (opsional) dipanggil sekali setelah sertifikat dari generate massal selesai
+	// dirender, tidak dipanggil saat regenerate
+	OnGenerated func(cert models.Certificate)
+}
+
+// NewWorker membuat worker; panggil Start untuk menjalankannya dan Kick setelah Enqueue
+func NewWorker(db *gorm.DB, store storage.Storage, verifyURL func(serial string) string, onGenerated func(cert models.Certificate)) *Worker {
+	w := &Worker{DB: db, Storage: store, VerifyURL: verifyURL, OnGenerated: onGenerated}
+	w.Loop = outbox.NewLoop("Certificate worker", batchSize, pollInterval, w.processPending)
+	return w
+}
+
+// ProcessPending merender satu putaran sertifikat pending, mengembalikan jumlah yang diambil
+func (w *Worker) ProcessPending() (int, error) {
+	return w.processPending(context.Background())
+}
+
+// processPending merender sertifikat pending dan sertifikat rendering yang lease-nya sudah habis
+func (w *Worker) processPending(ctx context.Context) (int, error) {
+	now := time.Now()
+	var certs []models.Certificate
+	if err := w.DB.Where("status = ? OR (status = ? AND lease_until < ?)", models.CertificatePending, models.CertificateRendering, now).
+		Order("created_at asc, id asc").Limit(batchSize).Find(&certs).Error; err != nil {
+		return 0, err
+	}
+	cache := map[uint]*templateAssets{}
+	for i := range certs {
+		// Saat Stop, sertifikat yang belum diambil ditinggal untuk instance lain / server start berikutnya
+		if ctx.Err() != nil {
+			break
+		}
+		if !w.claim(&certs[i], now) {
+			continue
+		}
+		key, err := w.render(&certs[i], cache)
+		if err != nil {
+			log.Printf("ERROR: Gagal membuat sertifikat %s: %v", certs[i].Serial, err)
+			w.finishRendering(&certs[i], map[string]interface{}{"status": models.CertificateFailed, "error": err.Error()})
+			continue
+		}
+		done, err := w.finishRendering(&certs[i], generatedColumns(&certs[i], key))
+		if err != nil || !done {
+			continue
+		}
+		if w.OnGenerated != nil {
+			w.OnGenerated(certs[i])
+		}
+	}
+	if err := w.finishBatches(); err != nil {
+		return len(certs), err
+	}
+	return len(certs), nil
+}
+
+// claim mengubah sertifikat menjadi rendering dengan lease sebelum dirender supaya instance lain
+// (atau putaran berikutnya) tidak merender dan mengirim email "sertifikat siap" dua kali
+func (w *Worker) claim(cert *models.Certificate, now time.Time) bool {
+	lease := now.Add(leaseDuration)
+	result := w.DB.Model(cert).
+		Where("status = ? OR (status = ? AND lease_until < ?)", models.CertificatePending, models.CertificateRendering, now).
+		UpdateColumns(map[string]interface{}{"status": models.CertificateRendering, "lease_until": lease, "updated_at": now})
+	if result.Error != nil {
+		log.Printf("ERROR: Certificate worker: gagal mengambil sertifikat %s: %v", cert.Serial, result.Error)
+		return false
+	}
+	if result.RowsAffected != 1 {
+		return false
+	}
+	cert.Status = models.CertificateRendering
+	cert.LeaseUntil = &lease
+	return true
+}
+
+// finishRendering menyimpan hasil render jika sertifikat masih rendering. Jika lease sudah habis
+// dan instance lain lebih dulu selesai, hasil ini dibuang (done = false).
+func (w *Worker) finishRendering(cert *models.Certificate, columns map[string]interface{}) (bool, error) {
+	columns["lease_until"] = nil
+	columns["updated_at"] = time.Now()
+	result := w.DB.Model(cert).Where("status = ?", models.CertificateRendering).UpdateColumns(columns)
+	if result.Error != nil {
+		log.Printf("ERROR: Certificate worker: gagal menyimpan sertifikat %s: %v", cert.Serial, result.Error)
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// templateAssets adalah template + gambar latar yang sudah diambil dari storage
+type templateAssets struct {
+	template   models.CertificateTemplate
+	event      models.Event
+	background []byte
+}
+
+func (w *Worker) loadAssets(templateID uint, cache map[uint]*templateAssets) (*templateAssets, error) {
+	if a, ok := cache[templateID]; ok {
+		return a, nil
+	}
+	a := &templateAssets{}
+	if err := w.DB.First(&a.template, templateID).Error; err != nil {
+		return nil, err
+	}
+	if err := w.DB.First(&a.event, a.template.EventID).Error; err != nil {
+		return nil, err
+	}
+	if a.template.BackgroundKey != nil {
+		body, err := w.Storage.Get(context.Background(), *a.template.BackgroundKey)
+		if err != nil {
+			return nil, fmt.Errorf("gambar latar: %w", err)
+		}
+		defer body.Close()
+		if a.background, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+	cache[templateID] = a
+	return a, nil
+}
+
+// Generate merender ulang satu sertifikat (mis. setelah template diperbaiki)
+func (w *Worker) Generate(cert *models.Certificate) error {
+	key, err := w.render(cert, map[uint]*templateAssets{})
+	if err != nil {
+		return err
+	}
+	columns := generatedColumns(cert, key)
+	columns["updated_at"] = time.Now()
+	return w.DB.Model(cert).UpdateColumns(columns).Error
+}
+
+// render membuat PDF sertifikat dan menyimpannya ke storage, mengembalikan storage key
+func (w *Worker) render(cert *models.Certificate, cache map[uint]*templateAssets) (string, error) {
+	assets, err := w.loadAssets(cert.TemplateID, cache)
+	if err != nil {
+		return "", err
+	}
+	var participant models.Participant
+	if err := w.DB.Where("id = ?", cert.ParticipantID).First(&participant).Error; err != nil {
+		return "", err
+	}
+
+	pdf, err := Render(assets.template, assets.background, Placeholders(participant, assets.event, *cert), w.VerifyURL(cert.Serial))
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("certificates/%d/%s.pdf", cert.EventID, cert.Serial)
+	if err := w.Storage.Put(context.Background(), key, bytes.NewReader(pdf), int64(len(pdf)), "application/pdf"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// generatedColumns menandai cert selesai dirender dan mengembalikan kolom yang perlu diupdate
+func generatedColumns(cert *models.Certificate, key string) map[string]interface{} {
+	now := time.Now()
+	cert.Status = models.CertificateGenerated
+	cert.StorageKey = &key
+	cert.Error = ""
+	cert.GeneratedAt = &now
+	cert.LeaseUntil = nil
+	return map[string]interface{}{
+		"status": cert.Status, "storage_key": key, "error": "", "generated_at": now, "lease_until": nil,
+	}
+}
+
+// finishBatches menandai batch selesai jika tidak ada lagi sertifikat pending / rendering di dalamnya
+func (w *Worker) finishBatches() error {
+	return w.DB.Model(&models.CertificateBatch{}).
+		Where("finished_at IS NULL AND NOT EXISTS (SELECT 1 FROM certificates c WHERE c.batch_id = certificate_batches.id AND c.status IN ?)",
+			[]string{models.CertificatePending, models.CertificateRendering}).
+		UpdateColumn("finished_at", time.Now()).Error
+}
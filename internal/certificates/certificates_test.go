@@ -0,0 +1,127 @@
+package certificates
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+	"backend/internal/testutil"
+)
+
+// setup membuat event dengan template dan n participant yang hadir, lalu mengantrikan sertifikatnya
+func setup(t *testing.T, n int) (*gorm.DB, *models.CertificateBatch) {
+	t.Helper()
+	db := testutil.OpenDB(t, &models.Event{}, &models.Participant{}, &models.CertificateTemplate{},
+		&models.Certificate{}, &models.CertificateBatch{})
+	event := testutil.CreateEvent(t, db)
+	tpl := models.CertificateTemplate{
+		EventID: event.ID, Name: "Sertifikat Peserta", Orientation: "L", PageSize: "A4",
+		Fields: []models.CertificateField{{Text: "{name}", Y: 90}},
+	}
+	if err := db.Create(&tpl).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		testutil.CreateParticipant(t, db, "Peserta", func(p *models.Participant) {
+			p.EventID = &event.ID
+			p.Status = models.StatusAttended
+		})
+	}
+	batch, err := Enqueue(db, tpl, event, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Total != n {
+		t.Fatalf("batch total = %d, want %d", batch.Total, n)
+	}
+	return db, batch
+}
+
+// newWorker membuat worker yang mencatat sertifikat yang dilaporkan lewat OnGenerated
+func newWorker(t *testing.T, db *gorm.DB, mu *sync.Mutex, notified map[string]int) *Worker {
+	store := &storage.LocalStorage{Dir: t.TempDir()}
+	return NewWorker(db, store, func(serial string) string { return "https://example.com/verify/" + serial },
+		func(cert models.Certificate) {
+			mu.Lock()
+			notified[cert.ID]++
+			mu.Unlock()
+		})
+}
+
+func TestConcurrentWorkersRenderOnce(t *testing.T) {
+	db, batch := setup(t, 8)
+	var mu sync.Mutex
+	notified := map[string]int{}
+	// Dua instance server berbagi database yang sama
+	workers := []*Worker{newWorker(t, db, &mu, notified), newWorker(t, db, &mu, notified)}
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *Worker) {
+			defer wg.Done()
+			for {
+				n, err := w.ProcessPending()
+				if err != nil {
+					t.Errorf("ProcessPending: %v", err)
+				}
+				if n == 0 || err != nil {
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var certs []models.Certificate
+	db.Find(&certs)
+	for _, cert := range certs {
+		if cert.Status != models.CertificateGenerated || cert.StorageKey == nil || cert.LeaseUntil != nil {
+			t.Errorf("certificate %s = %s, lease %v", cert.Serial, cert.Status, cert.LeaseUntil)
+		}
+		if notified[cert.ID] != 1 {
+			t.Errorf("certificate %s notified %d times, want once", cert.Serial, notified[cert.ID])
+		}
+	}
+	if len(certs) != 8 || len(notified) != 8 {
+		t.Errorf("certificates = %d, notified = %d; want 8", len(certs), len(notified))
+	}
+	db.First(batch, batch.ID)
+	if batch.FinishedAt == nil {
+		t.Error("batch not finished")
+	}
+}
+
+func TestWorkerRespectsLease(t *testing.T) {
+	db, batch := setup(t, 1)
+	var mu sync.Mutex
+	notified := map[string]int{}
+	w := newWorker(t, db, &mu, notified)
+
+	// Sertifikat sedang dirender instance lain: tidak diambil selama lease masih berlaku
+	lease := time.Now().Add(time.Minute)
+	db.Model(&models.Certificate{}).Where("batch_id = ?", batch.ID).
+		UpdateColumns(map[string]interface{}{"status": models.CertificateRendering, "lease_until": lease})
+	if n, _ := w.ProcessPending(); n != 0 {
+		t.Fatalf("ProcessPending with active lease = %d", n)
+	}
+	db.First(batch, batch.ID)
+	if batch.FinishedAt != nil {
+		t.Fatal("batch finished while a certificate is still rendering")
+	}
+
+	// Instance tersebut mati: setelah lease habis sertifikat diambil alih
+	db.Model(&models.Certificate{}).Where("batch_id = ?", batch.ID).UpdateColumn("lease_until", time.Now().Add(-time.Second))
+	if n, err := w.ProcessPending(); n != 1 || err != nil {
+		t.Fatalf("ProcessPending after lease expired = %d, %v", n, err)
+	}
+	var cert models.Certificate
+	db.Where("batch_id = ?", batch.ID).First(&cert)
+	if cert.Status != models.CertificateGenerated || len(notified) != 1 {
+		t.Errorf("certificate = %s, notified = %v", cert.Status, notified)
+	}
+}
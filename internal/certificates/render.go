@@ -0,0 +1,147 @@
+package certificates
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"backend/internal/models"
+	"backend/internal/tickets"
+	"backend/internal/uploads"
+)
+
+// Nilai default desain sertifikat
+const (
+	defaultFontSize = 12.0
+	defaultQRSize   = 25.0
+	qrMargin        = 10.0
+	ptToMM          = 0.3528
+)
+
+// ErrInvalidBackground dikembalikan jika gambar latar bukan JPEG / PNG
+var ErrInvalidBackground = errors.New("gambar latar sertifikat harus JPEG atau PNG")
+
+// Render membuat PDF sertifikat satu halaman: gambar latar (boleh nil), teks dengan placeholder
+// yang sudah diisi values, dan QR berisi qrContent (link verifikasi).
+func Render(tpl models.CertificateTemplate, background []byte, values map[string]string, qrContent string) ([]byte, error) {
+	orientation := tpl.Orientation
+	if orientation != "P" {
+		orientation = "L"
+	}
+	pageSize := tpl.PageSize
+	if pageSize == "" {
+		pageSize = "A4"
+	}
+	pdf := gofpdf.New(orientation, "mm", pageSize, "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetTitle("Sertifikat "+values["serial"], true)
+	pdf.SetCreator("Youth College", true)
+	pdf.AddPage()
+	pageW, pageH := pdf.GetPageSize()
+
+	if len(background) > 0 {
+		imageType := ""
+		switch uploads.Sniff(background) {
+		case uploads.TypeJPEG:
+			imageType = "JPG"
+		case uploads.TypePNG:
+			imageType = "PNG"
+		default:
+			return nil, ErrInvalidBackground
+		}
+		opts := gofpdf.ImageOptions{ImageType: imageType}
+		pdf.RegisterImageOptionsReader("background", opts, bytes.NewReader(background))
+		pdf.ImageOptions("background", 0, 0, pageW, pageH, false, opts, 0, "")
+	}
+
+	// Font bawaan PDF hanya cp1252, karakter non-latin diterjemahkan semampunya
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	fill := placeholderReplacer(values)
+	for _, f := range tpl.Fields {
+		size := f.FontSize
+		if size <= 0 {
+			size = defaultFontSize
+		}
+		pdf.SetFont(fontFamily(f.Font), fontStyle(f.Style), size)
+		r, g, b := parseColor(f.Color)
+		pdf.SetTextColor(r, g, b)
+		width := f.Width
+		if width <= 0 {
+			width = pageW - f.X
+		}
+		align := strings.ToUpper(f.Align)
+		if align != "L" && align != "R" {
+			align = "C"
+		}
+		pdf.SetXY(f.X, f.Y)
+		pdf.MultiCell(width, size*ptToMM*1.25, tr(fill.Replace(f.Text)), "", align, false)
+	}
+
+	if qrContent != "" {
+		size := tpl.QRSize
+		if size <= 0 {
+			size = defaultQRSize
+		}
+		x, y := tpl.QRX, tpl.QRY
+		if x == 0 && y == 0 {
+			x, y = pageW-size-qrMargin, pageH-size-qrMargin
+		}
+		qr, err := tickets.QRPNG(qrContent, 512)
+		if err != nil {
+			return nil, err
+		}
+		opts := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader("qr", opts, bytes.NewReader(qr))
+		pdf.ImageOptions("qr", x, y, size, size, false, opts, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// placeholderReplacer mengganti {key} dengan nilainya
+func placeholderReplacer(values map[string]string) *strings.Replacer {
+	pairs := make([]string, 0, len(values)*2)
+	for k, v := range values {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+func fontFamily(name string) string {
+	switch strings.ToLower(name) {
+	case "times":
+		return "Times"
+	case "courier":
+		return "Courier"
+	}
+	return "Helvetica"
+}
+
+func fontStyle(style string) string {
+	switch strings.ToUpper(style) {
+	case "B", "I", "BI", "IB":
+		return strings.ToUpper(style)
+	}
+	return ""
+}
+
+// parseColor membaca warna #RRGGBB, hitam jika tidak valid
+func parseColor(hex string) (int, int, int) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return 0, 0, 0
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)
+}
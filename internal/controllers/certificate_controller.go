@@ -0,0 +1,543 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/certificates"
+	"backend/internal/emails"
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/storage"
+	"backend/internal/uploads"
+)
+
+type CertificateController struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+	Worker  *certificates.Worker
+}
+
+// NewCertificateWorker membuat worker render sertifikat. Participant diberi tahu lewat email setiap
+// kali sertifikatnya selesai dibuat.
+func NewCertificateWorker(db *gorm.DB, store storage.Storage, outbox *emails.Queue) *certificates.Worker {
+	return certificates.NewWorker(db, store, certificateVerifyURL, outbox.NotifyCertificate)
+}
+
+// NewCertificateController membuat instance controller baru
+func NewCertificateController(db *gorm.DB, store storage.Storage, worker *certificates.Worker) *CertificateController {
+	return &CertificateController{DB: db, Storage: store, Worker: worker}
+}
+
+// certificateVerifyURL adalah link verifikasi publik yang dicetak sebagai QR di sertifikat
+func certificateVerifyURL(serial string) string {
+	return getAppBaseURL() + "/verify/" + serial
+}
+
+// applyCertificateTemplateForm menyalin form ke template lalu memvalidasinya
+func applyCertificateTemplateForm(form forms.CertificateTemplateForm, tpl *models.CertificateTemplate) error {
+	tpl.Name = form.Name
+	tpl.Orientation = form.Orientation
+	if tpl.Orientation == "" {
+		tpl.Orientation = "L"
+	}
+	tpl.PageSize = form.PageSize
+	if tpl.PageSize == "" {
+		tpl.PageSize = "A4"
+	}
+	tpl.Fields = form.Fields
+	if tpl.Fields == nil {
+		tpl.Fields = []models.CertificateField{}
+	}
+	tpl.QRX = form.QRX
+	tpl.QRY = form.QRY
+	tpl.QRSize = form.QRSize
+	tpl.Rules = form.Rules
+	return certificates.CheckTemplate(*tpl)
+}
+
+// findTemplate mengambil template sertifikat milik event di context
+func (cc *CertificateController) findTemplate(c *gin.Context) (*models.CertificateTemplate, bool) {
+	var tpl models.CertificateTemplate
+	if err := cc.DB.Where("id = ? AND event_id = ?", c.Param("id"), eventFromContext(c).ID).First(&tpl).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Certificate template not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &tpl, true
+}
+
+// findCertificate mengambil sertifikat milik event di context
+func (cc *CertificateController) findCertificate(c *gin.Context) (*models.Certificate, bool) {
+	var cert models.Certificate
+	if err := cc.DB.Where("id = ? AND event_id = ?", c.Param("id"), eventFromContext(c).ID).First(&cert).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Certificate not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &cert, true
+}
+
+// GetTemplates mengambil template sertifikat event (protected)
+func (cc *CertificateController) GetTemplates(c *gin.Context) {
+	var templates []models.CertificateTemplate
+	if err := cc.DB.Where("event_id = ?", eventFromContext(c).ID).Order("id asc").Find(&templates).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Certificate templates retrieved successfully", gin.H{"templates": templates})
+}
+
+// CreateTemplate membuat template sertifikat (protected)
+func (cc *CertificateController) CreateTemplate(c *gin.Context) {
+	var form forms.CertificateTemplateForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	tpl := models.CertificateTemplate{EventID: eventFromContext(c).ID}
+	if err := applyCertificateTemplateForm(form, &tpl); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if err := cc.DB.Create(&tpl).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseCreated(c, "Certificate template created successfully", tpl)
+}
+
+// UpdateTemplate mengubah template sertifikat. Sertifikat yang sudah dibuat tidak ikut berubah
+// sampai di-generate ulang (protected).
+func (cc *CertificateController) UpdateTemplate(c *gin.Context) {
+	tpl, ok := cc.findTemplate(c)
+	if !ok {
+		return
+	}
+	var form forms.CertificateTemplateForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if err := applyCertificateTemplateForm(form, tpl); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if err := cc.DB.Save(tpl).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Certificate template updated successfully", tpl)
+}
+
+// DeleteTemplate menghapus template yang belum dipakai menerbitkan sertifikat (protected)
+func (cc *CertificateController) DeleteTemplate(c *gin.Context) {
+	tpl, ok := cc.findTemplate(c)
+	if !ok {
+		return
+	}
+	var issued int64
+	if err := cc.DB.Model(&models.Certificate{}).Where("template_id = ?", tpl.ID).Count(&issued).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if issued > 0 {
+		helpers.ResponseConflict(c, fmt.Sprintf("Template sudah dipakai untuk %d sertifikat", issued))
+		return
+	}
+	if err := cc.DB.Delete(tpl).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if tpl.BackgroundKey != nil {
+		if err := cc.Storage.Delete(context.Background(), *tpl.BackgroundKey); err != nil {
+			log.Printf("ERROR: Gagal menghapus gambar latar template %d: %v", tpl.ID, err)
+		}
+	}
+	helpers.ResponseSuccess(c, "Certificate template deleted successfully", nil)
+}
+
+// UploadTemplateBackground mengupload gambar latar template (JPEG / PNG, multipart field "file") (protected)
+func (cc *CertificateController) UploadTemplateBackground(c *gin.Context) {
+	tpl, ok := cc.findTemplate(c)
+	if !ok {
+		return
+	}
+	data, _, ok := readFormFile(c, getUploadMaxSize())
+	if !ok {
+		return
+	}
+	contentType := uploads.Sniff(data)
+	if !uploads.IsImage(contentType) {
+		helpers.ResponseError(c, http.StatusUnsupportedMediaType, certificates.ErrInvalidBackground.Error())
+		return
+	}
+
+	key := fmt.Sprintf("certificates/templates/%d/background%s", tpl.ID, uploads.Extension(contentType))
+	if err := cc.Storage.Put(c.Request.Context(), key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		helpers.ResponseInternalServerError(c, "Gagal menyimpan gambar latar: "+err.Error())
+		return
+	}
+	old := tpl.BackgroundKey
+	if err := cc.DB.Model(tpl).UpdateColumns(map[string]interface{}{"background_key": key, "updated_at": time.Now()}).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if old != nil && *old != key {
+		if err := cc.Storage.Delete(context.Background(), *old); err != nil {
+			log.Printf("ERROR: Gagal menghapus gambar latar lama %s: %v", *old, err)
+		}
+	}
+	tpl.BackgroundKey = &key
+	tpl.HasBackground = true
+	helpers.ResponseSuccess(c, "Certificate background uploaded successfully", tpl)
+}
+
+// PreviewTemplate merender contoh sertifikat untuk mengecek posisi teks (protected).
+// Query participant_id memakai data participant tersebut, selain itu data contoh.
+func (cc *CertificateController) PreviewTemplate(c *gin.Context) {
+	tpl, ok := cc.findTemplate(c)
+	if !ok {
+		return
+	}
+	event := eventFromContext(c)
+	participant := models.Participant{Name: "Nama Peserta", Place: "Kota", Kampus: "Nama Kampus", Jurusan: "Jurusan", Angkatan: "2024"}
+	if id := c.Query("participant_id"); id != "" {
+		if err := cc.DB.Where("id = ? AND event_id = ?", id, event.ID).First(&participant).Error; err != nil {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return
+		}
+	}
+	sample := models.Certificate{Serial: "YC-" + strconv.Itoa(event.StartDate.Year()) + "-CONT-0H00", IssuedAt: time.Now()}
+
+	background, err := cc.loadBackground(tpl)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	pdf, err := certificates.Render(*tpl, background, certificates.Placeholders(participant, *event, sample), certificateVerifyURL(sample.Serial))
+	if err != nil {
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Disposition", "inline; filename=\"preview.pdf\"")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// loadBackground mengambil gambar latar template dari storage (nil jika belum diupload)
+func (cc *CertificateController) loadBackground(tpl *models.CertificateTemplate) ([]byte, error) {
+	if tpl.BackgroundKey == nil {
+		return nil, nil
+	}
+	body, err := cc.Storage.Get(context.Background(), *tpl.BackgroundKey)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// GetEligible menghitung participant yang berhak dan yang sudah mendapat sertifikat dari template (protected)
+func (cc *CertificateController) GetEligible(c *gin.Context) {
+	tpl, ok := cc.findTemplate(c)
+	if !ok {
+		return
+	}
+	query, err := certificates.EligibleQuery(cc.DB, *tpl)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var eligible, issued int64
+	if err := query.Count(&eligible).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if err := cc.DB.Model(&models.Certificate{}).Where("template_id = ?", tpl.ID).Count(&issued).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Eligibility retrieved successfully", gin.H{
+		"eligible": eligible,
+		"issued":   issued,
+		"rules":    tpl.Rules,
+	})
+}
+
+// GenerateCertificates membuat sertifikat untuk semua participant yang berhak dan belum punya,
+// PDF dirender di background (protected). Progres bisa dicek lewat GetBatch.
+func (cc *CertificateController) GenerateCertificates(c *gin.Context) {
+	event := eventFromContext(c)
+	var form forms.GenerateCertificatesForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	var tpl models.CertificateTemplate
+	if err := cc.DB.Where("id = ? AND event_id = ?", form.TemplateID, event.ID).First(&tpl).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Certificate template not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	batch, err := certificates.Enqueue(cc.DB, tpl, *event, currentActor(c))
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	cc.Worker.Kick()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("%d certificates queued for generation", batch.Total),
+		"data":    batch,
+	})
+}
+
+// GetBatch mengambil progres generate sertifikat massal (protected)
+func (cc *CertificateController) GetBatch(c *gin.Context) {
+	var batch models.CertificateBatch
+	if err := cc.DB.Where("id = ? AND event_id = ?", c.Param("id"), eventFromContext(c).ID).First(&batch).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Batch not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	counts := map[string]int64{
+		models.CertificatePending: 0, models.CertificateRendering: 0, models.CertificateGenerated: 0, models.CertificateFailed: 0,
+	}
+	var rows []struct {
+		Status string
+		Total  int64
+	}
+	if err := cc.DB.Model(&models.Certificate{}).Select("status, COUNT(*) AS total").
+		Where("batch_id = ?", batch.ID).Group("status").Scan(&rows).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	for _, r := range rows {
+		counts[r.Status] = r.Total
+	}
+	helpers.ResponseSuccess(c, "Batch retrieved successfully", gin.H{
+		"batch":  batch,
+		"counts": counts,
+		"done":   batch.FinishedAt != nil,
+	})
+}
+
+// GetCertificates mengambil sertifikat event dengan filter status / template (protected)
+func (cc *CertificateController) GetCertificates(c *gin.Context) {
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	query := cc.DB.Model(&models.Certificate{}).Where("event_id = ?", eventFromContext(c).ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if templateID := c.Query("template_id"); templateID != "" {
+		query = query.Where("template_id = ?", templateID)
+	}
+	if participantID := c.Query("participant_id"); participantID != "" {
+		query = query.Where("participant_id = ?", participantID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var certs []models.Certificate
+	if err := query.Order("created_at asc, id asc").Offset((page - 1) * limit).Limit(limit).Find(&certs).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if err := cc.attachParticipants(certs); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	helpers.ResponseSuccess(c, "Certificates retrieved successfully", gin.H{
+		"certificates": certs,
+		"pagination": gin.H{
+			"current_page": page,
+			"per_page":     limit,
+			"total_items":  total,
+			"total_pages":  totalPages,
+			"has_next":     page < totalPages,
+			"has_prev":     page > 1,
+		},
+	})
+}
+
+// attachParticipants mengisi data participant untuk setiap sertifikat
+func (cc *CertificateController) attachParticipants(certs []models.Certificate) error {
+	if len(certs) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(certs))
+	for _, cert := range certs {
+		ids = append(ids, cert.ParticipantID)
+	}
+	var participants []models.Participant
+	if err := cc.DB.Where("id IN ?", ids).Find(&participants).Error; err != nil {
+		return err
+	}
+	byID := make(map[string]*models.Participant, len(participants))
+	for i := range participants {
+		byID[participants[i].ID] = &participants[i]
+	}
+	for i := range certs {
+		certs[i].Participant = byID[certs[i].ParticipantID]
+	}
+	return nil
+}
+
+// DownloadCertificate mengirim PDF sertifikat (protected)
+func (cc *CertificateController) DownloadCertificate(c *gin.Context) {
+	cert, ok := cc.findCertificate(c)
+	if !ok {
+		return
+	}
+	sendCertificatePDF(c, cc.Storage, cert)
+}
+
+// sendCertificatePDF mengirim PDF sertifikat yang sudah dirender dari storage
+func sendCertificatePDF(c *gin.Context, store storage.Storage, cert *models.Certificate) {
+	if cert.Status != models.CertificateGenerated || cert.StorageKey == nil {
+		helpers.ResponseConflict(c, "Sertifikat belum selesai dibuat (status: "+cert.Status+")")
+		return
+	}
+	body, err := store.Get(c.Request.Context(), *cert.StorageKey)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			helpers.ResponseNotFound(c, "Certificate file not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.DataFromReader(http.StatusOK, -1, "application/pdf", body, map[string]string{
+		"Content-Disposition": "inline; filename=\"sertifikat-" + cert.Serial + ".pdf\"",
+	})
+}
+
+// RegenerateCertificate merender ulang sertifikat dengan template terbaru, nomor seri tetap (protected)
+func (cc *CertificateController) RegenerateCertificate(c *gin.Context) {
+	cert, ok := cc.findCertificate(c)
+	if !ok {
+		return
+	}
+	if cert.RevokedAt != nil {
+		helpers.ResponseConflict(c, "Sertifikat sudah dicabut")
+		return
+	}
+	if err := cc.Worker.Generate(cert); err != nil {
+		cc.DB.Model(cert).UpdateColumns(map[string]interface{}{
+			"status": models.CertificateFailed, "error": err.Error(), "updated_at": time.Now(),
+		})
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, "Gagal membuat sertifikat: "+err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Certificate regenerated successfully", cert)
+}
+
+// RevokeCertificate mencabut sertifikat; verifikasi publik akan menampilkan status dicabut (protected)
+func (cc *CertificateController) RevokeCertificate(c *gin.Context) {
+	cert, ok := cc.findCertificate(c)
+	if !ok {
+		return
+	}
+	var form forms.RevokeCertificateForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if cert.RevokedAt != nil {
+		helpers.ResponseConflict(c, "Sertifikat sudah dicabut")
+		return
+	}
+	now := time.Now()
+	if err := cc.DB.Model(cert).UpdateColumns(map[string]interface{}{
+		"revoked_at": now, "revoked_reason": form.Reason, "updated_at": now,
+	}).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	cert.RevokedAt = &now
+	cert.RevokedReason = form.Reason
+	helpers.ResponseSuccess(c, "Certificate revoked successfully", cert)
+}
+
+// VerifyCertificate memverifikasi keaslian sertifikat dari nomor seri (public, tujuan QR di sertifikat).
+// Hanya data yang tercetak di sertifikat yang ditampilkan.
+func (cc *CertificateController) VerifyCertificate(c *gin.Context) {
+	serial := strings.ToUpper(strings.TrimSpace(c.Param("serial")))
+	var cert models.Certificate
+	err := cc.DB.Where("serial = ? AND status = ?", serial, models.CertificateGenerated).First(&cert).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Sertifikat dengan nomor seri ini tidak ditemukan")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var participant models.Participant
+	if err := cc.DB.Select("id", "name").Where("id = ?", cert.ParticipantID).First(&participant).Error; err != nil && err != gorm.ErrRecordNotFound {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var event models.Event
+	if err := cc.DB.First(&event, cert.EventID).Error; err != nil && err != gorm.ErrRecordNotFound {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	data := gin.H{
+		"valid":          cert.RevokedAt == nil,
+		"serial":         cert.Serial,
+		"name":           participant.Name,
+		"event_name":     event.Name,
+		"event_date":     formatEventDates(event),
+		"issued_at":      cert.IssuedAt,
+		"revoked_at":     cert.RevokedAt,
+		"revoked_reason": cert.RevokedReason,
+	}
+	if cert.RevokedAt != nil {
+		helpers.ResponseSuccess(c, "Sertifikat ini sudah dicabut", data)
+		return
+	}
+	helpers.ResponseSuccess(c, "Sertifikat asli", data)
+}
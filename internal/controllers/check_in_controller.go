@@ -0,0 +1,346 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/attendance"
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/webhooks"
+	"backend/internal/workflow"
+)
+
+type CheckInController struct {
+	DB       *gorm.DB
+	Webhooks *webhooks.Dispatcher
+}
+
+// NewCheckInController membuat instance controller baru
+func NewCheckInController(db *gorm.DB, hooks *webhooks.Dispatcher) *CheckInController {
+	return &CheckInController{DB: db, Webhooks: hooks}
+}
+
+// resolveCheckInCode mengubah hasil scan / ketikan petugas menjadi kode registrasi.
+// Isi QR diverifikasi signature-nya; kode yang diketik manual cukup dinormalisasi.
+func resolveCheckInCode(raw string) (code, method string, err error) {
+	raw = strings.TrimSpace(raw)
+	if strings.Contains(raw, ":") {
+		code, err = ticketSigner().ParsePayload(raw)
+		return code, models.CheckInMethodQR, err
+	}
+	code = helpers.NormalizeRegistrationCode(raw)
+	if code == "" {
+		return "", models.CheckInMethodCode, errors.New("kode registrasi tidak valid")
+	}
+	return code, models.CheckInMethodCode, nil
+}
+
+// CheckIn mencatat kedatangan participant dari scan QR tiket atau kode registrasi (protected, per event)
+func (cc *CheckInController) CheckIn(c *gin.Context) {
+	event := eventFromContext(c)
+	var form forms.CheckInForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	code, method, err := resolveCheckInCode(form.Code)
+	if err != nil {
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var participant models.Participant
+	if err := cc.DB.Where("registration_code = ?", code).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Kode registrasi "+code+" tidak ditemukan")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if participant.EventID == nil || *participant.EventID != event.ID {
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, "Tiket "+code+" bukan untuk event ini")
+		return
+	}
+
+	var session *models.Session
+	if form.SessionID != nil {
+		session = &models.Session{}
+		if err := cc.DB.Where("id = ? AND event_id = ?", *form.SessionID, event.ID).First(session).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				helpers.ResponseNotFound(c, "Session not found")
+				return
+			}
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+	}
+
+	gate := strings.TrimSpace(form.Gate)
+	checkIn, err := workflow.CheckIn(cc.DB, participant.ID, gate, method, currentActor(c))
+	// Scan di sesi untuk participant yang sudah check-in event bukan scan ganda, lanjut catat kehadiran sesi
+	if err != nil && !(session != nil && errors.Is(err, workflow.ErrAlreadyCheckedIn)) {
+		switch {
+		case errors.Is(err, workflow.ErrAlreadyCheckedIn):
+			helpers.ResponseConflict(c, alreadyCheckedInMessage(participant, checkIn))
+		case errors.Is(err, workflow.ErrCheckInNotAllowed):
+			helpers.ResponseError(c, http.StatusUnprocessableEntity,
+				fmt.Sprintf("%s belum bisa check-in, status pendaftaran: %s", participant.Name, participant.Status))
+		case errors.Is(err, workflow.ErrStatusChanged):
+			helpers.ResponseConflict(c, err.Error())
+		default:
+			helpers.ResponseInternalServerError(c, err.Error())
+		}
+		return
+	}
+
+	participant.Status = models.StatusAttended
+	if err == nil {
+		cc.Webhooks.PublishCheckIn(participant, checkIn)
+	}
+	if session != nil {
+		cc.recordSession(c, *session, participant, checkIn, method)
+		return
+	}
+	checkIn.Participant = &participant
+	helpers.ResponseCreated(c, "Check-in successful", checkIn)
+}
+
+// recordSession mencatat kehadiran sesi setelah participant dipastikan sudah check-in event
+func (cc *CheckInController) recordSession(c *gin.Context, session models.Session, participant models.Participant, checkIn *models.CheckIn, method string) {
+	record, err := attendance.Record(cc.DB, session.ID, participant.ID, method, currentActor(c))
+	if err != nil {
+		if errors.Is(err, attendance.ErrAlreadyRecorded) {
+			helpers.ResponseConflict(c, fmt.Sprintf("%s sudah tercatat hadir di sesi %q pada %s",
+				participant.Name, session.Title, record.CreatedAt.Format("02 Jan 2006 15:04")))
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseCreated(c, "Session check-in successful", gin.H{
+		"participant": participant,
+		"session":     session,
+		"attendance":  record,
+		"check_in":    checkIn,
+	})
+}
+
+// alreadyCheckedInMessage menjelaskan kapan, di mana dan oleh siapa participant sudah check-in
+func alreadyCheckedInMessage(participant models.Participant, existing *models.CheckIn) string {
+	msg := participant.Name + " sudah check-in"
+	if existing == nil {
+		return msg
+	}
+	msg += " pada " + existing.CheckedInAt.Format("02 Jan 2006 15:04")
+	if existing.Gate != "" {
+		msg += " di gate " + existing.Gate
+	}
+	if existing.CheckedInBy != "" {
+		msg += " oleh " + existing.CheckedInBy
+	}
+	return msg
+}
+
+// UndoCheckIn membatalkan check-in dan mengembalikan status participant ke approved (protected, per event)
+func (cc *CheckInController) UndoCheckIn(c *gin.Context) {
+	event := eventFromContext(c)
+	var form forms.UndoCheckInForm
+	// Body opsional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		helpers.ResponseNotFound(c, "Check-in not found")
+		return
+	}
+	var existing models.CheckIn
+	if err := cc.DB.Where("id = ? AND event_id = ?", id, event.ID).First(&existing).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Check-in not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	checkIn, err := workflow.UndoCheckIn(cc.DB, existing.ID, form.Reason, currentActor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, workflow.ErrNotCheckedIn):
+			helpers.ResponseConflict(c, "Check-in sudah dibatalkan sebelumnya")
+		case errors.Is(err, workflow.ErrInvalidTransition), errors.Is(err, workflow.ErrStatusChanged):
+			helpers.ResponseConflict(c, err.Error())
+		default:
+			helpers.ResponseInternalServerError(c, err.Error())
+		}
+		return
+	}
+
+	var participant models.Participant
+	if err := cc.DB.Where("id = ?", checkIn.ParticipantID).First(&participant).Error; err != nil {
+		log.Printf("ERROR: Gagal memuat participant %s untuk webhook: %v", checkIn.ParticipantID, err)
+	} else {
+		cc.Webhooks.PublishCheckInUndone(participant, checkIn)
+	}
+
+	helpers.ResponseSuccess(c, "Check-in undone successfully", checkIn)
+}
+
+// GetCheckIns mengambil check-in terbaru event, untuk layar petugas di gate (protected, per event)
+func (cc *CheckInController) GetCheckIns(c *gin.Context) {
+	event := eventFromContext(c)
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+
+	query := cc.DB.Where("event_id = ?", event.ID)
+	if c.Query("include_undone") != "true" {
+		query = query.Where("undone_at IS NULL")
+	}
+	if gate := c.Query("gate"); gate != "" {
+		query = query.Where("gate = ?", gate)
+	}
+	var checkIns []models.CheckIn
+	if err := query.Order("checked_in_at desc, id desc").Limit(limit).Find(&checkIns).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if err := cc.attachParticipants(checkIns); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	helpers.ResponseSuccess(c, "Check-ins retrieved successfully", gin.H{
+		"check_ins": checkIns,
+	})
+}
+
+// attachParticipants mengisi data participant untuk setiap check-in
+func (cc *CheckInController) attachParticipants(checkIns []models.CheckIn) error {
+	if len(checkIns) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(checkIns))
+	for _, ci := range checkIns {
+		ids = append(ids, ci.ParticipantID)
+	}
+	var participants []models.Participant
+	if err := cc.DB.Where("id IN ?", ids).Find(&participants).Error; err != nil {
+		return err
+	}
+	byID := make(map[string]*models.Participant, len(participants))
+	for i := range participants {
+		byID[participants[i].ID] = &participants[i]
+	}
+	for i := range checkIns {
+		checkIns[i].Participant = byID[checkIns[i].ParticipantID]
+	}
+	return nil
+}
+
+// GetAttendance mengambil jumlah kehadiran live per event (protected, per event)
+func (cc *CheckInController) GetAttendance(c *gin.Context) {
+	event := eventFromContext(c)
+
+	counts, err := countByStatus(cc.DB.Model(&models.Participant{}).Where("event_id = ?", event.ID))
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	checkedIn := counts[models.StatusAttended]
+	expected := checkedIn + counts[models.StatusApproved]
+
+	var gates []struct {
+		Gate  string
+		Total int64
+	}
+	if err := cc.DB.Model(&models.CheckIn{}).Select("gate, COUNT(*) AS total").
+		Where("event_id = ? AND undone_at IS NULL", event.ID).Group("gate").Scan(&gates).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	byGate := make(map[string]int64, len(gates))
+	for _, g := range gates {
+		byGate[g.Gate] = g.Total
+	}
+
+	var last models.CheckIn
+	var lastCheckInAt *time.Time
+	if err := cc.DB.Where("event_id = ? AND undone_at IS NULL", event.ID).
+		Order("checked_in_at desc").Limit(1).Find(&last).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if last.ID != 0 {
+		lastCheckInAt = &last.CheckedInAt
+	}
+
+	rate := 0.0
+	if expected > 0 {
+		rate = math.Round(float64(checkedIn)/float64(expected)*1000) / 10
+	}
+	helpers.ResponseSuccess(c, "Attendance retrieved successfully", gin.H{
+		"expected":         expected,
+		"checked_in":       checkedIn,
+		"not_checked_in":   expected - checkedIn,
+		"attendance_rate":  rate, // persen
+		"by_gate":          byGate,
+		"last_check_in_at": lastCheckInAt,
+	})
+}
+
+// GetNoShows mengambil participant approved yang belum check-in (protected, per event)
+func (cc *CheckInController) GetNoShows(c *gin.Context) {
+	event := eventFromContext(c)
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+
+	query := cc.DB.Model(&models.Participant{}).Where("event_id = ? AND status = ?", event.ID, models.StatusApproved)
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var participants []models.Participant
+	if err := query.Order("name asc, id asc").Offset((page - 1) * limit).Limit(limit).Find(&participants).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	helpers.ResponseSuccess(c, "No-shows retrieved successfully", gin.H{
+		"participants": participants,
+		"pagination": gin.H{
+			"current_page": page,
+			"per_page":     limit,
+			"total_items":  total,
+			"total_pages":  totalPages,
+			"has_next":     page < totalPages,
+			"has_prev":     page > 1,
+		},
+	})
+}
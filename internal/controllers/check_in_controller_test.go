@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+	"backend/internal/tickets"
+)
+
+// newCheckInServer mendaftarkan endpoint check-in seperti di router
+func newCheckInServer(t *testing.T) *testServer {
+	t.Setenv("TICKET_SECRET", "ticket-secret")
+	s := newTestServer(t)
+	cc := NewCheckInController(s.db, nil)
+	event := s.event()
+	event.POST("/check-ins", cc.CheckIn)
+	event.POST("/check-ins/:id/undo", cc.UndoCheckIn)
+	event.GET("/no-shows", cc.GetNoShows)
+	return s
+}
+
+// approved menyimpan participant approved untuk event
+func approved(t *testing.T, s *testServer, event models.Event, name string) models.Participant {
+	t.Helper()
+	return testutil.CreateParticipant(t, s.db, name, func(p *models.Participant) {
+		p.EventID = &event.ID
+		p.Status = models.StatusApproved
+	})
+}
+
+func TestCheckInRejectsInvalidTicketSignature(t *testing.T) {
+	s := newCheckInServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	p := approved(t, s, event, "Budi")
+	code := *p.RegistrationCode
+	forged := tickets.Signer{Secret: []byte("secret-lain")}
+
+	for _, payload := range []string{
+		forged.Payload(code),                // ditandatangani secret lain
+		"YC1:" + code + ":AAAAAAAAAAAAAAAA", // signature tebakan
+		"YC1:" + code,                       // tanpa signature
+		"YC2:" + code + ":" + ticketSigner().Sign(code),
+	} {
+		w := s.do(http.MethodPost, "/events/"+event.Slug+"/check-ins", map[string]string{"code": payload})
+		if res := decode(t, w, http.StatusUnprocessableEntity); res.Error != tickets.ErrInvalidTicket.Error() {
+			t.Errorf("%s: error = %q", payload, res.Error)
+		}
+	}
+
+	var count int64
+	s.db.Model(&models.CheckIn{}).Count(&count)
+	s.db.First(&p, "id = ?", p.ID)
+	if count != 0 || p.Status != models.StatusApproved {
+		t.Errorf("check-ins = %d, status = %s after rejected scans", count, p.Status)
+	}
+
+	// QR asli diterima
+	w := s.do(http.MethodPost, "/events/"+event.Slug+"/check-ins", map[string]string{"code": ticketSigner().Payload(code), "gate": "A"})
+	var checkIn models.CheckIn
+	decode(t, w, http.StatusCreated).into(t, &checkIn)
+	if checkIn.Method != models.CheckInMethodQR || checkIn.Gate != "A" || checkIn.CheckedInBy != "petugas" {
+		t.Errorf("check-in = %+v", checkIn)
+	}
+}
+
+func TestCheckInDuplicateScanMessage(t *testing.T) {
+	s := newCheckInServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	p := approved(t, s, event, "Budi Santoso")
+	path := "/events/" + event.Slug + "/check-ins"
+
+	w := s.do(http.MethodPost, path, map[string]string{"code": strings.ToLower(*p.RegistrationCode), "gate": "Utara"}, "X-User", "andi")
+	var first models.CheckIn
+	decode(t, w, http.StatusCreated).into(t, &first)
+	if first.Method != models.CheckInMethodCode {
+		t.Errorf("method = %s, want code", first.Method)
+	}
+
+	// Scan kedua di gate lain oleh petugas lain: 409 dengan keterangan check-in pertama
+	w = s.do(http.MethodPost, path, map[string]string{"code": ticketSigner().Payload(*p.RegistrationCode), "gate": "Selatan"}, "X-User", "sari")
+	res := decode(t, w, http.StatusConflict)
+	want := fmt.Sprintf("Budi Santoso sudah check-in pada %s di gate Utara oleh andi", first.CheckedInAt.Format("02 Jan 2006 15:04"))
+	if res.Error != want {
+		t.Errorf("error = %q, want %q", res.Error, want)
+	}
+	var count int64
+	s.db.Model(&models.CheckIn{}).Count(&count)
+	if count != 1 {
+		t.Errorf("check-ins = %d, want 1", count)
+	}
+}
+
+func TestCheckInRejectsParticipantOutsideEventOrNotApproved(t *testing.T) {
+	s := newCheckInServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+	outside := approved(t, s, other, "Citra")
+	pending := testutil.CreateParticipant(t, s.db, "Dewi", func(p *models.Participant) { p.EventID = &event.ID })
+	path := "/events/" + event.Slug + "/check-ins"
+
+	res := decode(t, s.do(http.MethodPost, path, map[string]string{"code": *outside.RegistrationCode}), http.StatusUnprocessableEntity)
+	if res.Error != "Tiket "+*outside.RegistrationCode+" bukan untuk event ini" {
+		t.Errorf("other event: %q", res.Error)
+	}
+	res = decode(t, s.do(http.MethodPost, path, map[string]string{"code": *pending.RegistrationCode}), http.StatusUnprocessableEntity)
+	if res.Error != "Dewi belum bisa check-in, status pendaftaran: pending" {
+		t.Errorf("pending: %q", res.Error)
+	}
+	decode(t, s.do(http.MethodPost, path, map[string]string{"code": "ZZZZ-ZZZZ"}), http.StatusNotFound)
+}
+
+func TestUndoCheckIn(t *testing.T) {
+	s := newCheckInServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+	p := approved(t, s, event, "Budi")
+	path := "/events/" + event.Slug + "/check-ins"
+
+	var checkIn models.CheckIn
+	decode(t, s.do(http.MethodPost, path, map[string]string{"code": *p.RegistrationCode}), http.StatusCreated).into(t, &checkIn)
+	undo := fmt.Sprintf("%s/%d/undo", path, checkIn.ID)
+
+	// Check-in event lain tidak bisa dibatalkan lewat event ini
+	decode(t, s.do(http.MethodPost, fmt.Sprintf("/events/%s/check-ins/%d/undo", other.Slug, checkIn.ID), nil), http.StatusNotFound)
+
+	var undone models.CheckIn
+	decode(t, s.do(http.MethodPost, undo, map[string]string{"reason": "Salah scan"}, "X-User", "koordinator"), http.StatusOK).into(t, &undone)
+	if undone.UndoneAt == nil || undone.UndoneBy != "koordinator" || undone.UndoReason != "Salah scan" {
+		t.Errorf("undone = %+v", undone)
+	}
+	s.db.First(&p, "id = ?", p.ID)
+	if p.Status != models.StatusApproved {
+		t.Errorf("status after undo = %s, want approved", p.Status)
+	}
+	var history models.ParticipantStatusHistory
+	s.db.Where("participant_id = ?", p.ID).Order("id desc").First(&history)
+	if history.FromStatus != models.StatusAttended || history.ToStatus != models.StatusApproved || history.Reason != "Salah scan" {
+		t.Errorf("history = %+v", history)
+	}
+
+	// Undo kedua ditolak, tanpa body memakai alasan default
+	if res := decode(t, s.do(http.MethodPost, undo, nil), http.StatusConflict); res.Error != "Check-in sudah dibatalkan sebelumnya" {
+		t.Errorf("second undo: %q", res.Error)
+	}
+
+	// Setelah dibatalkan participant bisa check-in lagi
+	decode(t, s.do(http.MethodPost, path, map[string]string{"code": *p.RegistrationCode}), http.StatusCreated)
+}
+
+func TestGetNoShows(t *testing.T) {
+	s := newCheckInServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+	for _, name := range []string{"Eka", "Andi", "Citra"} {
+		approved(t, s, event, name)
+	}
+	came := approved(t, s, event, "Budi")
+	approved(t, s, other, "Fajar")
+	testutil.CreateParticipant(t, s.db, "Gita", func(p *models.Participant) { p.EventID = &event.ID })
+	decode(t, s.do(http.MethodPost, "/events/"+event.Slug+"/check-ins", map[string]string{"code": *came.RegistrationCode}), http.StatusCreated)
+
+	var page struct {
+		Participants []models.Participant `json:"participants"`
+		Pagination   struct {
+			TotalItems int64 `json:"total_items"`
+			TotalPages int   `json:"total_pages"`
+			HasNext    bool  `json:"has_next"`
+		} `json:"pagination"`
+	}
+	decode(t, s.do(http.MethodGet, "/events/"+event.Slug+"/no-shows?limit=2", nil), http.StatusOK).into(t, &page)
+	if len(page.Participants) != 2 || page.Participants[0].Name != "Andi" || page.Participants[1].Name != "Citra" {
+		t.Errorf("page 1 = %+v", page.Participants)
+	}
+	if page.Pagination.TotalItems != 3 || page.Pagination.TotalPages != 2 || !page.Pagination.HasNext {
+		t.Errorf("pagination = %+v", page.Pagination)
+	}
+
+	decode(t, s.do(http.MethodGet, "/events/"+event.Slug+"/no-shows?limit=2&page=2", nil), http.StatusOK).into(t, &page)
+	if len(page.Participants) != 1 || page.Participants[0].Name != "Eka" || page.Pagination.HasNext {
+		t.Errorf("page 2 = %+v", page)
+	}
+}
@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/emails"
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/mailer"
+	"backend/internal/models"
+)
+
+// EmailController mengelola email transaksional: template per bahasa, outbox beserta status
+// kirim / bounce, dan laporan bounce dari server email
+type EmailController struct {
+	DB           *gorm.DB
+	Emails       *emails.Queue
+	Participants *ParticipantController
+}
+
+// NewEmailController membuat instance controller baru
+func NewEmailController(db *gorm.DB, outbox *emails.Queue, participants *ParticipantController) *EmailController {
+	return &EmailController{DB: db, Emails: outbox, Participants: participants}
+}
+
+// NewEmailQueue membuat outbox email dari environment dengan link tiket, portal dan verifikasi
+// sertifikat untuk placeholder template. Panggil Start untuk menjalankan worker.
+func NewEmailQueue(db *gorm.DB, mail mailer.Mailer) *emails.Queue {
+	outbox := emails.NewQueue(db, emails.ConfigFromEnv(), mail)
+	outbox.TicketURL = ticketURL
+	outbox.PortalURL = getPortalURL()
+	outbox.VerifyURL = certificateVerifyURL
+	return outbox
+}
+
+// emailTemplateResponse menyusun template email untuk response admin
+func emailTemplateResponse(tpl models.EmailTemplate) gin.H {
+	def := emails.Default(tpl.Key, tpl.Language)
+	return gin.H{
+		"key":        tpl.Key,
+		"language":   tpl.Language,
+		"subject":    tpl.Subject,
+		"text":       tpl.Text,
+		"html":       tpl.HTML,
+		"default":    gin.H{"subject": def.Subject, "text": def.Text, "html": def.HTML},
+		"customized": tpl.ID != 0,
+		"active":     tpl.Active,
+		"updated_by": tpl.UpdatedBy,
+		"updated_at": tpl.UpdatedAt,
+	}
+}
+
+// findEmailTemplate memastikan :key adalah jenis email dan :lang bahasa yang dikenal
+func findEmailTemplate(c *gin.Context) (string, string, bool) {
+	key, lang := c.Param("key"), c.Param("lang")
+	if _, ok := emails.DefaultTemplates[key]; !ok || !emails.SupportedLanguage(lang) {
+		helpers.ResponseNotFound(c, "Email template not found")
+		return "", "", false
+	}
+	return key, lang, true
+}
+
+// GetTemplates mengambil semua template email untuk setiap bahasa beserta placeholder yang tersedia (protected)
+func (ec *EmailController) GetTemplates(c *gin.Context) {
+	templates := []gin.H{}
+	for _, key := range emails.TemplateKeys() {
+		for _, lang := range emails.Languages {
+			tpl, err := emails.LoadTemplate(ec.DB, key, lang)
+			if err != nil {
+				helpers.ResponseInternalServerError(c, err.Error())
+				return
+			}
+			templates = append(templates, emailTemplateResponse(tpl))
+		}
+	}
+	helpers.ResponseSuccess(c, "Email templates retrieved successfully", gin.H{
+		"templates": templates,
+		"languages": emails.Languages,
+		"variables": emails.Variables,
+		"enabled":   ec.Emails.Enabled(),
+	})
+}
+
+// UpdateTemplate menyimpan template email versi admin untuk satu jenis & bahasa (protected)
+func (ec *EmailController) UpdateTemplate(c *gin.Context) {
+	key, lang, ok := findEmailTemplate(c)
+	if !ok {
+		return
+	}
+	var form forms.EmailTemplateForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	tpl, err := emails.LoadTemplate(ec.DB, key, lang)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	tpl.Subject = form.Subject
+	tpl.Text = form.Text
+	tpl.HTML = form.HTML
+	if form.Active != nil {
+		tpl.Active = *form.Active
+	}
+	tpl.UpdatedBy = currentActor(c)
+	if err := ec.DB.Save(&tpl).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Email template updated successfully", emailTemplateResponse(tpl))
+}
+
+// ResetTemplate menghapus versi admin sehingga template kembali ke teks bawaan (protected)
+func (ec *EmailController) ResetTemplate(c *gin.Context) {
+	key, lang, ok := findEmailTemplate(c)
+	if !ok {
+		return
+	}
+	if err := ec.DB.Where("template_key = ? AND language = ?", key, lang).Delete(&models.EmailTemplate{}).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	tpl, err := emails.LoadTemplate(ec.DB, key, lang)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Email template reset to default", emailTemplateResponse(tpl))
+}
+
+// PreviewTemplate merender template (tersimpan atau dari body) dengan data participant atau contoh (protected).
+// ?format=html mengembalikan versi HTML apa adanya untuk dibuka di browser.
+func (ec *EmailController) PreviewTemplate(c *gin.Context) {
+	key, lang, ok := findEmailTemplate(c)
+	if !ok {
+		return
+	}
+	var form forms.EmailPreviewForm
+	// Body opsional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+	}
+	tpl, err := emails.LoadTemplate(ec.DB, key, lang)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	content := emails.ContentOf(tpl)
+	if form.Subject != "" {
+		content.Subject = form.Subject
+	}
+	if form.Text != "" {
+		content.Text = form.Text
+	}
+	if form.HTML != "" {
+		content.HTML = form.HTML
+	}
+
+	code := "ABCD-1234"
+	participant := models.Participant{Name: "Budi Santoso", Status: models.StatusApproved, RegistrationCode: &code}
+	var event *models.Event
+	if form.ParticipantID != "" {
+		if err := ec.DB.Where("id = ?", form.ParticipantID).First(&participant).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				helpers.ResponseNotFound(c, "Participant not found")
+				return
+			}
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		if participant.EventID != nil {
+			event = &models.Event{}
+			if err := ec.DB.First(event, *participant.EventID).Error; err != nil {
+				helpers.ResponseInternalServerError(c, err.Error())
+				return
+			}
+		}
+	}
+	vars := ec.Emails.Vars(participant, event)
+	if key == emails.TemplateCertificateReady {
+		vars = ec.Emails.CertificateVars(vars, models.Certificate{Serial: "YC-2026-K7QM-3XPA"})
+	}
+	rendered := emails.Render(content, vars)
+
+	if c.Query("format") == "html" {
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(rendered.HTML))
+		return
+	}
+	helpers.ResponseSuccess(c, "Email template rendered successfully", gin.H{
+		"key":      key,
+		"language": lang,
+		"subject":  rendered.Subject,
+		"text":     rendered.Text,
+		"html":     rendered.HTML,
+	})
+}
+
+// listEmails menjalankan query outbox dengan pagination dan filter status / template_key / event_id
+func (ec *EmailController) listEmails(c *gin.Context, query *gorm.DB) {
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if key := c.Query("template_key"); key != "" {
+		query = query.Where("template_key = ?", key)
+	}
+	if eventID := c.Query("event_id"); eventID != "" {
+		query = query.Where("event_id = ?", eventID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var messages []models.OutboundEmail
+	if err := query.Omit("text_body", "html_body").Order("id desc").Offset((page - 1) * limit).Limit(limit).Find(&messages).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	helpers.ResponseSuccess(c, "Emails retrieved successfully", gin.H{
+		"emails": messages,
+		"pagination": gin.H{
+			"current_page": page,
+			"per_page":     limit,
+			"total_items":  total,
+			"total_pages":  totalPages,
+			"has_next":     page < totalPages,
+			"has_prev":     page > 1,
+		},
+	})
+}
+
+// GetEmails mengambil isi outbox email, filter status, template_key, event_id dan recipient (protected)
+func (ec *EmailController) GetEmails(c *gin.Context) {
+	query := ec.DB.Model(&models.OutboundEmail{})
+	if recipient := c.Query("recipient"); recipient != "" {
+		query = query.Where("recipient = ?", recipient)
+	}
+	ec.listEmails(c, query)
+}
+
+// findEmail mengambil email outbox dari parameter :id
+func (ec *EmailController) findEmail(c *gin.Context) (*models.OutboundEmail, bool) {
+	var msg models.OutboundEmail
+	if err := ec.DB.Where("id = ?", c.Param("id")).First(&msg).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Email not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &msg, true
+}
+
+// GetEmail mengambil satu email outbox lengkap dengan isi teks & HTML (protected)
+func (ec *EmailController) GetEmail(c *gin.Context) {
+	msg, ok := ec.findEmail(c)
+	if !ok {
+		return
+	}
+	helpers.ResponseSuccess(c, "Email retrieved successfully", msg)
+}
+
+// RetryEmail mengantrikan ulang email yang gagal, bounce atau suppressed (protected)
+func (ec *EmailController) RetryEmail(c *gin.Context) {
+	msg, ok := ec.findEmail(c)
+	if !ok {
+		return
+	}
+	if err := ec.Emails.Retry(msg); err != nil {
+		helpers.ResponseConflict(c, err.Error())
+		return
+	}
+	msg, ok = ec.findEmail(c)
+	if !ok {
+		return
+	}
+	helpers.ResponseSuccess(c, "Email queued for retry", msg)
+}
+
+// GetParticipantEmails mengambil riwayat email participant (protected)
+func (ec *EmailController) GetParticipantEmails(c *gin.Context) {
+	var participant models.Participant
+	if err := ec.Participants.scopedParticipants(c).Where("id = ?", c.Param("id")).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	ec.listEmails(c, ec.DB.Model(&models.OutboundEmail{}).Where("participant_id = ?", participant.ID))
+}
+
+// RecordBounce menerima laporan bounce dari server / provider email (public, wajib header
+// X-Mail-Bounce-Token sama dengan MAIL_BOUNCE_TOKEN; endpoint nonaktif jika token belum diatur)
+func (ec *EmailController) RecordBounce(c *gin.Context) {
+	token := os.Getenv("MAIL_BOUNCE_TOKEN")
+	if token == "" {
+		helpers.ResponseNotFound(c, "Bounce endpoint tidak aktif")
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Mail-Bounce-Token")), []byte(token)) != 1 {
+		helpers.ResponseUnauthorized(c, "Token bounce tidak valid")
+		return
+	}
+	var form forms.EmailBounceForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	msg, err := ec.Emails.RecordBounce(emails.Bounce{
+		MessageID: form.MessageID,
+		Recipient: form.Recipient,
+		Type:      form.Type,
+		Reason:    form.Reason,
+		SMTPCode:  form.SMTPCode,
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Email not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Bounce recorded", gin.H{
+		"id":          msg.ID,
+		"status":      msg.Status,
+		"bounce_type": msg.BounceType,
+	})
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/mailer"
+	"backend/internal/models"
+)
+
+// getEmailRequired menentukan apakah email wajib diisi saat registrasi
+func getEmailRequired() bool {
+	return helpers.GetEnvBool("EMAIL_REQUIRED", false)
+}
+
+// getEmailUnique menentukan apakah satu email hanya boleh dipakai satu participant
+func getEmailUnique() bool {
+	return helpers.GetEnvBool("EMAIL_UNIQUE", true)
+}
+
+// getAppBaseURL adalah URL publik backend, dipakai untuk link di email
+func getAppBaseURL() string {
+	return strings.TrimRight(helpers.GetEnv("APP_BASE_URL", "http://localhost:8001"), "/")
+}
+
+// normalizeEmail membuat email lowercase tanpa spasi, nil jika kosong
+func normalizeEmail(email string) *string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil
+	}
+	return &email
+}
+
+// errEmailTaken dikembalikan dari transaksi simpan participant jika email ternyata sudah dipakai
+var errEmailTaken = errors.New("email sudah terdaftar")
+
+// emailTaken mengecek apakah email sudah dipakai participant lain di event yang sama
+func emailTaken(db *gorm.DB, eventID *uint, email string, exceptID string) (bool, error) {
+	var count int64
+	query := db.Model(&models.Participant{}).Where("email = ?", email)
+	if eventID != nil {
+		query = query.Where("event_id = ?", *eventID)
+	} else {
+		query = query.Where("event_id IS NULL")
+	}
+	if exceptID != "" {
+		query = query.Where("id <> ?", exceptID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ensureEmailAvailable mengecek ulang email unik di dalam transaksi setelah event dikunci (workflow.Register /
+// workflow.LockEvent), sehingga dua request bersamaan dengan email yang sama tidak lolos dua-duanya.
+// Pengecekan di awal request tetap ada supaya pendaftar cepat mendapat 409 tanpa menunggu lock.
+func ensureEmailAvailable(tx *gorm.DB, p models.Participant) error {
+	if p.Email == nil || !getEmailUnique() {
+		return nil
+	}
+	taken, err := emailTaken(tx, p.EventID, *p.Email, p.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errEmailTaken
+	}
+	return nil
+}
+
+// sendEmailVerification membuat token verifikasi baru dan mengirim link ke email participant.
+// Pengiriman berjalan di background agar registrasi tidak menunggu mailer.
+func (pc *ParticipantController) sendEmailVerification(participant models.Participant) error {
+	if participant.Email == nil {
+		return nil
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	verification := models.EmailVerification{
+		ParticipantID: participant.ID,
+		Email:         *participant.Email,
+		TokenHash:     helpers.SHA256Hash(token),
+		ExpiresAt:     time.Now().Add(helpers.GetEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour)),
+		CreatedAt:     time.Now(),
+	}
+	if err := pc.DB.Create(&verification).Error; err != nil {
+		return err
+	}
+
+	link := getAppBaseURL() + "/api/participants/verify-email?token=" + token
+	msg := mailer.Message{
+		To:      *participant.Email,
+		Subject: "Verifikasi email pendaftaran Youth College",
+		Text: fmt.Sprintf("Halo %s,\n\nTerima kasih sudah mendaftar Youth College. Klik link berikut untuk memverifikasi email kamu:\n\n%s\n\nLink berlaku sampai %s.\n",
+			participant.Name, link, verification.ExpiresAt.Format("02 Jan 2006 15:04")),
+	}
+	if participant.TicketURL != "" && participant.RegistrationCode != nil {
+		msg.Text += fmt.Sprintf("\nKode registrasi kamu: %s\nTiket (tunjukkan saat registrasi ulang di lokasi): %s\n",
+			*participant.RegistrationCode, participant.TicketURL)
+	}
+	go func() {
+		if err := pc.Mailer.Send(context.Background(), msg); err != nil {
+			log.Printf("ERROR: Gagal mengirim email verifikasi ke %s: %v", msg.To, err)
+		}
+	}()
+	return nil
+}
+
+// VerifyEmail memverifikasi email participant dari link yang dikirim (public)
+func (pc *ParticipantController) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		helpers.ResponseBadRequest(c, "token required")
+		return
+	}
+
+	var verification models.EmailVerification
+	if err := pc.DB.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", helpers.SHA256Hash(token), time.Now()).
+		First(&verification).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseBadRequest(c, "Link verifikasi tidak valid atau sudah kedaluwarsa")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	now := time.Now()
+	err := pc.DB.Transaction(func(tx *gorm.DB) error {
+		// Email bisa sudah diganti setelah link dikirim; hanya verifikasi jika masih sama
+		result := tx.Model(&models.Participant{}).
+			Where("id = ? AND email = ?", verification.ParticipantID, verification.Email).
+			UpdateColumn("email_verified_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&verification).UpdateColumn("used_at", now).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseBadRequest(c, "Link verifikasi tidak valid atau sudah kedaluwarsa")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	helpers.ResponseSuccess(c, "Email verified successfully", gin.H{
+		"email":             verification.Email,
+		"email_verified_at": now,
+	})
+}
+
+// ResendEmailVerification mengirim ulang link verifikasi email participant (admin)
+func (pc *ParticipantController) ResendEmailVerification(c *gin.Context) {
+	id := c.Param("id")
+	var participant models.Participant
+	if err := pc.scopedParticipants(c).Where("id = ?", id).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if participant.Email == nil {
+		helpers.ResponseBadRequest(c, "Participant tidak memiliki email")
+		return
+	}
+	if participant.EmailVerifiedAt != nil {
+		helpers.ResponseBadRequest(c, "Email sudah terverifikasi")
+		return
+	}
+	if err := pc.sendEmailVerification(participant); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Verification email sent", nil)
+}
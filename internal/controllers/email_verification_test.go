@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+var verifyLink = regexp.MustCompile(`https://yc\.test/api/participants/verify-email\?token=(\S+)`)
+
+// verificationToken mengambil token dari email verifikasi berikutnya yang dikirim ke to
+func (s *participantServer) verificationToken(t *testing.T, to string) string {
+	t.Helper()
+	msg := s.mail.next(t)
+	match := verifyLink.FindStringSubmatch(msg.Text)
+	if msg.To != to || match == nil {
+		t.Fatalf("verification email to %s: %s", msg.To, msg.Text)
+	}
+	return match[1]
+}
+
+func (s *participantServer) verify(token string) *apiResponse {
+	s.t.Helper()
+	res := decode(s.t, s.do(http.MethodGet, "/participants/verify-email?token="+url.QueryEscape(token), nil), http.StatusOK)
+	return &res
+}
+
+func TestEmailUniquenessIsConfigurable(t *testing.T) {
+	s := newParticipantServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+
+	first := s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{"email": "Budi@Example.COM"})
+	if first.Email == nil || *first.Email != "budi@example.com" {
+		t.Fatalf("email = %v, want normalized", first.Email)
+	}
+	// Email sama (beda huruf besar) di event yang sama ditolak, di event lain boleh
+	res := decode(t, s.do(http.MethodPost, "/events/"+event.Slug+"/participants",
+		registration(map[string]interface{}{"name": "Citra", "phone": "085711112222", "email": "budi@example.com"})), http.StatusConflict)
+	if res.Error != "Email sudah terdaftar" {
+		t.Errorf("duplicate email: %q", res.Error)
+	}
+	s.register(t, other.Slug, http.StatusCreated, map[string]interface{}{"name": "Citra", "phone": "085711112222", "email": "budi@example.com"})
+
+	// Mengganti email ke email participant lain di event yang sama juga ditolak
+	second := s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{"name": "Dewi", "phone": "089900001111", "email": "dewi@example.com"})
+	update := registration(map[string]interface{}{"name": "Dewi", "phone": "089900001111", "email": "BUDI@example.com"})
+	decode(t, s.do(http.MethodPut, "/admin/participants/"+second.ID, update), http.StatusConflict)
+
+	// EMAIL_UNIQUE=false: email boleh dipakai lebih dari satu participant
+	t.Setenv("EMAIL_UNIQUE", "false")
+	decode(t, s.do(http.MethodPut, "/admin/participants/"+second.ID, update), http.StatusOK)
+	s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{"name": "Eka", "phone": "081311112222", "email": "budi@example.com"})
+	var count int64
+	s.db.Model(&models.Participant{}).Where("event_id = ? AND email = ?", event.ID, "budi@example.com").Count(&count)
+	if count != 3 {
+		t.Errorf("participants with shared email = %d, want 3", count)
+	}
+
+	// EMAIL_REQUIRED=true: registrasi tanpa email ditolak
+	t.Setenv("EMAIL_REQUIRED", "true")
+	if res := decode(t, s.do(http.MethodPost, "/participants", registration(map[string]interface{}{"name": "Fajar", "phone": "081399998888"})), http.StatusBadRequest); res.Error != "Email wajib diisi" {
+		t.Errorf("email required: %q", res.Error)
+	}
+}
+
+func TestVerifyEmailTokenExpiryAndReuse(t *testing.T) {
+	s := newParticipantServer(t)
+	p := s.register(t, "", http.StatusCreated, map[string]interface{}{"email": "budi@example.com"})
+	token := s.verificationToken(t, "budi@example.com")
+	invalid := "Link verifikasi tidak valid atau sudah kedaluwarsa"
+
+	var verified struct {
+		Email           string     `json:"email"`
+		EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	}
+	s.verify(token).into(t, &verified)
+	s.db.First(&p, "id = ?", p.ID)
+	if verified.Email != "budi@example.com" || p.EmailVerifiedAt == nil {
+		t.Fatalf("verified = %+v, participant email_verified_at = %v", verified, p.EmailVerifiedAt)
+	}
+
+	// Token sekali pakai
+	if res := decode(t, s.do(http.MethodGet, "/participants/verify-email?token="+token, nil), http.StatusBadRequest); res.Error != invalid {
+		t.Errorf("reused: %q", res.Error)
+	}
+	decode(t, s.do(http.MethodGet, "/participants/verify-email", nil), http.StatusBadRequest)
+	decode(t, s.do(http.MethodPost, "/admin/participants/"+p.ID+"/resend-verification", nil), http.StatusBadRequest)
+
+	// Ganti email: status verifikasi di-reset dan link baru dikirim ke email baru
+	var updated models.Participant
+	decode(t, s.do(http.MethodPut, "/admin/participants/"+p.ID, registration(map[string]interface{}{"email": "budi.baru@example.com"})), http.StatusOK).into(t, &updated)
+	if updated.EmailVerifiedAt != nil {
+		t.Errorf("email_verified_at kept after email change")
+	}
+	newToken := s.verificationToken(t, "budi.baru@example.com")
+
+	// Link lama yang belum dipakai tidak memverifikasi email baru
+	expired, _ := helpers.GenerateRandomToken(32)
+	stale, _ := helpers.GenerateRandomToken(32)
+	s.db.Create(&models.EmailVerification{ParticipantID: p.ID, Email: "budi.baru@example.com", TokenHash: helpers.SHA256Hash(expired), ExpiresAt: time.Now().Add(-time.Minute)})
+	s.db.Create(&models.EmailVerification{ParticipantID: p.ID, Email: "budi@example.com", TokenHash: helpers.SHA256Hash(stale), ExpiresAt: time.Now().Add(time.Hour)})
+	for name, tok := range map[string]string{"expired": expired, "old email": stale} {
+		if res := decode(t, s.do(http.MethodGet, "/participants/verify-email?token="+tok, nil), http.StatusBadRequest); res.Error != invalid {
+			t.Errorf("%s: %q", name, res.Error)
+		}
+	}
+	var current models.Participant
+	s.db.First(&current, "id = ?", p.ID)
+	if current.EmailVerifiedAt != nil {
+		t.Fatal("new email verified by an old link")
+	}
+
+	// Kirim ulang: link baru tetap berlaku bersama link sebelumnya
+	decode(t, s.do(http.MethodPost, "/admin/participants/"+p.ID+"/resend-verification", nil), http.StatusOK)
+	s.verificationToken(t, "budi.baru@example.com")
+	s.verify(newToken)
+}
+
+func TestEmailVerifiedFilter(t *testing.T) {
+	s := newParticipantServer(t)
+	now := time.Now()
+	verified, unverified := "andi@example.com", "citra@example.com"
+	testutil.CreateParticipant(t, s.db, "Andi", func(p *models.Participant) { p.Email, p.EmailVerifiedAt = &verified, &now })
+	testutil.CreateParticipant(t, s.db, "Budi")
+	testutil.CreateParticipant(t, s.db, "Citra", func(p *models.Participant) { p.Email = &unverified })
+
+	for filter, want := range map[string][]string{
+		"true":  {"Andi"},
+		"false": {"Citra"}, // tanpa email tidak dihitung belum verifikasi
+		"":      {"Andi", "Budi", "Citra"},
+		"other": {"Andi", "Budi", "Citra"},
+	} {
+		var list participantList
+		decode(t, s.do(http.MethodGet, "/admin/participants?sort_by=name&sort_order=asc&email_verified="+filter, nil), http.StatusOK).into(t, &list)
+		if !reflect.DeepEqual(list.names(), want) {
+			t.Errorf("email_verified=%s: %v, want %v", filter, list.names(), want)
+		}
+	}
+}
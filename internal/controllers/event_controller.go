@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/eligibility"
+	"backend/internal/emails"
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/messaging"
+	"backend/internal/models"
+	"backend/internal/webhooks"
+	"backend/internal/workflow"
+)
+
+type EventController struct {
+	DB       *gorm.DB
+	Webhooks *webhooks.Dispatcher
+	Messages *messaging.Service
+	Emails   *emails.Queue
+}
+
+// NewEventController membuat instance controller baru
+func NewEventController(db *gorm.DB, hooks *webhooks.Dispatcher, messages *messaging.Service, outbox *emails.Queue) *EventController {
+	return &EventController{DB: db, Webhooks: hooks, Messages: messages, Emails: outbox}
+}
+
+var (
+	slugPattern      = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// slugify membuat slug dari nama event, mis. "Youth College 2025" -> "youth-college-2025"
+func slugify(name string) string {
+	return strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// eventFromContext mengambil event hasil middleware.EventScope (nil jika route tidak di-scope)
+func eventFromContext(c *gin.Context) *models.Event {
+	if v, exists := c.Get("event"); exists {
+		if event, ok := v.(*models.Event); ok {
+			return event
+		}
+	}
+	return nil
+}
+
+// applyEventForm memvalidasi form dan menyalin nilainya ke event
+func applyEventForm(form forms.EventForm, event *models.Event) string {
+	slug := form.Slug
+	if slug == "" {
+		slug = slugify(form.Name)
+	}
+	if !slugPattern.MatchString(slug) {
+		return "Slug hanya boleh huruf kecil, angka dan tanda -"
+	}
+
+	startDate, err := time.Parse("2006-01-02", form.StartDate)
+	if err != nil {
+		return "Format start_date harus YYYY-MM-DD"
+	}
+	endDate, err := time.Parse("2006-01-02", form.EndDate)
+	if err != nil {
+		return "Format end_date harus YYYY-MM-DD"
+	}
+	if endDate.Before(startDate) {
+		return "end_date tidak boleh sebelum start_date"
+	}
+
+	var opensAt, closesAt *time.Time
+	if form.RegistrationOpensAt != "" {
+		t, err := time.Parse(time.RFC3339, form.RegistrationOpensAt)
+		if err != nil {
+			return "Format registration_opens_at harus RFC3339"
+		}
+		opensAt = &t
+	}
+	if form.RegistrationClosesAt != "" {
+		t, err := time.Parse(time.RFC3339, form.RegistrationClosesAt)
+		if err != nil {
+			return "Format registration_closes_at harus RFC3339"
+		}
+		closesAt = &t
+	}
+	if opensAt != nil && closesAt != nil && !closesAt.After(*opensAt) {
+		return "registration_closes_at harus setelah registration_opens_at"
+	}
+	if err := eligibility.CheckRules(form.Eligibility); err != nil {
+		return err.Error()
+	}
+
+	event.Name = form.Name
+	event.Slug = slug
+	event.Description = form.Description
+	event.Location = form.Location
+	event.StartDate = startDate
+	event.EndDate = endDate
+	event.RegistrationOpensAt = opensAt
+	event.RegistrationClosesAt = closesAt
+	event.Capacity = form.Capacity
+	event.Eligibility = form.Eligibility
+	return ""
+}
+
+// eventResponse menambahkan status registrasi saat ini ke data event
+func eventResponse(event models.Event) gin.H {
+	return gin.H{
+		"event":             event,
+		"registration_open": event.RegistrationOpen(time.Now()),
+	}
+}
+
+// slugTaken mengecek apakah slug sudah dipakai event lain
+func (ec *EventController) slugTaken(slug string, exceptID uint) (bool, error) {
+	var count int64
+	if err := ec.DB.Model(&models.Event{}).Where("slug = ? AND id <> ?", slug, exceptID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreateEvent membuat event baru
+func (ec *EventController) CreateEvent(c *gin.Context) {
+	var form forms.EventForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	var event models.Event
+	if msg := applyEventForm(form, &event); msg != "" {
+		helpers.ResponseBadRequest(c, msg)
+		return
+	}
+	if taken, err := ec.slugTaken(event.Slug, 0); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if taken {
+		helpers.ResponseConflict(c, "Slug sudah dipakai event lain")
+		return
+	}
+
+	if err := ec.DB.Create(&event).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseCreated(c, "Event created successfully", eventResponse(event))
+}
+
+// GetAllEvents mengambil semua event, terbaru lebih dulu
+func (ec *EventController) GetAllEvents(c *gin.Context) {
+	var events []models.Event
+	if err := ec.DB.Order("start_date desc, id desc").Find(&events).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	data := make([]gin.H, len(events))
+	for i, event := range events {
+		data[i] = eventResponse(event)
+	}
+	helpers.ResponseSuccess(c, "Events retrieved successfully", gin.H{"events": data})
+}
+
+// GetEvent mengambil event berdasarkan slug, termasuk sisa kursi
+func (ec *EventController) GetEvent(c *gin.Context) {
+	event := eventFromContext(c)
+	data := eventResponse(*event)
+
+	taken, err := workflow.SeatsTaken(ec.DB, event.ID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	data["seats_taken"] = taken
+	if event.Capacity != nil {
+		available := int64(*event.Capacity) - taken
+		if available < 0 {
+			available = 0
+		}
+		data["seats_available"] = available
+	}
+	helpers.ResponseSuccess(c, "Event retrieved successfully", data)
+}
+
+// UpdateEvent mengupdate data event
+func (ec *EventController) UpdateEvent(c *gin.Context) {
+	event := eventFromContext(c)
+
+	var form forms.EventForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if msg := applyEventForm(form, event); msg != "" {
+		helpers.ResponseBadRequest(c, msg)
+		return
+	}
+	if taken, err := ec.slugTaken(event.Slug, event.ID); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if taken {
+		helpers.ResponseConflict(c, "Slug sudah dipakai event lain")
+		return
+	}
+
+	if err := ec.DB.Save(event).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	// Kapasitas bisa dinaikkan: isi kursi baru dari waitlist
+	promoted, err := workflow.FillOpenSeats(ec.DB, event.ID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	notifyPromoted(ec.Webhooks, ec.Messages, ec.Emails, promoted)
+
+	data := eventResponse(*event)
+	data["promoted"] = promoted
+	helpers.ResponseSuccess(c, "Event updated successfully", data)
+}
+
+// DeleteEvent menghapus event yang belum memiliki participant, beserta pertanyaan registrasi, sesi,
+// template sertifikat, job terjadwal dan webhook khusus event tersebut
+func (ec *EventController) DeleteEvent(c *gin.Context) {
+	event := eventFromContext(c)
+
+	var count int64
+	if err := ec.DB.Model(&models.Participant{}).Where("event_id = ?", event.ID).Count(&count).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if count > 0 {
+		helpers.ResponseConflict(c, "Event masih memiliki participant")
+		return
+	}
+
+	if err := ec.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.RegistrationQuestion{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.CertificateBatch{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.CertificateTemplate{}).Error; err != nil {
+			return err
+		}
+		// Job terjadwal event ikut dihapus supaya scheduler tidak menjalankan job untuk event yang sudah tidak ada
+		if err := tx.Where("job_id IN (SELECT id FROM scheduled_jobs WHERE event_id = ?)", event.ID).Delete(&models.JobRun{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.ScheduledJob{}).Error; err != nil {
+			return err
+		}
+		// Webhook khusus event ini tidak akan menerima event lagi, dihapus bersama riwayat pengirimannya
+		hooks := tx.Model(&models.Webhook{}).Select("id").Where("event_id = ?", event.ID)
+		if err := tx.Where("delivery_id IN (SELECT id FROM webhook_deliveries WHERE webhook_id IN (?))", hooks).
+			Delete(&models.WebhookAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("webhook_id IN (?)", hooks).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.Webhook{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(event).Error
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Event deleted successfully", nil)
+}
+
+// EvaluateEligibility mengevaluasi ulang semua participant event terhadap syarat saat ini (protected).
+// Participant yang tidak memenuhi syarat hanya ditandai (ineligible_at), status tidak diubah.
+func (ec *EventController) EvaluateEligibility(c *gin.Context) {
+	event := eventFromContext(c)
+	summary, err := eligibility.Reevaluate(ec.DB, *event)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Eligibility evaluated successfully", summary)
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/emails"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func newEventServer(t *testing.T) *testServer {
+	s := newTestServer(t)
+	ec := NewEventController(s.db, nil, newMessages(s.db, nil), emails.NewQueue(s.db, emails.Config{}, nil))
+	s.engine.GET("/events", ec.GetAllEvents)
+	s.engine.POST("/events", ec.CreateEvent)
+	event := s.event()
+	event.GET("", ec.GetEvent)
+	event.PUT("", ec.UpdateEvent)
+	event.DELETE("", ec.DeleteEvent)
+	return s
+}
+
+// eventData adalah data response endpoint event
+type eventData struct {
+	Event            models.Event         `json:"event"`
+	RegistrationOpen bool                 `json:"registration_open"`
+	SeatsTaken       int64                `json:"seats_taken"`
+	SeatsAvailable   *int64               `json:"seats_available"`
+	Promoted         []models.Participant `json:"promoted"`
+}
+
+// eventForm mengembalikan body event yang valid; fields menimpa nilai bawaan
+func eventForm(fields map[string]interface{}) map[string]interface{} {
+	body := map[string]interface{}{"name": "Youth College 2026", "start_date": "2026-12-01", "end_date": "2026-12-02"}
+	for k, v := range fields {
+		body[k] = v
+	}
+	return body
+}
+
+func TestCreateEventValidatesSlugAndDates(t *testing.T) {
+	s := newEventServer(t)
+
+	var created eventData
+	decode(t, s.do(http.MethodPost, "/events", eventForm(nil)), http.StatusCreated).into(t, &created)
+	if created.Event.ID == 0 || created.Event.Slug != "youth-college-2026" {
+		t.Fatalf("event = %+v, want slug from name", created.Event)
+	}
+	// Nama berbeda yang menghasilkan slug sama ditolak
+	if res := decode(t, s.do(http.MethodPost, "/events", eventForm(map[string]interface{}{"name": "Youth  College 2026!"})), http.StatusConflict); res.Error != "Slug sudah dipakai event lain" {
+		t.Errorf("duplicate slug: %q", res.Error)
+	}
+
+	for name, fields := range map[string]map[string]interface{}{
+		"invalid slug":       {"slug": "Youth_College"},
+		"end before start":   {"slug": "a", "end_date": "2026-11-30"},
+		"bad date format":    {"slug": "b", "start_date": "01-12-2026"},
+		"bad window format":  {"slug": "c", "registration_opens_at": "2026-11-01"},
+		"closes before open": {"slug": "d", "registration_opens_at": "2026-11-01T00:00:00+07:00", "registration_closes_at": "2026-10-01T00:00:00+07:00"},
+		"zero capacity":      {"slug": "e", "capacity": 0},
+		"missing name":       {"slug": "f", "name": ""},
+	} {
+		if w := s.do(http.MethodPost, "/events", eventForm(fields)); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+	var count int64
+	s.db.Model(&models.Event{}).Count(&count)
+	if count != 1 {
+		t.Errorf("events = %d, want 1", count)
+	}
+}
+
+func TestGetEventSeatsAndRegistrationWindow(t *testing.T) {
+	s := newEventServer(t)
+	capacity := 3
+	closed := time.Now().Add(-time.Hour)
+	event := testutil.CreateEvent(t, s.db, func(e *models.Event) { e.Capacity = &capacity })
+	past := testutil.CreateEvent(t, s.db, func(e *models.Event) { e.RegistrationClosesAt = &closed })
+	for _, status := range []string{models.StatusApproved, models.StatusPending, models.StatusWaitlisted, models.StatusRejected} {
+		testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) {
+			p.EventID = &event.ID
+			p.Status = status
+		})
+	}
+
+	var data eventData
+	decode(t, s.do(http.MethodGet, "/events/"+event.Slug, nil), http.StatusOK).into(t, &data)
+	if data.SeatsTaken != 2 || data.SeatsAvailable == nil || *data.SeatsAvailable != 1 || !data.RegistrationOpen {
+		t.Errorf("seats taken %d, available %v, open %v", data.SeatsTaken, data.SeatsAvailable, data.RegistrationOpen)
+	}
+	data = eventData{}
+	decode(t, s.do(http.MethodGet, "/events/"+past.Slug, nil), http.StatusOK).into(t, &data)
+	if data.SeatsAvailable != nil || data.RegistrationOpen {
+		t.Errorf("past event: available %v, open %v", data.SeatsAvailable, data.RegistrationOpen)
+	}
+
+	// EventScope: slug yang tidak ada menghasilkan 404 sebelum handler dijalankan
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		if res := decode(t, s.do(method, "/events/tidak-ada", eventForm(nil)), http.StatusNotFound); res.Error != "Event not found" {
+			t.Errorf("%s unknown slug: %q", method, res.Error)
+		}
+	}
+
+	var list struct {
+		Events []eventData `json:"events"`
+	}
+	decode(t, s.do(http.MethodGet, "/events", nil), http.StatusOK).into(t, &list)
+	if len(list.Events) != 2 || list.Events[0].Event.ID != past.ID {
+		t.Errorf("events = %+v, want newest first", list.Events)
+	}
+}
+
+func TestUpdateEventSlugAndCapacity(t *testing.T) {
+	s := newEventServer(t)
+	capacity := 1
+	event := testutil.CreateEvent(t, s.db, func(e *models.Event) { e.Capacity = &capacity })
+	other := testutil.CreateEvent(t, s.db)
+	testutil.CreateParticipant(t, s.db, "Andi", func(p *models.Participant) { p.EventID = &event.ID })
+	waiting := testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) {
+		p.EventID = &event.ID
+		p.Status = models.StatusWaitlisted
+	})
+
+	decode(t, s.do(http.MethodPut, "/events/"+event.Slug, eventForm(map[string]interface{}{"slug": other.Slug})), http.StatusConflict)
+
+	// Kapasitas dinaikkan: kursi baru diisi dari waitlist
+	var data eventData
+	decode(t, s.do(http.MethodPut, "/events/"+event.Slug, eventForm(map[string]interface{}{"slug": "youth-camp-baru", "capacity": 2})), http.StatusOK).into(t, &data)
+	if data.Event.Slug != "youth-camp-baru" || len(data.Promoted) != 1 || data.Promoted[0].ID != waiting.ID {
+		t.Fatalf("event %q, promoted %+v", data.Event.Slug, data.Promoted)
+	}
+	var p models.Participant
+	s.db.First(&p, "id = ?", waiting.ID)
+	if p.Status != models.StatusPending {
+		t.Errorf("status = %s, want pending", p.Status)
+	}
+	decode(t, s.do(http.MethodGet, "/events/"+event.Slug, nil), http.StatusNotFound)
+	decode(t, s.do(http.MethodGet, "/events/youth-camp-baru", nil), http.StatusOK)
+}
+
+func TestDeleteEventRemovesEventData(t *testing.T) {
+	s := newEventServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+
+	// Data event yang dihapus dan data yang harus tetap ada (event lain / webhook global)
+	seed := func(eventID *uint) (job models.ScheduledJob, hook models.Webhook, delivery models.WebhookDelivery) {
+		job = models.ScheduledJob{Name: "Pengingat", Type: "event_reminder", EventID: eventID, Trigger: "event"}
+		s.db.Create(&job)
+		s.db.Create(&models.JobRun{JobID: job.ID, RunBy: "schedule", Status: "success", StartedAt: time.Now()})
+		hook = models.Webhook{Name: "CRM", URL: "https://crm.test/hook", Secret: "rahasia", EventTypes: []string{"*"}, EventID: eventID, Active: true}
+		s.db.Create(&hook)
+		delivery = models.WebhookDelivery{WebhookID: hook.ID, EventType: "participant.created", MessageID: "msg", Payload: "{}", Status: "delivered"}
+		s.db.Create(&delivery)
+		s.db.Create(&models.WebhookAttempt{DeliveryID: delivery.ID, Attempt: 1, StatusCode: 200})
+		return job, hook, delivery
+	}
+	seed(&event.ID)
+	keptJob, keptHook, keptDelivery := seed(&other.ID)
+	_, globalHook, globalDelivery := seed(nil)
+	s.db.Create(&models.RegistrationQuestion{EventID: event.ID, Key: "ukuran_kaos", Label: "Ukuran kaos", Type: models.QuestionText})
+	s.db.Create(&models.Session{EventID: event.ID, Title: "Pembukaan", StartsAt: testutil.Date("2026-12-01"), EndsAt: testutil.Date("2026-12-01").Add(time.Hour)})
+
+	// Event yang masih punya participant tidak boleh dihapus
+	p := testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) { p.EventID = &event.ID })
+	if res := decode(t, s.do(http.MethodDelete, "/events/"+event.Slug, nil), http.StatusConflict); res.Error != "Event masih memiliki participant" {
+		t.Errorf("with participant: %q", res.Error)
+	}
+	s.db.Delete(&p)
+	decode(t, s.do(http.MethodDelete, "/events/"+event.Slug, nil), http.StatusOK)
+
+	counts := map[string]struct {
+		model interface{}
+		want  int64
+	}{
+		"events":             {&models.Event{}, 1},
+		"questions":          {&models.RegistrationQuestion{}, 0},
+		"sessions":           {&models.Session{}, 0},
+		"scheduled_jobs":     {&models.ScheduledJob{}, 2},
+		"job_runs":           {&models.JobRun{}, 2},
+		"webhooks":           {&models.Webhook{}, 2},
+		"webhook_deliveries": {&models.WebhookDelivery{}, 2},
+		"webhook_attempts":   {&models.WebhookAttempt{}, 2},
+	}
+	for name, c := range counts {
+		var n int64
+		s.db.Model(c.model).Count(&n)
+		if n != c.want {
+			t.Errorf("%s = %d, want %d", name, n, c.want)
+		}
+	}
+	var n int64
+	s.db.Model(&models.JobRun{}).Where("job_id = ?", keptJob.ID).Count(&n)
+	if n != 1 {
+		t.Errorf("runs of other event's job = %d", n)
+	}
+	for _, d := range []models.WebhookDelivery{keptDelivery, globalDelivery} {
+		s.db.Model(&models.WebhookAttempt{}).Where("delivery_id = ?", d.ID).Count(&n)
+		if n != 1 {
+			t.Errorf("attempts of delivery %d = %d", d.ID, n)
+		}
+	}
+	s.db.Model(&models.Webhook{}).Where("id IN ?", []uint{keptHook.ID, globalHook.ID}).Count(&n)
+	if n != 2 {
+		t.Errorf("other webhooks = %d, want 2", n)
+	}
+}
+
+func TestEventScopeLimitsParticipantEndpoints(t *testing.T) {
+	s := newParticipantServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+	testutil.CreateParticipant(t, s.db, "Andi", func(p *models.Participant) { p.EventID = &event.ID })
+	outside := testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) { p.EventID = &other.ID })
+	testutil.CreateParticipant(t, s.db, "Citra")
+
+	var list participantList
+	decode(t, s.do(http.MethodGet, "/events/"+event.Slug+"/admin/participants", nil), http.StatusOK).into(t, &list)
+	if names := list.names(); len(names) != 1 || names[0] != "Andi" || list.Pagination.TotalItems != 1 {
+		t.Errorf("scoped list = %v (total %d)", names, list.Pagination.TotalItems)
+	}
+	list = participantList{}
+	decode(t, s.do(http.MethodGet, "/admin/participants", nil), http.StatusOK).into(t, &list)
+	if list.Pagination.TotalItems != 3 {
+		t.Errorf("unscoped total = %d, want 3", list.Pagination.TotalItems)
+	}
+
+	// Participant event lain tidak bisa diubah lewat route event ini
+	decode(t, s.do(http.MethodPut, "/events/"+event.Slug+"/admin/participants/"+outside.ID, registration(nil)), http.StatusNotFound)
+	decode(t, s.do(http.MethodGet, "/events/tidak-ada/admin/participants", nil), http.StatusNotFound)
+
+	// Registrasi lewat route event menyimpan event_id dari slug
+	p := s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{"name": "Dewi", "phone": "089900001111"})
+	if p.EventID == nil || *p.EventID != event.ID {
+		t.Errorf("event_id = %v, want %d", p.EventID, event.ID)
+	}
+}
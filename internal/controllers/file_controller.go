@@ -0,0 +1,386 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/questions"
+	"backend/internal/storage"
+	"backend/internal/uploads"
+)
+
+type FileController struct {
+	DB      *gorm.DB
+	Storage storage.Storage
+}
+
+// NewFileController membuat instance controller baru
+func NewFileController(db *gorm.DB, store storage.Storage) *FileController {
+	return &FileController{DB: db, Storage: store}
+}
+
+// getUploadMaxSize mendapatkan batas ukuran file upload (byte) dari environment, default 5 MB
+func getUploadMaxSize() int64 {
+	return int64(helpers.GetEnvInt("UPLOAD_MAX_SIZE", 5<<20))
+}
+
+// getFileURLTTL mendapatkan umur signed URL download, default 5 menit
+func getFileURLTTL() time.Duration {
+	return helpers.GetEnvDuration("FILE_URL_TTL", 5*time.Minute)
+}
+
+// getUploadTokenTTL mendapatkan umur token upload mandiri pendaftar, default 24 jam
+func getUploadTokenTTL() time.Duration {
+	return helpers.GetEnvDuration("UPLOAD_TOKEN_TTL", 24*time.Hour)
+}
+
+// fileSigner membuat signer URL file; FILE_URL_SECRET boleh kosong (memakai JWT_SECRET)
+func fileSigner() uploads.Signer {
+	if secret := helpers.GetEnv("FILE_URL_SECRET", ""); secret != "" {
+		return uploads.Signer{Secret: []byte(secret)}
+	}
+	return uploads.Signer{Secret: getJWTSecret()}
+}
+
+// fileResponse menambahkan signed URL berumur pendek ke metadata file
+func fileResponse(f models.ParticipantFile) gin.H {
+	signer := fileSigner()
+	expires := time.Now().Add(getFileURLTTL())
+	data := gin.H{
+		"file":       f,
+		"url":        getAppBaseURL() + "/api/files/" + f.ID + "?" + signer.SignDownload(f.ID, "", expires).Encode(),
+		"expires_at": expires,
+	}
+	if f.ThumbnailKey != nil {
+		data["thumbnail_url"] = getAppBaseURL() + "/api/files/" + f.ID + "?" + signer.SignDownload(f.ID, "thumb", expires).Encode()
+	}
+	return data
+}
+
+// findParticipant mengambil participant (dibatasi ke event jika route di-scope per event)
+func (fc *FileController) findParticipant(c *gin.Context) (*models.Participant, bool) {
+	query := fc.DB.Model(&models.Participant{})
+	if event := eventFromContext(c); event != nil {
+		query = query.Where("event_id = ?", event.ID)
+	}
+	var participant models.Participant
+	if err := query.Where("id = ?", c.Param("id")).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &participant, true
+}
+
+// UploadFile mengupload file untuk participant (admin)
+func (fc *FileController) UploadFile(c *gin.Context) {
+	participant, ok := fc.findParticipant(c)
+	if !ok {
+		return
+	}
+	fc.upload(c, participant, currentActor(c))
+}
+
+// UploadFileWithToken mengupload file oleh pendaftar sendiri memakai upload_token dari response registrasi
+func (fc *FileController) UploadFileWithToken(c *gin.Context) {
+	token := c.GetHeader("X-Upload-Token")
+	if token == "" {
+		token = c.Query("token")
+	}
+	if err := fileSigner().VerifyUploadToken(c.Param("id"), token, time.Now()); err != nil {
+		helpers.ResponseUnauthorized(c, "Upload token tidak valid atau sudah kedaluwarsa")
+		return
+	}
+	participant, ok := fc.findParticipant(c)
+	if !ok {
+		return
+	}
+	fc.upload(c, participant, "participant")
+}
+
+// upload membaca multipart field "file" dan "kind", memvalidasi isi file lalu menyimpannya ke storage.
+// File lama dengan kind yang sama diganti.
+func (fc *FileController) upload(c *gin.Context, participant *models.Participant, uploadedBy string) {
+	data, filename, ok := readFormFile(c, getUploadMaxSize())
+	if !ok {
+		return
+	}
+
+	kind := c.PostForm("kind")
+	var question *models.RegistrationQuestion
+	switch kind {
+	case uploads.KindKTM, uploads.KindPhoto:
+	default:
+		// Selain ktm/photo, kind harus key pertanyaan registrasi tipe file di event participant
+		if participant.EventID != nil && kind != "" {
+			var q models.RegistrationQuestion
+			err := fc.DB.Where("event_id = ? AND question_key = ? AND type = ?", *participant.EventID, kind, models.QuestionFile).First(&q).Error
+			if err == nil {
+				question = &q
+			} else if err != gorm.ErrRecordNotFound {
+				helpers.ResponseInternalServerError(c, err.Error())
+				return
+			}
+		}
+		if question == nil {
+			helpers.ResponseBadRequest(c, "Kind harus ktm, photo, atau key pertanyaan tipe file")
+			return
+		}
+	}
+
+	// Tipe file ditentukan dari isi, header Content-Type dan ekstensi dari client tidak dipercaya
+	contentType := uploads.Sniff(data)
+	if err := uploads.Check(kind, contentType); err != nil {
+		helpers.ResponseError(c, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+
+	var thumbnail []byte
+	if uploads.IsImage(contentType) {
+		var err error
+		if thumbnail, err = uploads.Thumbnail(data); err != nil {
+			helpers.ResponseError(c, http.StatusUnprocessableEntity, "Gambar tidak bisa dibaca: "+err.Error())
+			return
+		}
+	}
+
+	// ID dibuat di sini (bukan di hook) karena dipakai sebagai key storage sebelum insert
+	sum := sha256.Sum256(data)
+	file := models.ParticipantFile{
+		ID:            uuid.New().String(),
+		ParticipantID: participant.ID,
+		Kind:          kind,
+		OriginalName:  filepath.Base(filename),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		Checksum:      hex.EncodeToString(sum[:]),
+		UploadedBy:    uploadedBy,
+	}
+	ctx := c.Request.Context()
+	file.StorageKey = "participants/" + participant.ID + "/" + file.ID + uploads.Extension(contentType)
+	if err := fc.Storage.Put(ctx, file.StorageKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		helpers.ResponseInternalServerError(c, "Gagal menyimpan file: "+err.Error())
+		return
+	}
+	if thumbnail != nil {
+		key := "participants/" + participant.ID + "/" + file.ID + "_thumb.jpg"
+		if err := fc.Storage.Put(ctx, key, bytes.NewReader(thumbnail), int64(len(thumbnail)), uploads.TypeJPEG); err != nil {
+			removeFileObjects(fc.Storage, file)
+			helpers.ResponseInternalServerError(c, "Gagal menyimpan thumbnail: "+err.Error())
+			return
+		}
+		file.ThumbnailKey = &key
+	}
+
+	var replaced []models.ParticipantFile
+	err := fc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("participant_id = ? AND kind = ?", participant.ID, kind).Find(&replaced).Error; err != nil {
+			return err
+		}
+		if len(replaced) > 0 {
+			if err := tx.Delete(&replaced).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&file).Error; err != nil {
+			return err
+		}
+		if question != nil {
+			return questions.SetFileAnswer(tx, participant.ID, question.ID, file.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		removeFileObjects(fc.Storage, file)
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	// Object file lama dihapus setelah commit; kegagalan hanya dicatat
+	for _, old := range replaced {
+		removeFileObjects(fc.Storage, old)
+	}
+
+	helpers.ResponseCreated(c, "File uploaded successfully", fileResponse(file))
+}
+
+// removeFileObjects menghapus file dan thumbnail dari storage; kegagalan hanya dicatat
+func removeFileObjects(store storage.Storage, f models.ParticipantFile) {
+	keys := []string{f.StorageKey}
+	if f.ThumbnailKey != nil {
+		keys = append(keys, *f.ThumbnailKey)
+	}
+	for _, key := range keys {
+		if err := store.Delete(context.Background(), key); err != nil {
+			log.Printf("ERROR: Gagal menghapus file %s dari storage: %v", key, err)
+		}
+	}
+}
+
+// ListFiles mengambil file participant beserta signed URL download
+func (fc *FileController) ListFiles(c *gin.Context) {
+	participant, ok := fc.findParticipant(c)
+	if !ok {
+		return
+	}
+	var files []models.ParticipantFile
+	if err := fc.DB.Where("participant_id = ?", participant.ID).Order("created_at asc").Find(&files).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	data := make([]gin.H, len(files))
+	for i, f := range files {
+		data[i] = fileResponse(f)
+	}
+	helpers.ResponseSuccess(c, "Files retrieved successfully", gin.H{"files": data})
+}
+
+// DeleteFile menghapus file participant (jawaban pertanyaan tipe file ikut dihapus)
+func (fc *FileController) DeleteFile(c *gin.Context) {
+	participant, ok := fc.findParticipant(c)
+	if !ok {
+		return
+	}
+	var file models.ParticipantFile
+	if err := fc.DB.Where("id = ? AND participant_id = ?", c.Param("fileId"), participant.ID).First(&file).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "File not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	if err := fc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("participant_id = ? AND value = ?", participant.ID, file.ID).Delete(&models.ParticipantAnswer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&file).Error
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	removeFileObjects(fc.Storage, file)
+
+	helpers.ResponseSuccess(c, "File deleted successfully", nil)
+}
+
+// DownloadFile mengirim isi file lewat signed URL (public, tanpa token login)
+func (fc *FileController) DownloadFile(c *gin.Context) {
+	id := c.Param("id")
+	variant := c.Query("variant")
+	if err := fileSigner().VerifyDownload(id, variant, c.Query("expires"), c.Query("signature"), time.Now()); err != nil {
+		helpers.ResponseError(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	var file models.ParticipantFile
+	if err := fc.DB.Where("id = ?", id).First(&file).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "File not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	key, contentType, name := file.StorageKey, file.ContentType, file.OriginalName
+	if variant == "thumb" {
+		if file.ThumbnailKey == nil {
+			helpers.ResponseNotFound(c, "Thumbnail not found")
+			return
+		}
+		key, contentType, name = *file.ThumbnailKey, uploads.TypeJPEG, "thumb_"+file.ID+".jpg"
+	}
+
+	body, err := fc.Storage.Get(c.Request.Context(), key)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			helpers.ResponseNotFound(c, "File not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, -1, contentType, body, map[string]string{
+		"Content-Disposition": "inline; filename=\"" + sanitizeFilename(name) + "\"",
+	})
+}
+
+// readFormFile membaca multipart field "file" dengan batas ukuran. Response error sudah dikirim jika ok false.
+func readFormFile(c *gin.Context, maxSize int64) ([]byte, string, bool) {
+	// Batas body sedikit di atas batas file untuk overhead multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			helpers.ResponseError(c, http.StatusRequestEntityTooLarge, "Ukuran file melebihi batas")
+			return nil, "", false
+		}
+		helpers.ResponseBadRequest(c, "Field file wajib diisi (multipart/form-data)")
+		return nil, "", false
+	}
+	if header.Size > maxSize {
+		helpers.ResponseError(c, http.StatusRequestEntityTooLarge, "Ukuran file melebihi batas")
+		return nil, "", false
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return nil, "", false
+	}
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	src.Close()
+	if err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return nil, "", false
+	}
+	if int64(len(data)) > maxSize {
+		helpers.ResponseError(c, http.StatusRequestEntityTooLarge, "Ukuran file melebihi batas")
+		return nil, "", false
+	}
+	if len(data) == 0 {
+		helpers.ResponseBadRequest(c, "File kosong")
+		return nil, "", false
+	}
+	return data, header.Filename, true
+}
+
+// sanitizeFilename membuang karakter yang bisa merusak header Content-Disposition
+func sanitizeFilename(name string) string {
+	b := []rune{}
+	for _, r := range name {
+		if r < 0x20 || r == '"' || r == '\\' || r == 0x7f {
+			continue
+		}
+		b = append(b, r)
+	}
+	if len(b) == 0 {
+		return "file"
+	}
+	return string(b)
+}
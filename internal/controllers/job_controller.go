@@ -0,0 +1,345 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/emails"
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/messaging"
+	"backend/internal/models"
+	"backend/internal/scheduler"
+)
+
+// JobController mengelola job terjadwal dan riwayat eksekusinya (protected)
+type JobController struct {
+	DB        *gorm.DB
+	Scheduler *scheduler.Scheduler
+}
+
+// NewJobController membuat instance controller baru
+func NewJobController(db *gorm.DB, jobs *scheduler.Scheduler) *JobController {
+	return &JobController{DB: db, Scheduler: jobs}
+}
+
+// NewScheduler membuat scheduler job beserta jenis job bawaan dan job sistem pembersihan data.
+// Scheduler dijalankan & dihentikan oleh cmd/server.
+func NewScheduler(db *gorm.DB, messages *messaging.Service, outbox *emails.Queue) *scheduler.Scheduler {
+	jobs := scheduler.New(db, scheduler.ConfigFromEnv())
+	jobs.Register(scheduler.JobEventNotification, scheduler.EventNotification(db, messages, outbox))
+	jobs.Register(scheduler.JobPurgeExpired, scheduler.PurgeExpired(db, jobs.Config.RunRetention))
+	if jobs.Config.PurgeCron != "" {
+		if err := jobs.EnsureSystemJob(scheduler.SystemPurgeKey, "Bersihkan data kedaluwarsa", scheduler.JobPurgeExpired, jobs.Config.PurgeCron); err != nil {
+			log.Printf("ERROR: Gagal membuat job sistem %s: %v", scheduler.SystemPurgeKey, err)
+		}
+	}
+	return jobs
+}
+
+// applyJobForm memvalidasi form dan menyalin nilainya ke job, mengembalikan pesan error validasi jika ada
+func (jc *JobController) applyJobForm(form forms.JobForm, job *models.ScheduledJob) (string, error) {
+	if job.SystemKey != nil && (form.Type != job.Type || form.Trigger != job.Trigger) {
+		return "Job sistem hanya bisa diubah nama dan jadwal cron-nya", nil
+	}
+	for _, s := range form.Params.Statuses {
+		if !models.IsValidParticipantStatus(s) {
+			return "params.statuses: status " + s + " tidak dikenal", nil
+		}
+	}
+	var event *models.Event
+	if form.EventID != nil {
+		event = &models.Event{}
+		if err := jc.DB.First(event, *form.EventID).Error; err == gorm.ErrRecordNotFound {
+			return "event_id tidak ditemukan", nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+
+	// Mengubah jadwal job event yang sudah selesai membuatnya dijadwalkan lagi
+	if job.Trigger != form.Trigger || job.Anchor != form.Anchor || job.Offset != form.Offset ||
+		job.TimeOfDay != form.TimeOfDay || !sameEventID(job.EventID, form.EventID) {
+		job.CompletedAt = nil
+	}
+	job.Name = form.Name
+	job.Type = form.Type
+	job.Trigger = form.Trigger
+	job.EventID = form.EventID
+	job.Cron, job.Anchor, job.Offset, job.TimeOfDay = "", "", "", ""
+	if form.Trigger == models.TriggerCron {
+		job.Cron = form.Cron
+	} else {
+		job.Anchor = form.Anchor
+		job.Offset = form.Offset
+		job.TimeOfDay = form.TimeOfDay
+	}
+	job.Params = models.JobParams{
+		Template: form.Params.Template,
+		Statuses: form.Params.Statuses,
+		Channels: form.Params.Channels,
+	}
+	if form.Paused != nil {
+		job.Paused = *form.Paused
+	}
+	if err := jc.Scheduler.Validate(job); err != nil {
+		return err.Error(), nil
+	}
+	if err := jc.Scheduler.Schedule(job, event); err != nil {
+		return err.Error(), nil
+	}
+	// Job event baru yang waktunya sudah lewat tidak akan pernah jalan sesuai jadwal
+	if job.Trigger == models.TriggerEvent && job.CompletedAt == nil && job.NextRunAt != nil &&
+		job.NextRunAt.Before(time.Now().Add(-time.Minute)) {
+		return "Waktu jalan job (" + job.NextRunAt.In(time.Local).Format("2006-01-02 15:04") + ") sudah lewat", nil
+	}
+	return "", nil
+}
+
+func sameEventID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// findJob mengambil job dari parameter :id
+func (jc *JobController) findJob(c *gin.Context) (*models.ScheduledJob, bool) {
+	var job models.ScheduledJob
+	if err := jc.DB.First(&job, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Job not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &job, true
+}
+
+// GetJobs mengambil job terjadwal beserta jenis job, anchor dan template yang tersedia.
+// Di route event hanya job milik event tersebut; filter type, trigger, paused dan event_id.
+func (jc *JobController) GetJobs(c *gin.Context) {
+	query := jc.DB.Model(&models.ScheduledJob{})
+	if event := eventFromContext(c); event != nil {
+		query = query.Where("event_id = ?", event.ID)
+	} else if eventID := c.Query("event_id"); eventID != "" {
+		query = query.Where("event_id = ?", eventID)
+	}
+	if jobType := c.Query("type"); jobType != "" {
+		query = query.Where("type = ?", jobType)
+	}
+	if trigger := c.Query("trigger"); trigger != "" {
+		query = query.Where("trigger_type = ?", trigger)
+	}
+	if paused, err := strconv.ParseBool(c.Query("paused")); err == nil {
+		query = query.Where("paused = ?", paused)
+	}
+	var jobs []models.ScheduledJob
+	if err := query.Order("id asc").Find(&jobs).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Jobs retrieved successfully", gin.H{
+		"jobs":      jobs,
+		"types":     jc.Scheduler.Types(),
+		"anchors":   scheduler.Anchors,
+		"templates": scheduler.NotificationTemplateKeys(),
+		"timezone":  time.Local.String(),
+	})
+}
+
+// CreateJob membuat job terjadwal. Di route event, job otomatis terhubung ke event tersebut.
+func (jc *JobController) CreateJob(c *gin.Context) {
+	var form forms.JobForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if event := eventFromContext(c); event != nil {
+		form.EventID = &event.ID
+	}
+	job := models.ScheduledJob{CreatedBy: currentActor(c)}
+	if msg, err := jc.applyJobForm(form, &job); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if msg != "" {
+		helpers.ResponseBadRequest(c, msg)
+		return
+	}
+	if err := jc.DB.Create(&job).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	jc.Scheduler.Kick()
+	helpers.ResponseCreated(c, "Job created successfully", job)
+}
+
+// GetJob mengambil satu job beserta 10 eksekusi terakhir
+func (jc *JobController) GetJob(c *gin.Context) {
+	job, ok := jc.findJob(c)
+	if !ok {
+		return
+	}
+	var runs []models.JobRun
+	if err := jc.DB.Where("job_id = ?", job.ID).Order("id desc").Limit(10).Find(&runs).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Job retrieved successfully", gin.H{"job": job, "recent_runs": runs})
+}
+
+// UpdateJob mengubah job; jadwal berikutnya dihitung ulang. Job sistem hanya bisa diubah nama & cron-nya.
+func (jc *JobController) UpdateJob(c *gin.Context) {
+	job, ok := jc.findJob(c)
+	if !ok {
+		return
+	}
+	var form forms.JobForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if msg, err := jc.applyJobForm(form, job); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if msg != "" {
+		helpers.ResponseBadRequest(c, msg)
+		return
+	}
+	// Kolom lease tidak ikut disimpan supaya tidak menimpa lease eksekusi yang sedang berjalan
+	if err := jc.DB.Model(job).Select("name", "type", "trigger_type", "cron", "event_id", "anchor", "anchor_offset", "time_of_day",
+		"params", "paused", "next_run_at", "completed_at", "updated_at").Updates(job).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	jc.Scheduler.Kick()
+	helpers.ResponseSuccess(c, "Job updated successfully", job)
+}
+
+// DeleteJob menghapus job beserta riwayat eksekusinya. Job sistem tidak bisa dihapus, hanya di-pause.
+func (jc *JobController) DeleteJob(c *gin.Context) {
+	job, ok := jc.findJob(c)
+	if !ok {
+		return
+	}
+	if job.SystemKey != nil {
+		helpers.ResponseError(c, http.StatusConflict, "Job sistem tidak bisa dihapus, gunakan pause untuk menonaktifkan")
+		return
+	}
+	if err := jc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", job.ID).Delete(&models.JobRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(job).Error
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Job deleted successfully", nil)
+}
+
+// PauseJob menghentikan sementara jadwal job; eksekusi yang sedang berjalan tetap diselesaikan
+func (jc *JobController) PauseJob(c *gin.Context) {
+	job, ok := jc.findJob(c)
+	if !ok {
+		return
+	}
+	job.Paused = true
+	if err := jc.DB.Model(job).Select("paused", "updated_at").Updates(job).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Job paused successfully", job)
+}
+
+// ResumeJob mengaktifkan kembali job. Jadwal berikutnya dihitung dari sekarang, sehingga
+// jadwal yang terlewat selama pause tidak dijalankan.
+func (jc *JobController) ResumeJob(c *gin.Context) {
+	job, ok := jc.findJob(c)
+	if !ok {
+		return
+	}
+	if err := jc.Scheduler.Schedule(job, nil); err != nil {
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, "Gagal menghitung jadwal job: "+err.Error())
+		return
+	}
+	job.Paused = false
+	if err := jc.DB.Model(job).Select("paused", "next_run_at", "updated_at").Updates(job).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	jc.Scheduler.Kick()
+	helpers.ResponseSuccess(c, "Job resumed successfully", job)
+}
+
+// TriggerJob menjalankan job sekarang di background tanpa mengubah jadwalnya (juga untuk job yang di-pause)
+func (jc *JobController) TriggerJob(c *gin.Context) {
+	job, ok := jc.findJob(c)
+	if !ok {
+		return
+	}
+	run, err := jc.Scheduler.Trigger(*job, currentActor(c))
+	if errors.Is(err, scheduler.ErrLocked) {
+		helpers.ResponseError(c, http.StatusConflict, "Job sedang berjalan, coba lagi setelah selesai")
+		return
+	} else if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Job dijalankan, cek hasilnya di riwayat eksekusi",
+		"data":    run,
+	})
+}
+
+// GetJobRuns mengambil riwayat eksekusi job, filter status
+func (jc *JobController) GetJobRuns(c *gin.Context) {
+	job, ok := jc.findJob(c)
+	if !ok {
+		return
+	}
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	query := jc.DB.Model(&models.JobRun{}).Where("job_id = ?", job.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var runs []models.JobRun
+	if err := query.Order("id desc").Offset((page - 1) * limit).Limit(limit).Find(&runs).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	helpers.ResponseSuccess(c, "Job runs retrieved successfully", gin.H{
+		"runs": runs,
+		"pagination": gin.H{
+			"current_page": page,
+			"per_page":     limit,
+			"total_items":  total,
+			"total_pages":  totalPages,
+			"has_next":     page < totalPages,
+			"has_prev":     page > 1,
+		},
+	})
+}
@@ -0,0 +1,371 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/masterdata"
+	"backend/internal/models"
+)
+
+type MasterDataController struct {
+	DB *gorm.DB
+}
+
+// NewMasterDataController membuat instance controller baru
+func NewMasterDataController(db *gorm.DB) *MasterDataController {
+	return &MasterDataController{DB: db}
+}
+
+// autocomplete mengirim hasil pencarian data master untuk form registrasi
+func (mc *MasterDataController) autocomplete(c *gin.Context, field masterdata.Field, key string) {
+	limit := 10
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 50 {
+		limit = l
+	}
+	results, err := masterdata.Search(mc.DB, field, c.Query("q"), limit)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	// Data master jarang berubah, boleh di-cache browser sebentar
+	c.Header("Cache-Control", "public, max-age=300")
+	helpers.ResponseSuccess(c, "Data retrieved successfully", gin.H{key: results})
+}
+
+// SearchCampuses autocomplete kampus untuk form registrasi (public), query q & limit
+func (mc *MasterDataController) SearchCampuses(c *gin.Context) {
+	mc.autocomplete(c, masterdata.Kampus, "campuses")
+}
+
+// SearchStudyPrograms autocomplete jurusan untuk form registrasi (public), query q & limit
+func (mc *MasterDataController) SearchStudyPrograms(c *gin.Context) {
+	mc.autocomplete(c, masterdata.Jurusan, "study_programs")
+}
+
+// nameTaken mengecek nama data master yang sudah dipakai entry lain
+func (mc *MasterDataController) nameTaken(model interface{}, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := mc.DB.Model(model).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// renameLinked menyamakan teks kampus / jurusan participant yang tertaut setelah nama kanonik diubah
+func renameLinked(db *gorm.DB, field masterdata.Field, id uint, name string) error {
+	return db.Model(&models.Participant{}).Where(field.IDColumn+" = ?", id).
+		UpdateColumn(field.Column, name).Error
+}
+
+// linkedCount menghitung participant yang tertaut ke entry data master
+func (mc *MasterDataController) linkedCount(field masterdata.Field, id uint) (int64, error) {
+	var count int64
+	err := mc.DB.Model(&models.Participant{}).Where(field.IDColumn+" = ?", id).Count(&count).Error
+	return count, err
+}
+
+// CreateCampus menambah data master kampus (protected)
+func (mc *MasterDataController) CreateCampus(c *gin.Context) {
+	var form forms.CampusForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if taken, err := mc.nameTaken(&models.Campus{}, form.Name, 0); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if taken {
+		helpers.ResponseConflict(c, "Kampus dengan nama ini sudah ada")
+		return
+	}
+	campus := models.Campus{Name: form.Name, ShortName: form.ShortName, City: form.City, Aliases: form.Aliases}
+	if campus.Aliases == nil {
+		campus.Aliases = []string{}
+	}
+	if err := mc.DB.Create(&campus).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseCreated(c, "Campus created successfully", campus)
+}
+
+// UpdateCampus mengubah data master kampus, participant yang tertaut ikut memakai nama baru (protected)
+func (mc *MasterDataController) UpdateCampus(c *gin.Context) {
+	var campus models.Campus
+	if err := mc.DB.First(&campus, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Campus not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var form forms.CampusForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if taken, err := mc.nameTaken(&models.Campus{}, form.Name, campus.ID); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if taken {
+		helpers.ResponseConflict(c, "Kampus dengan nama ini sudah ada")
+		return
+	}
+
+	renamed := campus.Name != form.Name
+	campus.Name = form.Name
+	campus.ShortName = form.ShortName
+	campus.City = form.City
+	campus.Aliases = form.Aliases
+	if campus.Aliases == nil {
+		campus.Aliases = []string{}
+	}
+	if err := mc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&campus).Error; err != nil {
+			return err
+		}
+		if !renamed {
+			return nil
+		}
+		return renameLinked(tx, masterdata.Kampus, campus.ID, campus.Name)
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Campus updated successfully", campus)
+}
+
+// DeleteCampus menghapus data master kampus yang belum tertaut ke participant (protected)
+func (mc *MasterDataController) DeleteCampus(c *gin.Context) {
+	var campus models.Campus
+	if err := mc.DB.First(&campus, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Campus not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	linked, err := mc.linkedCount(masterdata.Kampus, campus.ID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if linked > 0 {
+		helpers.ResponseConflict(c, "Kampus masih tertaut ke "+strconv.FormatInt(linked, 10)+" participant")
+		return
+	}
+	if err := mc.DB.Delete(&campus).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Campus deleted successfully", nil)
+}
+
+// CreateStudyProgram menambah data master jurusan (protected)
+func (mc *MasterDataController) CreateStudyProgram(c *gin.Context) {
+	var form forms.StudyProgramForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if taken, err := mc.nameTaken(&models.StudyProgram{}, form.Name, 0); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if taken {
+		helpers.ResponseConflict(c, "Jurusan dengan nama ini sudah ada")
+		return
+	}
+	program := models.StudyProgram{Name: form.Name, Aliases: form.Aliases}
+	if program.Aliases == nil {
+		program.Aliases = []string{}
+	}
+	if err := mc.DB.Create(&program).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseCreated(c, "Study program created successfully", program)
+}
+
+// UpdateStudyProgram mengubah data master jurusan, participant yang tertaut ikut memakai nama baru (protected)
+func (mc *MasterDataController) UpdateStudyProgram(c *gin.Context) {
+	var program models.StudyProgram
+	if err := mc.DB.First(&program, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Study program not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var form forms.StudyProgramForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if taken, err := mc.nameTaken(&models.StudyProgram{}, form.Name, program.ID); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if taken {
+		helpers.ResponseConflict(c, "Jurusan dengan nama ini sudah ada")
+		return
+	}
+
+	renamed := program.Name != form.Name
+	program.Name = form.Name
+	program.Aliases = form.Aliases
+	if program.Aliases == nil {
+		program.Aliases = []string{}
+	}
+	if err := mc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&program).Error; err != nil {
+			return err
+		}
+		if !renamed {
+			return nil
+		}
+		return renameLinked(tx, masterdata.Jurusan, program.ID, program.Name)
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Study program updated successfully", program)
+}
+
+// DeleteStudyProgram menghapus data master jurusan yang belum tertaut ke participant (protected)
+func (mc *MasterDataController) DeleteStudyProgram(c *gin.Context) {
+	var program models.StudyProgram
+	if err := mc.DB.First(&program, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Study program not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	linked, err := mc.linkedCount(masterdata.Jurusan, program.ID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if linked > 0 {
+		helpers.ResponseConflict(c, "Jurusan masih tertaut ke "+strconv.FormatInt(linked, 10)+" participant")
+		return
+	}
+	if err := mc.DB.Delete(&program).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Study program deleted successfully", nil)
+}
+
+// GetUnmapped mengambil teks bebas kampus / jurusan yang belum tertaut beserta saran data master (protected)
+func (mc *MasterDataController) GetUnmapped(c *gin.Context) {
+	field, ok := masterdata.Fields[c.Param("field")]
+	if !ok {
+		helpers.ResponseBadRequest(c, masterdata.ErrUnknownField.Error())
+		return
+	}
+	values, err := masterdata.Unmapped(mc.DB, field)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var participants int64
+	for _, v := range values {
+		participants += v.Total
+	}
+	helpers.ResponseSuccess(c, "Unmapped values retrieved successfully", gin.H{
+		"field":        field.Name,
+		"values":       values,
+		"participants": participants,
+	})
+}
+
+// MapValues menautkan teks bebas participant ke satu entry data master (protected)
+func (mc *MasterDataController) MapValues(c *gin.Context) {
+	var form forms.MapMasterDataForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	field := masterdata.Fields[form.Field]
+	entry, err := masterdata.Find(mc.DB, field, form.TargetID)
+	if err != nil {
+		if err == masterdata.ErrUnknownEntry {
+			helpers.ResponseNotFound(c, "target_id: "+err.Error())
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	var updated int64
+	if err := mc.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if updated, err = masterdata.Apply(tx, field, *entry, form.Values); err != nil {
+			return err
+		}
+		if !form.AddAliases {
+			return nil
+		}
+		return masterdata.AddAliases(tx, field, entry.ID, form.Values)
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Values mapped successfully", gin.H{
+		"field":   field.Name,
+		"target":  entry,
+		"updated": updated,
+	})
+}
+
+// AutoMapValues menautkan semua teks bebas yang cocok persis (setelah normalisasi) dengan data master (protected)
+func (mc *MasterDataController) AutoMapValues(c *gin.Context) {
+	var form forms.AutoMapMasterDataForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	field := masterdata.Fields[form.Field]
+	values, err := masterdata.Unmapped(mc.DB, field)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	type mapped struct {
+		Value  string           `json:"value"`
+		Target masterdata.Entry `json:"target"`
+		Total  int64            `json:"total"`
+	}
+	results := []mapped{}
+	var updated int64
+	if err := mc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, v := range values {
+			if v.Suggestion == nil {
+				continue
+			}
+			n, err := masterdata.Apply(tx, field, *v.Suggestion, []string{v.Value})
+			if err != nil {
+				return err
+			}
+			updated += n
+			results = append(results, mapped{Value: v.Value, Target: *v.Suggestion, Total: n})
+		}
+		return nil
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Values mapped successfully", gin.H{
+		"field":   field.Name,
+		"mapped":  results,
+		"updated": updated,
+	})
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// newMasterDataServer adalah participantServer ditambah endpoint data master seperti di router
+func newMasterDataServer(t *testing.T) *participantServer {
+	s := newParticipantServer(t)
+	mc := NewMasterDataController(s.db)
+	s.engine.GET("/campuses", mc.SearchCampuses)
+	s.engine.POST("/campuses", mc.CreateCampus)
+	s.engine.PUT("/campuses/:id", mc.UpdateCampus)
+	s.engine.DELETE("/campuses/:id", mc.DeleteCampus)
+	s.engine.GET("/master-data/unmapped/:field", mc.GetUnmapped)
+	s.engine.POST("/master-data/map", mc.MapValues)
+	s.engine.POST("/master-data/auto-map", mc.AutoMapValues)
+	return s
+}
+
+// campus membuat kampus lewat endpoint admin
+func (s *participantServer) campus(t *testing.T, body map[string]interface{}) models.Campus {
+	t.Helper()
+	var c models.Campus
+	decode(t, s.do(http.MethodPost, "/campuses", body), http.StatusCreated).into(t, &c)
+	return c
+}
+
+func TestRegistrationResolvesMasterData(t *testing.T) {
+	s := newMasterDataServer(t)
+	ui := s.campus(t, map[string]interface{}{"name": "Universitas Indonesia", "short_name": "UI", "aliases": []string{"Kampus Depok"}})
+	s.db.Create(&models.StudyProgram{Name: "Teknik Informatika", Aliases: []string{"TI"}})
+
+	// ID dari autocomplete: teks kampus tidak wajib dan diganti nama kanonik
+	p := s.register(t, "", http.StatusCreated, map[string]interface{}{"kampus": "", "campus_id": ui.ID, "jurusan": "ti"})
+	if p.CampusID == nil || *p.CampusID != ui.ID || p.Kampus != "Universitas Indonesia" || p.StudyProgramID == nil || p.Jurusan != "Teknik Informatika" {
+		t.Errorf("participant = %v %q / %v %q", p.CampusID, p.Kampus, p.StudyProgramID, p.Jurusan)
+	}
+	// Teks bebas yang cocok alias ditautkan otomatis, yang tidak cocok disimpan sebagai "lainnya"
+	p = s.register(t, "", http.StatusCreated, map[string]interface{}{"name": "Citra", "phone": "085711112222", "kampus": "kampus depok"})
+	if p.CampusID == nil || p.Kampus != "Universitas Indonesia" {
+		t.Errorf("alias: %v %q", p.CampusID, p.Kampus)
+	}
+	p = s.register(t, "", http.StatusCreated, map[string]interface{}{"name": "Dewi", "phone": "089900001111", "kampus": " Universitas Terbuka "})
+	if p.CampusID != nil || p.Kampus != "Universitas Terbuka" || p.StudyProgramID != nil {
+		t.Errorf("free text: %v %q, jurusan %v", p.CampusID, p.Kampus, p.StudyProgramID)
+	}
+
+	res := decode(t, s.do(http.MethodPost, "/participants", registration(map[string]interface{}{"campus_id": 99})), http.StatusBadRequest)
+	if res.Error != "campus_id: data master tidak ditemukan" {
+		t.Errorf("unknown campus_id: %q", res.Error)
+	}
+	decode(t, s.do(http.MethodPost, "/participants", registration(map[string]interface{}{"kampus": ""})), http.StatusBadRequest)
+
+	var found struct {
+		Campuses []struct {
+			Name string `json:"name"`
+		} `json:"campuses"`
+	}
+	w := s.do(http.MethodGet, "/campuses?q=depok", nil)
+	decode(t, w, http.StatusOK).into(t, &found)
+	if len(found.Campuses) != 1 || found.Campuses[0].Name != "Universitas Indonesia" || w.Header().Get("Cache-Control") == "" {
+		t.Errorf("autocomplete = %+v, Cache-Control %q", found.Campuses, w.Header().Get("Cache-Control"))
+	}
+}
+
+func TestMapUnmappedValues(t *testing.T) {
+	s := newMasterDataServer(t)
+	ugm := s.campus(t, map[string]interface{}{"name": "Universitas Gadjah Mada", "short_name": "UGM"})
+	itb := s.campus(t, map[string]interface{}{"name": "Institut Teknologi Bandung", "short_name": "ITB"})
+	for _, kampus := range []string{"Univ. Gadjah Mada", "ugm", "Gajah Mada", "gajah mada", "Kampus Lain"} {
+		testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) { p.Kampus = kampus })
+	}
+
+	var unmapped struct {
+		Values []struct {
+			Value      string `json:"value"`
+			Total      int64  `json:"total"`
+			Suggestion *struct {
+				ID uint `json:"id"`
+			} `json:"suggestion"`
+		} `json:"values"`
+		Participants int64 `json:"participants"`
+	}
+	decode(t, s.do(http.MethodGet, "/master-data/unmapped/kampus", nil), http.StatusOK).into(t, &unmapped)
+	if len(unmapped.Values) != 4 || unmapped.Participants != 5 || unmapped.Values[0].Total != 2 || unmapped.Values[0].Suggestion != nil {
+		t.Fatalf("unmapped = %+v", unmapped)
+	}
+	decode(t, s.do(http.MethodGet, "/master-data/unmapped/fakultas", nil), http.StatusBadRequest)
+
+	// Auto-map hanya menautkan teks yang cocok persis setelah normalisasi
+	var auto struct {
+		Updated int64 `json:"updated"`
+	}
+	decode(t, s.do(http.MethodPost, "/master-data/auto-map", map[string]string{"field": "kampus"}), http.StatusOK).into(t, &auto)
+	if auto.Updated != 2 {
+		t.Errorf("auto-mapped = %d, want 2", auto.Updated)
+	}
+
+	// Map manual dengan alias: pendaftar berikutnya yang menulis sama ikut tertaut
+	body := map[string]interface{}{"field": "kampus", "values": []string{"GAJAH MADA"}, "target_id": ugm.ID, "add_aliases": true}
+	var mapped struct {
+		Updated int64 `json:"updated"`
+	}
+	decode(t, s.do(http.MethodPost, "/master-data/map", body), http.StatusOK).into(t, &mapped)
+	if mapped.Updated != 2 {
+		t.Errorf("mapped = %d, want 2", mapped.Updated)
+	}
+	p := s.register(t, "", http.StatusCreated, map[string]interface{}{"kampus": "Gajah Mada"})
+	if p.CampusID == nil || *p.CampusID != ugm.ID {
+		t.Errorf("registration after alias: %v %q", p.CampusID, p.Kampus)
+	}
+	body["target_id"] = 99
+	decode(t, s.do(http.MethodPost, "/master-data/map", body), http.StatusNotFound)
+
+	var left int64
+	s.db.Model(&models.Participant{}).Where("campus_id IS NULL").Count(&left)
+	if left != 1 {
+		t.Errorf("unmapped participants = %d, want 1 (Kampus Lain)", left)
+	}
+
+	// Ganti nama kanonik ikut mengganti teks participant yang tertaut; kampus tertaut tidak bisa dihapus
+	decode(t, s.do(http.MethodPut, fmt.Sprintf("/campuses/%d", ugm.ID), map[string]interface{}{"name": "Institut Teknologi Bandung"}), http.StatusConflict)
+	decode(t, s.do(http.MethodPut, fmt.Sprintf("/campuses/%d", ugm.ID), map[string]interface{}{"name": "UGM Yogyakarta", "short_name": "UGM"}), http.StatusOK)
+	var renamed int64
+	s.db.Model(&models.Participant{}).Where("campus_id = ? AND kampus = ?", ugm.ID, "UGM Yogyakarta").Count(&renamed)
+	if renamed != 5 {
+		t.Errorf("renamed participants = %d, want 5", renamed)
+	}
+	if res := decode(t, s.do(http.MethodDelete, fmt.Sprintf("/campuses/%d", ugm.ID), nil), http.StatusConflict); res.Error != "Kampus masih tertaut ke 5 participant" {
+		t.Errorf("delete linked: %q", res.Error)
+	}
+	decode(t, s.do(http.MethodDelete, fmt.Sprintf("/campuses/%d", itb.ID), nil), http.StatusOK)
+}
@@ -0,0 +1,383 @@
+package controllers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/emails"
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/messaging"
+	"backend/internal/models"
+)
+
+// MessageController mengelola pesan WhatsApp / SMS: template, status kirim per participant,
+// opt-out, pengingat event (juga lewat email) dan callback status dari provider
+type MessageController struct {
+	DB           *gorm.DB
+	Messages     *messaging.Service
+	Emails       *emails.Queue
+	Participants *ParticipantController
+}
+
+// NewMessageController membuat instance controller baru
+func NewMessageController(db *gorm.DB, messages *messaging.Service, outbox *emails.Queue, participants *ParticipantController) *MessageController {
+	return &MessageController{DB: db, Messages: messages, Emails: outbox, Participants: participants}
+}
+
+// NewMessagingService membuat service WhatsApp / SMS dari environment dengan link tiket & portal
+// untuk placeholder template. Panggil Start untuk menjalankan worker antrian.
+func NewMessagingService(db *gorm.DB) *messaging.Service {
+	service := messaging.NewServiceFromEnv(db)
+	service.TicketURL = ticketURL
+	service.PortalURL = getPortalURL()
+	return service
+}
+
+// templateResponse menyusun template untuk response admin
+func templateResponse(tpl models.MessageTemplate) gin.H {
+	return gin.H{
+		"key":               tpl.Key,
+		"body":              tpl.Body,
+		"default_body":      messaging.DefaultTemplates[tpl.Key],
+		"customized":        tpl.ID != 0,
+		"whatsapp_template": tpl.WhatsAppTemplate,
+		"whatsapp_language": tpl.WhatsAppLanguage,
+		"whatsapp_params":   tpl.WhatsAppParams,
+		"active":            tpl.Active,
+		"updated_by":        tpl.UpdatedBy,
+		"updated_at":        tpl.UpdatedAt,
+	}
+}
+
+// findTemplateKey memastikan :key adalah jenis pesan yang dikenal
+func findTemplateKey(c *gin.Context) (string, bool) {
+	key := c.Param("key")
+	if _, ok := messaging.DefaultTemplates[key]; !ok {
+		helpers.ResponseNotFound(c, "Message template not found")
+		return "", false
+	}
+	return key, true
+}
+
+// GetTemplates mengambil semua template pesan beserta placeholder yang tersedia (protected)
+func (mc *MessageController) GetTemplates(c *gin.Context) {
+	keys := messaging.TemplateKeys()
+	templates := make([]gin.H, 0, len(keys))
+	for _, key := range keys {
+		tpl, err := messaging.LoadTemplate(mc.DB, key)
+		if err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		templates = append(templates, templateResponse(tpl))
+	}
+	helpers.ResponseSuccess(c, "Message templates retrieved successfully", gin.H{
+		"templates": templates,
+		"variables": messaging.Variables,
+		"channel":   mc.Messages.Config.Channel,
+		"enabled":   mc.Messages.Enabled(),
+	})
+}
+
+// UpdateTemplate menyimpan teks pesan versi admin untuk satu jenis pesan (protected)
+func (mc *MessageController) UpdateTemplate(c *gin.Context) {
+	key, ok := findTemplateKey(c)
+	if !ok {
+		return
+	}
+	var form forms.MessageTemplateForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	for _, param := range form.WhatsAppParams {
+		if !isMessageVariable(param) {
+			helpers.ResponseBadRequest(c, "whatsapp_params: placeholder "+param+" tidak dikenal")
+			return
+		}
+	}
+
+	tpl, err := messaging.LoadTemplate(mc.DB, key)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	tpl.Body = form.Body
+	tpl.WhatsAppTemplate = form.WhatsAppTemplate
+	tpl.WhatsAppLanguage = form.WhatsAppLanguage
+	tpl.WhatsAppParams = form.WhatsAppParams
+	if tpl.WhatsAppParams == nil {
+		tpl.WhatsAppParams = []string{}
+	}
+	if form.Active != nil {
+		tpl.Active = *form.Active
+	}
+	tpl.UpdatedBy = currentActor(c)
+	if err := mc.DB.Save(&tpl).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Message template updated successfully", templateResponse(tpl))
+}
+
+// ResetTemplate menghapus versi admin sehingga jenis pesan kembali memakai teks bawaan (protected)
+func (mc *MessageController) ResetTemplate(c *gin.Context) {
+	key, ok := findTemplateKey(c)
+	if !ok {
+		return
+	}
+	if err := mc.DB.Where("template_key = ?", key).Delete(&models.MessageTemplate{}).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	tpl, err := messaging.LoadTemplate(mc.DB, key)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Message template reset to default", templateResponse(tpl))
+}
+
+// PreviewTemplate merender template (tersimpan atau dari body) dengan data participant atau contoh (protected)
+func (mc *MessageController) PreviewTemplate(c *gin.Context) {
+	key, ok := findTemplateKey(c)
+	if !ok {
+		return
+	}
+	var form forms.MessagePreviewForm
+	// Body opsional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+	}
+	body := form.Body
+	if body == "" {
+		tpl, err := messaging.LoadTemplate(mc.DB, key)
+		if err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		body = tpl.Body
+	}
+
+	code := "ABCD-1234"
+	participant := models.Participant{Name: "Budi Santoso", Status: models.StatusApproved, RegistrationCode: &code}
+	var event *models.Event
+	if form.ParticipantID != "" {
+		if err := mc.DB.Where("id = ?", form.ParticipantID).First(&participant).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				helpers.ResponseNotFound(c, "Participant not found")
+				return
+			}
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		if participant.EventID != nil {
+			event = &models.Event{}
+			if err := mc.DB.First(event, *participant.EventID).Error; err != nil {
+				helpers.ResponseInternalServerError(c, err.Error())
+				return
+			}
+		}
+	}
+	helpers.ResponseSuccess(c, "Message template rendered successfully", gin.H{
+		"key":  key,
+		"body": messaging.Render(body, mc.Messages.Vars(participant, event)),
+	})
+}
+
+// isMessageVariable mengecek nama placeholder template pesan
+func isMessageVariable(name string) bool {
+	for _, v := range messaging.Variables {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findParticipant mengambil participant dari parameter :id sesuai scope event
+func (mc *MessageController) findParticipant(c *gin.Context) (*models.Participant, bool) {
+	var participant models.Participant
+	if err := mc.Participants.scopedParticipants(c).Where("id = ?", c.Param("id")).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &participant, true
+}
+
+// optOutRecord mengambil data opt-out nomor HP (nil jika masih berlangganan)
+func (mc *MessageController) optOutRecord(phone string) (*models.MessageOptOut, error) {
+	var optOut models.MessageOptOut
+	err := mc.DB.Where("phone_key = ?", helpers.PhoneKey(phone)).First(&optOut).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &optOut, nil
+}
+
+// GetParticipantMessages mengambil riwayat & status pesan WhatsApp / SMS participant (protected)
+func (mc *MessageController) GetParticipantMessages(c *gin.Context) {
+	participant, ok := mc.findParticipant(c)
+	if !ok {
+		return
+	}
+	var messages []models.OutboundMessage
+	if err := mc.DB.Where("participant_id = ?", participant.ID).Order("created_at desc, id desc").Find(&messages).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	optOut, err := mc.optOutRecord(participant.Phone)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Participant messages retrieved successfully", gin.H{
+		"messages":  messages,
+		"opted_out": optOut != nil,
+		"opt_out":   optOut,
+		"phone":     participant.Phone,
+		"channel":   mc.Messages.Config.Channel,
+		"enabled":   mc.Messages.Enabled(),
+	})
+}
+
+// OptOutParticipant menghentikan WhatsApp / SMS ke nomor HP participant (protected)
+func (mc *MessageController) OptOutParticipant(c *gin.Context) {
+	participant, ok := mc.findParticipant(c)
+	if !ok {
+		return
+	}
+	var form forms.OptOutForm
+	// Body opsional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+	}
+	if err := mc.Messages.OptOut(participant.Phone, "admin", form.Reason, currentActor(c)); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Participant opted out of WhatsApp/SMS messages", gin.H{"opted_out": true})
+}
+
+// OptInParticipant mengaktifkan kembali WhatsApp / SMS ke nomor HP participant (protected)
+func (mc *MessageController) OptInParticipant(c *gin.Context) {
+	participant, ok := mc.findParticipant(c)
+	if !ok {
+		return
+	}
+	if err := mc.Messages.OptIn(participant.Phone); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Participant opted in to WhatsApp/SMS messages", gin.H{"opted_out": false})
+}
+
+// SendEventReminder mengantrikan pengingat event ke participant lewat WhatsApp / SMS dan email (protected, per event)
+func (mc *MessageController) SendEventReminder(c *gin.Context) {
+	event := eventFromContext(c)
+	var form forms.EventReminderForm
+	// Body opsional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+	}
+	for _, s := range form.Statuses {
+		if !models.IsValidParticipantStatus(s) {
+			helpers.ResponseBadRequest(c, "statuses: status "+s+" tidak dikenal")
+			return
+		}
+	}
+	channels := map[string]bool{"message": len(form.Channels) == 0, "email": len(form.Channels) == 0}
+	for _, ch := range form.Channels {
+		channels[ch] = true
+	}
+	sendMessages := channels["message"] && mc.Messages.Enabled()
+	sendEmails := channels["email"] && mc.Emails.Enabled()
+	if !sendMessages && !sendEmails {
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, "Pengiriman WhatsApp / SMS dan email tidak aktif, cek MESSAGING_CHANNEL dan MAIL_NOTIFICATIONS")
+		return
+	}
+
+	queued, queuedEmails := 0, 0
+	var err error
+	if sendMessages {
+		if queued, err = mc.Messages.RemindEvent(*event, form.Statuses); err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+	}
+	if sendEmails {
+		if queuedEmails, err = mc.Emails.RemindEvent(*event, form.Statuses); err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Event reminders queued",
+		"data":    gin.H{"queued": queued, "emails": queuedEmails},
+	})
+}
+
+// VerifyWhatsAppWebhook menjawab verifikasi URL callback dari Meta (hub.challenge)
+func (mc *MessageController) VerifyWhatsAppWebhook(c *gin.Context) {
+	token := os.Getenv("WHATSAPP_VERIFY_TOKEN")
+	if token == "" || c.Query("hub.mode") != "subscribe" || c.Query("hub.verify_token") != token {
+		helpers.ResponseError(c, http.StatusForbidden, "Verify token tidak cocok")
+		return
+	}
+	c.String(http.StatusOK, c.Query("hub.challenge"))
+}
+
+// WhatsAppWebhook menerima callback WhatsApp Cloud API: laporan status pesan (delivered, read, failed)
+// dan balasan participant (STOP / MULAI untuk opt-out / opt-in). Signature X-Hub-Signature-256 wajib valid.
+func (mc *MessageController) WhatsAppWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if !messaging.VerifyWhatsAppSignature(os.Getenv("WHATSAPP_APP_SECRET"), body, c.GetHeader("X-Hub-Signature-256")) {
+		helpers.ResponseUnauthorized(c, "Signature tidak valid")
+		return
+	}
+	statuses, inbound, err := messaging.ParseWhatsAppCallback(body)
+	if err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	for _, update := range statuses {
+		if err := mc.Messages.ApplyStatus(update); err != nil {
+			log.Printf("ERROR: Gagal mencatat status WhatsApp %s: %v", update.ProviderMessageID, err)
+		}
+	}
+	for _, msg := range inbound {
+		if err := mc.Messages.HandleInbound(msg); err != nil {
+			log.Printf("ERROR: Gagal memproses balasan WhatsApp dari %s: %v", msg.From, err)
+		}
+	}
+	// Meta mengulang callback yang tidak dibalas 200, jadi error internal hanya dicatat
+	helpers.ResponseSuccess(c, "Callback processed", gin.H{"statuses": len(statuses), "messages": len(inbound), "received_at": time.Now()})
+}
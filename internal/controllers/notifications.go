@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"log"
+
+	"backend/internal/emails"
+	"backend/internal/messaging"
+	"backend/internal/models"
+	"backend/internal/webhooks"
+	"backend/internal/workflow"
+)
+
+// notifyPromoted memberi tahu participant yang naik dari waitlist (email & WhatsApp / SMS) dan
+// webhook yang berlangganan (lewat antrian, error hanya dicatat)
+func notifyPromoted(hooks *webhooks.Dispatcher, messages *messaging.Service, outbox *emails.Queue, participants []models.Participant) {
+	for _, p := range participants {
+		log.Printf("Participant %s (%s) promoted from waitlist", p.ID, p.Name)
+		hooks.PublishStatusChange(p, models.StatusWaitlisted, "Dipromosikan dari waitlist karena ada kursi kosong", workflow.ActorSystem)
+		messages.Notify(messaging.TemplateWaitlistPromoted, p)
+		outbox.Notify(emails.TemplateWaitlistPromoted, p)
+	}
+}
@@ -1,25 +1,126 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"math"
+	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"backend/internal/antispam"
+	"backend/internal/duplicates"
+	"backend/internal/eligibility"
+	"backend/internal/emails"
 	"backend/internal/forms"
 	"backend/internal/helpers"
+	"backend/internal/mailer"
+	"backend/internal/masterdata"
+	"backend/internal/messaging"
 	"backend/internal/models"
+	"backend/internal/questions"
+	"backend/internal/ratelimit"
+	"backend/internal/search"
+	"backend/internal/storage"
+	"backend/internal/webhooks"
+	"backend/internal/workflow"
 )
 
 type ParticipantController struct {
-	DB *gorm.DB
+	DB         *gorm.DB
+	Search     *search.ParticipantIndex
+	Duplicates *duplicates.Detector
+	Mailer     mailer.Mailer
+	Storage    storage.Storage
+	Guard      *antispam.Guard
+	Webhooks   *webhooks.Dispatcher
+	Messages   *messaging.Service
+	Emails     *emails.Queue
+}
+
+// getDuplicatePolicy mendapatkan policy duplikat dari environment (reject, flag, allow)
+func getDuplicatePolicy() duplicates.Policy {
+	return duplicates.ParsePolicy(os.Getenv("DUPLICATE_POLICY"))
 }
 
 // NewParticipantController membuat instance controller baru
-func NewParticipantController(db *gorm.DB) *ParticipantController {
-	return &ParticipantController{DB: db}
+func NewParticipantController(db *gorm.DB, mail mailer.Mailer, store storage.Storage, hooks *webhooks.Dispatcher, messages *messaging.Service, outbox *emails.Queue) *ParticipantController {
+	index := search.NewParticipantIndex(db)
+
+	detector := duplicates.NewDetector(db)
+
+	guard := antispam.NewGuard(antispam.ConfigFromEnv(), getAntispamSecret(), antispam.NewVerifierFromEnv())
+	return &ParticipantController{DB: db, Search: index, Duplicates: detector, Mailer: mail, Storage: store, Guard: guard, Webhooks: hooks, Messages: messages, Emails: outbox}
+}
+
+// getAntispamSecret adalah secret HMAC form_token registrasi (default JWT_SECRET)
+func getAntispamSecret() []byte {
+	if secret := helpers.GetEnv("ANTISPAM_SECRET", ""); secret != "" {
+		return []byte(secret)
+	}
+	return getJWTSecret()
+}
+
+// RegistrationRateLimiter membuat limiter per IP untuk registrasi publik (default 30 request / 10 menit, 0 = tanpa batas)
+func RegistrationRateLimiter() *ratelimit.Limiter {
+	return ratelimit.New(helpers.GetEnvInt("REGISTRATION_RATE_LIMIT", 30), helpers.GetEnvDuration("REGISTRATION_RATE_WINDOW", 10*time.Minute))
+}
+
+// GetRegistrationChallenge membuat form_token dan konfigurasi proteksi spam untuk form registrasi (public)
+func (pc *ParticipantController) GetRegistrationChallenge(c *gin.Context) {
+	challenge, err := pc.Guard.NewChallenge(time.Now())
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	helpers.ResponseSuccess(c, "Registration challenge created", challenge)
+}
+
+// scopedParticipants membuat query participant yang dibatasi ke event di context (jika route di-scope per event)
+func (pc *ParticipantController) scopedParticipants(c *gin.Context) *gorm.DB {
+	query := pc.DB.Model(&models.Participant{})
+	if event := eventFromContext(c); event != nil {
+		query = query.Where("event_id = ?", event.ID)
+	}
+	return query
+}
+
+// resolveMasterData mengisi kampus & jurusan participant dari form: ID data master dari autocomplete,
+// atau teks bebas yang dicocokkan otomatis ke data master (tetap disimpan sebagai "lainnya" jika tidak cocok)
+func resolveMasterData(db *gorm.DB, form forms.ParticipantForm, p *models.Participant) error {
+	var err error
+	if p.CampusID, p.Kampus, err = masterdata.Resolve(db, masterdata.Kampus, form.CampusID, form.Kampus); err != nil {
+		return fmt.Errorf("campus_id: %w", err)
+	}
+	if p.StudyProgramID, p.Jurusan, err = masterdata.Resolve(db, masterdata.Jurusan, form.StudyProgramID, form.Jurusan); err != nil {
+		return fmt.Errorf("study_program_id: %w", err)
+	}
+	return nil
+}
+
+// registrationEvent menentukan event tujuan registrasi: dari route /events/:slug,
+// atau DEFAULT_EVENT_SLUG untuk endpoint lama POST /api/participants
+func (pc *ParticipantController) registrationEvent(c *gin.Context) (*models.Event, error) {
+	if event := eventFromContext(c); event != nil {
+		return event, nil
+	}
+	slug := helpers.GetEnv("DEFAULT_EVENT_SLUG", "")
+	if slug == "" {
+		return nil, nil
+	}
+	var event models.Event
+	if err := pc.DB.Where("slug = ?", slug).First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
 }
 
 // CreateParticipant membuat participant baru
@@ -29,6 +130,33 @@ func (pc *ParticipantController) CreateParticipant(c *gin.Context) {
 		helpers.ResponseBadRequest(c, err.Error())
 		return
 	}
+	// Proteksi spam: honeypot, form_token, proof-of-work dan captcha sesuai konfigurasi
+	if err := pc.Guard.Check(c.Request.Context(), antispam.Submission{
+		Honeypot:     form.Website,
+		FormToken:    form.FormToken,
+		PowNonce:     form.PowNonce,
+		CaptchaToken: form.CaptchaToken,
+		RemoteIP:     c.ClientIP(),
+	}, time.Now()); err != nil {
+		log.Printf("Registration blocked from %s: %v", c.ClientIP(), err)
+		switch {
+		case errors.Is(err, antispam.ErrHoneypot):
+			// Bot tidak diberi tahu field mana yang membuatnya ditolak
+			helpers.ResponseError(c, http.StatusForbidden, "Pendaftaran tidak dapat diproses")
+		case errors.Is(err, antispam.ErrUnavailable):
+			helpers.ResponseError(c, http.StatusServiceUnavailable, "Verifikasi captcha sedang tidak tersedia, coba lagi")
+		default:
+			helpers.ResponseError(c, http.StatusForbidden, err.Error())
+		}
+		return
+	}
+	// form_token sudah dipesan oleh Check; lepas lagi jika registrasi gagal supaya form bisa dikirim ulang
+	registered := false
+	defer func() {
+		if !registered {
+			pc.Guard.Release(form.FormToken)
+		}
+	}()
 	// Konversi string ke time.Time
 	var birthDate time.Time
 	if form.BirthDate != "" {
@@ -39,22 +167,149 @@ func (pc *ParticipantController) CreateParticipant(c *gin.Context) {
 			return
 		}
 	}
+	if !helpers.ValidBirthDate(birthDate) {
+		helpers.ResponseBadRequest(c, "birth_date tidak valid")
+		return
+	}
+
+	// Normalisasi nomor HP ke E.164, input asli tetap disimpan untuk ditampilkan
+	phone, err := helpers.NormalizeIndonesianPhone(form.Phone)
+	if err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	event, err := pc.registrationEvent(c)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, "Default event tidak ditemukan: "+err.Error())
+		return
+	}
+	var eventID *uint
+	if event != nil {
+		if !event.RegistrationOpen(time.Now()) {
+			helpers.ResponseError(c, http.StatusForbidden, "Pendaftaran untuk event ini sedang ditutup")
+			return
+		}
+		eventID = &event.ID
+	}
+
+	// Jawaban pertanyaan registrasi divalidasi terhadap pertanyaan event (tanpa event tidak ada pertanyaan)
+	var eventQuestions []models.RegistrationQuestion
+	if eventID != nil {
+		if eventQuestions, err = questions.Load(pc.DB, *eventID); err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+	}
+	answers, answerErrs := questions.Validate(eventQuestions, form.Answers)
+	if len(answerErrs) > 0 {
+		helpers.ResponseValidationError(c, "Jawaban pertanyaan registrasi tidak valid", answerErrs)
+		return
+	}
+
+	email := normalizeEmail(form.Email)
+	if email == nil && getEmailRequired() {
+		helpers.ResponseBadRequest(c, "Email wajib diisi")
+		return
+	}
+	if email != nil && getEmailUnique() {
+		taken, err := emailTaken(pc.DB, eventID, *email, "")
+		if err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		if taken {
+			helpers.ResponseConflict(c, "Email sudah terdaftar")
+			return
+		}
+	}
 
 	participant := models.Participant{
-		Name:      form.Name,
-		Place:     form.Place,
-		BirthDate: birthDate,
-		Kampus:    form.Kampus,
-		Jurusan:   form.Jurusan,
-		Angkatan:  form.Angkatan,
-		Phone:     form.Phone,
+		EventID:    eventID,
+		Name:       form.Name,
+		Place:      form.Place,
+		BirthDate:  birthDate,
+		Angkatan:   form.Angkatan,
+		Phone:      phone,
+		PhoneInput: form.Phone,
+		Email:      email,
+		Language:   form.Language,
+	}
+	if participant.Language == "" {
+		participant.Language = helpers.PreferredLanguage(c.GetHeader("Accept-Language"))
+	}
+	if err := resolveMasterData(pc.DB, form, &participant); err != nil {
+		if errors.Is(err, masterdata.ErrUnknownEntry) {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
 	}
 
-	if err := pc.DB.Create(&participant).Error; err != nil {
+	// Syarat pendaftar event
+	if event != nil && !pc.checkEligibility(c, *event, participant, answers) {
+		return
+	}
+
+	// Deteksi pendaftaran ganda (nomor HP sama, atau nama mirip + tanggal lahir sama)
+	if policy := getDuplicatePolicy(); policy != duplicates.PolicyAllow {
+		matches, err := pc.Duplicates.FindMatches(participant)
+		if err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		if len(matches) > 0 {
+			if policy == duplicates.PolicyReject {
+				helpers.ResponseConflict(c, "Peserta dengan data yang sama sudah terdaftar")
+				return
+			}
+			participant.PossibleDuplicateOf = &matches[0].Participant.ID
+		}
+	}
+
+	// Register mengunci event sehingga kapasitas tidak terlampaui saat banyak pendaftar bersamaan
+	if err := pc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := workflow.Register(tx, &participant); err != nil {
+			return err
+		}
+		if err := ensureEmailAvailable(tx, participant); err != nil {
+			return err
+		}
+		return questions.SaveAnswers(tx, participant.ID, answers)
+	}); err != nil {
+		if errors.Is(err, errEmailTaken) {
+			helpers.ResponseConflict(c, "Email sudah terdaftar")
+			return
+		}
 		helpers.ResponseInternalServerError(c, err.Error())
 		return
 	}
+	registered = true
+	pc.Webhooks.PublishParticipant(webhooks.ParticipantRegistered, participant, nil)
+	if participant.Status == models.StatusWaitlisted {
+		pc.Messages.Notify(messaging.TemplateRegistrationWaitlisted, participant)
+		pc.Emails.Notify(emails.TemplateRegistrationWaitlisted, participant)
+	} else {
+		pc.Messages.Notify(messaging.TemplateRegistrationReceived, participant)
+		pc.Emails.Notify(emails.TemplateRegistrationReceived, participant)
+	}
+	if len(answers) > 0 {
+		participant.Answers = questions.AnswerMap(eventQuestions, answers)
+	}
+	// Token untuk upload KTM / pas foto mandiri lewat POST /api/participants/:id/uploads
+	participant.UploadToken = fileSigner().UploadToken(participant.ID, time.Now().Add(getUploadTokenTTL()))
+	participant.TicketURL = ticketURL(*participant.RegistrationCode)
 
+	// Registrasi tetap berhasil walaupun link verifikasi gagal dibuat; admin bisa kirim ulang
+	if err := pc.sendEmailVerification(participant); err != nil {
+		log.Printf("ERROR: Gagal membuat verifikasi email untuk %s: %v", participant.ID, err)
+	}
+
+	if participant.Status == models.StatusWaitlisted {
+		helpers.ResponseCreated(c, "Event is full, participant added to the waitlist", participant)
+		return
+	}
 	helpers.ResponseCreated(c, "Participant created successfully", participant)
 }
 
@@ -64,7 +319,7 @@ func (pc *ParticipantController) GetAllParticipants(c *gin.Context) {
 	page := c.DefaultQuery("page", "1")
 	limit := c.DefaultQuery("limit", "10")
 	search := c.Query("search")
-	sortBy := c.DefaultQuery("sort_by", "created_at")
+	sortBy := c.Query("sort_by")
 	sortOrder := c.DefaultQuery("sort_order", "desc")
 
 	// Convert to int
@@ -80,25 +335,19 @@ func (pc *ParticipantController) GetAllParticipants(c *gin.Context) {
 	}
 
 	// Validate sort parameters
-	validSortFields := map[string]bool{
-		"id":         true,
-		"name":       true,
-		"place":      true,
-		"birth_date": true,
-		"kampus":     true,
-		"jurusan":    true,
-		"angkatan":   true,
-		"phone":      true,
-		"created_at": true,
-		"updated_at": true,
-	}
-
 	validSortOrders := map[string]bool{
 		"asc":  true,
 		"desc": true,
 	}
 
-	if !validSortFields[sortBy] {
+	// Default sorting: relevansi jika ada search, selain itu created_at
+	if sortBy == "" && search != "" {
+		sortBy = "relevance"
+	}
+	if sortBy == "relevance" && search == "" {
+		sortBy = "created_at"
+	}
+	if sortBy != "relevance" && !participantSortFields[sortBy] {
 		sortBy = "created_at"
 	}
 
@@ -106,24 +355,55 @@ func (pc *ParticipantController) GetAllParticipants(c *gin.Context) {
 		sortOrder = "desc"
 	}
 
-	offset := (pageInt - 1) * limitInt
-
 	// Query dengan search, sorting, dan pagination
-	query := pc.DB.Model(&models.Participant{})
+	query, filters, err := pc.filterParticipants(c, pc.scopedParticipants(c))
+	if err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
 
-	if search != "" {
-		query = query.Where("name LIKE ?",
-			"%"+search+"%")
+	// Jumlah per status dihitung sebelum filter status, supaya tab status di dashboard tetap lengkap
+	_, cursorMode := c.GetQuery("cursor")
+	var statusCounts map[string]int64
+	if !cursorMode || c.Query("include_total") == "true" {
+		if statusCounts, err = countByStatus(query); err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+	}
+
+	statuses := statusFilter(c)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	filters["status"] = statuses
+	filters["sort_by"] = sortBy
+	filters["sort_order"] = sortOrder
+
+	// Mode cursor (keyset pagination) aktif jika parameter cursor dikirim, walaupun kosong
+	if cursorParam, ok := c.GetQuery("cursor"); ok {
+		pc.listParticipantsByCursor(c, query, cursorParam, limitInt, filters, statusCounts)
+		return
 	}
 
+	offset := (pageInt - 1) * limitInt
+
 	// Get total count
 	var total int64
 	query.Count(&total)
 
 	// Get paginated data dengan sorting
 	var participants []models.Participant
-	orderClause := sortBy + " " + sortOrder
-	if err := query.Order(orderClause).Offset(offset).Limit(limitInt).Find(&participants).Error; err != nil {
+	if sortBy == "relevance" {
+		query = pc.Search.OrderByRelevance(query, search, "created_at desc")
+	} else {
+		query = query.Order(sortBy + " " + sortOrder)
+	}
+	if err := query.Offset(offset).Limit(limitInt).Find(&participants).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if err := questions.Attach(pc.DB, participants); err != nil {
 		helpers.ResponseInternalServerError(c, err.Error())
 		return
 	}
@@ -143,22 +423,283 @@ func (pc *ParticipantController) GetAllParticipants(c *gin.Context) {
 			"has_next":     hasNext,
 			"has_prev":     hasPrev,
 		},
-		"filters": gin.H{
-			"search":     search,
-			"sort_by":    sortBy,
-			"sort_order": sortOrder,
-		},
+		"filters":       filters,
+		"status_counts": statusCounts,
 	}
 
 	helpers.ResponseSuccess(c, "Participants retrieved successfully", data)
 }
 
+// filterParticipants menerapkan filter list participant dari query string (search, duplikat, verifikasi email, syarat, jawaban).
+// Filter status diterapkan terpisah karena jumlah per status dihitung sebelum filter status.
+func (pc *ParticipantController) filterParticipants(c *gin.Context, query *gorm.DB) (*gorm.DB, gin.H, error) {
+	search := c.Query("search")
+	if search != "" {
+		query = pc.Search.Apply(query, search)
+	}
+
+	// Filter duplikat: flagged = hanya yang ditandai kemungkinan duplikat
+	duplicateFilter := c.Query("duplicates")
+	switch duplicateFilter {
+	case "flagged":
+		query = query.Where("possible_duplicate_of IS NOT NULL")
+	case "unflagged":
+		query = query.Where("possible_duplicate_of IS NULL")
+	default:
+		duplicateFilter = ""
+	}
+
+	// Filter verifikasi email: true = sudah verifikasi, false = punya email tapi belum verifikasi
+	emailVerified := c.Query("email_verified")
+	switch emailVerified {
+	case "true":
+		query = query.Where("email_verified_at IS NOT NULL")
+	case "false":
+		query = query.Where("email IS NOT NULL AND email_verified_at IS NULL")
+	default:
+		emailVerified = ""
+	}
+
+	// Filter syarat event: failed = ditandai tidak memenuhi syarat saat evaluasi ulang terakhir
+	eligibilityFilter := c.Query("eligibility")
+	switch eligibilityFilter {
+	case "failed":
+		query = query.Where("ineligible_at IS NOT NULL")
+	case "passed":
+		query = query.Where("ineligible_at IS NULL")
+	default:
+		eligibilityFilter = ""
+	}
+
+	// Filter jawaban pertanyaan registrasi, mis. answers[ukuran_kaos]=L (hanya untuk route per event)
+	answerFilters := c.QueryMap("answers")
+	if len(answerFilters) > 0 {
+		event := eventFromContext(c)
+		if event == nil {
+			return nil, nil, errors.New("filter answers hanya tersedia di /api/events/{slug}/participants")
+		}
+		list, err := questions.Load(pc.DB, event.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		byKey := make(map[string]models.RegistrationQuestion, len(list))
+		for _, q := range list {
+			byKey[q.Key] = q
+		}
+		for key, value := range answerFilters {
+			q, ok := byKey[key]
+			if !ok {
+				return nil, nil, fmt.Errorf("answers[%s]: %v", key, questions.ErrUnknownQuestion)
+			}
+			if query, err = questions.Filter(query, q, value); err != nil {
+				return nil, nil, fmt.Errorf("answers[%s]: %v", key, err)
+			}
+		}
+	}
+
+	filters := gin.H{
+		"search":         search,
+		"duplicates":     duplicateFilter,
+		"email_verified": emailVerified,
+		"eligibility":    eligibilityFilter,
+		"answers":        answerFilters,
+	}
+	return query, filters, nil
+}
+
+// statusFilter membaca filter status, boleh lebih dari satu dipisah koma (mis. status=pending,waitlisted)
+func statusFilter(c *gin.Context) []string {
+	var statuses []string
+	for _, s := range strings.Split(c.Query("status"), ",") {
+		if s = strings.TrimSpace(s); models.IsValidParticipantStatus(s) {
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses
+}
+
+// listParticipantsByCursor menjalankan keyset pagination berdasarkan kolom sort + id.
+// Total hanya dihitung jika include_total=true karena Count mahal untuk tabel besar.
+func (pc *ParticipantController) listParticipantsByCursor(c *gin.Context, query *gorm.DB, cursorParam string, limit int, filters gin.H, statusCounts map[string]int64) {
+	sortBy := filters["sort_by"].(string)
+	sortOrder := filters["sort_order"].(string)
+	if sortBy == "relevance" {
+		// Skor relevansi tidak stabil untuk keyset, jadi cursor selalu memakai kolom biasa
+		sortBy = "created_at"
+	}
+
+	var cursor *helpers.Cursor
+	if cursorParam != "" {
+		decoded, err := helpers.DecodeCursor(cursorParam)
+		if err != nil || !participantSortFields[decoded.SortBy] || (decoded.SortOrder != "asc" && decoded.SortOrder != "desc") {
+			helpers.ResponseBadRequest(c, "Cursor tidak valid")
+			return
+		}
+		// Cursor bersifat opaque: urutan di dalam cursor menang atas query parameter
+		cursor = &decoded
+		sortBy = decoded.SortBy
+		sortOrder = decoded.SortOrder
+	}
+	filters["sort_by"] = sortBy
+	filters["sort_order"] = sortOrder
+
+	var total int64
+	includeTotal := c.Query("include_total") == "true"
+	if includeTotal {
+		query.Count(&total)
+	}
+
+	// Arah scan: mundur (prev) membalik urutan lalu hasilnya dibalik lagi
+	backward := cursor != nil && cursor.Backward
+	scanOrder := sortOrder
+	if backward {
+		scanOrder = reverseSortOrder(sortOrder)
+	}
+
+	if cursor != nil {
+		value, err := parseParticipantCursorValue(sortBy, cursor.Value)
+		if err != nil {
+			helpers.ResponseBadRequest(c, "Cursor tidak valid")
+			return
+		}
+		op := ">"
+		if scanOrder == "desc" {
+			op = "<"
+		}
+		query = query.Where("("+sortBy+" "+op+" ? OR ("+sortBy+" = ? AND id "+op+" ?))", value, value, cursor.ID)
+	}
+
+	// Ambil satu baris ekstra untuk mengetahui apakah masih ada halaman berikutnya
+	var participants []models.Participant
+	if err := query.Order(sortBy + " " + scanOrder).Order("id " + scanOrder).Limit(limit + 1).Find(&participants).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	hasMore := len(participants) > limit
+	if hasMore {
+		participants = participants[:limit]
+	}
+	if backward {
+		for i, j := 0, len(participants)-1; i < j; i, j = i+1, j-1 {
+			participants[i], participants[j] = participants[j], participants[i]
+		}
+	}
+	if err := questions.Attach(pc.DB, participants); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	hasNext := (!backward && hasMore) || backward
+	hasPrev := (backward && hasMore) || (!backward && cursor != nil)
+
+	var nextCursor, prevCursor interface{}
+	if len(participants) > 0 {
+		first := participants[0]
+		last := participants[len(participants)-1]
+		if hasNext {
+			nextCursor = helpers.EncodeCursor(helpers.Cursor{
+				SortBy: sortBy, SortOrder: sortOrder, Value: participantCursorValue(last, sortBy), ID: last.ID,
+			})
+		}
+		if hasPrev {
+			prevCursor = helpers.EncodeCursor(helpers.Cursor{
+				SortBy: sortBy, SortOrder: sortOrder, Value: participantCursorValue(first, sortBy), ID: first.ID, Backward: true,
+			})
+		}
+	}
+
+	pagination := gin.H{
+		"mode":        "cursor",
+		"per_page":    limit,
+		"next_cursor": nextCursor,
+		"prev_cursor": prevCursor,
+		"has_next":    nextCursor != nil,
+		"has_prev":    prevCursor != nil,
+	}
+	if includeTotal {
+		pagination["total_items"] = total
+	}
+
+	data := gin.H{
+		"participants": participants,
+		"pagination":   pagination,
+		"filters":      filters,
+	}
+	if statusCounts != nil {
+		data["status_counts"] = statusCounts
+	}
+	helpers.ResponseSuccess(c, "Participants retrieved successfully", data)
+}
+
+// participantSortFields adalah kolom yang boleh dipakai untuk sorting (dan sebagai kunci cursor)
+var participantSortFields = map[string]bool{
+	"id":         true,
+	"name":       true,
+	"place":      true,
+	"birth_date": true,
+	"kampus":     true,
+	"jurusan":    true,
+	"angkatan":   true,
+	"phone":      true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// participantCursorValue mengambil nilai kolom sort dari participant untuk disimpan di cursor
+func participantCursorValue(p models.Participant, sortBy string) string {
+	switch sortBy {
+	case "name":
+		return p.Name
+	case "place":
+		return p.Place
+	case "birth_date":
+		return p.BirthDate.Format(time.RFC3339Nano)
+	case "kampus":
+		return p.Kampus
+	case "jurusan":
+		return p.Jurusan
+	case "angkatan":
+		return p.Angkatan
+	case "phone":
+		return p.Phone
+	case "created_at":
+		return p.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return p.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	return p.ID
+}
+
+// parseParticipantCursorValue mengubah nilai cursor kembali ke tipe kolomnya
+func parseParticipantCursorValue(sortBy, value string) (interface{}, error) {
+	switch sortBy {
+	case "birth_date":
+		return time.Parse(time.RFC3339Nano, value)
+	case "created_at", "updated_at":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, err
+		}
+		// Timestamp ditulis dengan zona waktu lokal (time.Now), samakan agar perbandingan di SQLite konsisten
+		return t.Local(), nil
+	}
+	return value, nil
+}
+
+func reverseSortOrder(order string) string {
+	if order == "asc" {
+		return "desc"
+	}
+	return "asc"
+}
+
 // GetParticipant mengambil data participant berdasarkan ID
 func (pc *ParticipantController) GetParticipant(c *gin.Context) {
 	id := c.Param("id")
 	var participant models.Participant
 
-	if err := pc.DB.Where("id = ?", id).First(&participant).Error; err != nil {
+	if err := pc.scopedParticipants(c).Where("id = ?", id).First(&participant).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			helpers.ResponseNotFound(c, "Participant not found")
 			return
@@ -167,7 +708,13 @@ func (pc *ParticipantController) GetParticipant(c *gin.Context) {
 		return
 	}
 
-	helpers.ResponseSuccess(c, "Participant retrieved successfully", participant)
+	list := []models.Participant{participant}
+	if err := questions.Attach(pc.DB, list); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	helpers.ResponseSuccess(c, "Participant retrieved successfully", list[0])
 }
 
 // UpdateParticipant mengupdate data participant
@@ -176,7 +723,7 @@ func (pc *ParticipantController) UpdateParticipant(c *gin.Context) {
 	var participant models.Participant
 
 	// Cek apakah participant ada
-	if err := pc.DB.Where("id = ?", id).First(&participant).Error; err != nil {
+	if err := pc.scopedParticipants(c).Where("id = ?", id).First(&participant).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			helpers.ResponseNotFound(c, "Participant not found")
 			return
@@ -192,6 +739,19 @@ func (pc *ParticipantController) UpdateParticipant(c *gin.Context) {
 		return
 	}
 
+	if !pc.updateParticipant(c, &participant, form, false) {
+		return
+	}
+	helpers.ResponseSuccess(c, "Participant updated successfully", participant)
+}
+
+// updateParticipant memvalidasi form dan menyimpan perubahan data participant (admin maupun portal pendaftar).
+// selfService = perubahan oleh pendaftar sendiri: syarat event ditegakkan seperti saat registrasi,
+// sedangkan perubahan oleh admin hanya memperbarui tanda tidak memenuhi syarat.
+// Jika gagal response error sudah dikirim dan hasilnya false.
+func (pc *ParticipantController) updateParticipant(c *gin.Context, p *models.Participant, form forms.ParticipantForm, selfService bool) bool {
+	participant := *p
+
 	// Konversi birth_date string ke time.Time
 	var birthDate time.Time
 	if form.BirthDate != "" {
@@ -199,25 +759,180 @@ func (pc *ParticipantController) UpdateParticipant(c *gin.Context) {
 		birthDate, err = time.Parse("2006-01-02", form.BirthDate)
 		if err != nil {
 			helpers.ResponseBadRequest(c, "Format birth_date harus YYYY-MM-DD")
-			return
+			return false
+		}
+	}
+	if !helpers.ValidBirthDate(birthDate) {
+		helpers.ResponseBadRequest(c, "birth_date tidak valid")
+		return false
+	}
+
+	phone, err := helpers.NormalizeIndonesianPhone(form.Phone)
+	if err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return false
+	}
+
+	email := normalizeEmail(form.Email)
+	if email == nil && getEmailRequired() {
+		helpers.ResponseBadRequest(c, "Email wajib diisi")
+		return false
+	}
+	emailChanged := (email == nil) != (participant.Email == nil) || (email != nil && *email != *participant.Email)
+	if emailChanged && email != nil && getEmailUnique() {
+		taken, err := emailTaken(pc.DB, participant.EventID, *email, participant.ID)
+		if err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return false
+		}
+		if taken {
+			helpers.ResponseConflict(c, "Email sudah terdaftar")
+			return false
+		}
+	}
+
+	// Jawaban hanya diganti jika field answers dikirim; key yang tidak dikirim tetap memakai jawaban lama
+	var answers []models.ParticipantAnswer
+	var eventQuestions []models.RegistrationQuestion
+	if form.Answers != nil {
+		if participant.EventID != nil {
+			if eventQuestions, err = questions.Load(pc.DB, *participant.EventID); err != nil {
+				helpers.ResponseInternalServerError(c, err.Error())
+				return false
+			}
+		}
+		current := []models.Participant{participant}
+		if err := questions.Attach(pc.DB, current); err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return false
+		}
+		var answerErrs map[string]string
+		answers, answerErrs = questions.Validate(eventQuestions, questions.Merge(current[0].Answers, form.Answers))
+		if len(answerErrs) > 0 {
+			helpers.ResponseValidationError(c, "Jawaban pertanyaan registrasi tidak valid", answerErrs)
+			return false
 		}
 	}
 
+	if err := resolveMasterData(pc.DB, form, &participant); err != nil {
+		if errors.Is(err, masterdata.ErrUnknownEntry) {
+			helpers.ResponseBadRequest(c, err.Error())
+			return false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return false
+	}
+
 	// Update data
 	participant.Name = form.Name
 	participant.Place = form.Place
 	participant.BirthDate = birthDate
-	participant.Kampus = form.Kampus
-	participant.Jurusan = form.Jurusan
 	participant.Angkatan = form.Angkatan
-	participant.Phone = form.Phone
+	participant.Phone = phone
+	participant.PhoneInput = form.Phone
+	if form.Language != "" {
+		participant.Language = form.Language
+	}
+	if emailChanged {
+		// Email baru harus diverifikasi ulang
+		participant.Email = email
+		participant.EmailVerifiedAt = nil
+	}
+
+	var event *models.Event
+	if participant.EventID != nil {
+		event = &models.Event{}
+		if err := pc.DB.First(event, *participant.EventID).Error; err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return false
+		}
+	}
+	// Pendaftar tidak boleh mengubah data sampai tidak memenuhi syarat event
+	if selfService && event != nil {
+		current := answers
+		if form.Answers == nil {
+			if err := pc.DB.Where("participant_id = ?", participant.ID).Find(&current).Error; err != nil {
+				helpers.ResponseInternalServerError(c, err.Error())
+				return false
+			}
+		}
+		if !pc.checkEligibility(c, *event, participant, current) {
+			return false
+		}
+	}
 
-	if err := pc.DB.Save(&participant).Error; err != nil {
+	// Status hanya boleh berubah lewat endpoint status (state machine)
+	if err := pc.DB.Transaction(func(tx *gorm.DB) error {
+		// Email baru dicek ulang setelah event dikunci, bergantian dengan registrasi ke event yang sama
+		if emailChanged && participant.EventID != nil && getEmailUnique() {
+			if err := workflow.LockEvent(tx, *participant.EventID); err != nil {
+				return err
+			}
+		}
+		if emailChanged {
+			if err := ensureEmailAvailable(tx, participant); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("status").Save(&participant).Error; err != nil {
+			return err
+		}
+		if form.Answers == nil {
+			return nil
+		}
+		return questions.SaveAnswers(tx, participant.ID, answers)
+	}); err != nil {
+		if errors.Is(err, errEmailTaken) {
+			helpers.ResponseConflict(c, "Email sudah terdaftar")
+			return false
+		}
 		helpers.ResponseInternalServerError(c, err.Error())
-		return
+		return false
+	}
+	updated := []models.Participant{participant}
+	if err := questions.Attach(pc.DB, updated); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return false
 	}
+	participant = updated[0]
 
-	helpers.ResponseSuccess(c, "Participant updated successfully", participant)
+	// Tanda tidak memenuhi syarat diperbarui sesuai data baru
+	if event != nil {
+		if err := eligibility.Refresh(pc.DB, *event, &participant); err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return false
+		}
+	}
+
+	if emailChanged {
+		if err := pc.sendEmailVerification(participant); err != nil {
+			log.Printf("ERROR: Gagal membuat verifikasi email untuk %s: %v", participant.ID, err)
+		}
+	}
+
+	*p = participant
+	return true
+}
+
+// checkEligibility menolak pendaftar yang tidak memenuhi syarat event (umur, angkatan, kampus, jawaban wajib)
+// dengan pesan sesuai Accept-Language. File belum dicek karena diupload setelah registrasi.
+// Jika gagal response error sudah dikirim dan hasilnya false.
+func (pc *ParticipantController) checkEligibility(c *gin.Context, event models.Event, participant models.Participant, answers []models.ParticipantAnswer) bool {
+	if event.Eligibility.Empty() {
+		return true
+	}
+	rules, err := eligibility.LoadContext(pc.DB, event)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return false
+	}
+	lang := helpers.PreferredLanguage(c.GetHeader("Accept-Language"))
+	issues := rules.Evaluate(eligibility.Facts{Participant: participant, Answered: rules.AnsweredKeys(answers)}, lang)
+	if len(issues) > 0 {
+		helpers.ResponseValidationError(c, eligibility.RejectionMessage(lang), eligibility.IssueMap(issues))
+		return false
+	}
+	return true
 }
 
 // DeleteParticipant menghapus participant
@@ -226,7 +941,7 @@ func (pc *ParticipantController) DeleteParticipant(c *gin.Context) {
 	var participant models.Participant
 
 	// Cek apakah participant ada
-	if err := pc.DB.Where("id = ?", id).First(&participant).Error; err != nil {
+	if err := pc.scopedParticipants(c).Where("id = ?", id).First(&participant).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			helpers.ResponseNotFound(c, "Participant not found")
 			return
@@ -235,21 +950,160 @@ func (pc *ParticipantController) DeleteParticipant(c *gin.Context) {
 		return
 	}
 
-	// Hapus participant
-	if err := pc.DB.Delete(&participant).Error; err != nil {
+	// Hapus participant beserta jawaban, file upload, riwayat kehadiran, sertifikat, riwayat status dan log pesannya
+	var files []models.ParticipantFile
+	var certificateKeys []string
+	if err := pc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("participant_id = ?", participant.ID).Delete(&models.ParticipantAnswer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", participant.ID).Find(&files).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", participant.ID).Delete(&models.ParticipantFile{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", participant.ID).Delete(&models.CheckIn{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", participant.ID).Delete(&models.SessionAttendance{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Certificate{}).Where("participant_id = ? AND storage_key IS NOT NULL", participant.ID).
+			Pluck("storage_key", &certificateKeys).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", participant.ID).Delete(&models.Certificate{}).Error; err != nil {
+			return err
+		}
+		// Sesi & magic link portal tidak berlaku lagi
+		if err := tx.Where("participant_id = ?", participant.ID).Delete(&models.ParticipantSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", participant.ID).Delete(&models.ParticipantMagicLink{}).Error; err != nil {
+			return err
+		}
+		// Riwayat status, token verifikasi email serta log (dan antrian) WhatsApp / SMS dan email
+		for _, model := range []interface{}{&models.ParticipantStatusHistory{}, &models.EmailVerification{}, &models.OutboundMessage{}, &models.OutboundEmail{}} {
+			if err := tx.Where("participant_id = ?", participant.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		// Opt-out berlaku per nomor HP, jadi hanya dihapus jika tidak ada pendaftaran lain dengan nomor yang sama
+		var sharedPhone int64
+		if err := tx.Model(&models.Participant{}).Where("phone_key = ? AND id <> ?", participant.PhoneKey, participant.ID).
+			Count(&sharedPhone).Error; err != nil {
+			return err
+		}
+		if sharedPhone == 0 && participant.PhoneKey != "" {
+			if err := tx.Where("phone_key = ?", participant.PhoneKey).Delete(&models.MessageOptOut{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&participant).Error
+	}); err != nil {
 		helpers.ResponseInternalServerError(c, err.Error())
 		return
 	}
+	for _, f := range files {
+		removeFileObjects(pc.Storage, f)
+	}
+	for _, key := range certificateKeys {
+		if err := pc.Storage.Delete(context.Background(), key); err != nil {
+			log.Printf("ERROR: Gagal menghapus sertifikat %s: %v", key, err)
+		}
+	}
+
+	// Kursi yang dilepas langsung diisi waitlist berikutnya
+	if participant.EventID != nil && workflow.HoldsSeat(participant.Status) {
+		pc.fillOpenSeats(*participant.EventID)
+	}
 
 	helpers.ResponseSuccess(c, "Participant deleted successfully", nil)
 }
 
-// CountParticipant returns the total number of participants
+// CountParticipant returns the total number of participants, dipecah per status
 func (pc *ParticipantController) CountParticipant(c *gin.Context) {
 	var count int64
-	if err := pc.DB.Model(&models.Participant{}).Count(&count).Error; err != nil {
+	if err := pc.scopedParticipants(c).Count(&count).Error; err != nil {
 		helpers.ResponseInternalServerError(c, err.Error())
 		return
 	}
-	helpers.ResponseSuccess(c, "Total participants counted successfully", gin.H{"total": count})
+	byStatus, err := countByStatus(pc.scopedParticipants(c))
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Total participants counted successfully", gin.H{"total": count, "by_status": byStatus})
+}
+
+// GetDuplicateReport mengelompokkan participant yang kemungkinan terdaftar lebih dari sekali
+func (pc *ParticipantController) GetDuplicateReport(c *gin.Context) {
+	var eventID *uint
+	if event := eventFromContext(c); event != nil {
+		eventID = &event.ID
+	}
+	groups, err := pc.Duplicates.Report(eventID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Duplicate report generated successfully", gin.H{
+		"groups":      groups,
+		"total_group": len(groups),
+	})
+}
+
+// MergeParticipants menggabungkan participant duplikat ke participant utama
+func (pc *ParticipantController) MergeParticipants(c *gin.Context) {
+	var form forms.MergeParticipantForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	// Kedua participant harus berada di event yang sedang di-scope
+	var inScope int64
+	if err := pc.scopedParticipants(c).Where("id IN ?", []string{form.PrimaryID, form.DuplicateID}).Count(&inScope).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if inScope < 2 && form.PrimaryID != form.DuplicateID {
+		helpers.ResponseNotFound(c, "Participant not found")
+		return
+	}
+
+	participant, audit, err := pc.Duplicates.Merge(form.PrimaryID, form.DuplicateID, form.Fields, currentActor(c))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return
+		}
+		if err == duplicates.ErrSameParticipant || err == duplicates.ErrDifferentEvents {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	if participant.EventID != nil {
+		pc.fillOpenSeats(*participant.EventID)
+	}
+
+	helpers.ResponseSuccess(c, "Participants merged successfully", gin.H{
+		"participant": participant,
+		"merge":       audit,
+	})
+}
+
+// fillOpenSeats mempromosikan waitlist setelah kursi dilepas; kegagalan hanya dicatat
+// karena aksi utama (hapus/merge) sudah berhasil
+func (pc *ParticipantController) fillOpenSeats(eventID uint) {
+	promoted, err := workflow.FillOpenSeats(pc.DB, eventID)
+	if err != nil {
+		log.Printf("ERROR: Gagal mempromosikan waitlist event %d: %v", eventID, err)
+		return
+	}
+	notifyPromoted(pc.Webhooks, pc.Messages, pc.Emails, promoted)
 }
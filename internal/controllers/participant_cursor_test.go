@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+func TestParticipantCursorValueRoundTrip(t *testing.T) {
+	created := time.Date(2026, 3, 4, 5, 6, 7, 123456789, time.FixedZone("WIB", 7*3600))
+	p := models.Participant{
+		ID: "p-1", Name: "Budi Santoso", Place: "Jakarta", BirthDate: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		Kampus: "UI", Jurusan: "Teknik", Angkatan: "2020", Phone: "+6281234567890",
+		CreatedAt: created, UpdatedAt: created.Add(time.Hour),
+	}
+
+	for sortBy := range participantSortFields {
+		encoded := helpers.EncodeCursor(helpers.Cursor{
+			SortBy: sortBy, SortOrder: "asc", Value: participantCursorValue(p, sortBy), ID: p.ID,
+		})
+		cur, err := helpers.DecodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("%s: DecodeCursor: %v", sortBy, err)
+		}
+		value, err := parseParticipantCursorValue(cur.SortBy, cur.Value)
+		if err != nil {
+			t.Fatalf("%s: parseParticipantCursorValue(%q): %v", sortBy, cur.Value, err)
+		}
+
+		var want interface{}
+		switch sortBy {
+		case "birth_date":
+			want = p.BirthDate
+		case "created_at":
+			want = p.CreatedAt
+		case "updated_at":
+			want = p.UpdatedAt
+		}
+		if want != nil {
+			// Timestamp harus menunjuk instant yang sama, termasuk nanodetik
+			if got, ok := value.(time.Time); !ok || !got.Equal(want.(time.Time)) {
+				t.Errorf("%s: value = %v, want %v", sortBy, value, want)
+			}
+			continue
+		}
+		if value != participantCursorValue(p, sortBy) {
+			t.Errorf("%s: value = %v, want %q", sortBy, value, participantCursorValue(p, sortBy))
+		}
+	}
+}
+
+func TestParseParticipantCursorValueRejectsBadTimestamps(t *testing.T) {
+	for _, sortBy := range []string{"birth_date", "created_at", "updated_at"} {
+		if _, err := parseParticipantCursorValue(sortBy, "kemarin"); err == nil {
+			t.Errorf("%s: accepted invalid timestamp", sortBy)
+		}
+	}
+	if reverseSortOrder("asc") != "desc" || reverseSortOrder("desc") != "asc" {
+		t.Error("reverseSortOrder does not flip the order")
+	}
+}
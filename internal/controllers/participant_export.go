@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/questions"
+)
+
+// exportBatchSize adalah jumlah participant yang dibaca per query saat export
+const exportBatchSize = 500
+
+// participantExportHeader adalah kolom tetap di file export, diikuti key pertanyaan registrasi event
+var participantExportHeader = []string{
+	"id", "event_id", "name", "place", "birth_date", "kampus", "jurusan", "angkatan", "phone", "phone_input",
+	"email", "email_verified", "status", "possible_duplicate_of", "created_at",
+}
+
+// ExportParticipants mengunduh participant sebagai CSV dengan filter yang sama seperti list.
+// Di route per event, setiap pertanyaan registrasi menjadi satu kolom tambahan.
+func (pc *ParticipantController) ExportParticipants(c *gin.Context) {
+	query, _, err := pc.filterParticipants(c, pc.scopedParticipants(c))
+	if err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if statuses := statusFilter(c); len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	filename := "participants"
+	var eventQuestions []models.RegistrationQuestion
+	if event := eventFromContext(c); event != nil {
+		filename += "-" + event.Slug
+		if eventQuestions, err = questions.Load(pc.DB, event.ID); err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+	}
+	filename += "-" + time.Now().Format("20060102") + ".csv"
+
+	header := append([]string{}, participantExportHeader...)
+	for _, q := range eventQuestions {
+		header = append(header, q.Key)
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w := csv.NewWriter(c.Writer)
+	if err := w.Write(header); err != nil {
+		return
+	}
+
+	// Data ditulis per batch (keyset created_at + id) supaya export event besar tidak dimuat sekaligus ke memori.
+	// Header response sudah terkirim, jadi error di tengah jalan hanya bisa dicatat.
+	var last *models.Participant
+	for {
+		batchQuery := query.Session(&gorm.Session{})
+		if last != nil {
+			createdAt := last.CreatedAt.Local()
+			batchQuery = batchQuery.Where("(created_at > ? OR (created_at = ? AND id > ?))", createdAt, createdAt, last.ID)
+		}
+		var batch []models.Participant
+		if err := batchQuery.Order("created_at asc").Order("id asc").Limit(exportBatchSize).Find(&batch).Error; err != nil {
+			log.Printf("ERROR: Export participant gagal: %v", err)
+			break
+		}
+		answers, err := exportAnswers(pc.DB, batch)
+		if err != nil {
+			log.Printf("ERROR: Export participant gagal: %v", err)
+			break
+		}
+		for _, p := range batch {
+			if err := w.Write(participantExportRow(p, eventQuestions, answers[p.ID])); err != nil {
+				return
+			}
+		}
+		w.Flush()
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+// exportAnswers mengambil nilai kanonik jawaban per participant (participant ID -> question ID -> value)
+func exportAnswers(db *gorm.DB, participants []models.Participant) (map[string]map[uint]string, error) {
+	ids := make([]string, len(participants))
+	for i, p := range participants {
+		ids[i] = p.ID
+	}
+	var answers []models.ParticipantAnswer
+	if err := db.Where("participant_id IN ?", ids).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]map[uint]string)
+	for _, a := range answers {
+		if result[a.ParticipantID] == nil {
+			result[a.ParticipantID] = make(map[uint]string)
+		}
+		result[a.ParticipantID][a.QuestionID] = a.Value
+	}
+	return result, nil
+}
+
+// participantExportRow menyusun satu baris CSV sesuai urutan participantExportHeader
+func participantExportRow(p models.Participant, eventQuestions []models.RegistrationQuestion, answers map[uint]string) []string {
+	var eventID, email, duplicateOf string
+	if p.EventID != nil {
+		eventID = strconv.FormatUint(uint64(*p.EventID), 10)
+	}
+	if p.Email != nil {
+		email = *p.Email
+	}
+	if p.PossibleDuplicateOf != nil {
+		duplicateOf = *p.PossibleDuplicateOf
+	}
+
+	row := []string{
+		p.ID, eventID, p.Name, p.Place, p.BirthDate.Format("2006-01-02"), p.Kampus, p.Jurusan, p.Angkatan,
+		p.Phone, p.PhoneInput, email, strconv.FormatBool(p.EmailVerifiedAt != nil), p.Status, duplicateOf,
+		p.CreatedAt.Format(time.RFC3339),
+	}
+	for _, q := range eventQuestions {
+		value, ok := answers[q.ID]
+		if ok {
+			value = questions.Display(q, value)
+		}
+		row = append(row, value)
+	}
+	for i := range row {
+		row[i] = csvSafe(row[i])
+	}
+	return row
+}
+
+// csvSafe mencegah formula injection saat CSV dibuka di spreadsheet.
+// Nomor telepon seperti "+62812..." tetap dibiarkan karena isinya murni angka.
+func csvSafe(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + value
+	case '+', '-':
+		if _, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+			return "'" + value
+		}
+	}
+	return value
+}
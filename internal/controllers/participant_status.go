@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/emails"
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/messaging"
+	"backend/internal/models"
+	"backend/internal/workflow"
+)
+
+// currentActor mengambil username admin yang sedang login untuk dicatat di audit
+func currentActor(c *gin.Context) string {
+	if username, exists := c.Get("username"); exists && username != nil {
+		if s, ok := username.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// ChangeStatus mengubah status pendaftaran participant sesuai state machine
+func (pc *ParticipantController) ChangeStatus(c *gin.Context) {
+	var form forms.StatusTransitionForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	var existing models.Participant
+	if err := pc.scopedParticipants(c).Select("id").Where("id = ?", c.Param("id")).First(&existing).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	result, err := workflow.Transition(pc.DB, existing.ID, form.Status, form.Reason, currentActor(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			helpers.ResponseNotFound(c, "Participant not found")
+		case errors.Is(err, workflow.ErrInvalidStatus), errors.Is(err, workflow.ErrInvalidTransition):
+			helpers.ResponseError(c, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, workflow.ErrStatusChanged), errors.Is(err, workflow.ErrEventFull):
+			helpers.ResponseConflict(c, err.Error())
+		default:
+			helpers.ResponseInternalServerError(c, err.Error())
+		}
+		return
+	}
+	pc.Webhooks.PublishStatusChange(*result.Participant, result.History.FromStatus, form.Reason, currentActor(c))
+	if result.Participant.Status == models.StatusApproved {
+		pc.Messages.Notify(messaging.TemplateRegistrationApproved, *result.Participant)
+		pc.Emails.Notify(emails.TemplateRegistrationApproved, *result.Participant)
+	}
+	notifyPromoted(pc.Webhooks, pc.Messages, pc.Emails, result.Promoted)
+
+	helpers.ResponseSuccess(c, "Participant status updated successfully", gin.H{
+		"participant":         result.Participant,
+		"history":             result.History,
+		"allowed_transitions": workflow.AllowedTransitions(result.Participant.Status),
+		"promoted":            result.Promoted,
+	})
+}
+
+// GetStatusHistory mengambil riwayat perubahan status participant
+func (pc *ParticipantController) GetStatusHistory(c *gin.Context) {
+	var participant models.Participant
+	if err := pc.scopedParticipants(c).Where("id = ?", c.Param("id")).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	var histories []models.ParticipantStatusHistory
+	if err := pc.DB.Where("participant_id = ?", participant.ID).Order("created_at asc, id asc").Find(&histories).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	helpers.ResponseSuccess(c, "Status history retrieved successfully", gin.H{
+		"status":              participant.Status,
+		"allowed_transitions": workflow.AllowedTransitions(participant.Status),
+		"history":             histories,
+	})
+}
+
+// countByStatus menghitung jumlah participant per status untuk query yang sudah difilter
+func countByStatus(query *gorm.DB) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Total  int64
+	}
+	if err := query.Session(&gorm.Session{}).Select("status, COUNT(*) AS total").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(models.ParticipantStatuses))
+	for _, s := range models.ParticipantStatuses {
+		counts[s] = 0
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Total
+	}
+	return counts, nil
+}
@@ -0,0 +1,494 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/mailer"
+	"backend/internal/messaging"
+	"backend/internal/models"
+	"backend/internal/questions"
+	"backend/internal/ratelimit"
+	"backend/internal/workflow"
+)
+
+// PortalController melayani portal mandiri pendaftar: login lewat magic link, lihat & ubah data,
+// mengundurkan diri dan download tiket. Semua endpoint (kecuali minta link & tukar token)
+// memakai sesi dari middleware.ParticipantAuth, bukan JWT admin.
+type PortalController struct {
+	DB           *gorm.DB
+	Mailer       mailer.Mailer
+	Messages     *messaging.Service
+	Participants *ParticipantController
+	Tickets      *TicketController
+}
+
+// NewPortalController membuat instance controller baru
+func NewPortalController(db *gorm.DB, mail mailer.Mailer, messages *messaging.Service, participants *ParticipantController, tickets *TicketController) *PortalController {
+	return &PortalController{DB: db, Mailer: mail, Messages: messages, Participants: participants, Tickets: tickets}
+}
+
+// getPortalLinkTTL mendapatkan masa berlaku magic link (default 15 menit)
+func getPortalLinkTTL() time.Duration {
+	return helpers.GetEnvDuration("PORTAL_LINK_TTL", 15*time.Minute)
+}
+
+// getPortalSessionTTL mendapatkan masa berlaku sesi portal setelah magic link ditukar (default 2 jam)
+func getPortalSessionTTL() time.Duration {
+	return helpers.GetEnvDuration("PORTAL_SESSION_TTL", 2*time.Hour)
+}
+
+// MagicLinkRateLimiter membuat limiter per IP untuk endpoint minta magic link (default 5 request / 10 menit)
+func MagicLinkRateLimiter() *ratelimit.Limiter {
+	return ratelimit.New(helpers.GetEnvInt("PORTAL_LINK_RATE_LIMIT", 5), helpers.GetEnvDuration("PORTAL_LINK_RATE_WINDOW", 10*time.Minute))
+}
+
+// getPortalURL adalah halaman portal di frontend yang menerima ?token= dari magic link
+func getPortalURL() string {
+	return strings.TrimRight(helpers.GetEnv("PORTAL_URL", getAppBaseURL()+"/portal"), "/")
+}
+
+// getCookieSecure menentukan atribut Secure cookie sesi portal (default true jika APP_BASE_URL memakai https)
+func getCookieSecure() bool {
+	return helpers.GetEnvBool("COOKIE_SECURE", strings.HasPrefix(getAppBaseURL(), "https://"))
+}
+
+// portalLinkCooldown mencegah magic link dikirim beruntun ke participant yang sama
+const portalLinkCooldown = time.Minute
+
+// portalMaxMatches membatasi jumlah pendaftaran yang dikirimi link untuk satu permintaan
+const portalMaxMatches = 5
+
+// portalEditableStatuses adalah status yang datanya masih boleh diubah pendaftar
+var portalEditableStatuses = map[string]bool{
+	models.StatusPending:    true,
+	models.StatusWaitlisted: true,
+	models.StatusApproved:   true,
+}
+
+// RequestMagicLink mengirim link masuk portal ke pendaftaran yang cocok dengan nomor HP / email (public).
+// Permintaan dengan nomor HP dijawab lewat WhatsApp / SMS, dengan email lewat email pendaftaran.
+// Response selalu sama walaupun data tidak ditemukan supaya tidak bisa dipakai menebak pendaftar.
+func (pc *PortalController) RequestMagicLink(c *gin.Context) {
+	var form forms.MagicLinkRequestForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	query := pc.DB.Model(&models.Participant{})
+	contact := strings.TrimSpace(form.Contact)
+	byPhone := !strings.Contains(contact, "@")
+	if byPhone {
+		phone, err := helpers.NormalizeIndonesianPhone(contact)
+		if err != nil {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+		query = query.Where("phone = ?", phone)
+	} else {
+		query = query.Where("email = ?", *normalizeEmail(contact))
+	}
+	if form.Event != "" {
+		query = query.Where("event_id IN (?)", pc.DB.Model(&models.Event{}).Select("id").Where("slug = ?", form.Event))
+	}
+
+	var participants []models.Participant
+	if err := query.Order("created_at desc").Limit(portalMaxMatches).Find(&participants).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	for _, p := range participants {
+		if err := pc.sendMagicLink(p, byPhone); err != nil {
+			log.Printf("ERROR: Gagal membuat magic link untuk %s: %v", p.ID, err)
+		}
+	}
+
+	helpers.ResponseSuccess(c, "Jika data cocok, link masuk portal sudah dikirim ke WhatsApp / SMS atau email pendaftaran", nil)
+}
+
+// sendMagicLink membuat token sekali pakai dan mengirim link portal ke participant (background):
+// lewat WhatsApp / SMS jika diminta dengan nomor HP atau participant tidak punya email, selain itu
+// lewat email. Jika WhatsApp / SMS tidak aktif atau gagal, link dikirim ke email (jika ada).
+// Link yang baru dikirim tidak dikirim ulang selama cooldown.
+func (pc *PortalController) sendMagicLink(participant models.Participant, byPhone bool) error {
+	viaMessage := (byPhone || participant.Email == nil) && pc.Messages.Enabled()
+	if !viaMessage && participant.Email == nil {
+		log.Printf("Magic link for participant %s skipped: no email and messaging disabled", participant.ID)
+		return nil
+	}
+	var recent int64
+	if err := pc.DB.Model(&models.ParticipantMagicLink{}).
+		Where("participant_id = ? AND used_at IS NULL AND created_at > ?", participant.ID, time.Now().Add(-portalLinkCooldown)).
+		Count(&recent).Error; err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	link := models.ParticipantMagicLink{
+		ParticipantID: participant.ID,
+		TokenHash:     helpers.SHA256Hash(token),
+		ExpiresAt:     time.Now().Add(getPortalLinkTTL()),
+		CreatedAt:     time.Now(),
+	}
+	if err := pc.DB.Create(&link).Error; err != nil {
+		return err
+	}
+
+	var event *models.Event
+	eventName := "Youth College"
+	if participant.EventID != nil {
+		event = &models.Event{}
+		if err := pc.DB.First(event, *participant.EventID).Error; err == nil {
+			eventName = event.Name
+		} else {
+			event = nil
+		}
+	}
+	url := getPortalURL() + "?token=" + token
+	expires := link.ExpiresAt.Format("02 Jan 2006 15:04")
+	go func() {
+		if viaMessage {
+			err := pc.Messages.SendDirect(context.Background(), messaging.TemplatePortalLink, participant, event,
+				map[string]string{"portal_link": url, "link_expires_at": expires})
+			if err == nil {
+				return
+			}
+			log.Printf("ERROR: Gagal mengirim magic link ke %s: %v", participant.Phone, err)
+			if participant.Email == nil {
+				return
+			}
+		}
+		msg := mailer.Message{
+			To:      *participant.Email,
+			Subject: "Link masuk portal pendaftaran " + eventName,
+			Text: fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk melihat dan mengubah data pendaftaran kamu di %s:\n\n%s\n\n"+
+				"Link hanya bisa dipakai sekali dan berlaku sampai %s. Abaikan email ini jika kamu tidak memintanya.\n",
+				participant.Name, eventName, url, expires),
+		}
+		if err := pc.Mailer.Send(context.Background(), msg); err != nil {
+			log.Printf("ERROR: Gagal mengirim magic link ke %s: %v", msg.To, err)
+		}
+	}()
+	return nil
+}
+
+// ExchangeMagicLink menukar token magic link (sekali pakai) dengan token sesi portal (public)
+func (pc *PortalController) ExchangeMagicLink(c *gin.Context) {
+	var form forms.MagicLinkExchangeForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	sessionToken, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	now := time.Now()
+	session := models.ParticipantSession{
+		TokenHash: helpers.SHA256Hash(sessionToken),
+		ExpiresAt: now.Add(getPortalSessionTTL()),
+		CreatedAt: now,
+	}
+	err = pc.DB.Transaction(func(tx *gorm.DB) error {
+		var link models.ParticipantMagicLink
+		if err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", helpers.SHA256Hash(form.Token), now).
+			First(&link).Error; err != nil {
+			return err
+		}
+		// used_at di WHERE supaya dua request bersamaan tidak sama-sama berhasil
+		result := tx.Model(&link).Where("used_at IS NULL").UpdateColumn("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		session.ParticipantID = link.ParticipantID
+		return tx.Create(&session).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseError(c, http.StatusUnauthorized, "Link tidak valid, sudah dipakai atau sudah kedaluwarsa")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "participant_token",
+		Value:    sessionToken,
+		Path:     "/api/portal",
+		MaxAge:   int(getPortalSessionTTL().Seconds()),
+		HttpOnly: true,
+		Secure:   getCookieSecure(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	helpers.ResponseSuccess(c, "Portal session created", gin.H{
+		"token":      sessionToken,
+		"expires_at": session.ExpiresAt,
+	})
+}
+
+// Logout mengakhiri sesi portal
+func (pc *PortalController) Logout(c *gin.Context) {
+	if err := pc.DB.Delete(&models.ParticipantSession{}, c.MustGet("participant_session_id")).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "participant_token",
+		Value:    "",
+		Path:     "/api/portal",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   getCookieSecure(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	helpers.ResponseSuccess(c, "Logout successful", nil)
+}
+
+// currentParticipant mengambil participant pemilik sesi portal beserta event-nya (event nil jika tanpa event).
+// Jika gagal response error sudah dikirim dan hasilnya false.
+func (pc *PortalController) currentParticipant(c *gin.Context) (*models.Participant, *models.Event, bool) {
+	var participant models.Participant
+	if err := pc.DB.Where("id = ?", c.GetString("participant_id")).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// Participant sudah dihapus admin setelah sesi dibuat
+			helpers.ResponseUnauthorized(c, "Invalid or expired session")
+			return nil, nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, nil, false
+	}
+	if participant.EventID == nil {
+		return &participant, nil, true
+	}
+	var event models.Event
+	if err := pc.DB.First(&event, *participant.EventID).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, nil, false
+	}
+	return &participant, &event, true
+}
+
+// canEdit menentukan apakah pendaftar masih boleh mengubah datanya: status masih aktif dan registrasi event masih dibuka
+func canEdit(participant models.Participant, event *models.Event) bool {
+	if !portalEditableStatuses[participant.Status] {
+		return false
+	}
+	return event == nil || event.RegistrationOpen(time.Now())
+}
+
+// portalResponse menyusun data pendaftaran untuk portal
+func (pc *PortalController) portalResponse(participant models.Participant, event *models.Event) (gin.H, error) {
+	list := []models.Participant{participant}
+	if err := questions.Attach(pc.DB, list); err != nil {
+		return nil, err
+	}
+	participant = list[0]
+	if participant.RegistrationCode != nil {
+		participant.TicketURL = ticketURL(*participant.RegistrationCode)
+	}
+	participant.UploadToken = fileSigner().UploadToken(participant.ID, time.Now().Add(getUploadTokenTTL()))
+
+	var histories []models.ParticipantStatusHistory
+	if err := pc.DB.Where("participant_id = ?", participant.ID).Order("created_at asc, id asc").Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	optedOut, err := pc.Participants.Messages.OptedOut(participant.Phone)
+	if err != nil {
+		return nil, err
+	}
+	certs := []gin.H{}
+	var issued []models.Certificate
+	if err := pc.DB.Where("participant_id = ? AND status = ? AND revoked_at IS NULL", participant.ID, models.CertificateGenerated).
+		Order("issued_at asc").Find(&issued).Error; err != nil {
+		return nil, err
+	}
+	for _, cert := range issued {
+		certs = append(certs, gin.H{"serial": cert.Serial, "issued_at": cert.IssuedAt})
+	}
+	data := gin.H{
+		"participant":       participant,
+		"status_history":    histories,
+		"can_edit":          canEdit(participant, event),
+		"can_withdraw":      workflow.CanTransition(participant.Status, models.StatusWithdrawn),
+		"messaging_opt_out": optedOut,
+		"certificates":      certs,
+	}
+	if event != nil {
+		data["event"] = eventResponse(*event)
+	}
+	return data, nil
+}
+
+// GetMe mengambil data pendaftaran, status dan riwayat status milik pendaftar
+func (pc *PortalController) GetMe(c *gin.Context) {
+	participant, event, ok := pc.currentParticipant(c)
+	if !ok {
+		return
+	}
+	data, err := pc.portalResponse(*participant, event)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Registration retrieved successfully", data)
+}
+
+// UpdateMe mengubah data pendaftaran oleh pendaftar sendiri selama registrasi event masih dibuka.
+// Validasi sama dengan registrasi, termasuk syarat event; nomor HP tidak bisa diubah.
+func (pc *PortalController) UpdateMe(c *gin.Context) {
+	var form forms.PortalUpdateForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	participant, event, ok := pc.currentParticipant(c)
+	if !ok {
+		return
+	}
+	if !canEdit(*participant, event) {
+		helpers.ResponseError(c, http.StatusForbidden, "Data pendaftaran tidak bisa diubah lagi, hubungi panitia")
+		return
+	}
+
+	phone := participant.PhoneInput
+	if phone == "" {
+		phone = participant.Phone
+	}
+	update := forms.ParticipantForm{
+		Name:           form.Name,
+		Place:          form.Place,
+		BirthDate:      form.BirthDate,
+		Kampus:         form.Kampus,
+		Jurusan:        form.Jurusan,
+		Angkatan:       form.Angkatan,
+		Phone:          phone,
+		Email:          form.Email,
+		Language:       form.Language,
+		CampusID:       form.CampusID,
+		StudyProgramID: form.StudyProgramID,
+		Answers:        form.Answers,
+	}
+	if !pc.Participants.updateParticipant(c, participant, update, true) {
+		return
+	}
+
+	data, err := pc.portalResponse(*participant, event)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Registration updated successfully", data)
+}
+
+// UpdateNotifications mengatur apakah pendaftar mau menerima WhatsApp / SMS ke nomor HP-nya
+func (pc *PortalController) UpdateNotifications(c *gin.Context) {
+	var form forms.PortalNotificationForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	participant, _, ok := pc.currentParticipant(c)
+	if !ok {
+		return
+	}
+	var err error
+	if *form.OptOut {
+		err = pc.Participants.Messages.OptOut(participant.Phone, "participant", "Lewat portal pendaftar", "")
+	} else {
+		err = pc.Participants.Messages.OptIn(participant.Phone)
+	}
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Notification preference updated successfully", gin.H{"messaging_opt_out": *form.OptOut})
+}
+
+// Withdraw mengundurkan diri dari event; kursi yang dilepas otomatis diisi dari waitlist
+func (pc *PortalController) Withdraw(c *gin.Context) {
+	var form forms.WithdrawForm
+	// Body opsional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			helpers.ResponseBadRequest(c, err.Error())
+			return
+		}
+	}
+	participant, event, ok := pc.currentParticipant(c)
+	if !ok {
+		return
+	}
+
+	result, err := workflow.Transition(pc.DB, participant.ID, models.StatusWithdrawn, form.Reason, workflow.ActorParticipant)
+	if err != nil {
+		switch {
+		case errors.Is(err, workflow.ErrInvalidTransition):
+			helpers.ResponseError(c, http.StatusUnprocessableEntity, "Pendaftaran dengan status "+participant.Status+" tidak bisa dibatalkan")
+		case errors.Is(err, workflow.ErrStatusChanged):
+			helpers.ResponseConflict(c, err.Error())
+		default:
+			helpers.ResponseInternalServerError(c, err.Error())
+		}
+		return
+	}
+	pc.Participants.Webhooks.PublishStatusChange(*result.Participant, result.History.FromStatus, form.Reason, workflow.ActorParticipant)
+	notifyPromoted(pc.Participants.Webhooks, pc.Participants.Messages, pc.Participants.Emails, result.Promoted)
+
+	data, err := pc.portalResponse(*result.Participant, event)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Registration withdrawn successfully", data)
+}
+
+// GetTicket mengirim tiket milik pendaftar (format pdf, png atau svg seperti link tiket)
+func (pc *PortalController) GetTicket(c *gin.Context) {
+	participant, _, ok := pc.currentParticipant(c)
+	if !ok {
+		return
+	}
+	pc.Tickets.writeTicket(c, *participant)
+}
+
+// DownloadCertificate mengirim PDF sertifikat milik pendaftar (link dari email sertifikat)
+func (pc *PortalController) DownloadCertificate(c *gin.Context) {
+	participant, _, ok := pc.currentParticipant(c)
+	if !ok {
+		return
+	}
+	var cert models.Certificate
+	serial := strings.ToUpper(strings.TrimSpace(c.Param("serial")))
+	if err := pc.DB.Where("serial = ? AND participant_id = ? AND revoked_at IS NULL", serial, participant.ID).First(&cert).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Certificate not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	sendCertificatePDF(c, pc.Participants.Storage, &cert)
+}
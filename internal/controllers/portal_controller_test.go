@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/helpers"
+	"backend/internal/messaging"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// portalServer mendaftarkan endpoint portal seperti di router, plus satu endpoint admin untuk
+// memastikan sesi portal dan JWT admin tidak bisa saling dipakai
+type portalServer struct {
+	*testServer
+	mail  *mailbox
+	phone *phoneBox
+}
+
+func newPortalServer(t *testing.T, messagingEnabled bool) *portalServer {
+	t.Setenv("PORTAL_URL", "https://yc.test/portal")
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	s := &portalServer{testServer: newTestServer(t), mail: newMailbox(), phone: newPhoneBox()}
+	var providers map[string]messaging.Provider
+	if messagingEnabled {
+		providers = map[string]messaging.Provider{models.ChannelWhatsApp: s.phone}
+	}
+	messages := newMessages(s.db, providers)
+	participants := s.participantController(s.mail, messages)
+	pc := NewPortalController(s.db, s.mail, messages, participants, NewTicketController(s.db))
+	ac := NewAuthController(s.db)
+
+	s.engine.POST("/api/login", ac.Login)
+	s.engine.GET("/api/admin/profile", middleware.AuthMiddleware(), ac.GetProfile)
+	s.engine.POST("/api/portal/magic-link", pc.RequestMagicLink)
+	s.engine.POST("/api/portal/session", pc.ExchangeMagicLink)
+	portal := s.engine.Group("/api/portal")
+	portal.Use(middleware.ParticipantAuth())
+	portal.GET("/me", pc.GetMe)
+	portal.PUT("/me", pc.UpdateMe)
+	portal.POST("/me/withdraw", pc.Withdraw)
+	portal.POST("/logout", pc.Logout)
+	return s
+}
+
+// magicLink menyimpan magic link untuk participant dan mengembalikan tokennya
+func (s *portalServer) magicLink(t *testing.T, participantID string, expiresAt time.Time) string {
+	t.Helper()
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := models.ParticipantMagicLink{ParticipantID: participantID, TokenHash: helpers.SHA256Hash(token), ExpiresAt: expiresAt, CreatedAt: time.Now()}
+	if err := s.db.Create(&link).Error; err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// login menukar magic link baru dengan token sesi portal
+func (s *portalServer) login(t *testing.T, participantID string) string {
+	t.Helper()
+	var session struct {
+		Token string `json:"token"`
+	}
+	w := s.do(http.MethodPost, "/api/portal/session", map[string]string{"token": s.magicLink(t, participantID, time.Now().Add(time.Minute))})
+	decode(t, w, http.StatusOK).into(t, &session)
+	return session.Token
+}
+
+// portalData adalah bagian response portal yang dicek test
+type portalData struct {
+	Participant models.Participant `json:"participant"`
+	CanEdit     bool               `json:"can_edit"`
+	CanWithdraw bool               `json:"can_withdraw"`
+}
+
+var tokenParam = regexp.MustCompile(`token=([^\s&]+)`)
+
+func TestPortalMagicLinkIsSingleUseAndExpires(t *testing.T) {
+	s := newPortalServer(t, false)
+	p := testutil.CreateParticipant(t, s.db, "Budi")
+	token := s.magicLink(t, p.ID, time.Now().Add(time.Minute))
+	expired := s.magicLink(t, p.ID, time.Now().Add(-time.Second))
+
+	w := s.do(http.MethodPost, "/api/portal/session", map[string]string{"token": token})
+	var session struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	decode(t, w, http.StatusOK).into(t, &session)
+	cookie := w.Result().Cookies()
+	if len(cookie) != 1 || cookie[0].Name != "participant_token" || cookie[0].Value != session.Token || !cookie[0].HttpOnly {
+		t.Errorf("cookies = %+v", cookie)
+	}
+	if until := time.Until(session.ExpiresAt); until < time.Hour || until > 2*time.Hour {
+		t.Errorf("session expires in %s, want PORTAL_SESSION_TTL default 2h", until)
+	}
+
+	for name, tok := range map[string]string{"reused": token, "expired": expired, "unknown": "tidak-ada"} {
+		res := decode(t, s.do(http.MethodPost, "/api/portal/session", map[string]string{"token": tok}), http.StatusUnauthorized)
+		if res.Error != "Link tidak valid, sudah dipakai atau sudah kedaluwarsa" {
+			t.Errorf("%s: %q", name, res.Error)
+		}
+	}
+	var sessions int64
+	s.db.Model(&models.ParticipantSession{}).Count(&sessions)
+	if sessions != 1 {
+		t.Errorf("sessions = %d, want 1", sessions)
+	}
+
+	// Sesi bisa dipakai lewat header maupun cookie
+	var me portalData
+	decode(t, s.do(http.MethodGet, "/api/portal/me", nil, "Authorization", "Bearer "+session.Token), http.StatusOK).into(t, &me)
+	if me.Participant.ID != p.ID {
+		t.Errorf("me = %s, want %s", me.Participant.ID, p.ID)
+	}
+	decode(t, s.do(http.MethodGet, "/api/portal/me", nil, "Cookie", "participant_token="+session.Token), http.StatusOK)
+}
+
+func TestPortalRequestMagicLinkByEmail(t *testing.T) {
+	s := newPortalServer(t, true)
+	email := "budi@example.com"
+	p := testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) { p.Email = &email })
+
+	// Data tidak ditemukan dijawab sama persis supaya tidak bisa dipakai menebak pendaftar
+	unknown := decode(t, s.do(http.MethodPost, "/api/portal/magic-link", map[string]string{"contact": "lain@example.com"}), http.StatusOK)
+	found := decode(t, s.do(http.MethodPost, "/api/portal/magic-link", map[string]string{"contact": " Budi@Example.com "}), http.StatusOK)
+	if unknown.Message != found.Message {
+		t.Errorf("responses differ: %q vs %q", unknown.Message, found.Message)
+	}
+
+	// Diminta dengan email: dikirim ke email walaupun WhatsApp aktif
+	msg := s.mail.next(t)
+	if msg.To != email || !strings.Contains(msg.Text, "https://yc.test/portal?token=") {
+		t.Fatalf("email to %s: %s", msg.To, msg.Text)
+	}
+	select {
+	case sent := <-s.phone.sent:
+		t.Errorf("unexpected WhatsApp to %s", sent.To)
+	default:
+	}
+
+	// Cooldown: permintaan beruntun tidak membuat link baru
+	decode(t, s.do(http.MethodPost, "/api/portal/magic-link", map[string]string{"contact": email}), http.StatusOK)
+	s.mail.empty(t)
+	var links int64
+	s.db.Model(&models.ParticipantMagicLink{}).Where("participant_id = ?", p.ID).Count(&links)
+	if links != 1 {
+		t.Errorf("magic links = %d, want 1", links)
+	}
+
+	token := tokenParam.FindStringSubmatch(msg.Text)[1]
+	decode(t, s.do(http.MethodPost, "/api/portal/session", map[string]string{"token": token}), http.StatusOK)
+}
+
+func TestPortalRequestMagicLinkByPhone(t *testing.T) {
+	s := newPortalServer(t, true)
+	email := "budi@example.com"
+	testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) { p.Email = &email })
+
+	decode(t, s.do(http.MethodPost, "/api/portal/magic-link", map[string]string{"contact": "0812-3456-7890"}), http.StatusOK)
+	msg := s.phone.next(t)
+	if msg.To != "+6281234567890" || !strings.Contains(msg.Body, "https://yc.test/portal?token=") {
+		t.Fatalf("message to %s: %s", msg.To, msg.Body)
+	}
+	s.mail.empty(t)
+	// Token sekali pakai tidak disimpan di log pesan
+	var logged int64
+	s.db.Model(&models.OutboundMessage{}).Count(&logged)
+	if logged != 0 {
+		t.Errorf("outbound messages = %d, want 0", logged)
+	}
+	decode(t, s.do(http.MethodPost, "/api/portal/session", map[string]string{"token": tokenParam.FindStringSubmatch(msg.Body)[1]}), http.StatusOK)
+}
+
+func TestPortalMagicLinkFallsBackToEmail(t *testing.T) {
+	s := newPortalServer(t, true)
+	s.phone.Err = errors.New("nomor tidak terdaftar di WhatsApp")
+	email := "budi@example.com"
+	testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) { p.Email = &email })
+
+	decode(t, s.do(http.MethodPost, "/api/portal/magic-link", map[string]string{"contact": "+6281234567890"}), http.StatusOK)
+	s.phone.next(t)
+	if msg := s.mail.next(t); msg.To != email || !tokenParam.MatchString(msg.Text) {
+		t.Errorf("fallback email to %s: %s", msg.To, msg.Text)
+	}
+}
+
+func TestPortalUpdateOnlyWhileRegistrationOpen(t *testing.T) {
+	s := newPortalServer(t, false)
+	closes := time.Now().Add(time.Hour)
+	event := testutil.CreateEvent(t, s.db, func(e *models.Event) { e.RegistrationClosesAt = &closes })
+	p := testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) { p.EventID = &event.ID })
+	token := s.login(t, p.ID)
+	auth := []string{"Authorization", "Bearer " + token}
+	update := map[string]string{
+		"name": "Budi Santoso", "place": "Bandung", "birth_date": "2001-02-03",
+		"kampus": "ITB", "jurusan": "Informatika", "angkatan": "2021",
+	}
+
+	var me portalData
+	decode(t, s.do(http.MethodPut, "/api/portal/me", update, auth...), http.StatusOK).into(t, &me)
+	if me.Participant.Name != "Budi Santoso" || me.Participant.Kampus != "ITB" || !me.CanEdit {
+		t.Errorf("updated = %+v, can_edit %v", me.Participant, me.CanEdit)
+	}
+	if me.Participant.Phone != p.Phone {
+		t.Errorf("phone changed to %s", me.Participant.Phone)
+	}
+
+	// Registrasi ditutup: data tidak bisa diubah lagi
+	s.db.Model(&event).UpdateColumn("registration_closes_at", time.Now().Add(-time.Minute))
+	update["name"] = "Budi Lain"
+	if res := decode(t, s.do(http.MethodPut, "/api/portal/me", update, auth...), http.StatusForbidden); res.Error != "Data pendaftaran tidak bisa diubah lagi, hubungi panitia" {
+		t.Errorf("closed: %q", res.Error)
+	}
+	decode(t, s.do(http.MethodGet, "/api/portal/me", nil, auth...), http.StatusOK).into(t, &me)
+	if me.CanEdit || me.Participant.Name != "Budi Santoso" {
+		t.Errorf("after close: name %s, can_edit %v", me.Participant.Name, me.CanEdit)
+	}
+
+	// Registrasi dibuka lagi, tapi pendaftaran yang ditolak tetap tidak bisa diubah
+	s.db.Model(&event).UpdateColumn("registration_closes_at", nil)
+	s.db.Model(&p).UpdateColumn("status", models.StatusRejected)
+	decode(t, s.do(http.MethodPut, "/api/portal/me", update, auth...), http.StatusForbidden)
+}
+
+func TestPortalWithdrawPromotesWaitlist(t *testing.T) {
+	s := newPortalServer(t, false)
+	capacity := 1
+	event := testutil.CreateEvent(t, s.db, func(e *models.Event) { e.Capacity = &capacity })
+	p := testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) {
+		p.EventID = &event.ID
+		p.Status = models.StatusApproved
+	})
+	email := "citra@example.com"
+	waiting := testutil.CreateParticipant(t, s.db, "Citra", func(p *models.Participant) {
+		p.EventID = &event.ID
+		p.Status = models.StatusWaitlisted
+		p.Email = &email
+	})
+	auth := []string{"Authorization", "Bearer " + s.login(t, p.ID)}
+
+	var me portalData
+	decode(t, s.do(http.MethodPost, "/api/portal/me/withdraw", map[string]string{"reason": "Bentrok jadwal"}, auth...), http.StatusOK).into(t, &me)
+	if me.Participant.Status != models.StatusWithdrawn || me.CanWithdraw || me.CanEdit {
+		t.Errorf("withdrawn = %s, can_withdraw %v, can_edit %v", me.Participant.Status, me.CanWithdraw, me.CanEdit)
+	}
+	var history models.ParticipantStatusHistory
+	s.db.Where("participant_id = ?", p.ID).Order("id desc").First(&history)
+	if history.Actor != "participant" || history.Reason != "Bentrok jadwal" {
+		t.Errorf("history = %+v", history)
+	}
+
+	// Kursi yang dilepas diisi dari waitlist dan pendaftar yang naik diberi tahu
+	s.db.First(&waiting, "id = ?", waiting.ID)
+	if waiting.Status != models.StatusPending {
+		t.Errorf("waitlisted participant = %s, want pending", waiting.Status)
+	}
+	var queued int64
+	s.db.Model(&models.OutboundEmail{}).Where("participant_id = ?", waiting.ID).Count(&queued)
+	if queued != 1 {
+		t.Errorf("promotion emails = %d, want 1", queued)
+	}
+
+	// Mundur dua kali ditolak
+	res := decode(t, s.do(http.MethodPost, "/api/portal/me/withdraw", nil, auth...), http.StatusUnprocessableEntity)
+	if res.Error != "Pendaftaran dengan status withdrawn tidak bisa dibatalkan" {
+		t.Errorf("second withdraw: %q", res.Error)
+	}
+}
+
+func TestPortalSessionSeparateFromAdminJWT(t *testing.T) {
+	s := newPortalServer(t, false)
+	password, _ := helpers.HashPassword("rahasia123")
+	s.db.Create(&models.User{Username: "admin", Password: password})
+	var login struct {
+		Token string `json:"token"`
+	}
+	decode(t, s.do(http.MethodPost, "/api/login", map[string]string{"username": "admin", "password": "rahasia123"}), http.StatusOK).into(t, &login)
+	decode(t, s.do(http.MethodGet, "/api/admin/profile", nil, "Authorization", "Bearer "+login.Token), http.StatusOK)
+
+	p := testutil.CreateParticipant(t, s.db, "Budi")
+	session := s.login(t, p.ID)
+
+	// JWT admin tidak berlaku di portal, token sesi portal tidak berlaku di endpoint admin
+	decode(t, s.do(http.MethodGet, "/api/portal/me", nil, "Authorization", "Bearer "+login.Token), http.StatusUnauthorized)
+	decode(t, s.do(http.MethodGet, "/api/admin/profile", nil, "Authorization", "Bearer "+session), http.StatusUnauthorized)
+	decode(t, s.do(http.MethodGet, "/api/admin/profile", nil, "Cookie", "participant_token="+session), http.StatusUnauthorized)
+
+	// Logout mengakhiri sesi portal saja
+	decode(t, s.do(http.MethodPost, "/api/portal/logout", nil, "Authorization", "Bearer "+session), http.StatusOK)
+	decode(t, s.do(http.MethodGet, "/api/portal/me", nil, "Authorization", "Bearer "+session), http.StatusUnauthorized)
+	decode(t, s.do(http.MethodGet, "/api/admin/profile", nil, "Authorization", "Bearer "+login.Token), http.StatusOK)
+}
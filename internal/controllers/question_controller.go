@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/questions"
+)
+
+type QuestionController struct {
+	DB *gorm.DB
+}
+
+// NewQuestionController membuat instance controller baru
+func NewQuestionController(db *gorm.DB) *QuestionController {
+	return &QuestionController{DB: db}
+}
+
+// applyQuestionForm menyalin form ke pertanyaan lalu memvalidasi definisinya
+func applyQuestionForm(form forms.RegistrationQuestionForm, q *models.RegistrationQuestion) error {
+	key := strings.TrimSpace(form.Key)
+	if key == "" {
+		key = questions.KeyFromLabel(form.Label)
+	}
+	q.Key = key
+	q.Label = form.Label
+	q.HelpText = form.HelpText
+	q.Type = form.Type
+	q.Required = form.Required
+	q.Options = form.Options
+	q.Rules = form.Rules
+	q.Position = form.Position
+	return questions.CheckDefinition(*q)
+}
+
+// keyTaken mengecek apakah key sudah dipakai pertanyaan lain di event yang sama
+func (qc *QuestionController) keyTaken(eventID uint, key string, exceptID uint) (bool, error) {
+	var count int64
+	err := qc.DB.Model(&models.RegistrationQuestion{}).
+		Where("event_id = ? AND question_key = ? AND id <> ?", eventID, key, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// findQuestion mengambil pertanyaan milik event di context
+func (qc *QuestionController) findQuestion(c *gin.Context) (*models.RegistrationQuestion, bool) {
+	event := eventFromContext(c)
+	var q models.RegistrationQuestion
+	if err := qc.DB.Where("id = ? AND event_id = ?", c.Param("id"), event.ID).First(&q).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Question not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &q, true
+}
+
+// countAnswers menghitung jumlah jawaban yang sudah tersimpan untuk pertanyaan
+func (qc *QuestionController) countAnswers(questionID uint) (int64, error) {
+	var count int64
+	err := qc.DB.Model(&models.ParticipantAnswer{}).Where("question_id = ?", questionID).Count(&count).Error
+	return count, err
+}
+
+// GetQuestions mengambil pertanyaan registrasi event (public, untuk render form pendaftaran)
+func (qc *QuestionController) GetQuestions(c *gin.Context) {
+	list, err := questions.Load(qc.DB, eventFromContext(c).ID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Questions retrieved successfully", gin.H{"questions": list})
+}
+
+// CreateQuestion menambahkan pertanyaan registrasi ke event
+func (qc *QuestionController) CreateQuestion(c *gin.Context) {
+	event := eventFromContext(c)
+
+	var form forms.RegistrationQuestionForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	q := models.RegistrationQuestion{EventID: event.ID}
+	if err := applyQuestionForm(form, &q); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if taken, err := qc.keyTaken(event.ID, q.Key, 0); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if taken {
+		helpers.ResponseConflict(c, "Key pertanyaan sudah dipakai di event ini")
+		return
+	}
+
+	if err := qc.DB.Create(&q).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseCreated(c, "Question created successfully", q)
+}
+
+// UpdateQuestion mengupdate pertanyaan registrasi.
+// Tipe pertanyaan tidak bisa diubah jika sudah ada jawaban karena nilai tersimpan tidak lagi valid.
+func (qc *QuestionController) UpdateQuestion(c *gin.Context) {
+	q, ok := qc.findQuestion(c)
+	if !ok {
+		return
+	}
+
+	var form forms.RegistrationQuestionForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	oldType, oldKey := q.Type, q.Key
+	if err := applyQuestionForm(form, q); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if q.Type != oldType {
+		answered, err := qc.countAnswers(q.ID)
+		if err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		if answered > 0 {
+			helpers.ResponseConflict(c, "Tipe pertanyaan tidak bisa diubah karena sudah ada jawaban")
+			return
+		}
+	}
+	if q.Key != oldKey {
+		if taken, err := qc.keyTaken(q.EventID, q.Key, q.ID); err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		} else if taken {
+			helpers.ResponseConflict(c, "Key pertanyaan sudah dipakai di event ini")
+			return
+		}
+	}
+
+	if err := qc.DB.Save(q).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Question updated successfully", q)
+}
+
+// DeleteQuestion menghapus pertanyaan registrasi.
+// Jika sudah ada jawaban, penghapusan harus dikonfirmasi dengan ?force=true (jawaban ikut terhapus).
+func (qc *QuestionController) DeleteQuestion(c *gin.Context) {
+	q, ok := qc.findQuestion(c)
+	if !ok {
+		return
+	}
+
+	answered, err := qc.countAnswers(q.ID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if answered > 0 && c.Query("force") != "true" {
+		helpers.ResponseConflict(c, "Pertanyaan sudah memiliki jawaban, gunakan force=true untuk menghapus beserta jawabannya")
+		return
+	}
+
+	if err := qc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", q.ID).Delete(&models.ParticipantAnswer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(q).Error
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Question deleted successfully", gin.H{"deleted_answers": answered})
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// newQuestionServer adalah participantServer ditambah endpoint pertanyaan registrasi per event
+func newQuestionServer(t *testing.T) *participantServer {
+	s := newParticipantServer(t)
+	qc := NewQuestionController(s.db)
+	event := s.event()
+	event.GET("/questions", qc.GetQuestions)
+	event.POST("/questions", qc.CreateQuestion)
+	event.PUT("/questions/:id", qc.UpdateQuestion)
+	event.DELETE("/questions/:id", qc.DeleteQuestion)
+	return s
+}
+
+// question membuat pertanyaan lewat endpoint admin
+func (s *participantServer) question(t *testing.T, slug string, body map[string]interface{}) models.RegistrationQuestion {
+	t.Helper()
+	var q models.RegistrationQuestion
+	decode(t, s.do(http.MethodPost, "/events/"+slug+"/questions", body), http.StatusCreated).into(t, &q)
+	return q
+}
+
+func TestQuestionCRUD(t *testing.T) {
+	s := newQuestionServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+
+	q := s.question(t, event.Slug, map[string]interface{}{"label": "Ukuran Kaos", "type": "select", "options": []string{"S", "M", "L"}, "position": 2})
+	if q.Key != "ukuran_kaos" || q.EventID != event.ID {
+		t.Fatalf("question = %+v", q)
+	}
+	s.question(t, event.Slug, map[string]interface{}{"key": "motivasi", "label": "Motivasi", "type": "text", "position": 1})
+	// Key sama boleh di event lain, tidak di event yang sama
+	s.question(t, other.Slug, map[string]interface{}{"label": "Ukuran Kaos", "type": "text"})
+	decode(t, s.do(http.MethodPost, "/events/"+event.Slug+"/questions", map[string]interface{}{"label": "Ukuran kaos", "type": "text"}), http.StatusConflict)
+	decode(t, s.do(http.MethodPost, "/events/"+event.Slug+"/questions", map[string]interface{}{"label": "Kaos", "type": "select"}), http.StatusBadRequest)
+
+	var list struct {
+		Questions []models.RegistrationQuestion `json:"questions"`
+	}
+	decode(t, s.do(http.MethodGet, "/events/"+event.Slug+"/questions", nil), http.StatusOK).into(t, &list)
+	if len(list.Questions) != 2 || list.Questions[0].Key != "motivasi" {
+		t.Errorf("questions = %+v, want ordered by position", list.Questions)
+	}
+
+	// Pertanyaan event lain tidak bisa diakses lewat slug event ini
+	path := fmt.Sprintf("/events/%s/questions/%d", event.Slug, q.ID)
+	decode(t, s.do(http.MethodPut, fmt.Sprintf("/events/%s/questions/%d", other.Slug, q.ID), map[string]interface{}{"label": "Kaos", "type": "text"}), http.StatusNotFound)
+
+	// Setelah ada jawaban, tipe tidak bisa diubah dan hapus perlu force=true
+	s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{"answers": map[string]interface{}{"ukuran_kaos": "M"}})
+	update := map[string]interface{}{"label": "Ukuran Kaos", "type": "text"}
+	if res := decode(t, s.do(http.MethodPut, path, update), http.StatusConflict); res.Error != "Tipe pertanyaan tidak bisa diubah karena sudah ada jawaban" {
+		t.Errorf("type change: %q", res.Error)
+	}
+	update = map[string]interface{}{"label": "Ukuran Kaos", "type": "select", "options": []string{"S", "M", "L", "XL"}, "required": true}
+	decode(t, s.do(http.MethodPut, path, update), http.StatusOK)
+
+	decode(t, s.do(http.MethodDelete, path, nil), http.StatusConflict)
+	var deleted struct {
+		DeletedAnswers int64 `json:"deleted_answers"`
+	}
+	decode(t, s.do(http.MethodDelete, path+"?force=true", nil), http.StatusOK).into(t, &deleted)
+	var answers int64
+	s.db.Model(&models.ParticipantAnswer{}).Count(&answers)
+	if deleted.DeletedAnswers != 1 || answers != 0 {
+		t.Errorf("deleted answers = %d, remaining %d", deleted.DeletedAnswers, answers)
+	}
+}
+
+func TestRegistrationAnswers(t *testing.T) {
+	s := newQuestionServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	s.question(t, event.Slug, map[string]interface{}{"label": "Ukuran Kaos", "type": "select", "options": []string{"S", "M", "L"}, "required": true})
+	s.question(t, event.Slug, map[string]interface{}{"label": "Sesi", "type": "multi_select", "options": []string{"Pagi", "Siang", "Malam"}})
+	s.question(t, event.Slug, map[string]interface{}{"label": "Umur", "type": "number", "rules": map[string]interface{}{"min": 17}})
+
+	res := decode(t, s.do(http.MethodPost, "/events/"+event.Slug+"/participants",
+		registration(map[string]interface{}{"answers": map[string]interface{}{"ukuran_kaos": "XL", "umur": 16, "hobi": "x"}})), http.StatusBadRequest)
+	want := map[string]string{"ukuran_kaos": "pilihan tidak valid", "umur": "minimal 17", "hobi": "pertanyaan tidak dikenal"}
+	if !reflect.DeepEqual(res.Fields, want) {
+		t.Errorf("fields = %v, want %v", res.Fields, want)
+	}
+	// Tanpa event tidak ada pertanyaan, jadi answers apa pun ditolak
+	decode(t, s.do(http.MethodPost, "/participants", registration(map[string]interface{}{"answers": map[string]interface{}{"ukuran_kaos": "M"}})), http.StatusBadRequest)
+
+	p := s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{
+		"answers": map[string]interface{}{"ukuran_kaos": "M", "sesi": []string{"Malam", "Pagi"}, "umur": "20"},
+	})
+	wantAnswers := map[string]interface{}{"ukuran_kaos": "M", "sesi": []interface{}{"Pagi", "Malam"}, "umur": 20.0}
+	if !reflect.DeepEqual(p.Answers, wantAnswers) {
+		t.Fatalf("answers = %v, want %v", p.Answers, wantAnswers)
+	}
+
+	// Update hanya mengganti key yang dikirim; null menghapus jawaban
+	var updated models.Participant
+	body := registration(map[string]interface{}{"answers": map[string]interface{}{"ukuran_kaos": "L", "umur": nil}})
+	decode(t, s.do(http.MethodPut, "/events/"+event.Slug+"/admin/participants/"+p.ID, body), http.StatusOK).into(t, &updated)
+	wantAnswers = map[string]interface{}{"ukuran_kaos": "L", "sesi": []interface{}{"Pagi", "Malam"}}
+	if !reflect.DeepEqual(updated.Answers, wantAnswers) {
+		t.Errorf("updated answers = %v, want %v", updated.Answers, wantAnswers)
+	}
+	// Tanpa field answers jawaban lama tetap ada
+	updated = models.Participant{}
+	decode(t, s.do(http.MethodPut, "/events/"+event.Slug+"/admin/participants/"+p.ID, registration(map[string]interface{}{"place": "Bandung"})), http.StatusOK).into(t, &updated)
+	if !reflect.DeepEqual(updated.Answers, wantAnswers) {
+		t.Errorf("answers after update without answers = %v", updated.Answers)
+	}
+	body = registration(map[string]interface{}{"answers": map[string]interface{}{"ukuran_kaos": nil}})
+	if res := decode(t, s.do(http.MethodPut, "/events/"+event.Slug+"/admin/participants/"+p.ID, body), http.StatusBadRequest); res.Fields["ukuran_kaos"] != "wajib diisi" {
+		t.Errorf("removing required answer: %v", res.Fields)
+	}
+}
+
+func TestAnswerFiltersAndExport(t *testing.T) {
+	s := newQuestionServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	s.question(t, event.Slug, map[string]interface{}{"label": "Ukuran Kaos", "type": "select", "options": []string{"S", "M", "L"}, "position": 1})
+	s.question(t, event.Slug, map[string]interface{}{"label": "Vegetarian", "type": "boolean", "position": 2})
+	s.question(t, event.Slug, map[string]interface{}{"label": "Catatan", "type": "text", "position": 3})
+	s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{"name": "Andi", "phone": "081111111111",
+		"answers": map[string]interface{}{"ukuran_kaos": "M", "vegetarian": true, "catatan": "=HYPERLINK(\"x\")"}})
+	s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{"name": "Budi", "phone": "082222222222",
+		"answers": map[string]interface{}{"ukuran_kaos": "L", "vegetarian": false}})
+	s.register(t, event.Slug, http.StatusCreated, map[string]interface{}{"name": "Citra", "phone": "083333333333",
+		"answers": map[string]interface{}{"ukuran_kaos": "M"}})
+
+	base := "/events/" + event.Slug + "/admin/participants"
+	for query, want := range map[string][]string{
+		"answers[ukuran_kaos]=M":                       {"Andi", "Citra"},
+		"answers[ukuran_kaos]=M&answers[vegetarian]=1": {"Andi"},
+		"answers[vegetarian]=false":                    {"Budi"},
+		"answers[catatan]=hyperlink":                   {"Andi"},
+	} {
+		var list participantList
+		decode(t, s.do(http.MethodGet, base+"?sort_by=name&sort_order=asc&"+query, nil), http.StatusOK).into(t, &list)
+		if !reflect.DeepEqual(list.names(), want) {
+			t.Errorf("%s: %v, want %v", query, list.names(), want)
+		}
+	}
+	for _, path := range []string{
+		base + "?answers[ukuran_kaos]=XL",
+		base + "?answers[hobi]=x",
+		"/admin/participants?answers[ukuran_kaos]=M",
+	} {
+		decode(t, s.do(http.MethodGet, path, nil), http.StatusBadRequest)
+	}
+
+	w := s.do(http.MethodGet, base+"/export?answers[ukuran_kaos]=M", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "participants-"+event.Slug+"-") {
+		t.Fatalf("export = %d, %q", w.Code, w.Header().Get("Content-Disposition"))
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := rows[0]
+	if got := header[len(participantExportHeader):]; !reflect.DeepEqual(got, []string{"ukuran_kaos", "vegetarian", "catatan"}) {
+		t.Errorf("question columns = %v", got)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("rows = %d, want header + 2", len(rows))
+	}
+	// Urut waktu daftar; boolean ditampilkan ya/tidak dan formula di-escape
+	want := [][]string{{"Andi", "M", "ya", "'=HYPERLINK(\"x\")"}, {"Citra", "M", "", ""}}
+	for i, row := range rows[1:] {
+		got := append([]string{row[2]}, row[len(participantExportHeader):]...)
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("row %d = %v, want %v", i+1, got, want[i])
+		}
+	}
+
+	// Export tanpa event tidak punya kolom pertanyaan
+	rows, _ = csv.NewReader(s.do(http.MethodGet, "/admin/participants/export", nil).Body).ReadAll()
+	if len(rows) != 4 || len(rows[0]) != len(participantExportHeader) {
+		t.Errorf("unscoped export: %d rows, %d columns", len(rows), len(rows[0]))
+	}
+}
@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/emails"
+	"backend/internal/mailer"
+	"backend/internal/messaging"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/storage"
+	"backend/internal/testutil"
+)
+
+// allTables adalah tabel yang dimigrasi cmd/server, supaya handler yang menyentuh tabel lain
+// (riwayat status, webhook, outbox) tidak gagal di test
+var allTables = []interface{}{
+	&models.User{}, &models.Participant{}, &models.BlacklistedToken{}, &models.RefreshToken{}, &models.ParticipantMerge{},
+	&models.EmailVerification{}, &models.ParticipantStatusHistory{}, &models.Event{}, &models.RegistrationQuestion{},
+	&models.ParticipantAnswer{}, &models.ParticipantFile{}, &models.CheckIn{}, &models.Session{}, &models.SessionAttendance{},
+	&models.CertificateTemplate{}, &models.Certificate{}, &models.CertificateBatch{}, &models.Campus{}, &models.StudyProgram{},
+	&models.ParticipantMagicLink{}, &models.ParticipantSession{}, &models.IdempotencyKey{}, &models.Webhook{},
+	&models.WebhookDelivery{}, &models.WebhookAttempt{}, &models.OutboundMessage{}, &models.MessageTemplate{},
+	&models.MessageOptOut{}, &models.OutboundEmail{}, &models.EmailTemplate{}, &models.ScheduledJob{}, &models.JobRun{},
+}
+
+// testServer menjalankan handler controller lewat gin tanpa AuthMiddleware.
+// Username petugas diambil dari header X-User (default "petugas").
+type testServer struct {
+	t      *testing.T
+	db     *gorm.DB
+	engine *gin.Engine
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db := testutil.OpenDB(t, allTables...)
+	engine := gin.New()
+	engine.Use(gin.Recovery(), func(c *gin.Context) {
+		c.Set("db", db)
+		username := c.GetHeader("X-User")
+		if username == "" {
+			username = "petugas"
+		}
+		c.Set("username", username)
+		c.Next()
+	})
+	return &testServer{t: t, db: db, engine: engine}
+}
+
+// event mengembalikan group /events/:slug di belakang middleware.EventScope
+func (s *testServer) event() *gin.RouterGroup {
+	group := s.engine.Group("/events/:slug")
+	group.Use(middleware.EventScope())
+	return group
+}
+
+// do mengirim request; body berupa string dikirim apa adanya, selain itu di-encode JSON.
+// headers berisi pasangan nama dan nilai.
+func (s *testServer) do(method, path string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	s.t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+	return w
+}
+
+// apiResponse adalah bentuk response helpers.ResponseX
+type apiResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Error   string            `json:"error"`
+	Fields  map[string]string `json:"fields"`
+	Data    json.RawMessage   `json:"data"`
+}
+
+// decode mem-parse response dan menggagalkan test jika status tidak sesuai
+func decode(t *testing.T, w *httptest.ResponseRecorder, status int) apiResponse {
+	t.Helper()
+	var res apiResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("response %d is not JSON: %s", w.Code, w.Body.String())
+	}
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d: %s", w.Code, status, w.Body.String())
+	}
+	return res
+}
+
+// into mem-parse field data ke v
+func (r apiResponse) into(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		t.Fatalf("data: %v: %s", err, r.Data)
+	}
+}
+
+// participantController membuat ParticipantController dengan mailer dan service pesan yang diberikan.
+// Outbox email tidak dijalankan; email notifikasi hanya tersimpan di tabel outbound_emails.
+func (s *testServer) participantController(mail mailer.Mailer, messages *messaging.Service) *ParticipantController {
+	outbox := emails.NewQueue(s.db, emails.Config{Enabled: true}, mail)
+	return NewParticipantController(s.db, mail, &storage.LocalStorage{Dir: s.t.TempDir()}, nil, messages, outbox)
+}
+
+// newMessages membuat service pesan dengan channel WhatsApp (tanpa worker); providers boleh nil
+func newMessages(db *gorm.DB, providers map[string]messaging.Provider) *messaging.Service {
+	return messaging.NewService(db, messaging.Config{Channel: models.ChannelWhatsApp}, providers)
+}
+
+// mailbox adalah mailer palsu yang menampung email terkirim
+type mailbox struct {
+	sent chan mailer.Message
+}
+
+func newMailbox() *mailbox { return &mailbox{sent: make(chan mailer.Message, 20)} }
+
+func (m *mailbox) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent <- msg
+	return nil
+}
+
+// next menunggu email berikutnya (beberapa email dikirim di goroutine)
+func (m *mailbox) next(t *testing.T) mailer.Message {
+	t.Helper()
+	select {
+	case msg := <-m.sent:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email sent")
+		return mailer.Message{}
+	}
+}
+
+// empty memastikan tidak ada email yang terkirim
+func (m *mailbox) empty(t *testing.T) {
+	t.Helper()
+	select {
+	case msg := <-m.sent:
+		t.Errorf("unexpected email to %s: %s", msg.To, msg.Subject)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// phoneBox adalah provider WhatsApp / SMS palsu; Err dikembalikan untuk setiap pengiriman jika diisi
+type phoneBox struct {
+	Err  error
+	sent chan messaging.Message
+}
+
+func newPhoneBox() *phoneBox { return &phoneBox{sent: make(chan messaging.Message, 20)} }
+
+func (p *phoneBox) Name() string { return "test" }
+
+func (p *phoneBox) Send(ctx context.Context, msg messaging.Message) (string, error) {
+	p.sent <- msg
+	return "wamid.test", p.Err
+}
+
+// next menunggu pesan berikutnya
+func (p *phoneBox) next(t *testing.T) messaging.Message {
+	t.Helper()
+	select {
+	case msg := <-p.sent:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message sent")
+		return messaging.Message{}
+	}
+}
+
+// participantServer mendaftarkan endpoint registrasi publik dan endpoint admin participant
+// (semua event & per event) seperti di router. Proteksi spam dimatikan kecuali honeypot.
+type participantServer struct {
+	*testServer
+	mail *mailbox
+	pc   *ParticipantController
+}
+
+func newParticipantServer(t *testing.T) *participantServer {
+	t.Setenv("ANTISPAM_MIN_FILL_TIME", "0")
+	t.Setenv("APP_BASE_URL", "https://yc.test")
+	s := &participantServer{testServer: newTestServer(t), mail: newMailbox()}
+	s.pc = s.participantController(s.mail, newMessages(s.db, nil))
+	s.engine.POST("/participants", s.pc.CreateParticipant)
+	s.engine.GET("/participants/verify-email", s.pc.VerifyEmail)
+	event := s.event()
+	event.POST("/participants", s.pc.CreateParticipant)
+	for _, group := range []*gin.RouterGroup{s.engine.Group("/admin/participants"), event.Group("/admin/participants")} {
+		group.GET("", s.pc.GetAllParticipants)
+		group.GET("/export", s.pc.ExportParticipants)
+		group.PUT("/:id", s.pc.UpdateParticipant)
+		group.POST("/:id/resend-verification", s.pc.ResendEmailVerification)
+	}
+	return s
+}
+
+// registration mengembalikan body registrasi yang valid; fields menimpa nilai bawaan
+func registration(fields map[string]interface{}) map[string]interface{} {
+	body := map[string]interface{}{
+		"name": "Budi Santoso", "place": "Jakarta", "birth_date": "2000-01-01",
+		"kampus": "UI", "jurusan": "Teknik", "angkatan": "2020", "phone": "081234567890",
+	}
+	for k, v := range fields {
+		body[k] = v
+	}
+	return body
+}
+
+// register mendaftarkan participant lewat endpoint publik (path kosong = tanpa event)
+func (s *participantServer) register(t *testing.T, slug string, status int, fields map[string]interface{}) models.Participant {
+	t.Helper()
+	path := "/participants"
+	if slug != "" {
+		path = "/events/" + slug + "/participants"
+	}
+	var p models.Participant
+	res := decode(t, s.do(http.MethodPost, path, registration(fields)), status)
+	if status == http.StatusCreated {
+		res.into(t, &p)
+	}
+	return p
+}
+
+// participantList adalah bagian response list participant admin yang dicek test
+type participantList struct {
+	Participants []models.Participant `json:"participants"`
+	Pagination   struct {
+		TotalItems int64 `json:"total_items"`
+	} `json:"pagination"`
+}
+
+// names mengembalikan nama participant di list, urut sesuai response
+func (l participantList) names() []string {
+	names := make([]string, len(l.Participants))
+	for i, p := range l.Participants {
+		names[i] = p.Name
+	}
+	return names
+}
@@ -0,0 +1,327 @@
+package controllers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/attendance"
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/webhooks"
+	"backend/internal/workflow"
+)
+
+type SessionController struct {
+	DB       *gorm.DB
+	Webhooks *webhooks.Dispatcher
+}
+
+// NewSessionController membuat instance controller baru
+func NewSessionController(db *gorm.DB, hooks *webhooks.Dispatcher) *SessionController {
+	return &SessionController{DB: db, Webhooks: hooks}
+}
+
+// applySessionForm menyalin form ke sesi, mengembalikan pesan error validasi jika ada
+func applySessionForm(form forms.SessionForm, s *models.Session) string {
+	if !form.EndsAt.After(form.StartsAt) {
+		return "ends_at harus setelah starts_at"
+	}
+	s.Title = form.Title
+	s.Speaker = form.Speaker
+	s.Room = form.Room
+	s.StartsAt = form.StartsAt
+	s.EndsAt = form.EndsAt
+	return ""
+}
+
+// findSession mengambil sesi milik event di context
+func (sc *SessionController) findSession(c *gin.Context) (*models.Session, bool) {
+	event := eventFromContext(c)
+	var s models.Session
+	if err := sc.DB.Where("id = ? AND event_id = ?", c.Param("id"), event.ID).First(&s).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Session not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &s, true
+}
+
+// GetSessions mengambil jadwal sesi event (public)
+func (sc *SessionController) GetSessions(c *gin.Context) {
+	var sessions []models.Session
+	if err := sc.DB.Where("event_id = ?", eventFromContext(c).ID).Order("starts_at asc, id asc").Find(&sessions).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Sessions retrieved successfully", gin.H{"sessions": sessions})
+}
+
+// CreateSession menambah sesi ke jadwal event (protected)
+func (sc *SessionController) CreateSession(c *gin.Context) {
+	var form forms.SessionForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	session := models.Session{EventID: eventFromContext(c).ID}
+	if msg := applySessionForm(form, &session); msg != "" {
+		helpers.ResponseBadRequest(c, msg)
+		return
+	}
+	if err := sc.DB.Create(&session).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseCreated(c, "Session created successfully", session)
+}
+
+// UpdateSession mengubah sesi (protected)
+func (sc *SessionController) UpdateSession(c *gin.Context) {
+	session, ok := sc.findSession(c)
+	if !ok {
+		return
+	}
+	var form forms.SessionForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if msg := applySessionForm(form, session); msg != "" {
+		helpers.ResponseBadRequest(c, msg)
+		return
+	}
+	if err := sc.DB.Save(session).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Session updated successfully", session)
+}
+
+// DeleteSession menghapus sesi; jika sudah ada kehadiran tercatat perlu force=true (protected)
+func (sc *SessionController) DeleteSession(c *gin.Context) {
+	session, ok := sc.findSession(c)
+	if !ok {
+		return
+	}
+
+	var recorded int64
+	if err := sc.DB.Model(&models.SessionAttendance{}).Where("session_id = ?", session.ID).Count(&recorded).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if recorded > 0 && c.Query("force") != "true" {
+		helpers.ResponseConflict(c, "Sesi sudah memiliki data kehadiran, gunakan force=true untuk menghapus beserta kehadirannya")
+		return
+	}
+
+	if err := sc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", session.ID).Delete(&models.SessionAttendance{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(session).Error
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Session deleted successfully", gin.H{"deleted_attendances": recorded})
+}
+
+// GetSessionAttendance mengambil daftar participant yang hadir di sesi (protected)
+func (sc *SessionController) GetSessionAttendance(c *gin.Context) {
+	session, ok := sc.findSession(c)
+	if !ok {
+		return
+	}
+	var records []models.SessionAttendance
+	if err := sc.DB.Where("session_id = ?", session.ID).Order("created_at asc, id asc").Find(&records).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	ids := make([]string, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.ParticipantID)
+	}
+	participants := []models.Participant{}
+	if len(ids) > 0 {
+		if err := sc.DB.Where("id IN ?", ids).Order("name asc").Find(&participants).Error; err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+	}
+
+	helpers.ResponseSuccess(c, "Session attendance retrieved successfully", gin.H{
+		"session":      session,
+		"attendances":  records,
+		"participants": participants,
+	})
+}
+
+// MarkSessionAttendance menandai banyak participant hadir / tidak hadir di sesi sekaligus (protected).
+// Participant yang ditandai hadir tapi belum check-in event otomatis ikut check-in.
+func (sc *SessionController) MarkSessionAttendance(c *gin.Context) {
+	session, ok := sc.findSession(c)
+	if !ok {
+		return
+	}
+	var form forms.SessionAttendanceForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+
+	if !*form.Present {
+		result := sc.DB.Where("session_id = ? AND participant_id IN ?", session.ID, form.ParticipantIDs).Delete(&models.SessionAttendance{})
+		if result.Error != nil {
+			helpers.ResponseInternalServerError(c, result.Error.Error())
+			return
+		}
+		helpers.ResponseSuccess(c, "Session attendance updated successfully", gin.H{"removed": result.RowsAffected})
+		return
+	}
+
+	var participants []models.Participant
+	if err := sc.DB.Where("event_id = ? AND id IN ?", session.EventID, form.ParticipantIDs).Find(&participants).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	found := make(map[string]models.Participant, len(participants))
+	for _, p := range participants {
+		found[p.ID] = p
+	}
+
+	actor := currentActor(c)
+	var marked, already int
+	skipped := map[string]string{} // participant_id -> alasan
+	for _, id := range form.ParticipantIDs {
+		p, exists := found[id]
+		switch {
+		case !exists:
+			skipped[id] = "participant tidak ditemukan di event ini"
+			continue
+		case p.Status != models.StatusApproved && p.Status != models.StatusAttended:
+			skipped[id] = "status pendaftaran " + p.Status
+			continue
+		}
+		if p.Status == models.StatusApproved {
+			checkIn, err := workflow.CheckIn(sc.DB, p.ID, "", models.CheckInMethodBulk, actor)
+			if err != nil && !errors.Is(err, workflow.ErrAlreadyCheckedIn) {
+				skipped[id] = err.Error()
+				continue
+			}
+			if err == nil {
+				p.Status = models.StatusAttended
+				sc.Webhooks.PublishCheckIn(p, checkIn)
+			}
+		}
+		if _, err := attendance.Record(sc.DB, session.ID, p.ID, models.CheckInMethodBulk, actor); err != nil {
+			if errors.Is(err, attendance.ErrAlreadyRecorded) {
+				already++
+				continue
+			}
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		marked++
+	}
+
+	helpers.ResponseSuccess(c, "Session attendance updated successfully", gin.H{
+		"marked":           marked,
+		"already_recorded": already,
+		"skipped":          skipped,
+	})
+}
+
+// RemoveSessionAttendance membatalkan kehadiran satu participant di sesi, mis. salah scan (protected)
+func (sc *SessionController) RemoveSessionAttendance(c *gin.Context) {
+	session, ok := sc.findSession(c)
+	if !ok {
+		return
+	}
+	result := sc.DB.Where("session_id = ? AND participant_id = ?", session.ID, c.Param("participantId")).Delete(&models.SessionAttendance{})
+	if result.Error != nil {
+		helpers.ResponseInternalServerError(c, result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		helpers.ResponseNotFound(c, "Attendance not found")
+		return
+	}
+	helpers.ResponseSuccess(c, "Session attendance removed successfully", nil)
+}
+
+// GetSessionReport mengambil jumlah hadir per sesi (protected)
+func (sc *SessionController) GetSessionReport(c *gin.Context) {
+	turnout, err := attendance.SessionTurnout(sc.DB, eventFromContext(c).ID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Session report retrieved successfully", gin.H{"sessions": turnout})
+}
+
+// GetParticipantAttendanceReport mengambil persentase kehadiran sesi per participant (protected).
+// Query min_percentage menyaring participant yang memenuhi syarat (mis. untuk sertifikat).
+func (sc *SessionController) GetParticipantAttendanceReport(c *gin.Context) {
+	event := eventFromContext(c)
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	minPercentage := 0.0
+	if raw := c.Query("min_percentage"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v > 100 {
+			helpers.ResponseError(c, http.StatusBadRequest, "min_percentage harus angka 0-100")
+			return
+		}
+		minPercentage = v
+	}
+
+	totalSessions, err := attendance.TotalSessions(sc.DB, event.ID)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	query := attendance.SummaryQuery(sc.DB, event.ID, totalSessions, minPercentage)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	summaries := []attendance.Summary{}
+	if err := query.Order("attended desc, participants.name asc, participants.id asc").
+		Offset((page - 1) * limit).Limit(limit).Scan(&summaries).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	attendance.Fill(summaries, totalSessions)
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	helpers.ResponseSuccess(c, "Attendance report retrieved successfully", gin.H{
+		"total_sessions": totalSessions,
+		"min_percentage": minPercentage,
+		"participants":   summaries,
+		"pagination": gin.H{
+			"current_page": page,
+			"per_page":     limit,
+			"total_items":  total,
+			"total_pages":  totalPages,
+			"has_next":     page < totalPages,
+			"has_prev":     page > 1,
+		},
+	})
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend/internal/attendance"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// newSessionServer adalah server check-in ditambah endpoint sesi seperti di router
+func newSessionServer(t *testing.T) *testServer {
+	s := newCheckInServer(t)
+	sc := NewSessionController(s.db, nil)
+	event := s.event()
+	event.GET("/sessions", sc.GetSessions)
+	event.POST("/sessions", sc.CreateSession)
+	event.GET("/sessions/report", sc.GetSessionReport)
+	event.PUT("/sessions/:id", sc.UpdateSession)
+	event.DELETE("/sessions/:id", sc.DeleteSession)
+	event.GET("/sessions/:id/attendance", sc.GetSessionAttendance)
+	event.POST("/sessions/:id/attendance", sc.MarkSessionAttendance)
+	event.DELETE("/sessions/:id/attendance/:participantId", sc.RemoveSessionAttendance)
+	event.GET("/attendance/participants", sc.GetParticipantAttendanceReport)
+	return s
+}
+
+// createSession menyimpan sesi event mulai jam ke-hour tanggal 1 Desember 2026, durasi satu jam
+func createSession(t *testing.T, s *testServer, event models.Event, title string, hour int) models.Session {
+	t.Helper()
+	start := testutil.Date("2026-12-01").Add(time.Duration(hour) * time.Hour)
+	session := models.Session{EventID: event.ID, Title: title, StartsAt: start, EndsAt: start.Add(time.Hour)}
+	if err := s.db.Create(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func TestSessionCRUD(t *testing.T) {
+	s := newSessionServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+	base := "/events/" + event.Slug + "/sessions"
+
+	body := map[string]interface{}{"title": "Penutupan", "room": "Aula", "starts_at": "2026-12-02T15:00:00+07:00", "ends_at": "2026-12-02T16:00:00+07:00"}
+	var closing models.Session
+	decode(t, s.do(http.MethodPost, base, body), http.StatusCreated).into(t, &closing)
+	decode(t, s.do(http.MethodPost, base, map[string]interface{}{"title": "Pembukaan", "starts_at": "2026-12-01T08:00:00+07:00", "ends_at": "2026-12-01T08:00:00+07:00"}), http.StatusBadRequest)
+	opening := createSession(t, s, event, "Pembukaan", 1)
+
+	var list struct {
+		Sessions []models.Session `json:"sessions"`
+	}
+	decode(t, s.do(http.MethodGet, base, nil), http.StatusOK).into(t, &list)
+	if len(list.Sessions) != 2 || list.Sessions[0].ID != opening.ID {
+		t.Errorf("sessions = %+v, want ordered by starts_at", list.Sessions)
+	}
+
+	path := fmt.Sprintf("%s/%d", base, closing.ID)
+	body["speaker"] = "Pak Andi"
+	var updated models.Session
+	decode(t, s.do(http.MethodPut, path, body), http.StatusOK).into(t, &updated)
+	if updated.Speaker != "Pak Andi" {
+		t.Errorf("speaker = %q", updated.Speaker)
+	}
+	// Sesi event lain tidak bisa diakses lewat slug event ini
+	decode(t, s.do(http.MethodPut, fmt.Sprintf("/events/%s/sessions/%d", other.Slug, closing.ID), body), http.StatusNotFound)
+
+	// Sesi yang sudah punya kehadiran hanya bisa dihapus dengan force=true
+	p := approved(t, s, event, "Budi")
+	attendance.Record(s.db, closing.ID, p.ID, models.CheckInMethodBulk, "petugas")
+	decode(t, s.do(http.MethodDelete, path, nil), http.StatusConflict)
+	var deleted struct {
+		DeletedAttendances int64 `json:"deleted_attendances"`
+	}
+	decode(t, s.do(http.MethodDelete, path+"?force=true", nil), http.StatusOK).into(t, &deleted)
+	var remaining int64
+	s.db.Model(&models.SessionAttendance{}).Count(&remaining)
+	if deleted.DeletedAttendances != 1 || remaining != 0 {
+		t.Errorf("deleted attendances = %d, remaining %d", deleted.DeletedAttendances, remaining)
+	}
+	decode(t, s.do(http.MethodDelete, fmt.Sprintf("%s/%d", base, opening.ID), nil), http.StatusOK)
+}
+
+func TestSessionCheckInRecordsAttendance(t *testing.T) {
+	s := newSessionServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+	opening := createSession(t, s, event, "Pembukaan", 1)
+	workshop := createSession(t, s, event, "Workshop", 3)
+	otherSession := createSession(t, s, other, "Pembukaan", 1)
+	p := approved(t, s, event, "Budi")
+	path := "/events/" + event.Slug + "/check-ins"
+	scan := func(session models.Session) map[string]interface{} {
+		return map[string]interface{}{"code": *p.RegistrationCode, "session_id": session.ID}
+	}
+
+	decode(t, s.do(http.MethodPost, path, scan(otherSession)), http.StatusNotFound)
+
+	// Scan pertama di pintu sesi sekaligus check-in event
+	var first struct {
+		Participant models.Participant       `json:"participant"`
+		Attendance  models.SessionAttendance `json:"attendance"`
+		CheckIn     *models.CheckIn          `json:"check_in"`
+	}
+	decode(t, s.do(http.MethodPost, path, scan(opening)), http.StatusCreated).into(t, &first)
+	if first.Participant.Status != models.StatusAttended || first.CheckIn == nil || first.Attendance.SessionID != opening.ID {
+		t.Fatalf("first scan = %+v", first)
+	}
+
+	// Participant yang sudah check-in event tetap bisa dicatat di sesi lain, tapi tidak dua kali di sesi yang sama
+	decode(t, s.do(http.MethodPost, path, scan(workshop)), http.StatusCreated)
+	res := decode(t, s.do(http.MethodPost, path, scan(opening)), http.StatusConflict)
+	want := fmt.Sprintf("Budi sudah tercatat hadir di sesi %q pada %s", "Pembukaan", first.Attendance.CreatedAt.Local().Format("02 Jan 2006 15:04"))
+	if res.Error != want {
+		t.Errorf("duplicate session scan: %q, want %q", res.Error, want)
+	}
+
+	var checkIns, records int64
+	s.db.Model(&models.CheckIn{}).Count(&checkIns)
+	s.db.Model(&models.SessionAttendance{}).Count(&records)
+	if checkIns != 1 || records != 2 {
+		t.Errorf("check-ins = %d, attendances = %d", checkIns, records)
+	}
+}
+
+func TestMarkSessionAttendance(t *testing.T) {
+	s := newSessionServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+	session := createSession(t, s, event, "Pembukaan", 1)
+	path := fmt.Sprintf("/events/%s/sessions/%d/attendance", event.Slug, session.ID)
+
+	andi := approved(t, s, event, "Andi")
+	budi := approved(t, s, event, "Budi")
+	pending := testutil.CreateParticipant(t, s.db, "Citra", func(p *models.Participant) { p.EventID = &event.ID })
+	outside := approved(t, s, other, "Dewi")
+	attendance.Record(s.db, session.ID, budi.ID, models.CheckInMethodQR, "petugas")
+
+	var result struct {
+		Marked          int               `json:"marked"`
+		AlreadyRecorded int               `json:"already_recorded"`
+		Skipped         map[string]string `json:"skipped"`
+	}
+	body := map[string]interface{}{"participant_ids": []string{andi.ID, budi.ID, pending.ID, outside.ID}, "present": true}
+	decode(t, s.do(http.MethodPost, path, body, "X-User", "andi"), http.StatusOK).into(t, &result)
+	wantSkipped := map[string]string{pending.ID: "status pendaftaran pending", outside.ID: "participant tidak ditemukan di event ini"}
+	if result.Marked != 1 || result.AlreadyRecorded != 1 || !reflect.DeepEqual(result.Skipped, wantSkipped) {
+		t.Errorf("result = %+v", result)
+	}
+
+	// Participant approved yang ditandai hadir otomatis check-in event
+	var checkIn models.CheckIn
+	if err := s.db.Where("participant_id = ?", andi.ID).First(&checkIn).Error; err != nil || checkIn.Method != models.CheckInMethodBulk || checkIn.CheckedInBy != "andi" {
+		t.Errorf("check-in = %+v, err %v", checkIn, err)
+	}
+	s.db.First(&andi, "id = ?", andi.ID)
+	if andi.Status != models.StatusAttended {
+		t.Errorf("status = %s, want attended", andi.Status)
+	}
+
+	var list struct {
+		Attendances  []models.SessionAttendance `json:"attendances"`
+		Participants []models.Participant       `json:"participants"`
+	}
+	decode(t, s.do(http.MethodGet, path, nil), http.StatusOK).into(t, &list)
+	if len(list.Attendances) != 2 || len(list.Participants) != 2 || list.Participants[0].Name != "Andi" {
+		t.Errorf("attendance list = %+v", list)
+	}
+
+	// Tandai tidak hadir dan batalkan satu kehadiran
+	var removed struct {
+		Removed int64 `json:"removed"`
+	}
+	decode(t, s.do(http.MethodPost, path, map[string]interface{}{"participant_ids": []string{andi.ID, pending.ID}, "present": false}), http.StatusOK).into(t, &removed)
+	if removed.Removed != 1 {
+		t.Errorf("removed = %d", removed.Removed)
+	}
+	decode(t, s.do(http.MethodDelete, path+"/"+budi.ID, nil), http.StatusOK)
+	decode(t, s.do(http.MethodDelete, path+"/"+budi.ID, nil), http.StatusNotFound)
+	decode(t, s.do(http.MethodPost, path, map[string]interface{}{"participant_ids": []string{andi.ID}}), http.StatusBadRequest)
+}
+
+func TestSessionAttendanceReports(t *testing.T) {
+	s := newSessionServer(t)
+	event := testutil.CreateEvent(t, s.db)
+	other := testutil.CreateEvent(t, s.db)
+	sessions := []models.Session{
+		createSession(t, s, event, "Sesi 1", 1),
+		createSession(t, s, event, "Sesi 2", 2),
+		createSession(t, s, event, "Sesi 3", 3),
+	}
+	otherSession := createSession(t, s, other, "Sesi lain", 1)
+
+	attend := func(p models.Participant, list ...models.Session) {
+		for _, session := range list {
+			if _, err := attendance.Record(s.db, session.ID, p.ID, models.CheckInMethodQR, "petugas"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	andi := approved(t, s, event, "Andi")
+	budi := approved(t, s, event, "Budi")
+	approved(t, s, event, "Citra")
+	rejected := testutil.CreateParticipant(t, s.db, "Dewi", func(p *models.Participant) {
+		p.EventID = &event.ID
+		p.Status = models.StatusRejected
+	})
+	attend(andi, sessions...)
+	attend(budi, sessions[0], sessions[1])
+	attend(rejected, sessions[0])
+	attend(budi, otherSession) // kehadiran di event lain tidak dihitung
+
+	var turnout struct {
+		Sessions []attendance.Turnout `json:"sessions"`
+	}
+	decode(t, s.do(http.MethodGet, "/events/"+event.Slug+"/sessions/report", nil), http.StatusOK).into(t, &turnout)
+	var got []string
+	for _, row := range turnout.Sessions {
+		got = append(got, fmt.Sprintf("%s %d/%d %.1f", row.Title, row.Attended, row.Expected, row.Turnout))
+	}
+	// Expected hanya participant approved / attended; kehadiran participant rejected tetap dihitung di sesi
+	want := []string{"Sesi 1 3/3 100.0", "Sesi 2 2/3 66.7", "Sesi 3 1/3 33.3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("turnout = %v, want %v", got, want)
+	}
+
+	report := func(query string) []string {
+		t.Helper()
+		var data struct {
+			TotalSessions int64                `json:"total_sessions"`
+			Participants  []attendance.Summary `json:"participants"`
+			Pagination    struct {
+				TotalItems int64 `json:"total_items"`
+			} `json:"pagination"`
+		}
+		decode(t, s.do(http.MethodGet, "/events/"+event.Slug+"/attendance/participants"+query, nil), http.StatusOK).into(t, &data)
+		rows := []string{}
+		for _, p := range data.Participants {
+			rows = append(rows, fmt.Sprintf("%s %d/%d %.1f", p.Name, p.Attended, p.TotalSessions, p.Percentage))
+		}
+		// total_items adalah jumlah seluruh participant yang lolos filter, bukan hanya halaman ini
+		total := int64(len(rows))
+		if query == "?limit=1&page=2" {
+			total = 3
+		}
+		if data.Pagination.TotalItems != total || data.TotalSessions != 3 {
+			t.Errorf("%s: total items %d, want %d; total sessions %d", query, data.Pagination.TotalItems, total, data.TotalSessions)
+		}
+		return rows
+	}
+	for query, want := range map[string][]string{
+		"":                    {"Andi 3/3 100.0", "Budi 2/3 66.7", "Citra 0/3 0.0"},
+		"?min_percentage=60":  {"Andi 3/3 100.0", "Budi 2/3 66.7"},
+		"?min_percentage=100": {"Andi 3/3 100.0"},
+		"?limit=1&page=2":     {"Budi 2/3 66.7"},
+	} {
+		if got := report(query); !reflect.DeepEqual(got, want) {
+			t.Errorf("report%s = %v, want %v", query, got, want)
+		}
+	}
+	for _, query := range []string{"?min_percentage=101", "?min_percentage=abc"} {
+		decode(t, s.do(http.MethodGet, "/events/"+event.Slug+"/attendance/participants"+query, nil), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/stats"
+)
+
+type StatsController struct {
+	DB           *gorm.DB
+	Participants *ParticipantController
+	Cache        *stats.Cache
+}
+
+// getStatsCacheTTL mendapatkan lama cache statistik dari environment (default 30 detik, 0 = nonaktif)
+func getStatsCacheTTL() time.Duration {
+	if helpers.GetEnv("STATS_CACHE_TTL", "") == "0" {
+		return 0
+	}
+	return helpers.GetEnvDuration("STATS_CACHE_TTL", 30*time.Second)
+}
+
+// NewStatsController membuat instance controller baru. Filter participant memakai
+// ParticipantController supaya sama persis dengan list participant (termasuk full-text search).
+func NewStatsController(db *gorm.DB, participants *ParticipantController) *StatsController {
+	return &StatsController{DB: db, Participants: participants, Cache: stats.NewCache(getStatsCacheTTL())}
+}
+
+// filteredQuery membuat query participant dengan filter list participant.
+// all belum difilter status (untuk jumlah per status), query sudah.
+func (sc *StatsController) filteredQuery(c *gin.Context) (all *gorm.DB, query *gorm.DB, filters gin.H, err error) {
+	all, filters, err = sc.Participants.filterParticipants(c, sc.Participants.scopedParticipants(c))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	statuses := statusFilter(c)
+	filters["status"] = statuses
+	query = all.Session(&gorm.Session{})
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	return all, query, filters, nil
+}
+
+// cached mengirim hasil dari cache jika ada, selain itu menjalankan compute lalu menyimpannya.
+// Key memakai event, path dan query string (url.Values.Encode sudah urut).
+func (sc *StatsController) cached(c *gin.Context, message string, compute func() (gin.H, error)) {
+	key := c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()
+	if event := eventFromContext(c); event != nil {
+		key = fmt.Sprintf("event:%d:%s", event.ID, key)
+	}
+	if value, createdAt, ok := sc.Cache.Get(key); ok {
+		data := copyH(value.(gin.H))
+		data["generated_at"] = createdAt
+		data["cached"] = true
+		helpers.ResponseSuccess(c, message, data)
+		return
+	}
+
+	data, err := compute()
+	if err != nil {
+		// Error input (filter / parameter) dikembalikan compute sebagai badRequestError
+		if bad, ok := err.(badRequestError); ok {
+			helpers.ResponseBadRequest(c, bad.Error())
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	createdAt := sc.Cache.Set(key, data)
+	data = copyH(data)
+	data["generated_at"] = createdAt
+	data["cached"] = false
+	helpers.ResponseSuccess(c, message, data)
+}
+
+// badRequestError menandai error karena input client
+type badRequestError struct{ error }
+
+func copyH(h gin.H) gin.H {
+	out := make(gin.H, len(h)+2)
+	for k, v := range h {
+		out[k] = v
+	}
+	return out
+}
+
+// GetSummary mengambil total participant, jumlah per status dan funnel pendaftaran (protected)
+func (sc *StatsController) GetSummary(c *gin.Context) {
+	sc.cached(c, "Statistics retrieved successfully", func() (gin.H, error) {
+		all, query, filters, err := sc.filteredQuery(c)
+		if err != nil {
+			return nil, badRequestError{err}
+		}
+		byStatus, err := countByStatus(all)
+		if err != nil {
+			return nil, err
+		}
+		var total, emailVerified int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		if err := query.Session(&gorm.Session{}).Where("email_verified_at IS NOT NULL").Count(&emailVerified).Error; err != nil {
+			return nil, err
+		}
+		return gin.H{
+			"total":          total,
+			"email_verified": emailVerified,
+			"by_status":      byStatus,
+			"funnel":         stats.Funnel(byStatus),
+			"filters":        filters,
+		}, nil
+	})
+}
+
+// GetBreakdown memecah participant per kampus, jurusan, angkatan, place atau kelompok umur (protected).
+// Umur dihitung pada tanggal mulai event untuk route per event, selain itu pada hari ini.
+func (sc *StatsController) GetBreakdown(c *gin.Context) {
+	dimension := c.Param("dimension")
+	sc.cached(c, "Statistics retrieved successfully", func() (gin.H, error) {
+		_, query, filters, err := sc.filteredQuery(c)
+		if err != nil {
+			return nil, badRequestError{err}
+		}
+
+		if dimension == "age" {
+			edges, err := stats.ParseAgeEdges(c.Query("age_brackets"))
+			if err != nil {
+				return nil, badRequestError{err}
+			}
+			ref := time.Now()
+			if event := eventFromContext(c); event != nil {
+				ref = event.StartDate
+			}
+			items, err := stats.AgeBrackets(query, ref, edges)
+			if err != nil {
+				return nil, err
+			}
+			return gin.H{
+				"dimension":    dimension,
+				"items":        items,
+				"reference_at": ref.Format("2006-01-02"),
+				"filters":      filters,
+			}, nil
+		}
+
+		limit := 20
+		if l, err := strconv.Atoi(c.Query("limit")); err == nil && l >= 0 && l <= 500 {
+			limit = l
+		}
+		items, others, err := stats.Breakdown(query, dimension, limit)
+		if err == stats.ErrUnknownDimension {
+			return nil, badRequestError{fmt.Errorf("%v: %s (kampus, jurusan, angkatan, place, age)", err, dimension)}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return gin.H{
+			"dimension": dimension,
+			"items":     items,
+			"others":    others,
+			"filters":   filters,
+		}, nil
+	})
+}
+
+// GetRegistrations mengambil jumlah registrasi per hari / minggu (protected).
+// Query: interval=day|week, from & to (YYYY-MM-DD, default 30 hari / 12 minggu terakhir).
+func (sc *StatsController) GetRegistrations(c *gin.Context) {
+	sc.cached(c, "Statistics retrieved successfully", func() (gin.H, error) {
+		_, query, filters, err := sc.filteredQuery(c)
+		if err != nil {
+			return nil, badRequestError{err}
+		}
+		interval := c.DefaultQuery("interval", stats.IntervalDay)
+		if interval != stats.IntervalDay && interval != stats.IntervalWeek {
+			return nil, badRequestError{stats.ErrInvalidInterval}
+		}
+		// Periode mengikuti zona waktu server (env TZ), sama dengan scheduler
+		loc := time.Local
+		from, to := stats.DefaultRange(time.Now(), interval, loc)
+		if raw := c.Query("from"); raw != "" {
+			if from, err = time.ParseInLocation("2006-01-02", raw, loc); err != nil {
+				return nil, badRequestError{errors.New("from harus format YYYY-MM-DD")}
+			}
+		}
+		if raw := c.Query("to"); raw != "" {
+			if to, err = time.ParseInLocation("2006-01-02", raw, loc); err != nil {
+				return nil, badRequestError{errors.New("to harus format YYYY-MM-DD")}
+			}
+		}
+
+		points, err := stats.TimeSeries(query, interval, from, to, loc)
+		if err == stats.ErrInvalidRange {
+			return nil, badRequestError{fmt.Errorf("%v (maksimal 366 hari / 104 minggu)", err)}
+		}
+		if err != nil {
+			return nil, err
+		}
+		var total int64
+		for _, p := range points {
+			total += p.Total
+		}
+		return gin.H{
+			"interval": interval,
+			"from":     stats.PeriodStart(from, interval, loc).Format("2006-01-02"),
+			"to":       stats.PeriodStart(to, interval, loc).Format("2006-01-02"),
+			"total":    total,
+			"points":   points,
+			"filters":  filters,
+		}, nil
+	})
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/ratelimit"
+)
+
+// StatusLookupController melayani cek status pendaftaran publik dengan kode registrasi + 4 digit terakhir HP
+type StatusLookupController struct {
+	DB *gorm.DB
+	// Failures menghitung percobaan gagal per kode registrasi, supaya digit HP tidak bisa ditebak
+	// dari banyak IP sekaligus
+	Failures *ratelimit.Limiter
+}
+
+// NewStatusLookupController membuat instance controller baru
+func NewStatusLookupController(db *gorm.DB) *StatusLookupController {
+	return &StatusLookupController{
+		DB:       db,
+		Failures: ratelimit.New(helpers.GetEnvInt("STATUS_LOOKUP_MAX_FAILURES", 5), helpers.GetEnvDuration("STATUS_LOOKUP_LOCKOUT", 15*time.Minute)),
+	}
+}
+
+// StatusLookupRateLimiter membuat limiter per IP untuk endpoint cek status (default 10 request / menit)
+func StatusLookupRateLimiter() *ratelimit.Limiter {
+	return ratelimit.New(helpers.GetEnvInt("STATUS_LOOKUP_RATE_LIMIT", 10), helpers.GetEnvDuration("STATUS_LOOKUP_RATE_WINDOW", time.Minute))
+}
+
+// statusLookupNotFound adalah satu-satunya pesan gagal, sama untuk kode tidak ada, digit HP salah
+// maupun input tidak valid, supaya respon tidak membocorkan kode mana yang terdaftar
+const statusLookupNotFound = "Kode registrasi atau nomor HP tidak cocok"
+
+var phoneLastDigitsPattern = regexp.MustCompile(`^[0-9]{4}$`)
+
+// LookupStatus mengembalikan status pendaftaran dan jadwal event tanpa data pribadi lain (public, rate limited)
+func (sc *StatusLookupController) LookupStatus(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	var form forms.StatusLookupForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseNotFound(c, statusLookupNotFound)
+		return
+	}
+	code := helpers.NormalizeRegistrationCode(form.Code)
+	digits := strings.TrimSpace(form.PhoneLastDigits)
+	if code == "" || !phoneLastDigitsPattern.MatchString(digits) {
+		helpers.ResponseNotFound(c, statusLookupNotFound)
+		return
+	}
+
+	// Kode dikunci setelah terlalu banyak percobaan gagal, juga untuk kode yang tidak terdaftar
+	if blocked, retryAfter := sc.Failures.Blocked(code); blocked {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		helpers.ResponseError(c, http.StatusTooManyRequests, "Terlalu banyak percobaan, coba lagi nanti")
+		return
+	}
+
+	var participant models.Participant
+	err := sc.DB.Where("registration_code = ?", code).First(&participant).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	// Bandingkan waktu-konstan; kode tidak ada tetap dibandingkan dengan nilai kosong
+	if subtle.ConstantTimeCompare([]byte(phoneLastDigits(participant.Phone)), []byte(digits)) != 1 || err != nil {
+		sc.Failures.Allow(code)
+		helpers.ResponseNotFound(c, statusLookupNotFound)
+		return
+	}
+	sc.Failures.Reset(code)
+
+	data := gin.H{
+		"registration_code": code,
+		"name":              maskName(participant.Name),
+		"status":            participant.Status,
+		"status_label":      ticketStatusLabels[participant.Status],
+	}
+	if participant.Status == models.StatusWaitlisted && participant.EventID != nil {
+		var position int64
+		if err := sc.DB.Model(&models.Participant{}).
+			Where("event_id = ? AND status = ? AND created_at <= ?", *participant.EventID, models.StatusWaitlisted, participant.CreatedAt).
+			Count(&position).Error; err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		data["waitlist_position"] = position
+	}
+	if participant.EventID != nil {
+		var event models.Event
+		if err := sc.DB.First(&event, *participant.EventID).Error; err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		var sessions []models.Session
+		if err := sc.DB.Where("event_id = ?", event.ID).Order("starts_at asc, id asc").Find(&sessions).Error; err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		schedule := make([]gin.H, len(sessions))
+		for i, s := range sessions {
+			schedule[i] = gin.H{"title": s.Title, "speaker": s.Speaker, "room": s.Room, "starts_at": s.StartsAt, "ends_at": s.EndsAt}
+		}
+		data["event"] = gin.H{
+			"name":       event.Name,
+			"slug":       event.Slug,
+			"location":   event.Location,
+			"start_date": event.StartDate.Format("2006-01-02"),
+			"end_date":   event.EndDate.Format("2006-01-02"),
+			"sessions":   schedule,
+		}
+	}
+	helpers.ResponseSuccess(c, "Registration status retrieved successfully", data)
+}
+
+// phoneLastDigits mengambil 4 digit terakhir nomor HP (kosong jika kurang dari 4 digit)
+func phoneLastDigits(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) < 4 {
+		return ""
+	}
+	return digits[len(digits)-4:]
+}
+
+// maskName menyamarkan nama untuk konfirmasi tanpa membuka data pribadi, mis. "Budi Santoso" -> "B*** S******"
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = string(r[0]) + strings.Repeat("*", len(r)-1)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func newStatusLookupServer(t *testing.T) *testServer {
+	t.Setenv("STATUS_LOOKUP_MAX_FAILURES", "3")
+	s := newTestServer(t)
+	s.engine.POST("/registrations/status", NewStatusLookupController(s.db).LookupStatus)
+	return s
+}
+
+func TestLookupStatusUniformNotFound(t *testing.T) {
+	s := newStatusLookupServer(t)
+	email := "budi@example.com"
+	p := testutil.CreateParticipant(t, s.db, "Budi", func(p *models.Participant) { p.Email = &email })
+
+	// Kode tidak ada, digit salah dan input tidak valid dijawab dengan response yang identik
+	var first string
+	for name, body := range map[string]interface{}{
+		"wrong digits":   map[string]string{"code": *p.RegistrationCode, "phone_last_digits": "0000"},
+		"unknown code":   map[string]string{"code": "ZZZZ-ZZZZ", "phone_last_digits": "7890"},
+		"malformed code": map[string]string{"code": "???", "phone_last_digits": "7890"},
+		"short digits":   map[string]string{"code": *p.RegistrationCode, "phone_last_digits": "890"},
+		"missing field":  map[string]string{"code": *p.RegistrationCode},
+		"not json":       "kode=" + *p.RegistrationCode,
+	} {
+		w := s.do(http.MethodPost, "/registrations/status", body)
+		res := decode(t, w, http.StatusNotFound)
+		if res.Error != statusLookupNotFound || w.Header().Get("Cache-Control") != "no-store" {
+			t.Errorf("%s: %q, Cache-Control %q", name, res.Error, w.Header().Get("Cache-Control"))
+		}
+		if first == "" {
+			first = w.Body.String()
+		} else if w.Body.String() != first {
+			t.Errorf("%s: body %s differs from %s", name, w.Body.String(), first)
+		}
+	}
+}
+
+func TestLookupStatusLocksCodeAfterFailures(t *testing.T) {
+	s := newStatusLookupServer(t)
+	p := testutil.CreateParticipant(t, s.db, "Budi")
+	other := testutil.CreateParticipant(t, s.db, "Citra", func(p *models.Participant) { p.Phone = "+6285711112222" })
+	lookup := func(code, digits, ip string) int {
+		return s.do(http.MethodPost, "/registrations/status", map[string]string{"code": code, "phone_last_digits": digits}, "X-Forwarded-For", ip).Code
+	}
+
+	// Percobaan dari IP berbeda tetap dihitung per kode
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		if code := lookup(*p.RegistrationCode, "0000", ip); code != http.StatusNotFound {
+			t.Fatalf("attempt %d = %d", i+1, code)
+		}
+	}
+	w := s.do(http.MethodPost, "/registrations/status", map[string]string{"code": *p.RegistrationCode, "phone_last_digits": "7890"})
+	if res := decode(t, w, http.StatusTooManyRequests); res.Error != "Terlalu banyak percobaan, coba lagi nanti" {
+		t.Errorf("locked: %q", res.Error)
+	}
+	if retry := w.Header().Get("Retry-After"); retry == "" || retry == "0" {
+		t.Errorf("Retry-After = %q", retry)
+	}
+
+	// Kode lain tidak ikut terkunci; kode yang berhasil di-reset hitungannya
+	if code := lookup(*other.RegistrationCode, "0000", "10.0.0.1"); code != http.StatusNotFound {
+		t.Errorf("other code failure = %d", code)
+	}
+	if code := lookup(*other.RegistrationCode, "2222", "10.0.0.1"); code != http.StatusOK {
+		t.Errorf("other code = %d", code)
+	}
+	for i := 0; i < 2; i++ {
+		if code := lookup(*other.RegistrationCode, "0000", "10.0.0.1"); code != http.StatusNotFound {
+			t.Errorf("failure after reset = %d", code)
+		}
+	}
+	if code := lookup(*other.RegistrationCode, "2222", "10.0.0.1"); code != http.StatusOK {
+		t.Errorf("after reset = %d, want 200", code)
+	}
+
+	// Kode yang tidak terdaftar juga terkunci supaya tidak bisa dipakai memindai kode
+	for i := 0; i < 3; i++ {
+		lookup("ZZZZ-ZZZZ", "1234", "10.0.0.1")
+	}
+	if code := lookup("zzzz-zzzz", "1234", "10.0.0.9"); code != http.StatusTooManyRequests {
+		t.Errorf("unknown code after failures = %d", code)
+	}
+}
+
+func TestLookupStatusMasksPersonalData(t *testing.T) {
+	s := newStatusLookupServer(t)
+	event := testutil.CreateEvent(t, s.db, func(e *models.Event) { e.Location = "Aula Barat" })
+	s.db.Create(&models.Session{EventID: event.ID, Title: "Pembukaan", Room: "Aula", StartsAt: testutil.Date("2026-12-01").Add(8 * time.Hour), EndsAt: testutil.Date("2026-12-01").Add(9 * time.Hour)})
+	email := "budi@example.com"
+	testutil.CreateParticipant(t, s.db, "Andi", func(p *models.Participant) {
+		p.EventID = &event.ID
+		p.Status = models.StatusWaitlisted
+	})
+	p := testutil.CreateParticipant(t, s.db, "Budi  Santoso", func(p *models.Participant) {
+		p.EventID = &event.ID
+		p.Status = models.StatusWaitlisted
+		p.Email = &email
+		p.Place = "Surabaya"
+	})
+
+	w := s.do(http.MethodPost, "/registrations/status", map[string]string{"code": strings.ToLower(*p.RegistrationCode), "phone_last_digits": " 7890 "})
+	var data struct {
+		RegistrationCode string `json:"registration_code"`
+		Name             string `json:"name"`
+		Status           string `json:"status"`
+		StatusLabel      string `json:"status_label"`
+		WaitlistPosition int64  `json:"waitlist_position"`
+		Event            struct {
+			Slug     string `json:"slug"`
+			Location string `json:"location"`
+			Sessions []struct {
+				Title string `json:"title"`
+			} `json:"sessions"`
+		} `json:"event"`
+	}
+	decode(t, w, http.StatusOK).into(t, &data)
+	if data.Name != "B*** S******" || data.RegistrationCode != *p.RegistrationCode || data.Status != models.StatusWaitlisted {
+		t.Errorf("data = %+v", data)
+	}
+	if data.WaitlistPosition != 2 || data.StatusLabel == "" {
+		t.Errorf("waitlist position = %d, label %q", data.WaitlistPosition, data.StatusLabel)
+	}
+	if data.Event.Slug != event.Slug || data.Event.Location != "Aula Barat" || len(data.Event.Sessions) != 1 {
+		t.Errorf("event = %+v", data.Event)
+	}
+	for _, private := range []string{p.ID, "Budi", "Santoso", email, "Surabaya", "+6281234567890"} {
+		if strings.Contains(w.Body.String(), private) {
+			t.Errorf("response leaks %q: %s", private, w.Body.String())
+		}
+	}
+}
+
+func TestMaskName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Budi Santoso": "B*** S******",
+		"Ézra":         "É***",
+		"  ":           "",
+		"A":            "A",
+	} {
+		if got := maskName(name); got != want {
+			t.Errorf("maskName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/tickets"
+)
+
+type TicketController struct {
+	DB *gorm.DB
+}
+
+// NewTicketController membuat instance controller baru dan mengisi kode registrasi participant lama
+func NewTicketController(db *gorm.DB) *TicketController {
+	return &TicketController{DB: db}
+}
+
+// ticketSigner membuat signer tiket; TICKET_SECRET boleh kosong (memakai JWT_SECRET).
+// Mengganti secret membuat semua QR tiket yang sudah dibagikan tidak berlaku.
+func ticketSigner() tickets.Signer {
+	if secret := helpers.GetEnv("TICKET_SECRET", ""); secret != "" {
+		return tickets.Signer{Secret: []byte(secret)}
+	}
+	return tickets.Signer{Secret: getJWTSecret()}
+}
+
+// ticketURL adalah link tiket PDF untuk pendaftar (public, dilindungi signature kode registrasi)
+func ticketURL(code string) string {
+	return getAppBaseURL() + "/api/tickets/" + code + "?" + url.Values{"signature": {ticketSigner().Sign(code)}}.Encode()
+}
+
+// ticketStatusLabels adalah teks status yang dicetak di tiket
+var ticketStatusLabels = map[string]string{
+	models.StatusPending:    "Menunggu konfirmasi",
+	models.StatusApproved:   "Terkonfirmasi",
+	models.StatusWaitlisted: "Waitlist (belum mendapat kursi)",
+	models.StatusRejected:   "Ditolak",
+	models.StatusWithdrawn:  "Mengundurkan diri",
+	models.StatusAttended:   "Sudah hadir",
+}
+
+// GetTicket mengirim tiket pendaftar berdasarkan kode registrasi + signature (public).
+// Query format: pdf (default), png atau svg.
+func (tc *TicketController) GetTicket(c *gin.Context) {
+	code := helpers.NormalizeRegistrationCode(c.Param("code"))
+	if code == "" || !ticketSigner().Verify(code, c.Query("signature")) {
+		helpers.ResponseError(c, http.StatusForbidden, tickets.ErrInvalidTicket.Error())
+		return
+	}
+
+	var participant models.Participant
+	if err := tc.DB.Where("registration_code = ?", code).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Ticket not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	tc.writeTicket(c, participant)
+}
+
+// GetParticipantTicket mengirim tiket participant untuk admin (mis. untuk dicetak ulang di meja registrasi)
+func (tc *TicketController) GetParticipantTicket(c *gin.Context) {
+	query := tc.DB.Model(&models.Participant{})
+	if event := eventFromContext(c); event != nil {
+		query = query.Where("event_id = ?", event.ID)
+	}
+	var participant models.Participant
+	if err := query.Where("id = ?", c.Param("id")).First(&participant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Participant not found")
+			return
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	tc.writeTicket(c, participant)
+}
+
+// writeTicket merender tiket sesuai query format (pdf, png, svg)
+func (tc *TicketController) writeTicket(c *gin.Context, participant models.Participant) {
+	if participant.RegistrationCode == nil {
+		helpers.ResponseNotFound(c, "Ticket not found")
+		return
+	}
+	code := *participant.RegistrationCode
+	payload := ticketSigner().Payload(code)
+
+	var (
+		data        []byte
+		contentType string
+		ext         string
+		err         error
+	)
+	switch format := c.DefaultQuery("format", "pdf"); format {
+	case "png":
+		size, convErr := strconv.Atoi(c.DefaultQuery("size", "512"))
+		if convErr != nil || size < 128 || size > 2048 {
+			helpers.ResponseBadRequest(c, "size harus antara 128 dan 2048")
+			return
+		}
+		data, err = tickets.QRPNG(payload, size)
+		contentType, ext = "image/png", ".png"
+	case "svg":
+		data, err = tickets.QRSVG(payload)
+		contentType, ext = "image/svg+xml", ".svg"
+	case "pdf":
+		ticket := tickets.Ticket{
+			Code:    code,
+			Payload: payload,
+			Name:    participant.Name,
+			Kampus:  participant.Kampus,
+			Status:  ticketStatusLabels[participant.Status],
+		}
+		if participant.EventID != nil {
+			var event models.Event
+			if err := tc.DB.First(&event, *participant.EventID).Error; err == nil {
+				ticket.EventName = event.Name
+				ticket.Location = event.Location
+				ticket.EventDate = formatEventDates(event)
+			}
+		}
+		data, err = tickets.PDF(ticket)
+		contentType, ext = "application/pdf", ".pdf"
+	default:
+		helpers.ResponseBadRequest(c, fmt.Sprintf("format %q tidak didukung, gunakan pdf, png atau svg", format))
+		return
+	}
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", "inline; filename=\"tiket-"+code+ext+"\"")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// formatEventDates menampilkan tanggal event, mis. "12 Jan 2026" atau "12 Jan 2026 - 14 Jan 2026"
+func formatEventDates(event models.Event) string {
+	start := event.StartDate.Format("02 Jan 2006")
+	if end := event.EndDate.Format("02 Jan 2006"); end != start && !event.EndDate.IsZero() {
+		return start + " - " + end
+	}
+	return start
+}
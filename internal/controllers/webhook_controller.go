@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/forms"
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/webhooks"
+)
+
+// WebhookController mengelola langganan webhook dan log pengirimannya (protected)
+type WebhookController struct {
+	DB         *gorm.DB
+	Dispatcher *webhooks.Dispatcher
+}
+
+// NewWebhookController membuat instance controller baru
+func NewWebhookController(db *gorm.DB, hooks *webhooks.Dispatcher) *WebhookController {
+	return &WebhookController{DB: db, Dispatcher: hooks}
+}
+
+// generateWebhookSecret membuat secret HMAC baru untuk webhook
+func generateWebhookSecret() (string, error) {
+	token, err := helpers.GenerateRandomToken(24)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + token, nil
+}
+
+// applyWebhookForm menyalin form ke webhook, mengembalikan pesan error validasi jika ada
+func (wc *WebhookController) applyWebhookForm(form forms.WebhookForm, hook *models.Webhook) (string, error) {
+	if u, err := url.Parse(form.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "url harus berupa URL http:// atau https://", nil
+	}
+	for _, t := range form.EventTypes {
+		if !webhooks.IsValidEventType(t) {
+			return "event_types: tipe event " + strconv.Quote(t) + " tidak dikenal", nil
+		}
+	}
+	if form.EventID != nil {
+		var count int64
+		if err := wc.DB.Model(&models.Event{}).Where("id = ?", *form.EventID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return "event_id tidak ditemukan", nil
+		}
+	}
+	hook.Name = form.Name
+	hook.URL = form.URL
+	hook.EventTypes = form.EventTypes
+	hook.EventID = form.EventID
+	return "", nil
+}
+
+// findWebhook mengambil webhook dari parameter :id
+func (wc *WebhookController) findWebhook(c *gin.Context) (*models.Webhook, bool) {
+	var hook models.Webhook
+	if err := wc.DB.First(&hook, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Webhook not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &hook, true
+}
+
+// GetWebhooks mengambil semua webhook beserta daftar tipe event yang tersedia
+func (wc *WebhookController) GetWebhooks(c *gin.Context) {
+	var hooks []models.Webhook
+	if err := wc.DB.Order("id asc").Find(&hooks).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Webhooks retrieved successfully", gin.H{
+		"webhooks":    hooks,
+		"event_types": webhooks.EventTypes,
+	})
+}
+
+// CreateWebhook mendaftarkan webhook baru. Secret hanya ditampilkan sekali di response ini.
+func (wc *WebhookController) CreateWebhook(c *gin.Context) {
+	var form forms.WebhookForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	hook := models.Webhook{Active: true, CreatedBy: currentActor(c)}
+	if msg, err := wc.applyWebhookForm(form, &hook); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if msg != "" {
+		helpers.ResponseBadRequest(c, msg)
+		return
+	}
+	if form.Active != nil {
+		hook.Active = *form.Active
+	}
+	hook.Secret = form.Secret
+	if hook.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			helpers.ResponseInternalServerError(c, err.Error())
+			return
+		}
+		hook.Secret = secret
+	}
+	if err := wc.DB.Create(&hook).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseCreated(c, "Webhook created successfully", gin.H{"webhook": hook, "secret": hook.Secret})
+}
+
+// GetWebhook mengambil satu webhook beserta jumlah delivery per status
+func (wc *WebhookController) GetWebhook(c *gin.Context) {
+	hook, ok := wc.findWebhook(c)
+	if !ok {
+		return
+	}
+	var rows []struct {
+		Status string
+		Total  int64
+	}
+	if err := wc.DB.Model(&models.WebhookDelivery{}).Select("status, COUNT(*) AS total").
+		Where("webhook_id = ?", hook.ID).Group("status").Scan(&rows).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	counts := map[string]int64{models.WebhookDeliveryPending: 0, models.WebhookDeliverySuccess: 0, models.WebhookDeliveryFailed: 0}
+	for _, row := range rows {
+		counts[row.Status] = row.Total
+	}
+	helpers.ResponseSuccess(c, "Webhook retrieved successfully", gin.H{"webhook": hook, "deliveries": counts})
+}
+
+// UpdateWebhook mengubah webhook. Mengaktifkan kembali webhook yang dinonaktifkan otomatis
+// mereset hitungan gagal; delivery pending lanjut dikirim.
+func (wc *WebhookController) UpdateWebhook(c *gin.Context) {
+	hook, ok := wc.findWebhook(c)
+	if !ok {
+		return
+	}
+	var form forms.WebhookForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		helpers.ResponseBadRequest(c, err.Error())
+		return
+	}
+	if msg, err := wc.applyWebhookForm(form, hook); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	} else if msg != "" {
+		helpers.ResponseBadRequest(c, msg)
+		return
+	}
+	if form.Secret != "" {
+		hook.Secret = form.Secret
+	}
+	if form.Active != nil && *form.Active != hook.Active {
+		hook.Active = *form.Active
+		if hook.Active {
+			hook.ConsecutiveFailures = 0
+			hook.DisabledAt = nil
+			hook.DisabledReason = ""
+		} else {
+			now := time.Now()
+			hook.DisabledAt = &now
+			hook.DisabledReason = "Dinonaktifkan oleh " + currentActor(c)
+		}
+	}
+	if err := wc.DB.Save(hook).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if hook.Active {
+		wc.Dispatcher.Kick()
+	}
+	helpers.ResponseSuccess(c, "Webhook updated successfully", hook)
+}
+
+// DeleteWebhook menghapus webhook beserta log pengirimannya
+func (wc *WebhookController) DeleteWebhook(c *gin.Context) {
+	hook, ok := wc.findWebhook(c)
+	if !ok {
+		return
+	}
+	if err := wc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("delivery_id IN (SELECT id FROM webhook_deliveries WHERE webhook_id = ?)", hook.ID).
+			Delete(&models.WebhookAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(hook).Error
+	}); err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Webhook deleted successfully", nil)
+}
+
+// RotateWebhookSecret membuat secret baru; signature dengan secret lama langsung tidak berlaku
+func (wc *WebhookController) RotateWebhookSecret(c *gin.Context) {
+	hook, ok := wc.findWebhook(c)
+	if !ok {
+		return
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	if err := wc.DB.Model(hook).UpdateColumns(map[string]interface{}{"secret": secret, "updated_at": time.Now()}).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Webhook secret rotated successfully", gin.H{"webhook": hook, "secret": secret})
+}
+
+// PingWebhook mengirim event ping untuk mengetes URL dan verifikasi signature di sisi penerima
+func (wc *WebhookController) PingWebhook(c *gin.Context) {
+	hook, ok := wc.findWebhook(c)
+	if !ok {
+		return
+	}
+	if !hook.Active {
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, "Webhook tidak aktif, aktifkan dulu sebelum mengirim ping")
+		return
+	}
+	delivery, err := wc.Dispatcher.SendPing(*hook)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Ping queued for delivery",
+		"data":    delivery,
+	})
+}
+
+// GetDeliveries mengambil log pengiriman webhook (tanpa payload), filter status & event_type
+func (wc *WebhookController) GetDeliveries(c *gin.Context) {
+	hook, ok := wc.findWebhook(c)
+	if !ok {
+		return
+	}
+	page, limit := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	query := wc.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if eventType := c.Query("event_type"); eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	var deliveries []models.WebhookDelivery
+	if err := query.Omit("payload").Order("id desc").Offset((page - 1) * limit).Limit(limit).Find(&deliveries).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	helpers.ResponseSuccess(c, "Webhook deliveries retrieved successfully", gin.H{
+		"deliveries": deliveries,
+		"pagination": gin.H{
+			"current_page": page,
+			"per_page":     limit,
+			"total_items":  total,
+			"total_pages":  totalPages,
+			"has_next":     page < totalPages,
+			"has_prev":     page > 1,
+		},
+	})
+}
+
+// findDelivery mengambil delivery dari parameter :deliveryId milik webhook
+func (wc *WebhookController) findDelivery(c *gin.Context, hook *models.Webhook) (*models.WebhookDelivery, bool) {
+	var delivery models.WebhookDelivery
+	if err := wc.DB.Where("id = ? AND webhook_id = ?", c.Param("deliveryId"), hook.ID).First(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			helpers.ResponseNotFound(c, "Webhook delivery not found")
+			return nil, false
+		}
+		helpers.ResponseInternalServerError(c, err.Error())
+		return nil, false
+	}
+	return &delivery, true
+}
+
+// GetDelivery mengambil satu delivery beserta payload dan semua percobaan pengirimannya
+func (wc *WebhookController) GetDelivery(c *gin.Context) {
+	hook, ok := wc.findWebhook(c)
+	if !ok {
+		return
+	}
+	delivery, ok := wc.findDelivery(c, hook)
+	if !ok {
+		return
+	}
+	if err := wc.DB.Where("delivery_id = ?", delivery.ID).Order("attempt asc, id asc").Find(&delivery.AttemptLog).Error; err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	helpers.ResponseSuccess(c, "Webhook delivery retrieved successfully", delivery)
+}
+
+// RedeliverDelivery mengirim ulang payload delivery sebagai delivery baru (message id sama)
+func (wc *WebhookController) RedeliverDelivery(c *gin.Context) {
+	hook, ok := wc.findWebhook(c)
+	if !ok {
+		return
+	}
+	delivery, ok := wc.findDelivery(c, hook)
+	if !ok {
+		return
+	}
+	if !hook.Active {
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, "Webhook tidak aktif, aktifkan dulu sebelum mengirim ulang")
+		return
+	}
+	redelivery, err := wc.Dispatcher.Redeliver(*delivery)
+	if err != nil {
+		helpers.ResponseInternalServerError(c, err.Error())
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Delivery queued for redelivery",
+		"data":    redelivery,
+	})
+}
@@ -3,6 +3,8 @@ package db
 import (
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -41,7 +43,7 @@ func Connect(dsnOrSqlitePath string) error {
 			instance, openErr = gorm.Open(mysql.Open(dsnOrSqlitePath), config)
 		default:
 			log.Printf("Using SQLite driver")
-			instance, openErr = gorm.Open(sqlite.Open(dsnOrSqlitePath), config)
+			instance, openErr = gorm.Open(sqlite.Open(sqliteDSN(dsnOrSqlitePath)), config)
 		}
 
 		if openErr != nil {
@@ -71,6 +73,47 @@ func Connect(dsnOrSqlitePath string) error {
 	return err
 }
 
+// sqliteDSN menambahkan opsi koneksi SQLite untuk penulisan bersamaan: tunggu lock (bukan langsung
+// SQLITE_BUSY) dan BEGIN IMMEDIATE supaya transaksi langsung memegang write lock.
+func sqliteDSN(path string) string {
+	options := []string{"_busy_timeout=5000", "_txlock=immediate"}
+	for _, opt := range options {
+		key := opt[:strings.Index(opt, "=")+1]
+		if strings.Contains(path, key) {
+			continue
+		}
+		if strings.Contains(path, "?") {
+			path += "&" + opt
+		} else {
+			path += "?" + opt
+		}
+	}
+	return path
+}
+
+// URLFromEnv builds the database DSN from environment variables.
+// MySQL is used when DB_HOST, DB_PORT, DB_NAME and DB_USER are all set; otherwise DATABASE_URL
+// (Postgres or MySQL) and finally DB_PATH (SQLite, default ./data/app.db).
+func URLFromEnv() string {
+	dbHost := os.Getenv("DB_HOST")
+	dbPort := os.Getenv("DB_PORT")
+	dbName := os.Getenv("DB_NAME")
+	dbUser := os.Getenv("DB_USER")
+	dbPassword := os.Getenv("DB_PASSWORD")
+
+	if dbHost != "" && dbPort != "" && dbName != "" && dbUser != "" {
+		// Format DSN MySQL: username:password@tcp(host:port)/dbname?parseTime=true
+		return dbUser + ":" + dbPassword + "@tcp(" + dbHost + ":" + dbPort + ")/" + dbName + "?parseTime=true"
+	}
+	if databaseURL := os.Getenv("DATABASE_URL"); databaseURL != "" {
+		return databaseURL
+	}
+	if dbPath := os.Getenv("DB_PATH"); dbPath != "" {
+		return dbPath
+	}
+	return filepath.Join(".", "data", "app.db")
+}
+
 // Instance returns the initialized DB instance
 func Instance() *gorm.DB {
 	return instance
@@ -0,0 +1,505 @@
+package duplicates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// Policy menentukan perlakuan terhadap pendaftaran yang terdeteksi duplikat
+type Policy string
+
+const (
+	PolicyReject Policy = "reject" // tolak pendaftaran
+	PolicyFlag   Policy = "flag"   // simpan, tapi tandai possible_duplicate_of
+	PolicyAllow  Policy = "allow"  // simpan tanpa pengecekan
+)
+
+// ParsePolicy membaca policy dari string, default ke PolicyFlag
+func ParsePolicy(s string) Policy {
+	switch Policy(strings.ToLower(strings.TrimSpace(s))) {
+	case PolicyReject:
+		return PolicyReject
+	case PolicyAllow:
+		return PolicyAllow
+	}
+	return PolicyFlag
+}
+
+// Alasan kecocokan duplikat
+const (
+	ReasonPhone         = "phone"
+	ReasonNameBirthDate = "name_birth_date"
+)
+
+// NameSimilarityThreshold adalah batas minimal kemiripan nama (0..1) untuk dianggap sama
+const NameSimilarityThreshold = 0.85
+
+// MergeableFields adalah field yang boleh diambil dari data duplikat saat merge
+var MergeableFields = []string{"name", "place", "birth_date", "kampus", "jurusan", "angkatan", "phone", "email"}
+
+var (
+	// ErrSameParticipant dikembalikan jika primary dan duplicate adalah data yang sama
+	ErrSameParticipant = errors.New("primary and duplicate must be different participants")
+	// ErrDifferentEvents dikembalikan jika primary dan duplicate terdaftar di event berbeda
+	ErrDifferentEvents = errors.New("primary and duplicate must belong to the same event")
+)
+
+// Match adalah satu participant yang cocok sebagai kemungkinan duplikat
+type Match struct {
+	Participant models.Participant `json:"participant"`
+	Reasons     []string           `json:"reasons"`
+}
+
+// Group adalah sekumpulan participant yang kemungkinan orang yang sama
+type Group struct {
+	Participants []models.Participant `json:"participants"`
+	Reasons      []string             `json:"reasons"`
+}
+
+// Detector mendeteksi dan menggabungkan data participant duplikat
+type Detector struct {
+	DB *gorm.DB
+}
+
+// NewDetector membuat detector baru
+func NewDetector(db *gorm.DB) *Detector {
+	return &Detector{DB: db}
+}
+
+// BackfillPhoneKeys mengisi phone_key untuk data lama yang dibuat sebelum kolom ini ada
+func (d *Detector) BackfillPhoneKeys() (int, error) {
+	var participants []models.Participant
+	if err := d.DB.Select("id", "phone").Where("phone_key = '' OR phone_key IS NULL").Find(&participants).Error; err != nil {
+		return 0, err
+	}
+	for _, p := range participants {
+		if err := d.DB.Model(&models.Participant{}).Where("id = ?", p.ID).
+			UpdateColumn("phone_key", helpers.PhoneKey(p.Phone)).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(participants), nil
+}
+
+// FindMatches mencari participant yang sudah ada dan kemungkinan sama dengan candidate
+func (d *Detector) FindMatches(candidate models.Participant) ([]Match, error) {
+	phoneKey := helpers.PhoneKey(candidate.Phone)
+
+	// Orang yang sama boleh ikut event yang berbeda, jadi pengecekan hanya di event yang sama
+	query := d.DB.Model(&models.Participant{})
+	if candidate.EventID != nil {
+		query = query.Where("event_id = ?", *candidate.EventID)
+	} else {
+		query = query.Where("event_id IS NULL")
+	}
+	if candidate.ID != "" {
+		query = query.Where("id <> ?", candidate.ID)
+	}
+	var existing []models.Participant
+	if err := query.Where("phone_key = ? OR birth_date = ?", phoneKey, candidate.BirthDate).
+		Order("created_at asc").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	for _, p := range existing {
+		if reasons := matchReasons(candidate, p); len(reasons) > 0 {
+			matches = append(matches, Match{Participant: p, Reasons: reasons})
+		}
+	}
+	return matches, nil
+}
+
+// Report mengelompokkan participant yang kemungkinan duplikat di dalam event yang sama.
+// Jika eventID nil, semua event diperiksa (tetap dikelompokkan per event).
+func (d *Detector) Report(eventID *uint) ([]Group, error) {
+	query := d.DB.Order("created_at asc")
+	if eventID != nil {
+		query = query.Where("event_id = ?", *eventID)
+	}
+	var participants []models.Participant
+	if err := query.Find(&participants).Error; err != nil {
+		return nil, err
+	}
+
+	// Union-find: participant yang cocok (langsung atau lewat participant lain) masuk satu grup
+	parent := make([]int, len(participants))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := make(map[int]map[string]bool)
+	link := func(a, b int, reason string) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[rb] = ra
+			merged := reasons[ra]
+			if merged == nil {
+				merged = make(map[string]bool)
+			}
+			for r := range reasons[rb] {
+				merged[r] = true
+			}
+			delete(reasons, rb)
+			reasons[ra] = merged
+		}
+		if reasons[ra] == nil {
+			reasons[ra] = make(map[string]bool)
+		}
+		reasons[ra][reason] = true
+	}
+
+	// Bandingkan hanya di dalam bucket (phone key / tanggal lahir) supaya tidak O(n^2) untuk seluruh tabel
+	byPhone := make(map[string][]int)
+	byBirthDate := make(map[string][]int)
+	for i, p := range participants {
+		scope := "-"
+		if p.EventID != nil {
+			scope = fmt.Sprint(*p.EventID)
+		}
+		if key := helpers.PhoneKey(p.Phone); len(key) >= 8 {
+			byPhone[scope+"|"+key] = append(byPhone[scope+"|"+key], i)
+		}
+		dateKey := scope + "|" + p.BirthDate.Format("2006-01-02")
+		byBirthDate[dateKey] = append(byBirthDate[dateKey], i)
+	}
+	for _, bucket := range byPhone {
+		for _, i := range bucket[1:] {
+			link(bucket[0], i, ReasonPhone)
+		}
+	}
+	for _, bucket := range byBirthDate {
+		for x := 0; x < len(bucket); x++ {
+			for y := x + 1; y < len(bucket); y++ {
+				if NameSimilarity(participants[bucket[x]].Name, participants[bucket[y]].Name) >= NameSimilarityThreshold {
+					link(bucket[x], bucket[y], ReasonNameBirthDate)
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]models.Participant)
+	for i, p := range participants {
+		root := find(i)
+		members[root] = append(members[root], p)
+	}
+	groups := make([]Group, 0)
+	for root, list := range members {
+		if len(list) < 2 {
+			continue
+		}
+		groups = append(groups, Group{Participants: list, Reasons: sortedKeys(reasons[root])})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Participants) != len(groups[j].Participants) {
+			return len(groups[i].Participants) > len(groups[j].Participants)
+		}
+		return groups[i].Participants[0].CreatedAt.Before(groups[j].Participants[0].CreatedAt)
+	})
+	return groups, nil
+}
+
+// Merge menggabungkan duplicateID ke primaryID. Field pada fields diambil dari data duplikat,
+// data duplikat dihapus dan snapshot-nya disimpan di participant_merges sebagai audit.
+func (d *Detector) Merge(primaryID, duplicateID string, fields []string, mergedBy string) (*models.Participant, *models.ParticipantMerge, error) {
+	if primaryID == duplicateID {
+		return nil, nil, ErrSameParticipant
+	}
+	for _, f := range fields {
+		if !isMergeableField(f) {
+			return nil, nil, fmt.Errorf("field %q cannot be merged", f)
+		}
+	}
+
+	var primary models.Participant
+	var audit models.ParticipantMerge
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var duplicate models.Participant
+		if err := tx.Where("id = ?", primaryID).First(&primary).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", duplicateID).First(&duplicate).Error; err != nil {
+			return err
+		}
+		if (primary.EventID == nil) != (duplicate.EventID == nil) ||
+			(primary.EventID != nil && *primary.EventID != *duplicate.EventID) {
+			return ErrDifferentEvents
+		}
+
+		snapshot, err := json.Marshal(duplicate)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range fields {
+			copyField(&primary, duplicate, f)
+		}
+		// Tanda duplikat di primary tidak relevan lagi jika menunjuk ke data yang digabung
+		if primary.PossibleDuplicateOf != nil && *primary.PossibleDuplicateOf == duplicate.ID {
+			primary.PossibleDuplicateOf = nil
+		}
+		if err := tx.Save(&primary).Error; err != nil {
+			return err
+		}
+
+		// Participant lain yang ditandai duplikat dari data yang dihapus dipindahkan ke primary
+		if err := tx.Model(&models.Participant{}).Where("possible_duplicate_of = ? AND id <> ?", duplicate.ID, primary.ID).
+			UpdateColumn("possible_duplicate_of", primary.ID).Error; err != nil {
+			return err
+		}
+
+		audit = models.ParticipantMerge{
+			PrimaryID: primary.ID,
+			MergedID:  duplicate.ID,
+			Fields:    strings.Join(fields, ","),
+			Snapshot:  string(snapshot),
+			MergedBy:  mergedBy,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&audit).Error; err != nil {
+			return err
+		}
+
+		// Jawaban pertanyaan registrasi yang belum dijawab primary diambil dari duplikat, sisanya ikut dihapus
+		var answered []uint
+		if err := tx.Model(&models.ParticipantAnswer{}).Where("participant_id = ?", primary.ID).Pluck("question_id", &answered).Error; err != nil {
+			return err
+		}
+		move := tx.Model(&models.ParticipantAnswer{}).Where("participant_id = ?", duplicate.ID)
+		if len(answered) > 0 {
+			move = move.Where("question_id NOT IN ?", answered)
+		}
+		if err := move.UpdateColumn("participant_id", primary.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", duplicate.ID).Delete(&models.ParticipantAnswer{}).Error; err != nil {
+			return err
+		}
+		// File upload duplikat ikut dipindahkan ke primary
+		if err := tx.Model(&models.ParticipantFile{}).Where("participant_id = ?", duplicate.ID).
+			UpdateColumn("participant_id", primary.ID).Error; err != nil {
+			return err
+		}
+		// Riwayat check-in ikut dipindahkan; yang masih aktif dibatalkan jika primary belum hadir
+		// supaya jumlah kehadiran tetap sesuai status
+		if primary.Status != models.StatusAttended {
+			if err := tx.Model(&models.CheckIn{}).Where("participant_id = ? AND undone_at IS NULL", duplicate.ID).
+				UpdateColumns(map[string]interface{}{"undone_at": time.Now(), "undone_by": mergedBy, "undo_reason": "Digabung ke " + primary.ID}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.CheckIn{}).Where("participant_id = ?", duplicate.ID).
+			UpdateColumn("participant_id", primary.ID).Error; err != nil {
+			return err
+		}
+		// Kehadiran sesi digabung: sesi yang belum tercatat di primary dipindahkan, sisanya dihapus
+		var attendedSessions []uint
+		if err := tx.Model(&models.SessionAttendance{}).Where("participant_id = ?", primary.ID).Pluck("session_id", &attendedSessions).Error; err != nil {
+			return err
+		}
+		moveAttendance := tx.Model(&models.SessionAttendance{}).Where("participant_id = ?", duplicate.ID)
+		if len(attendedSessions) > 0 {
+			moveAttendance = moveAttendance.Where("session_id NOT IN ?", attendedSessions)
+		}
+		if err := moveAttendance.UpdateColumn("participant_id", primary.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", duplicate.ID).Delete(&models.SessionAttendance{}).Error; err != nil {
+			return err
+		}
+		// Sertifikat dari template yang belum dimiliki primary dipindahkan (nomor seri tetap berlaku),
+		// sisanya dihapus
+		var certifiedTemplates []uint
+		if err := tx.Model(&models.Certificate{}).Where("participant_id = ?", primary.ID).Pluck("template_id", &certifiedTemplates).Error; err != nil {
+			return err
+		}
+		moveCertificates := tx.Model(&models.Certificate{}).Where("participant_id = ?", duplicate.ID)
+		if len(certifiedTemplates) > 0 {
+			moveCertificates = moveCertificates.Where("template_id NOT IN ?", certifiedTemplates)
+		}
+		if err := moveCertificates.UpdateColumn("participant_id", primary.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", duplicate.ID).Delete(&models.Certificate{}).Error; err != nil {
+			return err
+		}
+		// Sesi & magic link portal tidak berlaku lagi
+		if err := tx.Where("participant_id = ?", duplicate.ID).Delete(&models.ParticipantSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("participant_id = ?", duplicate.ID).Delete(&models.ParticipantMagicLink{}).Error; err != nil {
+			return err
+		}
+		// Riwayat status, token verifikasi email (hanya berlaku jika email primary sama) serta log
+		// WhatsApp / SMS dan email ikut dipindahkan ke primary
+		for _, model := range []interface{}{&models.ParticipantStatusHistory{}, &models.EmailVerification{}, &models.OutboundMessage{}, &models.OutboundEmail{}} {
+			if err := tx.Model(model).Where("participant_id = ?", duplicate.ID).
+				UpdateColumn("participant_id", primary.ID).Error; err != nil {
+				return err
+			}
+		}
+		// Pendaftar yang berhenti berlangganan WhatsApp / SMS di nomor duplikat juga tidak dikirimi
+		// lewat nomor primary
+		if err := carryOptOut(tx, duplicate, primary, mergedBy); err != nil {
+			return err
+		}
+		return tx.Delete(&duplicate).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &primary, &audit, nil
+}
+
+// carryOptOut menyalin opt-out WhatsApp / SMS nomor duplikat ke nomor primary jika berbeda.
+// Opt-out nomor duplikat sendiri tetap ada karena berlaku per nomor HP.
+func carryOptOut(tx *gorm.DB, duplicate, primary models.Participant, mergedBy string) error {
+	dupKey, primaryKey := helpers.PhoneKey(duplicate.Phone), helpers.PhoneKey(primary.Phone)
+	if dupKey == "" || primaryKey == "" || dupKey == primaryKey {
+		return nil
+	}
+	var optOut models.MessageOptOut
+	err := tx.Where("phone_key = ?", dupKey).First(&optOut).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	carried := models.MessageOptOut{
+		PhoneKey:  primaryKey,
+		Phone:     primary.Phone,
+		Source:    optOut.Source,
+		Reason:    "Digabung dari " + duplicate.ID + ": " + optOut.Reason,
+		CreatedBy: mergedBy,
+		CreatedAt: time.Now(),
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&carried).Error
+}
+
+// matchReasons mengembalikan alasan a dan b dianggap duplikat (kosong jika tidak cocok)
+func matchReasons(a, b models.Participant) []string {
+	var reasons []string
+	if key := helpers.PhoneKey(a.Phone); len(key) >= 8 && key == helpers.PhoneKey(b.Phone) {
+		reasons = append(reasons, ReasonPhone)
+	}
+	if a.BirthDate.Format("2006-01-02") == b.BirthDate.Format("2006-01-02") &&
+		NameSimilarity(a.Name, b.Name) >= NameSimilarityThreshold {
+		reasons = append(reasons, ReasonNameBirthDate)
+	}
+	return reasons
+}
+
+// NormalizeName membuat nama lowercase, tanpa tanda baca dan spasi ganda
+func NormalizeName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// NameSimilarity menghitung kemiripan dua nama (0..1) berdasarkan jarak Levenshtein.
+// Urutan kata diabaikan sehingga "Santoso Budi" sama dengan "Budi Santoso".
+func NameSimilarity(a, b string) float64 {
+	a, b = sortedWords(NormalizeName(a)), sortedWords(NormalizeName(b))
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func sortedWords(s string) string {
+	words := strings.Fields(s)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func isMergeableField(field string) bool {
+	for _, f := range MergeableFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// copyField menyalin satu field dari src ke dst
+func copyField(dst *models.Participant, src models.Participant, field string) {
+	switch field {
+	case "name":
+		dst.Name = src.Name
+	case "place":
+		dst.Place = src.Place
+	case "birth_date":
+		dst.BirthDate = src.BirthDate
+	case "kampus":
+		dst.Kampus = src.Kampus
+		dst.CampusID = src.CampusID
+	case "jurusan":
+		dst.Jurusan = src.Jurusan
+		dst.StudyProgramID = src.StudyProgramID
+	case "angkatan":
+		dst.Angkatan = src.Angkatan
+	case "phone":
+		dst.Phone = src.Phone
+		dst.PhoneInput = src.PhoneInput
+	case "email":
+		// Status verifikasi ikut pindah bersama email-nya
+		dst.Email = src.Email
+		dst.EmailVerifiedAt = src.EmailVerifiedAt
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
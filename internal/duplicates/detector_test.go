@@ -0,0 +1,251 @@
+package duplicates
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	return testutil.OpenDB(t, &models.Participant{}, &models.ParticipantMerge{}, &models.ParticipantAnswer{},
+		&models.ParticipantFile{}, &models.CheckIn{}, &models.SessionAttendance{}, &models.Certificate{},
+		&models.ParticipantSession{}, &models.ParticipantMagicLink{}, &models.ParticipantStatusHistory{},
+		&models.EmailVerification{}, &models.OutboundMessage{}, &models.OutboundEmail{}, &models.MessageOptOut{})
+}
+
+func createParticipant(t *testing.T, db *gorm.DB, name, birthDate, phone string) models.Participant {
+	t.Helper()
+	return testutil.CreateParticipant(t, db, name, func(p *models.Participant) {
+		p.BirthDate, p.Phone = testutil.Date(birthDate), phone
+	})
+}
+
+func countFor(t *testing.T, db *gorm.DB, model interface{}, participantID string) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(model).Where("participant_id = ?", participantID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestMergeMovesRelatedRows(t *testing.T) {
+	db := openTestDB(t)
+	primary := createParticipant(t, db, "Budi Santoso", "2000-01-01", "+6281111111111")
+	duplicate := createParticipant(t, db, "Budi Santosa", "2000-01-01", "+6282222222222")
+	now := time.Now()
+
+	dupID := duplicate.ID
+	rows := []interface{}{
+		&models.ParticipantStatusHistory{ParticipantID: dupID, FromStatus: models.StatusPending, ToStatus: models.StatusApproved, Actor: "admin", CreatedAt: now},
+		&models.EmailVerification{ParticipantID: dupID, Email: "budi@example.com", TokenHash: "hash", ExpiresAt: now.Add(time.Hour), CreatedAt: now},
+		&models.OutboundMessage{ParticipantID: dupID, Channel: models.ChannelWhatsApp, To: duplicate.Phone, TemplateKey: "registration_received", Body: "Halo", Status: models.MessageSent},
+		&models.OutboundEmail{ParticipantID: &dupID, TemplateKey: "registration_received", Language: "id", To: "budi@example.com", Subject: "Halo", TextBody: "Halo", Status: models.EmailSent, MessageID: "<1@example.com>"},
+		&models.MessageOptOut{PhoneKey: "82222222222", Phone: duplicate.Phone, Source: "inbound", Reason: "Membalas STOP", CreatedAt: now},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("create %T: %v", row, err)
+		}
+	}
+
+	merged, audit, err := NewDetector(db).Merge(primary.ID, duplicate.ID, []string{"name"}, "admin")
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if merged.Name != "Budi Santosa" || audit.MergedID != duplicate.ID {
+		t.Fatalf("merged = %q, audit merged %q", merged.Name, audit.MergedID)
+	}
+
+	for _, model := range []interface{}{&models.ParticipantStatusHistory{}, &models.EmailVerification{}, &models.OutboundMessage{}, &models.OutboundEmail{}} {
+		if n := countFor(t, db, model, duplicate.ID); n != 0 {
+			t.Errorf("%T: %d rows left on merged participant", model, n)
+		}
+		if n := countFor(t, db, model, primary.ID); n != 1 {
+			t.Errorf("%T: %d rows on primary, want 1", model, n)
+		}
+	}
+
+	// Opt-out berlaku untuk nomor duplikat dan sekarang juga nomor primary
+	var optOuts []models.MessageOptOut
+	db.Order("phone_key").Find(&optOuts)
+	if len(optOuts) != 2 || optOuts[0].PhoneKey != "81111111111" || optOuts[1].PhoneKey != "82222222222" {
+		t.Fatalf("opt-outs = %+v, want both numbers", optOuts)
+	}
+	if err := db.First(&models.Participant{}, "id = ?", duplicate.ID).Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("duplicate still exists: %v", err)
+	}
+}
+
+func TestMergeRejectsDifferentEvents(t *testing.T) {
+	db := openTestDB(t)
+	a := createParticipant(t, db, "Budi", "2000-01-01", "+6281111111111")
+	b := createParticipant(t, db, "Budi", "2000-01-01", "+6281111111111")
+	other := uint(2)
+	db.Model(&b).UpdateColumn("event_id", other)
+
+	if _, _, err := NewDetector(db).Merge(a.ID, b.ID, nil, "admin"); err != ErrDifferentEvents {
+		t.Fatalf("Merge = %v, want ErrDifferentEvents", err)
+	}
+	if _, _, err := NewDetector(db).Merge(a.ID, a.ID, nil, "admin"); err != ErrSameParticipant {
+		t.Fatalf("Merge same = %v, want ErrSameParticipant", err)
+	}
+	if _, _, err := NewDetector(db).Merge(a.ID, b.ID, []string{"status"}, "admin"); err == nil {
+		t.Fatal("Merge with non-mergeable field succeeded")
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		"  Budi   Santoso ":    "budi santoso",
+		"BUDI-SANTOSO, S.Kom.": "budi santoso s kom",
+		"Siti 'Aminah'":        "siti aminah",
+		"Ñoño Ümit":            "ñoño ümit",
+		"...":                  "",
+	}
+	for in, want := range tests {
+		if got := NormalizeName(in); got != want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Budi Santoso", "budi  santoso!", 1},
+		{"Santoso Budi", "Budi Santoso", 1}, // urutan kata diabaikan
+		{"Budi Santoso", "Budi Santosa", 1 - 1.0/12},
+		{"Budi", "Andi", 0.5},
+		{"Budi", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		if got := NameSimilarity(tt.a, tt.b); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("NameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if NameSimilarity(tt.a, tt.b) != NameSimilarity(tt.b, tt.a) {
+			t.Errorf("NameSimilarity(%q, %q) is not symmetric", tt.a, tt.b)
+		}
+	}
+	if NameSimilarity("Budi Santoso", "Budi Santosa") < NameSimilarityThreshold {
+		t.Error("one typo should stay above the threshold")
+	}
+	if NameSimilarity("Siti", "Siti Aminah") >= NameSimilarityThreshold {
+		t.Error("missing last name should stay below the threshold")
+	}
+	if got := levenshtein([]rune("kitten"), []rune("sitting")); got != 3 {
+		t.Errorf("levenshtein(kitten, sitting) = %d, want 3", got)
+	}
+}
+
+func TestMatchReasons(t *testing.T) {
+	born := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := models.Participant{Name: "Budi Santoso", BirthDate: born, Phone: "+6281234567890"}
+	tests := []struct {
+		name string
+		b    models.Participant
+		want []string
+	}{
+		{"same phone other format", models.Participant{Name: "Andi", BirthDate: born.AddDate(1, 0, 0), Phone: "0812-3456-7890"}, []string{ReasonPhone}},
+		{"similar name same birth date", models.Participant{Name: "Santoso Budi", BirthDate: born, Phone: "+6285700000000"}, []string{ReasonNameBirthDate}},
+		{"both", models.Participant{Name: "Budi Santosa", BirthDate: born, Phone: "6281234567890"}, []string{ReasonPhone, ReasonNameBirthDate}},
+		{"similar name other birth date", models.Participant{Name: "Budi Santoso", BirthDate: born.AddDate(0, 0, 1), Phone: "+6285700000000"}, nil},
+	}
+	for _, tt := range tests {
+		got := matchReasons(a, tt.b)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: matchReasons = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: matchReasons = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	}
+
+	// Kunci nomor terlalu pendek tidak dianggap cocok
+	short := models.Participant{Name: "X", BirthDate: born, Phone: "0812"}
+	if got := matchReasons(short, models.Participant{Name: "Y", BirthDate: born.AddDate(1, 0, 0), Phone: "+62812"}); len(got) != 0 {
+		t.Errorf("short phone keys matched: %v", got)
+	}
+}
+
+func TestReportGroupsTransitively(t *testing.T) {
+	db := openTestDB(t)
+	inEvent := func(p models.Participant, eventID uint) models.Participant {
+		if err := db.Model(&p).UpdateColumn("event_id", eventID).Error; err != nil {
+			t.Fatal(err)
+		}
+		p.EventID = &eventID
+		return p
+	}
+
+	// Budi ~ Santoso Budi lewat nama + tanggal lahir, Santoso Budi ~ Andi lewat nomor HP: satu grup
+	budi := inEvent(createParticipant(t, db, "Budi Santoso", "2000-01-01", "+6281111111111"), 1)
+	budiSwapped := inEvent(createParticipant(t, db, "Santoso Budi", "2000-01-01", "+6282222222222"), 1)
+	andi := inEvent(createParticipant(t, db, "Andi", "1999-05-05", "0822-2222-2222"), 1)
+	// Nama berbeda jauh di tanggal lahir sama: bukan duplikat
+	inEvent(createParticipant(t, db, "Siti", "2001-01-01", "+6283333333333"), 1)
+	inEvent(createParticipant(t, db, "Siti Aminah", "2001-01-01", "+6284444444444"), 1)
+	// Data yang sama di event lain hanya dikelompokkan di event tersebut
+	rina := inEvent(createParticipant(t, db, "Rina", "2002-02-02", "+6285555555555"), 1)
+	rinaOther := inEvent(createParticipant(t, db, "Rina", "2002-02-02", "+6285555555555"), 2)
+	rinaOther2 := inEvent(createParticipant(t, db, "rina.", "2002-02-02", "+6286666666666"), 2)
+
+	groups, err := NewDetector(db).Report(nil)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("Report = %d groups, want 2: %+v", len(groups), groups)
+	}
+	assertGroup(t, groups[0], []string{budi.ID, budiSwapped.ID, andi.ID}, []string{ReasonNameBirthDate, ReasonPhone})
+	assertGroup(t, groups[1], []string{rinaOther.ID, rinaOther2.ID}, []string{ReasonNameBirthDate})
+
+	event := uint(1)
+	groups, err = NewDetector(db).Report(&event)
+	if err != nil {
+		t.Fatalf("Report(event 1): %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("Report(event 1) = %d groups, want 1", len(groups))
+	}
+	for _, p := range groups[0].Participants {
+		if p.ID == rina.ID {
+			t.Error("Rina from event 1 grouped with participants from event 2")
+		}
+	}
+}
+
+func assertGroup(t *testing.T, g Group, ids []string, reasons []string) {
+	t.Helper()
+	got := make(map[string]bool)
+	for _, p := range g.Participants {
+		got[p.ID] = true
+	}
+	if len(got) != len(ids) {
+		t.Errorf("group has %d participants, want %d", len(got), len(ids))
+	}
+	for _, id := range ids {
+		if !got[id] {
+			t.Errorf("group is missing participant %s", id)
+		}
+	}
+	if len(g.Reasons) != len(reasons) {
+		t.Errorf("group reasons = %v, want %v", g.Reasons, reasons)
+		return
+	}
+	for i := range reasons {
+		if g.Reasons[i] != reasons[i] {
+			t.Errorf("group reasons = %v, want %v", g.Reasons, reasons)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package eligibility
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// Nama aturan di EligibilityIssue.Rule
+const (
+	RuleMinAge         = "min_age"
+	RuleMaxAge         = "max_age"
+	RuleAngkatan       = "angkatan"
+	RuleCampus         = "campus"
+	RuleRequiredAnswer = "required_answer"
+	RuleRequiredFile   = "required_file"
+)
+
+// documentKinds adalah dokumen bawaan (bukan pertanyaan registrasi) yang bisa diwajibkan
+var documentKinds = map[string]string{"ktm": "KTM", "photo": "Pas foto"}
+
+var answerKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// messages adalah template pesan per bahasa
+var messages = map[string]map[string]string{
+	helpers.LanguageID: {
+		RuleMinAge:         "Usia minimal %d tahun pada tanggal event (%s)",
+		RuleMaxAge:         "Usia maksimal %d tahun pada tanggal event (%s)",
+		RuleAngkatan:       "Angkatan %s tidak termasuk angkatan yang boleh mendaftar (%s)",
+		RuleCampus:         "Pendaftaran hanya untuk mahasiswa %s",
+		RuleRequiredAnswer: "Pertanyaan \"%s\" wajib dijawab",
+		RuleRequiredFile:   "%s wajib diupload",
+	},
+	helpers.LanguageEN: {
+		RuleMinAge:         "Minimum age is %d on the event date (%s)",
+		RuleMaxAge:         "Maximum age is %d on the event date (%s)",
+		RuleAngkatan:       "Class year %s is not eligible (allowed: %s)",
+		RuleCampus:         "Registration is limited to students of %s",
+		RuleRequiredAnswer: "Question \"%s\" must be answered",
+		RuleRequiredFile:   "%s must be uploaded",
+	},
+}
+
+// rejectionMessages adalah pesan utama saat pendaftaran ditolak karena syarat
+var rejectionMessages = map[string]string{
+	helpers.LanguageID: "Pendaftar tidak memenuhi syarat event",
+	helpers.LanguageEN: "Registrant does not meet the event requirements",
+}
+
+// RejectionMessage mengembalikan pesan penolakan dalam bahasa lang
+func RejectionMessage(lang string) string {
+	if msg, ok := rejectionMessages[lang]; ok {
+		return msg
+	}
+	return rejectionMessages[helpers.LanguageID]
+}
+
+// IssueMap mengubah daftar syarat yang gagal menjadi pesan per field (format ResponseValidationError).
+// Beberapa pesan untuk field yang sama digabung.
+func IssueMap(issues []models.EligibilityIssue) map[string]string {
+	errs := make(map[string]string, len(issues))
+	for _, issue := range issues {
+		if prev, ok := errs[issue.Field]; ok {
+			errs[issue.Field] = prev + "; " + issue.Message
+			continue
+		}
+		errs[issue.Field] = issue.Message
+	}
+	return errs
+}
+
+// CheckRules memvalidasi syarat yang diisi admin
+func CheckRules(r models.EligibilityRules) error {
+	if r.MinAge != nil && (*r.MinAge < 0 || *r.MinAge > 150) {
+		return errors.New("eligibility.min_age harus 0-150")
+	}
+	if r.MaxAge != nil && (*r.MaxAge < 0 || *r.MaxAge > 150) {
+		return errors.New("eligibility.max_age harus 0-150")
+	}
+	if r.MinAge != nil && r.MaxAge != nil && *r.MinAge > *r.MaxAge {
+		return errors.New("eligibility.min_age tidak boleh lebih besar dari max_age")
+	}
+	for _, a := range r.Angkatan {
+		if strings.TrimSpace(a) == "" {
+			return errors.New("eligibility.angkatan tidak boleh kosong")
+		}
+	}
+	for _, key := range r.RequiredAnswers {
+		if !answerKeyPattern.MatchString(key) {
+			return fmt.Errorf("eligibility.required_answers: key %q tidak valid", key)
+		}
+	}
+	return nil
+}
+
+// Facts adalah data participant yang dibutuhkan untuk evaluasi
+type Facts struct {
+	Participant models.Participant
+	// Answered berisi key pertanyaan yang sudah dijawab
+	Answered map[string]bool
+	// Files berisi kind file yang sudah diupload (ktm, photo, key pertanyaan tipe file);
+	// nil berarti file tidak dicek (saat registrasi file belum bisa diupload)
+	Files map[string]bool
+}
+
+// Context adalah data event yang dipakai bersama untuk banyak evaluasi
+type Context struct {
+	Event     models.Event
+	Questions []models.RegistrationQuestion
+	// CampusNames adalah nama kampus yang diperbolehkan, untuk pesan
+	CampusNames []string
+}
+
+// LoadContext mengambil pertanyaan dan nama kampus yang dibutuhkan untuk evaluasi event
+func LoadContext(db *gorm.DB, event models.Event) (*Context, error) {
+	ctx := &Context{Event: event}
+	if err := db.Where("event_id = ?", event.ID).Order("position asc, id asc").Find(&ctx.Questions).Error; err != nil {
+		return nil, err
+	}
+	if len(event.Eligibility.CampusIDs) > 0 {
+		if err := db.Model(&models.Campus{}).Where("id IN ?", event.Eligibility.CampusIDs).
+			Order("name asc").Pluck("name", &ctx.CampusNames).Error; err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// Evaluate mengembalikan syarat event yang tidak dipenuhi, pesan dalam bahasa lang
+func (ctx *Context) Evaluate(f Facts, lang string) []models.EligibilityIssue {
+	rules := ctx.Event.Eligibility
+	msg := messages[lang]
+	if msg == nil {
+		msg = messages[helpers.LanguageID]
+	}
+	var issues []models.EligibilityIssue
+	add := func(rule, field, format string, args ...interface{}) {
+		issues = append(issues, models.EligibilityIssue{Rule: rule, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	p := f.Participant
+
+	if rules.MinAge != nil || rules.MaxAge != nil {
+		age := helpers.Age(p.BirthDate, ctx.Event.StartDate)
+		date := ctx.Event.StartDate.Format("02-01-2006")
+		if rules.MinAge != nil && age < *rules.MinAge {
+			add(RuleMinAge, "birth_date", msg[RuleMinAge], *rules.MinAge, date)
+		}
+		if rules.MaxAge != nil && age > *rules.MaxAge {
+			add(RuleMaxAge, "birth_date", msg[RuleMaxAge], *rules.MaxAge, date)
+		}
+	}
+
+	if len(rules.Angkatan) > 0 && !containsFold(rules.Angkatan, p.Angkatan) {
+		add(RuleAngkatan, "angkatan", msg[RuleAngkatan], strings.TrimSpace(p.Angkatan), strings.Join(rules.Angkatan, ", "))
+	}
+
+	if len(rules.CampusIDs) > 0 && !containsID(rules.CampusIDs, p.CampusID) {
+		add(RuleCampus, "kampus", msg[RuleCampus], strings.Join(ctx.CampusNames, ", "))
+	}
+
+	byKey := make(map[string]models.RegistrationQuestion, len(ctx.Questions))
+	for _, q := range ctx.Questions {
+		byKey[q.Key] = q
+	}
+	for _, key := range rules.RequiredAnswers {
+		if label, ok := documentKinds[key]; ok {
+			if f.Files != nil && !f.Files[key] {
+				add(RuleRequiredFile, "files."+key, msg[RuleRequiredFile], label)
+			}
+			continue
+		}
+		q, ok := byKey[key]
+		if !ok {
+			// Pertanyaan sudah dihapus, syarat diabaikan
+			continue
+		}
+		if q.Type == models.QuestionFile {
+			if f.Files != nil && !f.Files[key] {
+				add(RuleRequiredFile, "answers."+key, msg[RuleRequiredFile], q.Label)
+			}
+			continue
+		}
+		if !f.Answered[key] {
+			add(RuleRequiredAnswer, "answers."+key, msg[RuleRequiredAnswer], q.Label)
+		}
+	}
+	return issues
+}
+
+// AnsweredKeys mengubah jawaban tersimpan / tervalidasi menjadi set key pertanyaan
+func (ctx *Context) AnsweredKeys(answers []models.ParticipantAnswer) map[string]bool {
+	keyByID := make(map[uint]string, len(ctx.Questions))
+	for _, q := range ctx.Questions {
+		keyByID[q.ID] = q.Key
+	}
+	answered := make(map[string]bool, len(answers))
+	for _, a := range answers {
+		if key, ok := keyByID[a.QuestionID]; ok {
+			answered[key] = true
+		}
+	}
+	return answered
+}
+
+// Result adalah hasil evaluasi ulang satu participant
+type Result struct {
+	ParticipantID string                    `json:"participant_id"`
+	Name          string                    `json:"name"`
+	Status        string                    `json:"status"`
+	Issues        []models.EligibilityIssue `json:"issues"`
+}
+
+// Summary adalah rekap evaluasi ulang satu event
+type Summary struct {
+	Evaluated int64    `json:"evaluated"`
+	Flagged   int64    `json:"flagged"`
+	Cleared   int64    `json:"cleared"` // sebelumnya ditandai, sekarang memenuhi syarat
+	Results   []Result `json:"flagged_participants"`
+}
+
+// skippedStatuses tidak dievaluasi ulang karena pendaftarannya sudah selesai
+var skippedStatuses = []string{models.StatusRejected, models.StatusWithdrawn}
+
+// Reevaluate mengevaluasi ulang semua participant event terhadap syarat saat ini (termasuk file upload)
+// dan menandai yang tidak memenuhi syarat lewat IneligibleAt / EligibilityIssues. Status tidak diubah,
+// keputusan tetap di admin.
+func Reevaluate(db *gorm.DB, event models.Event) (*Summary, error) {
+	ctx, err := LoadContext(db, event)
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{Results: []Result{}}
+	now := time.Now()
+
+	var participants []models.Participant
+	err = db.Where("event_id = ? AND status NOT IN ?", event.ID, skippedStatuses).Order("created_at asc, id asc").
+		FindInBatches(&participants, 500, func(tx *gorm.DB, batch int) error {
+			facts, err := ctx.loadFacts(db, participants)
+			if err != nil {
+				return err
+			}
+			for _, p := range participants {
+				issues := ctx.Evaluate(facts[p.ID], helpers.LanguageID)
+				summary.Evaluated++
+				if len(issues) == 0 {
+					if p.IneligibleAt == nil {
+						continue
+					}
+					summary.Cleared++
+					if err := db.Model(&p).UpdateColumns(map[string]interface{}{
+						"ineligible_at": nil, "eligibility_issues": nil,
+					}).Error; err != nil {
+						return err
+					}
+					continue
+				}
+				summary.Flagged++
+				summary.Results = append(summary.Results, Result{ParticipantID: p.ID, Name: p.Name, Status: p.Status, Issues: issues})
+				if err := db.Model(&p).Select("ineligible_at", "eligibility_issues").
+					Updates(&models.Participant{IneligibleAt: &now, EligibilityIssues: issues}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// Refresh mengevaluasi ulang satu participant tersimpan dan memperbarui tandanya (dipakai setelah data diubah)
+func Refresh(db *gorm.DB, event models.Event, p *models.Participant) error {
+	if event.Eligibility.Empty() && p.IneligibleAt == nil {
+		return nil
+	}
+	ctx, err := LoadContext(db, event)
+	if err != nil {
+		return err
+	}
+	facts, err := ctx.loadFacts(db, []models.Participant{*p})
+	if err != nil {
+		return err
+	}
+	issues := ctx.Evaluate(facts[p.ID], helpers.LanguageID)
+	if len(issues) == 0 {
+		p.IneligibleAt, p.EligibilityIssues = nil, nil
+		return db.Model(p).UpdateColumns(map[string]interface{}{"ineligible_at": nil, "eligibility_issues": nil}).Error
+	}
+	now := time.Now()
+	p.IneligibleAt, p.EligibilityIssues = &now, issues
+	return db.Model(p).Select("ineligible_at", "eligibility_issues").
+		Updates(&models.Participant{IneligibleAt: &now, EligibilityIssues: issues}).Error
+}
+
+// loadFacts mengambil jawaban dan file participant untuk evaluasi
+func (ctx *Context) loadFacts(db *gorm.DB, participants []models.Participant) (map[string]Facts, error) {
+	ids := make([]string, len(participants))
+	facts := make(map[string]Facts, len(participants))
+	for i, p := range participants {
+		ids[i] = p.ID
+		facts[p.ID] = Facts{Participant: p, Answered: map[string]bool{}, Files: map[string]bool{}}
+	}
+
+	var answers []models.ParticipantAnswer
+	if err := db.Where("participant_id IN ?", ids).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	byParticipant := map[string][]models.ParticipantAnswer{}
+	for _, a := range answers {
+		byParticipant[a.ParticipantID] = append(byParticipant[a.ParticipantID], a)
+	}
+	for id, list := range byParticipant {
+		f := facts[id]
+		f.Answered = ctx.AnsweredKeys(list)
+		facts[id] = f
+	}
+
+	var files []models.ParticipantFile
+	if err := db.Select("participant_id", "kind").Where("participant_id IN ?", ids).Find(&files).Error; err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		facts[file.ParticipantID].Files[file.Kind] = true
+	}
+	return facts, nil
+}
+
+func containsFold(list []string, value string) bool {
+	value = strings.TrimSpace(value)
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(list []uint, id *uint) bool {
+	if id == nil {
+		return false
+	}
+	for _, v := range list {
+		if v == *id {
+			return true
+		}
+	}
+	return false
+}
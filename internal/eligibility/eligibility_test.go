@@ -0,0 +1,207 @@
+package eligibility
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestCheckRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules models.EligibilityRules
+		ok    bool
+	}{
+		{"empty", models.EligibilityRules{}, true},
+		{"age range", models.EligibilityRules{MinAge: intPtr(17), MaxAge: intPtr(25)}, true},
+		{"same min and max", models.EligibilityRules{MinAge: intPtr(20), MaxAge: intPtr(20)}, true},
+		{"negative min", models.EligibilityRules{MinAge: intPtr(-1)}, false},
+		{"max too large", models.EligibilityRules{MaxAge: intPtr(151)}, false},
+		{"min above max", models.EligibilityRules{MinAge: intPtr(30), MaxAge: intPtr(25)}, false},
+		{"blank angkatan", models.EligibilityRules{Angkatan: []string{"2022", " "}}, false},
+		{"answer keys", models.EligibilityRules{RequiredAnswers: []string{"ktm", "ukuran_kaos", "q2"}}, true},
+		{"invalid answer key", models.EligibilityRules{RequiredAnswers: []string{"Ukuran Kaos"}}, false},
+		{"answer key starting with digit", models.EligibilityRules{RequiredAnswers: []string{"2q"}}, false},
+	}
+	for _, tt := range tests {
+		if err := CheckRules(tt.rules); (err == nil) != tt.ok {
+			t.Errorf("%s: CheckRules = %v, want ok=%v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func testContext(rules models.EligibilityRules) *Context {
+	return &Context{
+		Event: models.Event{ID: 1, StartDate: testutil.Date("2026-12-01"), Eligibility: rules},
+		Questions: []models.RegistrationQuestion{
+			{ID: 10, Key: "ukuran_kaos", Label: "Ukuran kaos", Type: models.QuestionSelect},
+			{ID: 11, Key: "surat_izin", Label: "Surat izin", Type: models.QuestionFile},
+		},
+		CampusNames: []string{"Universitas Indonesia"},
+	}
+}
+
+func rulesOf(issues []models.EligibilityIssue) []string {
+	rules := make([]string, len(issues))
+	for i, issue := range issues {
+		rules[i] = issue.Rule + ":" + issue.Field
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+func TestEvaluate(t *testing.T) {
+	ui, itb := uint(1), uint(2)
+	ctx := testContext(models.EligibilityRules{
+		MinAge:          intPtr(18),
+		MaxAge:          intPtr(25),
+		Angkatan:        []string{"2022", "2023"},
+		CampusIDs:       []uint{ui},
+		RequiredAnswers: []string{"ukuran_kaos", "surat_izin", "ktm", "pertanyaan_dihapus"},
+	})
+	eligible := models.Participant{BirthDate: testutil.Date("2008-12-01"), Angkatan: " 2023 ", CampusID: &ui}
+
+	tests := []struct {
+		name  string
+		facts Facts
+		want  []string
+	}{
+		{
+			// Ulang tahun ke-18 tepat di tanggal event dihitung sudah 18
+			"eligible", Facts{Participant: eligible, Answered: map[string]bool{"ukuran_kaos": true}, Files: map[string]bool{"surat_izin": true, "ktm": true}},
+			nil,
+		},
+		{
+			// Saat registrasi file belum dicek (Files nil)
+			"registration without files", Facts{Participant: eligible, Answered: map[string]bool{"ukuran_kaos": true}},
+			nil,
+		},
+		{
+			"one day too young", Facts{Participant: withBirthDate(eligible, "2008-12-02"), Answered: map[string]bool{"ukuran_kaos": true}},
+			[]string{"min_age:birth_date"},
+		},
+		{
+			"too old", Facts{Participant: withBirthDate(eligible, "2000-11-30"), Answered: map[string]bool{"ukuran_kaos": true}},
+			[]string{"max_age:birth_date"},
+		},
+		{
+			"wrong angkatan and campus", Facts{Participant: models.Participant{BirthDate: eligible.BirthDate, Angkatan: "2019", CampusID: &itb}, Answered: map[string]bool{"ukuran_kaos": true}},
+			[]string{"angkatan:angkatan", "campus:kampus"},
+		},
+		{
+			// Kampus "lainnya" (tanpa campus_id) tidak lolos syarat kampus
+			"custom campus", Facts{Participant: models.Participant{BirthDate: eligible.BirthDate, Angkatan: "2022"}, Answered: map[string]bool{"ukuran_kaos": true}},
+			[]string{"campus:kampus"},
+		},
+		{
+			"missing answer and files", Facts{Participant: eligible, Answered: map[string]bool{}, Files: map[string]bool{}},
+			[]string{"required_answer:answers.ukuran_kaos", "required_file:answers.surat_izin", "required_file:files.ktm"},
+		},
+	}
+	for _, tt := range tests {
+		got := rulesOf(ctx.Evaluate(tt.facts, helpers.LanguageID))
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: issues = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func withBirthDate(p models.Participant, birth string) models.Participant {
+	p.BirthDate = testutil.Date(birth)
+	return p
+}
+
+func TestEvaluateMessages(t *testing.T) {
+	ctx := testContext(models.EligibilityRules{MinAge: intPtr(18), CampusIDs: []uint{1}})
+	facts := Facts{Participant: models.Participant{BirthDate: testutil.Date("2010-01-01")}}
+
+	id := IssueMap(ctx.Evaluate(facts, helpers.LanguageID))
+	if id["birth_date"] != "Usia minimal 18 tahun pada tanggal event (01-12-2026)" {
+		t.Errorf("id birth_date = %q", id["birth_date"])
+	}
+	if id["kampus"] != "Pendaftaran hanya untuk mahasiswa Universitas Indonesia" {
+		t.Errorf("id kampus = %q", id["kampus"])
+	}
+	en := IssueMap(ctx.Evaluate(facts, helpers.LanguageEN))
+	if en["birth_date"] != "Minimum age is 18 on the event date (01-12-2026)" {
+		t.Errorf("en birth_date = %q", en["birth_date"])
+	}
+	// Bahasa tidak dikenal memakai bahasa Indonesia
+	if other := IssueMap(ctx.Evaluate(facts, "fr")); other["birth_date"] != id["birth_date"] {
+		t.Errorf("fallback birth_date = %q", other["birth_date"])
+	}
+	if RejectionMessage("fr") != RejectionMessage(helpers.LanguageID) || RejectionMessage(helpers.LanguageEN) == RejectionMessage(helpers.LanguageID) {
+		t.Error("RejectionMessage does not fall back to Indonesian")
+	}
+}
+
+func TestIssueMapJoinsSameField(t *testing.T) {
+	got := IssueMap([]models.EligibilityIssue{
+		{Rule: RuleMinAge, Field: "birth_date", Message: "a"},
+		{Rule: RuleMaxAge, Field: "birth_date", Message: "b"},
+		{Rule: RuleAngkatan, Field: "angkatan", Message: "c"},
+	})
+	want := map[string]string{"birth_date": "a; b", "angkatan": "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("IssueMap = %v, want %v", got, want)
+	}
+}
+
+func TestReevaluateFlagsAndClears(t *testing.T) {
+	db := testutil.OpenDB(t, &models.Event{}, &models.Campus{}, &models.RegistrationQuestion{}, &models.Participant{},
+		&models.ParticipantAnswer{}, &models.ParticipantFile{})
+	event := testutil.CreateEvent(t, db, func(e *models.Event) {
+		e.Eligibility = models.EligibilityRules{MinAge: intPtr(18), RequiredAnswers: []string{"ktm"}}
+	})
+	create := func(name, birth, status string) models.Participant {
+		return testutil.CreateParticipant(t, db, name, func(p *models.Participant) {
+			p.EventID, p.BirthDate, p.Angkatan, p.Status = &event.ID, testutil.Date(birth), "2022", status
+		})
+	}
+	adult := create("Dewasa", "2000-01-01", models.StatusPending)
+	minor := create("Anak", "2010-01-01", models.StatusApproved)
+	create("Ditolak", "2010-01-01", models.StatusRejected) // tidak dievaluasi ulang
+	if err := db.Create(&models.ParticipantFile{ParticipantID: adult.ID, Kind: "ktm"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := Reevaluate(db, event)
+	if err != nil {
+		t.Fatalf("Reevaluate: %v", err)
+	}
+	if summary.Evaluated != 2 || summary.Flagged != 1 || summary.Cleared != 0 || summary.Results[0].ParticipantID != minor.ID {
+		t.Fatalf("summary = %+v", summary)
+	}
+	if got := rulesOf(summary.Results[0].Issues); !reflect.DeepEqual(got, []string{"min_age:birth_date", "required_file:files.ktm"}) {
+		t.Errorf("issues = %v", got)
+	}
+	var stored models.Participant
+	db.First(&stored, "id = ?", minor.ID)
+	if stored.IneligibleAt == nil || len(stored.EligibilityIssues) != 2 || stored.Status != models.StatusApproved {
+		t.Errorf("stored minor = ineligible %v issues %v status %s", stored.IneligibleAt, stored.EligibilityIssues, stored.Status)
+	}
+
+	// Syarat dilonggarkan: tanda dihapus
+	event.Eligibility = models.EligibilityRules{}
+	summary, err = Reevaluate(db, event)
+	if err != nil {
+		t.Fatalf("second Reevaluate: %v", err)
+	}
+	if summary.Flagged != 0 || summary.Cleared != 1 {
+		t.Errorf("second summary = %+v", summary)
+	}
+	var cleared models.Participant
+	db.First(&cleared, "id = ?", minor.ID)
+	if cleared.IneligibleAt != nil || len(cleared.EligibilityIssues) != 0 {
+		t.Errorf("minor still flagged: %v %v", cleared.IneligibleAt, cleared.EligibilityIssues)
+	}
+}
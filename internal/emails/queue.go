@@ -0,0 +1,384 @@
+// Package emails mengirim email transaksional ke participant (tanda terima pendaftaran, persetujuan,
+// pengingat event, sertifikat) dari template per bahasa. Email disimpan dulu di outbox database lalu
+// dikirim worker lewat mailer (SMTP di production), dengan retry dan pencatatan bounce per email.
+package emails
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/outbox"
+)
+
+// Config mengatur outbox email dan retry
+type Config struct {
+	// Enabled mematikan semua email transaksional jika false (email verifikasi & magic link tetap dikirim)
+	Enabled bool
+	outbox.Retry
+	// SendTimeout adalah batas waktu satu pengiriman ke server SMTP
+	SendTimeout time.Duration
+	// PollInterval adalah jeda worker mengecek email yang jatuh tempo
+	PollInterval time.Duration
+}
+
+// ConfigFromEnv membaca konfigurasi outbox email dari environment
+func ConfigFromEnv() Config {
+	return Config{
+		Enabled: helpers.GetEnvBool("MAIL_NOTIFICATIONS", true),
+		Retry: outbox.Retry{
+			MaxAttempts: helpers.GetEnvInt("MAIL_QUEUE_MAX_ATTEMPTS", 8),
+			BaseBackoff: helpers.GetEnvDuration("MAIL_QUEUE_BASE_BACKOFF", time.Minute),
+			MaxBackoff:  helpers.GetEnvDuration("MAIL_QUEUE_MAX_BACKOFF", 2*time.Hour),
+		},
+		SendTimeout:  helpers.GetEnvDuration("MAIL_QUEUE_SEND_TIMEOUT", 30*time.Second),
+		PollInterval: helpers.GetEnvDuration("MAIL_QUEUE_POLL_INTERVAL", 15*time.Second),
+	}
+}
+
+// Queue mengantrikan email transaksional ke tabel outbound_emails; worker outbox mengirimnya lewat Mailer
+type Queue struct {
+	DB     *gorm.DB
+	Config Config
+	Mailer mailer.Mailer
+	// TicketURL membuat link tiket bertanda tangan untuk placeholder {ticket_url}
+	TicketURL func(code string) string
+	// PortalURL adalah halaman portal pendaftar untuk placeholder {portal_url}
+	PortalURL string
+	// VerifyURL membuat link verifikasi sertifikat untuk placeholder {certificate_verify_url}
+	VerifyURL func(serial string) string
+
+	worker *outbox.Worker[models.OutboundEmail]
+}
+
+// NewQueue membuat outbox email; panggil Start untuk menjalankan worker
+func NewQueue(db *gorm.DB, cfg Config, mail mailer.Mailer) *Queue {
+	q := &Queue{DB: db, Config: cfg, Mailer: mail}
+	q.worker = outbox.New(db, outbox.Options{
+		Name:          "Email worker",
+		PendingStatus: models.EmailQueued,
+		Retry:         cfg.Retry,
+		SendTimeout:   cfg.SendTimeout,
+		PollInterval:  cfg.PollInterval,
+		// Penerima ditolak (bounce) dan error 5xx lain tidak dicoba ulang
+		Permanent: func(err error) bool {
+			smtpErr := mailer.AsSMTPError(err)
+			return smtpErr != nil && (smtpErr.Bounced() || smtpErr.Permanent())
+		},
+	}, outbox.Handler[models.OutboundEmail]{
+		Attempts: func(msg *models.OutboundEmail) int { return msg.Attempts },
+		Send:     q.send,
+		Record:   q.record,
+	})
+	return q
+}
+
+// Enabled mengecek apakah email transaksional aktif
+func (q *Queue) Enabled() bool {
+	return q != nil && q.Mailer != nil && q.Config.Enabled
+}
+
+// Start menjalankan worker pengiriman di background (sekali per proses)
+func (q *Queue) Start() { q.worker.Start() }
+
+// Stop menghentikan worker setelah email yang sedang dikirim selesai (atau ctx habis)
+func (q *Queue) Stop(ctx context.Context) error { return q.worker.Stop(ctx) }
+
+// Kick membangunkan worker tanpa menunggu PollInterval
+func (q *Queue) Kick() { q.worker.Kick() }
+
+// Vars membuat nilai placeholder email untuk participant
+func (q *Queue) Vars(p models.Participant, event *models.Event) map[string]string {
+	vars := map[string]string{
+		"name":       p.Name,
+		"event_name": "Youth College",
+		"status":     p.Status,
+		"portal_url": q.PortalURL,
+	}
+	if p.RegistrationCode != nil {
+		vars["registration_code"] = *p.RegistrationCode
+		if q.TicketURL != nil {
+			vars["ticket_url"] = q.TicketURL(*p.RegistrationCode)
+		}
+	}
+	if event != nil {
+		vars["event_name"] = event.Name
+		vars["event_location"] = event.Location
+		vars["event_date"] = event.StartDate.Format("02 January 2006")
+		if !event.EndDate.IsZero() && !event.EndDate.Equal(event.StartDate) {
+			vars["event_date"] += " - " + event.EndDate.Format("02 January 2006")
+		}
+		if event.RegistrationClosesAt != nil {
+			vars["registration_closes_at"] = event.RegistrationClosesAt.Local().Format("02 January 2006 15:04")
+		}
+	}
+	return vars
+}
+
+// CertificateVars menambahkan placeholder sertifikat ke vars
+func (q *Queue) CertificateVars(vars map[string]string, cert models.Certificate) map[string]string {
+	vars["certificate_serial"] = cert.Serial
+	if q.VerifyURL != nil {
+		vars["certificate_verify_url"] = q.VerifyURL(cert.Serial)
+	}
+	return vars
+}
+
+// loadEvent mengambil event participant (nil untuk pendaftaran tanpa event)
+func (q *Queue) loadEvent(p models.Participant) (*models.Event, error) {
+	if p.EventID == nil {
+		return nil, nil
+	}
+	event := &models.Event{}
+	if err := q.DB.First(event, *p.EventID).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Notify mengantrikan email jenis key untuk participant dalam bahasanya.
+// Kegagalan dicatat di log saja karena email bukan bagian dari transaksi request.
+func (q *Queue) Notify(key string, p models.Participant) {
+	if !q.Enabled() || p.Email == nil {
+		return
+	}
+	event, err := q.loadEvent(p)
+	if err != nil {
+		log.Printf("ERROR: Gagal memuat event untuk email %s: %v", key, err)
+		return
+	}
+	if _, err := q.queue(key, p, q.Vars(p, event)); err != nil {
+		log.Printf("ERROR: Gagal mengantrikan email %s untuk %s: %v", key, p.ID, err)
+		return
+	}
+	q.Kick()
+}
+
+// NotifyCertificate mengantrikan email bahwa sertifikat participant sudah bisa diunduh
+func (q *Queue) NotifyCertificate(cert models.Certificate) {
+	if !q.Enabled() {
+		return
+	}
+	var p models.Participant
+	if err := q.DB.Where("id = ?", cert.ParticipantID).First(&p).Error; err != nil {
+		log.Printf("ERROR: Gagal memuat participant sertifikat %s: %v", cert.Serial, err)
+		return
+	}
+	if p.Email == nil {
+		return
+	}
+	event, err := q.loadEvent(p)
+	if err != nil {
+		log.Printf("ERROR: Gagal memuat event untuk email sertifikat %s: %v", cert.Serial, err)
+		return
+	}
+	if _, err := q.queue(TemplateCertificateReady, p, q.CertificateVars(q.Vars(p, event), cert)); err != nil {
+		log.Printf("ERROR: Gagal mengantrikan email sertifikat %s: %v", cert.Serial, err)
+		return
+	}
+	q.Kick()
+}
+
+// RemindEvent mengantrikan email pengingat event ke participant dengan status tertentu (default approved)
+// yang punya email. Mengembalikan jumlah email yang diantrikan.
+func (q *Queue) RemindEvent(event models.Event, statuses []string) (int, error) {
+	return q.SendToEvent(TemplateEventReminder, event, statuses)
+}
+
+// SendToEvent mengantrikan email jenis key ke participant event dengan status tertentu (default approved)
+// yang punya email. Mengembalikan jumlah email yang diantrikan.
+func (q *Queue) SendToEvent(key string, event models.Event, statuses []string) (int, error) {
+	if !q.Enabled() {
+		return 0, nil
+	}
+	if len(statuses) == 0 {
+		statuses = []string{models.StatusApproved}
+	}
+	var participants []models.Participant
+	if err := q.DB.Where("event_id = ? AND status IN ? AND email IS NOT NULL", event.ID, statuses).
+		Order("created_at asc").Find(&participants).Error; err != nil {
+		return 0, err
+	}
+	queued := 0
+	for _, p := range participants {
+		msg, err := q.queue(key, p, q.Vars(p, &event))
+		if err != nil {
+			return queued, err
+		}
+		if msg != nil {
+			queued++
+		}
+	}
+	if queued > 0 {
+		q.Kick()
+	}
+	return queued, nil
+}
+
+// queue merender template dalam bahasa participant dan menyimpan email ke outbox.
+// Mengembalikan nil jika template dinonaktifkan, participant tanpa email, atau alamatnya pernah
+// hard bounce (email tetap disimpan dengan status suppressed supaya terlihat di outbox).
+func (q *Queue) queue(key string, p models.Participant, vars map[string]string) (*models.OutboundEmail, error) {
+	if p.Email == nil || strings.TrimSpace(*p.Email) == "" {
+		return nil, nil
+	}
+	tpl, err := LoadTemplate(q.DB, key, p.Language)
+	if err != nil {
+		return nil, err
+	}
+	if !tpl.Active || tpl.Subject == "" || tpl.Text == "" {
+		return nil, nil
+	}
+	content := Render(ContentOf(tpl), vars)
+
+	now := time.Now()
+	participantID := p.ID
+	msg := models.OutboundEmail{
+		ParticipantID: &participantID,
+		EventID:       p.EventID,
+		TemplateKey:   key,
+		Language:      tpl.Language,
+		To:            *p.Email,
+		Subject:       content.Subject,
+		TextBody:      content.Text,
+		HTMLBody:      content.HTML,
+		Status:        models.EmailQueued,
+		NextAttemptAt: &now,
+		MessageID:     mailer.NewMessageID(),
+	}
+	suppressed, err := q.Suppressed(msg.To)
+	if err != nil {
+		return nil, err
+	}
+	if suppressed {
+		msg.Status = models.EmailSuppressed
+		msg.NextAttemptAt = nil
+		msg.LastError = "alamat penerima pernah hard bounce"
+	}
+	if err := q.DB.Create(&msg).Error; err != nil {
+		return nil, err
+	}
+	if suppressed {
+		return nil, nil
+	}
+	return &msg, nil
+}
+
+// Suppressed mengecek apakah alamat pernah hard bounce. Alamat dikirimi lagi setelah email yang
+// bounce dikirim ulang lewat Retry (bounce-nya dihapus).
+func (q *Queue) Suppressed(to string) (bool, error) {
+	var count int64
+	err := q.DB.Model(&models.OutboundEmail{}).
+		Where("LOWER(recipient) = ? AND status = ? AND bounce_type = ?",
+			strings.ToLower(strings.TrimSpace(to)), models.EmailBounced, models.BounceHard).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// send mengirim satu email ke Mailer
+func (q *Queue) send(ctx context.Context, msg *models.OutboundEmail) error {
+	return q.Mailer.Send(ctx, mailer.Message{
+		To:        msg.To,
+		Subject:   msg.Subject,
+		Text:      msg.TextBody,
+		HTML:      msg.HTMLBody,
+		MessageID: msg.MessageID,
+	})
+}
+
+// record mencatat hasil pengiriman. Penerima yang ditolak permanen saat RCPT TO dicatat sebagai
+// hard bounce, error permanen lain sebagai failed.
+func (q *Queue) record(msg *models.OutboundEmail, res outbox.Result) {
+	updates := map[string]interface{}{"attempts": res.Attempts, "next_attempt_at": res.NextAttemptAt, "updated_at": res.FinishedAt}
+	smtpErr := mailer.AsSMTPError(res.Err)
+	if smtpErr != nil {
+		updates["smtp_code"] = smtpErr.Code
+	}
+	switch {
+	case res.Err == nil:
+		updates["status"] = models.EmailSent
+		updates["sent_at"] = res.FinishedAt
+		updates["last_error"] = ""
+		updates["smtp_code"] = 0
+	case smtpErr != nil && smtpErr.Bounced():
+		updates["status"] = models.EmailBounced
+		updates["bounce_type"] = models.BounceHard
+		updates["bounced_at"] = res.FinishedAt
+		updates["last_error"] = res.Err.Error()
+	case res.Final:
+		updates["status"] = models.EmailFailed
+		updates["last_error"] = res.Err.Error()
+	default:
+		updates["last_error"] = res.Err.Error()
+	}
+	if err := q.DB.Model(&models.OutboundEmail{}).Where("id = ?", msg.ID).UpdateColumns(updates).Error; err != nil {
+		log.Printf("ERROR: Gagal mencatat status email %d: %v", msg.ID, err)
+	}
+	if status, ok := updates["status"]; ok && status != models.EmailSent {
+		log.Printf("ERROR: Email %s ke %s %s: %v", msg.TemplateKey, msg.To, status, res.Err)
+	}
+}
+
+// Retry mengantrikan ulang email yang gagal, bounce atau suppressed (mis. setelah alamat diperbaiki di
+// server penerima)
+func (q *Queue) Retry(msg *models.OutboundEmail) error {
+	if msg.Status != models.EmailFailed && msg.Status != models.EmailBounced && msg.Status != models.EmailSuppressed {
+		return fmt.Errorf("email berstatus %s tidak bisa dikirim ulang", msg.Status)
+	}
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status": models.EmailQueued, "attempts": 0, "next_attempt_at": now, "last_error": "",
+		"smtp_code": 0, "bounce_type": "", "bounced_at": nil, "updated_at": now,
+	}
+	if err := q.DB.Model(msg).UpdateColumns(updates).Error; err != nil {
+		return err
+	}
+	q.Kick()
+	return nil
+}
+
+// Bounce adalah laporan bounce yang datang setelah email diterima server SMTP (DSN / webhook provider)
+type Bounce struct {
+	MessageID string
+	Recipient string
+	Type      string // hard / soft
+	Reason    string
+	SMTPCode  int
+}
+
+// RecordBounce mencatat bounce ke email yang cocok dengan Message-ID, atau email terakhir yang terkirim
+// ke alamat penerima jika Message-ID kosong. Mengembalikan gorm.ErrRecordNotFound jika tidak ada yang cocok.
+func (q *Queue) RecordBounce(b Bounce) (*models.OutboundEmail, error) {
+	var msg models.OutboundEmail
+	query := q.DB.Model(&models.OutboundEmail{})
+	if id := strings.Trim(strings.TrimSpace(b.MessageID), "<>"); id != "" {
+		query = query.Where("message_id = ?", id)
+	} else {
+		query = query.Where("recipient = ? AND status IN ?", b.Recipient, []string{models.EmailSent, models.EmailBounced}).Order("sent_at desc")
+	}
+	if err := query.First(&msg).Error; err != nil {
+		return nil, err
+	}
+	if b.Type != models.BounceSoft {
+		b.Type = models.BounceHard
+	}
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status": models.EmailBounced, "bounce_type": b.Type, "bounced_at": now,
+		"last_error": b.Reason, "next_attempt_at": nil, "updated_at": now,
+	}
+	if b.SMTPCode != 0 {
+		updates["smtp_code"] = b.SMTPCode
+	}
+	if err := q.DB.Model(&msg).UpdateColumns(updates).Error; err != nil {
+		return nil, err
+	}
+	return &msg, q.DB.First(&msg, msg.ID).Error
+}
@@ -0,0 +1,200 @@
+package emails
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/mailer"
+	"backend/internal/mailer/fakesmtp"
+	"backend/internal/models"
+	"backend/internal/outbox"
+	"backend/internal/testutil"
+)
+
+// newTestQueue membuat outbox di SQLite sementara yang mengirim ke fake SMTP server.
+// bounce@example.com ditolak dengan 550, slow@example.com dengan 451.
+func newTestQueue(t *testing.T) (*Queue, *fakesmtp.Server) {
+	t.Helper()
+	db := testutil.OpenDB(t, &models.Event{}, &models.Participant{}, &models.EmailTemplate{}, &models.OutboundEmail{})
+
+	server := &fakesmtp.Server{
+		Reject:   map[string]bool{"bounce@example.com": true},
+		TempFail: map[string]bool{"slow@example.com": true},
+	}
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("start fake smtp: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	host, port, _ := net.SplitHostPort(server.Addr())
+	portNum, _ := strconv.Atoi(port)
+	mail := &mailer.SMTPMailer{Host: host, Port: portNum, From: "noreply@example.com", TLS: mailer.TLSNone, Timeout: 5 * time.Second}
+	cfg := Config{
+		Enabled:      true,
+		Retry:        outbox.Retry{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour},
+		SendTimeout:  5 * time.Second,
+		PollInterval: time.Hour,
+	}
+	return NewQueue(db, cfg, mail), server
+}
+
+func createParticipant(t *testing.T, q *Queue, email string) models.Participant {
+	t.Helper()
+	return testutil.CreateParticipant(t, q.DB, "Budi", func(p *models.Participant) {
+		p.Email, p.Language = &email, "id"
+	})
+}
+
+func lastEmail(t *testing.T, q *Queue, to string) models.OutboundEmail {
+	t.Helper()
+	var msg models.OutboundEmail
+	if err := q.DB.Where("recipient = ?", to).Order("id desc").First(&msg).Error; err != nil {
+		t.Fatalf("load email to %s: %v", to, err)
+	}
+	return msg
+}
+
+func TestQueueSendsEmail(t *testing.T) {
+	q, server := newTestQueue(t)
+	p := createParticipant(t, q, "budi@example.com")
+
+	q.Notify(TemplateRegistrationReceived, p)
+	if n, err := q.worker.ProcessDue(); err != nil || n != 1 {
+		t.Fatalf("ProcessDue = %d, %v; want 1, nil", n, err)
+	}
+
+	msg := lastEmail(t, q, "budi@example.com")
+	if msg.Status != models.EmailSent || msg.Attempts != 1 || msg.SentAt == nil || msg.NextAttemptAt != nil {
+		t.Fatalf("email = status %s attempts %d sent_at %v next %v; want sent after 1 attempt", msg.Status, msg.Attempts, msg.SentAt, msg.NextAttemptAt)
+	}
+	received := server.Messages()
+	if len(received) != 1 {
+		t.Fatalf("fake smtp received %d emails, want 1", len(received))
+	}
+	if len(received[0].To) != 1 || received[0].To[0] != "budi@example.com" {
+		t.Errorf("recipient = %v, want budi@example.com", received[0].To)
+	}
+	if !strings.Contains(string(received[0].Data), msg.MessageID) {
+		t.Errorf("received email does not carry Message-ID %s", msg.MessageID)
+	}
+}
+
+func TestQueueHardBounceIsRecordedAndSuppressed(t *testing.T) {
+	q, server := newTestQueue(t)
+	p := createParticipant(t, q, "bounce@example.com")
+
+	q.Notify(TemplateRegistrationReceived, p)
+	if _, err := q.worker.ProcessDue(); err != nil {
+		t.Fatalf("ProcessDue: %v", err)
+	}
+	bounced := lastEmail(t, q, "bounce@example.com")
+	if bounced.Status != models.EmailBounced || bounced.BounceType != models.BounceHard || bounced.SMTPCode != 550 || bounced.BouncedAt == nil {
+		t.Fatalf("email = status %s bounce %q code %d; want bounced / hard / 550", bounced.Status, bounced.BounceType, bounced.SMTPCode)
+	}
+
+	// Email berikutnya ke alamat yang sama tidak dikirim lagi
+	q.Notify(TemplateRegistrationApproved, p)
+	suppressed := lastEmail(t, q, "bounce@example.com")
+	if suppressed.ID == bounced.ID || suppressed.Status != models.EmailSuppressed || suppressed.NextAttemptAt != nil {
+		t.Fatalf("second email = id %d status %s; want a new suppressed email", suppressed.ID, suppressed.Status)
+	}
+	if n, err := q.worker.ProcessDue(); err != nil || n != 0 {
+		t.Fatalf("ProcessDue = %d, %v; want nothing due", n, err)
+	}
+	if len(server.Messages()) != 0 {
+		t.Fatalf("fake smtp received %d emails, want 0", len(server.Messages()))
+	}
+
+	// Alamat dengan huruf besar tetap dianggap sama
+	if ok, err := q.Suppressed(" Bounce@Example.com "); err != nil || !ok {
+		t.Fatalf("Suppressed = %v, %v; want true", ok, err)
+	}
+
+	// Retry mencabut bounce sehingga alamat bisa dikirimi lagi
+	if err := q.Retry(&bounced); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	if ok, err := q.Suppressed("bounce@example.com"); err != nil || ok {
+		t.Fatalf("Suppressed after retry = %v, %v; want false", ok, err)
+	}
+}
+
+func TestQueueTemporaryFailureRetriesWithBackoff(t *testing.T) {
+	q, server := newTestQueue(t)
+	p := createParticipant(t, q, "slow@example.com")
+
+	q.Notify(TemplateRegistrationReceived, p)
+	for attempt := 1; attempt <= q.Config.MaxAttempts; attempt++ {
+		before := time.Now()
+		if n, err := q.worker.ProcessDue(); err != nil || n != 1 {
+			t.Fatalf("attempt %d: ProcessDue = %d, %v; want 1, nil", attempt, n, err)
+		}
+		msg := lastEmail(t, q, "slow@example.com")
+		if msg.Attempts != attempt || msg.SMTPCode != 451 {
+			t.Fatalf("attempt %d: attempts %d code %d; want %d / 451", attempt, msg.Attempts, msg.SMTPCode, attempt)
+		}
+		if attempt == q.Config.MaxAttempts {
+			if msg.Status != models.EmailFailed || msg.NextAttemptAt != nil {
+				t.Fatalf("last attempt: status %s next %v; want failed", msg.Status, msg.NextAttemptAt)
+			}
+			break
+		}
+
+		want := q.Config.Backoff(attempt)
+		if msg.Status != models.EmailQueued || msg.NextAttemptAt == nil {
+			t.Fatalf("attempt %d: status %s next %v; want queued for retry", attempt, msg.Status, msg.NextAttemptAt)
+		}
+		if delay := msg.NextAttemptAt.Sub(before); delay < want || delay > want+5*time.Second {
+			t.Fatalf("attempt %d: retry in %s, want %s", attempt, delay, want)
+		}
+		// Belum jatuh tempo: tidak diambil worker
+		if n, _ := q.worker.ProcessDue(); n != 0 {
+			t.Fatalf("attempt %d: email picked up before backoff elapsed", attempt)
+		}
+		q.DB.Model(&msg).UpdateColumn("next_attempt_at", time.Now().Add(-time.Second))
+	}
+	if len(server.Messages()) != 0 {
+		t.Fatalf("fake smtp received %d emails, want 0", len(server.Messages()))
+	}
+}
+
+func TestQueueStopWaitsForWorker(t *testing.T) {
+	q, server := newTestQueue(t)
+	p := createParticipant(t, q, "budi@example.com")
+
+	// Stop sebelum Start tidak menunggu apa pun
+	idle, _ := newTestQueue(t)
+	if err := idle.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop before Start = %v", err)
+	}
+
+	q.Notify(TemplateRegistrationReceived, p)
+	q.Start()
+	q.Start() // Start kedua diabaikan
+	deadline := time.Now().Add(5 * time.Second)
+	for len(server.Messages()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.Stop(ctx); err != nil {
+		t.Fatalf("Stop = %v", err)
+	}
+	if msg := lastEmail(t, q, "budi@example.com"); msg.Status != models.EmailSent {
+		t.Fatalf("email status = %s, want sent before Stop returned", msg.Status)
+	}
+
+	// Setelah Stop, email baru tetap antri untuk start berikutnya
+	q.Notify(TemplateRegistrationReceived, p)
+	time.Sleep(50 * time.Millisecond)
+	if got := len(server.Messages()); got != 1 {
+		t.Fatalf("fake smtp received %d emails after Stop, want 1", got)
+	}
+	if msg := lastEmail(t, q, "budi@example.com"); msg.Status != models.EmailQueued {
+		t.Fatalf("email queued after Stop = %s, want queued", msg.Status)
+	}
+}
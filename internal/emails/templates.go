@@ -0,0 +1,280 @@
+package emails
+
+import (
+	"html"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// Jenis email transaksional ke participant
+const (
+	TemplateRegistrationReceived   = "registration_received"
+	TemplateRegistrationWaitlisted = "registration_waitlisted"
+	TemplateRegistrationApproved   = "registration_approved"
+	TemplateWaitlistPromoted       = "waitlist_promoted"
+	TemplateEventReminder          = "event_reminder"
+	TemplateCertificateReady       = "certificate_ready"
+	TemplateRegistrationClosing    = "registration_closing"
+)
+
+// Languages adalah bahasa template email yang didukung, bahasa pertama adalah default
+var Languages = []string{helpers.LanguageID, helpers.LanguageEN}
+
+// Variables adalah placeholder yang tersedia di template email
+var Variables = []string{
+	"name", "event_name", "event_date", "event_location", "registration_code", "ticket_url",
+	"status", "portal_url", "certificate_serial", "certificate_verify_url", "registration_closes_at",
+}
+
+// Content adalah isi satu template email: subject, versi teks dan versi HTML
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// page membungkus paragraf HTML dengan layout email sederhana (inline style supaya aman di semua klien email)
+func page(paragraphs ...string) string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html><html><body style="margin:0;padding:24px;background:#f4f5f7;font-family:Arial,sans-serif;color:#1f2933;">`)
+	b.WriteString(`<div style="max-width:560px;margin:0 auto;background:#ffffff;border-radius:8px;padding:24px;line-height:1.5;">`)
+	for _, p := range paragraphs {
+		b.WriteString("<p>" + p + "</p>")
+	}
+	b.WriteString(`</div></body></html>`)
+	return b.String()
+}
+
+// button adalah link berbentuk tombol di email HTML
+func button(url, label string) string {
+	return `<a href="` + url + `" style="display:inline-block;padding:10px 18px;background:#2563eb;color:#ffffff;border-radius:6px;text-decoration:none;">` + label + `</a>`
+}
+
+// DefaultTemplates adalah teks bawaan tiap jenis email per bahasa, bisa ditimpa admin lewat EmailTemplate
+var DefaultTemplates = map[string]map[string]Content{
+	TemplateRegistrationReceived: {
+		helpers.LanguageID: {
+			Subject: "Pendaftaran {event_name} sudah kami terima",
+			Text: "Halo {name},\n\nTerima kasih sudah mendaftar {event_name}. Pendaftaran kamu sudah kami terima dan akan diverifikasi panitia.\n\n" +
+				"Kode registrasi: {registration_code}\nTiket: {ticket_url}\n\nLihat atau perbarui data pendaftaran di portal pendaftar: {portal_url}\n\nSalam,\nPanitia {event_name}\n",
+			HTML: page("Halo {name},",
+				"Terima kasih sudah mendaftar <strong>{event_name}</strong>. Pendaftaran kamu sudah kami terima dan akan diverifikasi panitia.",
+				"Kode registrasi: <strong>{registration_code}</strong>",
+				button("{ticket_url}", "Lihat tiket"),
+				`Lihat atau perbarui data pendaftaran di <a href="{portal_url}">portal pendaftar</a>.`,
+				"Salam,<br>Panitia {event_name}"),
+		},
+		helpers.LanguageEN: {
+			Subject: "We have received your {event_name} registration",
+			Text: "Hi {name},\n\nThank you for registering for {event_name}. We have received your registration and the committee will review it shortly.\n\n" +
+				"Registration code: {registration_code}\nTicket: {ticket_url}\n\nView or update your registration in the registrant portal: {portal_url}\n\nBest regards,\n{event_name} Committee\n",
+			HTML: page("Hi {name},",
+				"Thank you for registering for <strong>{event_name}</strong>. We have received your registration and the committee will review it shortly.",
+				"Registration code: <strong>{registration_code}</strong>",
+				button("{ticket_url}", "View ticket"),
+				`View or update your registration in the <a href="{portal_url}">registrant portal</a>.`,
+				"Best regards,<br>{event_name} Committee"),
+		},
+	},
+	TemplateRegistrationWaitlisted: {
+		helpers.LanguageID: {
+			Subject: "Kamu masuk waitlist {event_name}",
+			Text: "Halo {name},\n\nKuota {event_name} sudah penuh sehingga pendaftaran kamu masuk waitlist. " +
+				"Kami akan mengabari kamu lewat email jika ada kursi kosong.\n\nKode registrasi: {registration_code}\n\nSalam,\nPanitia {event_name}\n",
+			HTML: page("Halo {name},",
+				"Kuota <strong>{event_name}</strong> sudah penuh sehingga pendaftaran kamu masuk <strong>waitlist</strong>. Kami akan mengabari kamu lewat email jika ada kursi kosong.",
+				"Kode registrasi: <strong>{registration_code}</strong>",
+				"Salam,<br>Panitia {event_name}"),
+		},
+		helpers.LanguageEN: {
+			Subject: "You are on the {event_name} waitlist",
+			Text: "Hi {name},\n\n{event_name} is currently full, so your registration has been added to the waitlist. " +
+				"We will email you as soon as a seat becomes available.\n\nRegistration code: {registration_code}\n\nBest regards,\n{event_name} Committee\n",
+			HTML: page("Hi {name},",
+				"<strong>{event_name}</strong> is currently full, so your registration has been added to the <strong>waitlist</strong>. We will email you as soon as a seat becomes available.",
+				"Registration code: <strong>{registration_code}</strong>",
+				"Best regards,<br>{event_name} Committee"),
+		},
+	},
+	TemplateRegistrationApproved: {
+		helpers.LanguageID: {
+			Subject: "Pendaftaran {event_name} disetujui",
+			Text: "Selamat {name}!\n\nPendaftaran kamu di {event_name} sudah disetujui. Tunjukkan tiket berikut saat registrasi ulang di lokasi:\n\n" +
+				"{ticket_url}\n\nKode registrasi: {registration_code}\nTanggal: {event_date}\nLokasi: {event_location}\n\nSampai jumpa!\nPanitia {event_name}\n",
+			HTML: page("Selamat {name}!",
+				"Pendaftaran kamu di <strong>{event_name}</strong> sudah disetujui. Tunjukkan tiket berikut saat registrasi ulang di lokasi.",
+				button("{ticket_url}", "Buka tiket"),
+				"Kode registrasi: <strong>{registration_code}</strong><br>Tanggal: {event_date}<br>Lokasi: {event_location}",
+				"Sampai jumpa!<br>Panitia {event_name}"),
+		},
+		helpers.LanguageEN: {
+			Subject: "Your {event_name} registration is approved",
+			Text: "Congratulations {name}!\n\nYour registration for {event_name} has been approved. Please show this ticket at check-in:\n\n" +
+				"{ticket_url}\n\nRegistration code: {registration_code}\nDate: {event_date}\nLocation: {event_location}\n\nSee you there!\n{event_name} Committee\n",
+			HTML: page("Congratulations {name}!",
+				"Your registration for <strong>{event_name}</strong> has been approved. Please show this ticket at check-in.",
+				button("{ticket_url}", "Open ticket"),
+				"Registration code: <strong>{registration_code}</strong><br>Date: {event_date}<br>Location: {event_location}",
+				"See you there!<br>{event_name} Committee"),
+		},
+	},
+	TemplateWaitlistPromoted: {
+		helpers.LanguageID: {
+			Subject: "Kamu mendapatkan kursi di {event_name}",
+			Text: "Halo {name},\n\nKabar baik! Ada kursi kosong dan pendaftaran kamu sudah dipindahkan dari waitlist. " +
+				"Status pendaftaran kamu sekarang: {status}.\n\nKode registrasi: {registration_code}\nTiket: {ticket_url}\n\nSalam,\nPanitia {event_name}\n",
+			HTML: page("Halo {name},",
+				"Kabar baik! Ada kursi kosong di <strong>{event_name}</strong> dan pendaftaran kamu sudah dipindahkan dari waitlist. Status pendaftaran kamu sekarang: <strong>{status}</strong>.",
+				"Kode registrasi: <strong>{registration_code}</strong>",
+				button("{ticket_url}", "Lihat tiket"),
+				"Salam,<br>Panitia {event_name}"),
+		},
+		helpers.LanguageEN: {
+			Subject: "You got a seat at {event_name}",
+			Text: "Hi {name},\n\nGood news! A seat became available and your registration has been moved off the waitlist. " +
+				"Your registration status is now: {status}.\n\nRegistration code: {registration_code}\nTicket: {ticket_url}\n\nBest regards,\n{event_name} Committee\n",
+			HTML: page("Hi {name},",
+				"Good news! A seat became available at <strong>{event_name}</strong> and your registration has been moved off the waitlist. Your registration status is now: <strong>{status}</strong>.",
+				"Registration code: <strong>{registration_code}</strong>",
+				button("{ticket_url}", "View ticket"),
+				"Best regards,<br>{event_name} Committee"),
+		},
+	},
+	TemplateEventReminder: {
+		helpers.LanguageID: {
+			Subject: "Pengingat: {event_name} dimulai {event_date}",
+			Text: "Halo {name},\n\nJangan lupa, {event_name} dimulai {event_date} di {event_location}. " +
+				"Tunjukkan tiket berikut saat registrasi ulang:\n\n{ticket_url}\n\nKode registrasi: {registration_code}\n\nSampai jumpa!\nPanitia {event_name}\n",
+			HTML: page("Halo {name},",
+				"Jangan lupa, <strong>{event_name}</strong> dimulai <strong>{event_date}</strong> di {event_location}. Tunjukkan tiket berikut saat registrasi ulang.",
+				button("{ticket_url}", "Buka tiket"),
+				"Kode registrasi: <strong>{registration_code}</strong>",
+				"Sampai jumpa!<br>Panitia {event_name}"),
+		},
+		helpers.LanguageEN: {
+			Subject: "Reminder: {event_name} starts {event_date}",
+			Text: "Hi {name},\n\nA friendly reminder that {event_name} starts {event_date} at {event_location}. " +
+				"Please show this ticket at check-in:\n\n{ticket_url}\n\nRegistration code: {registration_code}\n\nSee you there!\n{event_name} Committee\n",
+			HTML: page("Hi {name},",
+				"A friendly reminder that <strong>{event_name}</strong> starts <strong>{event_date}</strong> at {event_location}. Please show this ticket at check-in.",
+				button("{ticket_url}", "Open ticket"),
+				"Registration code: <strong>{registration_code}</strong>",
+				"See you there!<br>{event_name} Committee"),
+		},
+	},
+	TemplateRegistrationClosing: {
+		helpers.LanguageID: {
+			Subject: "Pendaftaran {event_name} segera ditutup",
+			Text: "Halo {name},\n\nPendaftaran {event_name} ditutup {registration_closes_at}. Pastikan data pendaftaran kamu sudah lengkap " +
+				"lewat portal pendaftar: {portal_url}\n\nKode registrasi: {registration_code}\n\nSalam,\nPanitia {event_name}\n",
+			HTML: page("Halo {name},",
+				"Pendaftaran <strong>{event_name}</strong> ditutup <strong>{registration_closes_at}</strong>. Pastikan data pendaftaran kamu sudah lengkap lewat portal pendaftar.",
+				button("{portal_url}", "Buka portal"),
+				"Kode registrasi: <strong>{registration_code}</strong>",
+				"Salam,<br>Panitia {event_name}"),
+		},
+		helpers.LanguageEN: {
+			Subject: "{event_name} registration closes soon",
+			Text: "Hi {name},\n\nRegistration for {event_name} closes {registration_closes_at}. Please make sure your registration details are complete " +
+				"in the registrant portal: {portal_url}\n\nRegistration code: {registration_code}\n\nBest regards,\n{event_name} Committee\n",
+			HTML: page("Hi {name},",
+				"Registration for <strong>{event_name}</strong> closes <strong>{registration_closes_at}</strong>. Please make sure your registration details are complete in the registrant portal.",
+				button("{portal_url}", "Open portal"),
+				"Registration code: <strong>{registration_code}</strong>",
+				"Best regards,<br>{event_name} Committee"),
+		},
+	},
+	TemplateCertificateReady: {
+		helpers.LanguageID: {
+			Subject: "Sertifikat {event_name} sudah tersedia",
+			Text: "Halo {name},\n\nTerima kasih sudah mengikuti {event_name}. Sertifikat kamu sudah tersedia dan bisa diunduh lewat portal pendaftar: {portal_url}\n\n" +
+				"Nomor seri: {certificate_serial}\nCek keaslian sertifikat: {certificate_verify_url}\n\nSalam,\nPanitia {event_name}\n",
+			HTML: page("Halo {name},",
+				"Terima kasih sudah mengikuti <strong>{event_name}</strong>. Sertifikat kamu sudah tersedia dan bisa diunduh lewat portal pendaftar.",
+				button("{portal_url}", "Buka portal"),
+				`Nomor seri: <strong>{certificate_serial}</strong><br><a href="{certificate_verify_url}">Cek keaslian sertifikat</a>`,
+				"Salam,<br>Panitia {event_name}"),
+		},
+		helpers.LanguageEN: {
+			Subject: "Your {event_name} certificate is ready",
+			Text: "Hi {name},\n\nThank you for joining {event_name}. Your certificate is ready and can be downloaded from the registrant portal: {portal_url}\n\n" +
+				"Serial number: {certificate_serial}\nVerify the certificate: {certificate_verify_url}\n\nBest regards,\n{event_name} Committee\n",
+			HTML: page("Hi {name},",
+				"Thank you for joining <strong>{event_name}</strong>. Your certificate is ready and can be downloaded from the registrant portal.",
+				button("{portal_url}", "Open portal"),
+				`Serial number: <strong>{certificate_serial}</strong><br><a href="{certificate_verify_url}">Verify the certificate</a>`,
+				"Best regards,<br>{event_name} Committee"),
+		},
+	},
+}
+
+// TemplateKeys mengembalikan semua jenis email, urut abjad
+func TemplateKeys() []string {
+	keys := make([]string, 0, len(DefaultTemplates))
+	for k := range DefaultTemplates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SupportedLanguage mengecek apakah bahasa punya template email
+func SupportedLanguage(lang string) bool {
+	for _, l := range Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Default mengambil teks bawaan jenis email untuk bahasa; bahasa yang tidak didukung memakai Bahasa Indonesia
+func Default(key, lang string) Content {
+	if content, ok := DefaultTemplates[key][lang]; ok {
+		return content
+	}
+	return DefaultTemplates[key][Languages[0]]
+}
+
+// replace mengganti {variabel} dengan nilainya (escape mengubah nilai sebelum disisipkan)
+func replace(body string, vars map[string]string, escape func(string) string) string {
+	pairs := make([]string, 0, len(vars)*2)
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", escape(v))
+	}
+	return strings.NewReplacer(pairs...).Replace(body)
+}
+
+// Render mengisi placeholder subject, teks dan HTML. Nilai di HTML di-escape; placeholder yang tidak dikenal dibiarkan.
+func Render(content Content, vars map[string]string) Content {
+	plain := func(s string) string { return s }
+	return Content{
+		Subject: strings.Join(strings.Fields(replace(content.Subject, vars, plain)), " "),
+		Text:    replace(content.Text, vars, plain),
+		HTML:    replace(content.HTML, vars, html.EscapeString),
+	}
+}
+
+// LoadTemplate mengambil template jenis email untuk bahasa: versi admin jika ada, selain itu teks bawaan
+func LoadTemplate(db *gorm.DB, key, lang string) (models.EmailTemplate, error) {
+	if !SupportedLanguage(lang) {
+		lang = Languages[0]
+	}
+	var tpl models.EmailTemplate
+	err := db.Where("template_key = ? AND language = ?", key, lang).First(&tpl).Error
+	if err == gorm.ErrRecordNotFound {
+		content := Default(key, lang)
+		return models.EmailTemplate{Key: key, Language: lang, Subject: content.Subject, Text: content.Text, HTML: content.HTML, Active: true}, nil
+	}
+	return tpl, err
+}
+
+// ContentOf mengambil isi template email
+func ContentOf(tpl models.EmailTemplate) Content {
+	return Content{Subject: tpl.Subject, Text: tpl.Text, HTML: tpl.HTML}
+}
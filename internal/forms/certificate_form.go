@@ -0,0 +1,25 @@
+package forms
+
+import "backend/internal/models"
+
+// CertificateTemplateForm untuk validasi input template sertifikat. Gambar latar diupload terpisah.
+type CertificateTemplateForm struct {
+	Name        string                    `json:"name" binding:"required,max=255"`
+	Orientation string                    `json:"orientation" binding:"omitempty,oneof=L P"`
+	PageSize    string                    `json:"page_size" binding:"omitempty,oneof=A4 A5 Letter"`
+	Fields      []models.CertificateField `json:"fields" binding:"max=50"`
+	QRX         float64                   `json:"qr_x"`
+	QRY         float64                   `json:"qr_y"`
+	QRSize      float64                   `json:"qr_size"`
+	Rules       models.CertificateRules   `json:"rules"`
+}
+
+// GenerateCertificatesForm untuk generate sertifikat massal dari satu template
+type GenerateCertificatesForm struct {
+	TemplateID uint `json:"template_id" binding:"required"`
+}
+
+// RevokeCertificateForm untuk mencabut sertifikat (mis. diterbitkan karena kesalahan data)
+type RevokeCertificateForm struct {
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
@@ -0,0 +1,14 @@
+package forms
+
+// CheckInForm untuk check-in di lokasi. Code boleh berupa isi QR tiket (YC1:...) atau kode registrasi yang diketik.
+// SessionID diisi jika scan dilakukan di pintu ruangan sesi, sekaligus mencatat kehadiran sesi.
+type CheckInForm struct {
+	Code      string `json:"code" binding:"required,max=200"`
+	Gate      string `json:"gate" binding:"max=100"`
+	SessionID *uint  `json:"session_id"`
+}
+
+// UndoCheckInForm untuk membatalkan check-in (mis. salah scan)
+type UndoCheckInForm struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
@@ -0,0 +1,29 @@
+package forms
+
+// EmailTemplateForm untuk mengubah template email satu bahasa. Subject, Text dan HTML berisi
+// placeholder seperti {name}; HTML kosong = email hanya versi teks.
+type EmailTemplateForm struct {
+	Subject string `json:"subject" binding:"required,max=998"`
+	Text    string `json:"text" binding:"required,max=65535"`
+	HTML    string `json:"html" binding:"max=200000"`
+	Active  *bool  `json:"active"`
+}
+
+// EmailPreviewForm untuk melihat hasil render template email dengan data participant (atau contoh).
+// Field yang kosong memakai template tersimpan.
+type EmailPreviewForm struct {
+	Subject       string `json:"subject" binding:"max=998"`
+	Text          string `json:"text" binding:"max=65535"`
+	HTML          string `json:"html" binding:"max=200000"`
+	ParticipantID string `json:"participant_id" binding:"max=36"`
+}
+
+// EmailBounceForm adalah laporan bounce dari server / provider email. Email dicocokkan lewat
+// MessageID, atau email terakhir yang terkirim ke Recipient jika MessageID kosong.
+type EmailBounceForm struct {
+	MessageID string `json:"message_id" binding:"required_without=Recipient,max=255"`
+	Recipient string `json:"recipient" binding:"omitempty,email,max=255"`
+	Type      string `json:"type" binding:"omitempty,oneof=hard soft"`
+	Reason    string `json:"reason" binding:"max=2000"`
+	SMTPCode  int    `json:"smtp_code" binding:"omitempty,min=200,max=599"`
+}
@@ -0,0 +1,19 @@
+package forms
+
+import "backend/internal/models"
+
+// EventForm untuk validasi input event.
+// Tanggal event format YYYY-MM-DD, jendela registrasi format RFC3339 (mis. 2025-07-01T00:00:00+07:00).
+type EventForm struct {
+	Name                 string `json:"name" binding:"required,min=2,max=255"`
+	Slug                 string `json:"slug" binding:"omitempty,max=100"`
+	Description          string `json:"description"`
+	Location             string `json:"location" binding:"max=255"`
+	StartDate            string `json:"start_date" binding:"required"`
+	EndDate              string `json:"end_date" binding:"required"`
+	RegistrationOpensAt  string `json:"registration_opens_at"`
+	RegistrationClosesAt string `json:"registration_closes_at"`
+	Capacity             *int   `json:"capacity" binding:"omitempty,min=1"` // Kosong = tanpa batas
+	// Eligibility adalah syarat pendaftar (kosong = semua boleh mendaftar)
+	Eligibility models.EligibilityRules `json:"eligibility"`
+}
@@ -0,0 +1,24 @@
+package forms
+
+// JobForm untuk membuat / mengubah job terjadwal. Trigger cron memakai Cron (mis. "0 9 * * 1" atau
+// @daily); trigger event memakai Anchor + Offset (mis. event_start dan -1d) dengan TimeOfDay opsional (HH:MM).
+type JobForm struct {
+	Name      string        `json:"name" binding:"required,max=100"`
+	Type      string        `json:"type" binding:"required,max=50"`
+	Trigger   string        `json:"trigger" binding:"required,oneof=cron event"`
+	Cron      string        `json:"cron" binding:"max=100"`
+	EventID   *uint         `json:"event_id"`
+	Anchor    string        `json:"anchor" binding:"max=30"`
+	Offset    string        `json:"offset" binding:"max=20"`
+	TimeOfDay string        `json:"time_of_day" binding:"max=5"`
+	Params    JobParamsForm `json:"params"`
+	Paused    *bool         `json:"paused"`
+}
+
+// JobParamsForm adalah parameter job event_notification. Kosong = template event_reminder
+// ke status bawaan template lewat semua channel.
+type JobParamsForm struct {
+	Template string   `json:"template" binding:"max=50"`
+	Statuses []string `json:"statuses" binding:"max=6,dive,required,max=20"`
+	Channels []string `json:"channels" binding:"max=2,dive,oneof=message email"`
+}
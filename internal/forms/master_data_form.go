@@ -0,0 +1,29 @@
+package forms
+
+// CampusForm untuk validasi input data master kampus
+type CampusForm struct {
+	Name      string   `json:"name" binding:"required,max=255"`
+	ShortName string   `json:"short_name" binding:"max=50"`
+	City      string   `json:"city" binding:"max=100"`
+	Aliases   []string `json:"aliases" binding:"max=50,dive,required,max=255"`
+}
+
+// StudyProgramForm untuk validasi input data master jurusan
+type StudyProgramForm struct {
+	Name    string   `json:"name" binding:"required,max=255"`
+	Aliases []string `json:"aliases" binding:"max=50,dive,required,max=255"`
+}
+
+// MapMasterDataForm untuk menautkan teks bebas kampus / jurusan participant ke data master.
+// AddAliases menyimpan values sebagai alias supaya pendaftaran berikutnya tertaut otomatis.
+type MapMasterDataForm struct {
+	Field      string   `json:"field" binding:"required,oneof=kampus jurusan"`
+	Values     []string `json:"values" binding:"required,min=1,max=200,dive,required,max=255"`
+	TargetID   uint     `json:"target_id" binding:"required"`
+	AddAliases bool     `json:"add_aliases"`
+}
+
+// AutoMapMasterDataForm untuk menautkan semua teks bebas yang cocok persis dengan data master
+type AutoMapMasterDataForm struct {
+	Field string `json:"field" binding:"required,oneof=kampus jurusan"`
+}
@@ -0,0 +1,35 @@
+package forms
+
+// MessageTemplateForm untuk mengubah teks pesan WhatsApp / SMS. Body berisi placeholder seperti {name};
+// WhatsAppTemplate (opsional) adalah nama template yang sudah disetujui Meta dengan parameter body
+// diisi berurutan dari WhatsAppParams (nama placeholder tanpa kurung kurawal).
+type MessageTemplateForm struct {
+	Body             string   `json:"body" binding:"required,max=4096"`
+	WhatsAppTemplate string   `json:"whatsapp_template" binding:"max=255"`
+	WhatsAppLanguage string   `json:"whatsapp_language" binding:"max=10"`
+	WhatsAppParams   []string `json:"whatsapp_params" binding:"max=20,dive,required,max=50"`
+	Active           *bool    `json:"active"`
+}
+
+// MessagePreviewForm untuk melihat hasil render template dengan data participant (atau contoh)
+type MessagePreviewForm struct {
+	Body          string `json:"body" binding:"max=4096"` // kosong = template tersimpan
+	ParticipantID string `json:"participant_id" binding:"max=36"`
+}
+
+// OptOutForm untuk admin menghentikan WhatsApp / SMS ke nomor participant
+type OptOutForm struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// EventReminderForm untuk mengirim pengingat event; Statuses kosong = hanya yang approved,
+// Channels kosong = WhatsApp / SMS dan email
+type EventReminderForm struct {
+	Statuses []string `json:"statuses" binding:"max=10,dive,required,max=20"`
+	Channels []string `json:"channels" binding:"max=2,dive,oneof=message email"`
+}
+
+// PortalNotificationForm untuk pendaftar berhenti / kembali menerima WhatsApp & SMS
+type PortalNotificationForm struct {
+	OptOut *bool `json:"opt_out" binding:"required"`
+}
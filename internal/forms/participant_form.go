@@ -7,8 +7,35 @@ type ParticipantForm struct {
 	Name      string `json:"name" binding:"required"`
 	Place     string `json:"place" binding:"required,min=2,max=255"`
 	BirthDate string `json:"birth_date" binding:"required"`
-	Kampus    string `json:"kampus" binding:"required"`
-	Jurusan   string `json:"jurusan" binding:"required"`
+	Kampus    string `json:"kampus" binding:"required_without=CampusID,max=255"`
+	Jurusan   string `json:"jurusan" binding:"required_without=StudyProgramID,max=255"`
 	Angkatan  string `json:"angkatan" binding:"required"`
-	Phone     string `json:"phone" binding:"required,min=8,max=20"`
+	Phone     string `json:"phone" binding:"required,min=8,max=30"` // Dinormalisasi ke E.164 di controller
+	Email     string `json:"email" binding:"omitempty,email,max=255"`
+	// Language bahasa email ke pendaftar; kosong = dari header Accept-Language (saat daftar) / tidak berubah
+	Language string `json:"language" binding:"omitempty,oneof=id en"`
+	// CampusID & StudyProgramID dari autocomplete data master; kosong = teks bebas kampus / jurusan ("lainnya")
+	CampusID       *uint `json:"campus_id"`
+	StudyProgramID *uint `json:"study_program_id"`
+	// Answers berisi jawaban pertanyaan registrasi event (key pertanyaan -> nilai), divalidasi di controller
+	Answers map[string]interface{} `json:"answers"`
+	// Proteksi spam registrasi publik (lihat GET /api/registration-challenge), diabaikan saat update
+	Website      string `json:"website"` // Honeypot: field tersembunyi, harus kosong
+	FormToken    string `json:"form_token" binding:"max=200"`
+	PowNonce     string `json:"pow_nonce" binding:"max=100"`
+	CaptchaToken string `json:"captcha_token" binding:"max=4096"`
+}
+
+// MergeParticipantForm untuk menggabungkan participant duplikat.
+// Fields berisi nama field yang diambil dari data duplikat (selain itu data primary dipertahankan).
+type MergeParticipantForm struct {
+	PrimaryID   string   `json:"primary_id" binding:"required"`
+	DuplicateID string   `json:"duplicate_id" binding:"required"`
+	Fields      []string `json:"fields" binding:"omitempty,dive,oneof=name place birth_date kampus jurusan angkatan phone email"`
+}
+
+// StatusTransitionForm untuk mengubah status pendaftaran participant
+type StatusTransitionForm struct {
+	Status string `json:"status" binding:"required,oneof=pending approved rejected waitlisted withdrawn attended"`
+	Reason string `json:"reason" binding:"max=1000"`
 }
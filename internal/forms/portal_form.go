@@ -0,0 +1,40 @@
+package forms
+
+// MagicLinkRequestForm untuk meminta link masuk portal pendaftar.
+// Contact berisi nomor HP atau email yang dipakai saat registrasi.
+type MagicLinkRequestForm struct {
+	Contact string `json:"contact" binding:"required,max=255"`
+	Event   string `json:"event" binding:"omitempty,max=100"` // Slug event, kosong = semua event
+}
+
+// MagicLinkExchangeForm untuk menukar token magic link dengan sesi portal
+type MagicLinkExchangeForm struct {
+	Token string `json:"token" binding:"required,max=128"`
+}
+
+// PortalUpdateForm untuk pendaftar mengubah datanya sendiri.
+// Nomor HP tidak bisa diubah karena dipakai untuk identitas & login portal (hubungi panitia).
+type PortalUpdateForm struct {
+	Name           string                 `json:"name" binding:"required"`
+	Place          string                 `json:"place" binding:"required,min=2,max=255"`
+	BirthDate      string                 `json:"birth_date" binding:"required"`
+	Kampus         string                 `json:"kampus" binding:"required_without=CampusID,max=255"`
+	Jurusan        string                 `json:"jurusan" binding:"required_without=StudyProgramID,max=255"`
+	Angkatan       string                 `json:"angkatan" binding:"required"`
+	Email          string                 `json:"email" binding:"omitempty,email,max=255"`
+	Language       string                 `json:"language" binding:"omitempty,oneof=id en"`
+	CampusID       *uint                  `json:"campus_id"`
+	StudyProgramID *uint                  `json:"study_program_id"`
+	Answers        map[string]interface{} `json:"answers"`
+}
+
+// WithdrawForm untuk pendaftar mengundurkan diri lewat portal
+type WithdrawForm struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// StatusLookupForm untuk cek status pendaftaran publik tanpa login
+type StatusLookupForm struct {
+	Code            string `json:"code" binding:"required,max=20"`
+	PhoneLastDigits string `json:"phone_last_digits" binding:"required,max=10"` // 4 digit terakhir nomor HP
+}
@@ -0,0 +1,16 @@
+package forms
+
+import "backend/internal/models"
+
+// RegistrationQuestionForm untuk validasi input pertanyaan registrasi event.
+// Key boleh kosong, akan dibuat dari label (mis. "Ukuran Kaos" -> "ukuran_kaos").
+type RegistrationQuestionForm struct {
+	Key      string               `json:"key" binding:"omitempty,max=100"`
+	Label    string               `json:"label" binding:"required,max=255"`
+	HelpText string               `json:"help_text"`
+	Type     string               `json:"type" binding:"required,oneof=text number select multi_select date boolean file"`
+	Required bool                 `json:"required"`
+	Options  []string             `json:"options" binding:"omitempty,max=100,dive,max=255"`
+	Rules    models.QuestionRules `json:"rules"`
+	Position int                  `json:"position"`
+}
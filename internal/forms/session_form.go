@@ -0,0 +1,18 @@
+package forms
+
+import "time"
+
+// SessionForm untuk validasi input sesi jadwal event
+type SessionForm struct {
+	Title    string    `json:"title" binding:"required,max=255"`
+	Speaker  string    `json:"speaker" binding:"max=255"`
+	Room     string    `json:"room" binding:"max=100"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+// SessionAttendanceForm untuk menandai hadir / tidak hadir banyak participant sekaligus
+type SessionAttendanceForm struct {
+	ParticipantIDs []string `json:"participant_ids" binding:"required,min=1,max=1000,dive,required,max=36"`
+	Present        *bool    `json:"present" binding:"required"`
+}
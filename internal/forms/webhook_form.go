@@ -0,0 +1,12 @@
+package forms
+
+// WebhookForm untuk validasi input langganan webhook. EventTypes berisi tipe event
+// (mis. participant.registered) atau "*" untuk semua; Secret kosong = dibuat otomatis saat create.
+type WebhookForm struct {
+	Name       string   `json:"name" binding:"required,max=255"`
+	URL        string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,max=20,dive,required,max=50"`
+	EventID    *uint    `json:"event_id"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=255"`
+	Active     *bool    `json:"active"`
+}
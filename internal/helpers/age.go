@@ -0,0 +1,18 @@
+package helpers
+
+import "time"
+
+// Age menghitung umur (tahun penuh) pada tanggal ref
+func Age(birth, ref time.Time) int {
+	age := ref.Year() - birth.Year()
+	if ref.Month() < birth.Month() || (ref.Month() == birth.Month() && ref.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+// ValidBirthDate mengecek tanggal lahir masuk akal: tidak kosong (zero value), tidak sebelum 1900
+// dan tidak di masa depan
+func ValidBirthDate(birth time.Time) bool {
+	return !birth.IsZero() && birth.Year() >= 1900 && !birth.After(time.Now())
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Cursor menyimpan posisi keyset pagination (kolom sort + id sebagai tie breaker)
+type Cursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Value     string `json:"v"`
+	ID        string `json:"i"`
+	Backward  bool   `json:"b,omitempty"`
+}
+
+// ErrInvalidCursor dikembalikan jika cursor tidak bisa di-decode
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor mengubah cursor menjadi string opaque yang aman untuk query string
+func EncodeCursor(cur Cursor) string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor membaca kembali cursor dari string opaque
+func DecodeCursor(s string) (Cursor, error) {
+	var cur Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID == "" {
+		return cur, ErrInvalidCursor
+	}
+	return cur, nil
+}
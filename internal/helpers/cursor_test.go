@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []Cursor{
+		{SortBy: "created_at", SortOrder: "desc", Value: "2026-01-02T03:04:05.123456789+07:00", ID: "3f1c9a52-0000-4000-8000-000000000001"},
+		{SortBy: "name", SortOrder: "asc", Value: "Budi \"Santoso\" & Siti/+=?", ID: "p-2", Backward: true},
+		{SortBy: "id", SortOrder: "asc", Value: "", ID: "p-3"},
+	}
+	for _, cur := range cursors {
+		encoded := EncodeCursor(cur)
+		// Aman dipakai di query string tanpa escape
+		if strings.ContainsAny(encoded, "+/=&?") {
+			t.Errorf("EncodeCursor(%+v) = %q, not URL safe", cur, encoded)
+		}
+		decoded, err := DecodeCursor(encoded)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q): %v", encoded, err)
+		}
+		if decoded != cur {
+			t.Errorf("round trip = %+v, want %+v", decoded, cur)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	inputs := map[string]string{
+		"empty":       "",
+		"not base64":  "!!!",
+		"padded":      base64.URLEncoding.EncodeToString([]byte(`{"s":"name","i":"p-1"}`)),
+		"not json":    encode("name:p-1"),
+		"missing id":  encode(`{"s":"name","o":"asc","v":"Budi"}`),
+		"wrong types": encode(`{"s":1,"i":"p-1"}`),
+	}
+	for name, in := range inputs {
+		if _, err := DecodeCursor(in); err != ErrInvalidCursor {
+			t.Errorf("%s: DecodeCursor(%q) = %v, want ErrInvalidCursor", name, in, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnv mengambil env, atau fallback jika kosong
+func GetEnv(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvBool mengambil env boolean ("true", "1", "yes"), atau fallback jika kosong/tidak valid
+func GetEnvBool(key string, fallback bool) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "true", "1", "yes", "on":
+		return true
+	case "false", "0", "no", "off":
+		return false
+	}
+	return fallback
+}
+
+// GetEnvInt mengambil env integer, atau fallback jika kosong/tidak valid
+func GetEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvDuration mengambil env durasi (format time.ParseDuration, mis. "48h"), atau fallback
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,25 @@
+package helpers
+
+import "strings"
+
+// Bahasa yang didukung untuk pesan ke pendaftar
+const (
+	LanguageID = "id"
+	LanguageEN = "en"
+)
+
+// PreferredLanguage memilih bahasa dari header Accept-Language (mis. "en-US,en;q=0.9,id;q=0.8").
+// Bahasa pertama yang didukung dipakai, default Bahasa Indonesia. Bobot q diabaikan karena
+// browser sudah mengurutkan sesuai preferensi.
+func PreferredLanguage(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch strings.SplitN(tag, "-", 2)[0] {
+		case LanguageID, "in":
+			return LanguageID
+		case LanguageEN:
+			return LanguageEN
+		}
+	}
+	return LanguageID
+}
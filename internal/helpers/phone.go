@@ -0,0 +1,102 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error validasi nomor HP Indonesia
+var (
+	ErrPhoneEmpty             = errors.New("nomor HP wajib diisi")
+	ErrPhoneInvalidCharacters = errors.New("nomor HP hanya boleh berisi angka, spasi, tanda +, -, titik atau kurung")
+	ErrPhoneNotIndonesian     = errors.New("nomor HP harus nomor Indonesia (+62)")
+	ErrPhoneInvalidPrefix     = errors.New("prefix operator nomor HP tidak valid")
+	ErrPhoneInvalidLength     = errors.New("panjang nomor HP tidak valid")
+)
+
+// indonesianMobilePrefixes adalah prefix operator seluler Indonesia (tanpa 0 / 62)
+var indonesianMobilePrefixes = map[string]bool{
+	// Telkomsel
+	"811": true, "812": true, "813": true, "821": true, "822": true, "823": true, "851": true, "852": true, "853": true,
+	// Indosat
+	"814": true, "815": true, "816": true, "855": true, "856": true, "857": true, "858": true,
+	// XL
+	"817": true, "818": true, "819": true, "859": true, "877": true, "878": true,
+	// Axis
+	"831": true, "832": true, "833": true, "838": true,
+	// Tri
+	"895": true, "896": true, "897": true, "898": true, "899": true,
+	// Smartfren
+	"881": true, "882": true, "883": true, "884": true, "885": true, "886": true, "887": true, "888": true, "889": true,
+	// Net1 / Ceria
+	"828": true,
+}
+
+// NormalizeIndonesianPhone memvalidasi nomor HP Indonesia dan mengubahnya ke format E.164 (+628xxxxxxxxx).
+// Input seperti "0812-3456-7890", "+62 812 3456 7890" dan "62812.3456.7890" menghasilkan nomor yang sama.
+func NormalizeIndonesianPhone(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ErrPhoneEmpty
+	}
+
+	var b strings.Builder
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+			// karakter format, dibuang
+		default:
+			return "", ErrPhoneInvalidCharacters
+		}
+	}
+	digits := b.String()
+
+	// Ambil nomor nasional (dimulai dari 8) dari berbagai format penulisan
+	var national string
+	switch {
+	case strings.HasPrefix(digits, "+62"):
+		national = digits[3:]
+	case strings.HasPrefix(digits, "+"):
+		return "", ErrPhoneNotIndonesian
+	case strings.HasPrefix(digits, "62"):
+		national = digits[2:]
+	case strings.HasPrefix(digits, "0"):
+		national = digits[1:]
+	default:
+		national = digits
+	}
+	// "+62 0812..." juga sering ditulis, buang 0 setelah kode negara
+	national = strings.TrimPrefix(national, "0")
+
+	if len(national) < 3 || !indonesianMobilePrefixes[national[:3]] {
+		return "", ErrPhoneInvalidPrefix
+	}
+	// Nomor seluler Indonesia: 9-12 digit setelah kode negara
+	if len(national) < 9 || len(national) > 12 {
+		return "", ErrPhoneInvalidLength
+	}
+	return "+62" + national, nil
+}
+
+// PhoneKey mengembalikan kunci pembanding nomor HP: hanya digit, tanpa prefix 62 atau 0.
+// "0812-3456-789", "+62 812 3456 789" dan "628123456789" menghasilkan kunci yang sama.
+func PhoneKey(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	key := b.String()
+	switch {
+	case strings.HasPrefix(key, "62"):
+		key = key[2:]
+	case strings.HasPrefix(key, "0"):
+		key = key[1:]
+	}
+	return key
+}
@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeIndonesianPhone(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"0812-3456-7890", "+6281234567890", nil},
+		{"+62 812 3456 7890", "+6281234567890", nil},
+		{"62812.3456.7890", "+6281234567890", nil},
+		{"(0812) 3456 7890", "+6281234567890", nil},
+		{"81234567890", "+6281234567890", nil},
+		{"+62 0812 3456 7890", "+6281234567890", nil}, // 0 setelah kode negara dibuang
+		{"  085712345678  ", "+6285712345678", nil},
+		{"0895123456", "+62895123456", nil},       // 9 digit, batas bawah
+		{"0888123456789", "+62888123456789", nil}, // 12 digit, batas atas
+		{"", "", ErrPhoneEmpty},
+		{"   ", "", ErrPhoneEmpty},
+		{"0812-3456-78a0", "", ErrPhoneInvalidCharacters},
+		{"0812+34567890", "", ErrPhoneInvalidCharacters}, // + hanya boleh di depan
+		{"+1 415 555 0100", "", ErrPhoneNotIndonesian},
+		{"+60 12 345 6789", "", ErrPhoneNotIndonesian},
+		{"021-5550123", "", ErrPhoneInvalidPrefix}, // telepon rumah
+		{"0800123456789", "", ErrPhoneInvalidPrefix},
+		{"08", "", ErrPhoneInvalidPrefix},
+		{"08123456", "", ErrPhoneInvalidLength},
+		{"081234567890123", "", ErrPhoneInvalidLength},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeIndonesianPhone(tt.in)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("NormalizeIndonesianPhone(%q) = %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestPhoneKey(t *testing.T) {
+	tests := map[string]string{
+		"0812-3456-789":    "8123456789",
+		"+62 812 3456 789": "8123456789",
+		"628123456789":     "8123456789",
+		"8123456789":       "8123456789",
+		"":                 "",
+	}
+	for in, want := range tests {
+		if got := PhoneKey(in); got != want {
+			t.Errorf("PhoneKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	// Nomor yang sudah dinormalisasi tetap punya kunci yang sama dengan input aslinya
+	for _, raw := range []string{"0812-3456-7890", "+62 812 3456 7890", "62812.3456.7890"} {
+		normalized, err := NormalizeIndonesianPhone(raw)
+		if err != nil {
+			t.Fatalf("NormalizeIndonesianPhone(%q): %v", raw, err)
+		}
+		if PhoneKey(normalized) != PhoneKey(raw) {
+			t.Errorf("PhoneKey(%q) = %q, PhoneKey(%q) = %q", normalized, PhoneKey(normalized), raw, PhoneKey(raw))
+		}
+	}
+}
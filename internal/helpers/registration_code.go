@@ -0,0 +1,55 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// registrationCodeAlphabet tanpa karakter yang mudah tertukar (0/O, 1/I/L) agar mudah dibacakan / diketik
+const registrationCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// registrationCodeLength adalah jumlah karakter acak kode registrasi (ditampilkan XXXX-XXXX)
+const registrationCodeLength = 8
+
+// GenerateRegistrationCode membuat kode registrasi acak format XXXX-XXXX (±39 bit, crypto/rand)
+func GenerateRegistrationCode() (string, error) {
+	b := make([]byte, registrationCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := make([]byte, 0, registrationCodeLength+1)
+	n := byte(len(registrationCodeAlphabet))
+	for i := 0; i < registrationCodeLength; i++ {
+		// Rejection sampling agar distribusi karakter tetap seragam
+		for int(b[i]) >= 256-256%int(n) {
+			if _, err := rand.Read(b[i : i+1]); err != nil {
+				return "", err
+			}
+		}
+		if i == registrationCodeLength/2 {
+			code = append(code, '-')
+		}
+		code = append(code, registrationCodeAlphabet[b[i]%n])
+	}
+	return string(code), nil
+}
+
+// NormalizeRegistrationCode merapikan kode yang diketik manual ("k7qm 3xpa", "K7QM3XPA") ke format XXXX-XXXX.
+// Mengembalikan string kosong jika bukan kode registrasi yang valid.
+func NormalizeRegistrationCode(raw string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(raw) {
+		if r == ' ' || r == '-' {
+			continue
+		}
+		if !strings.ContainsRune(registrationCodeAlphabet, r) {
+			return ""
+		}
+		b.WriteRune(r)
+	}
+	code := b.String()
+	if len(code) != registrationCodeLength {
+		return ""
+	}
+	return code[:registrationCodeLength/2] + "-" + code[registrationCodeLength/2:]
+}
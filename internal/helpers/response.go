@@ -51,3 +51,17 @@ func ResponseNotFound(c *gin.Context, message string) {
 func ResponseInternalServerError(c *gin.Context, message string) {
 	ResponseError(c, http.StatusInternalServerError, message)
 }
+
+// ResponseConflict untuk response data bentrok dengan data yang sudah ada
+func ResponseConflict(c *gin.Context, message string) {
+	ResponseError(c, http.StatusConflict, message)
+}
+
+// ResponseValidationError untuk response validation error dengan pesan per field
+func ResponseValidationError(c *gin.Context, message string, fields map[string]string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"success": false,
+		"error":   message,
+		"fields":  fields,
+	})
+}
@@ -1,17 +1,99 @@
 package httpapi
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"backend/internal/certificates"
 	"backend/internal/controllers"
+	"backend/internal/emails"
+	"backend/internal/mailer"
+	"backend/internal/messaging"
 	"backend/internal/middleware"
+	"backend/internal/scheduler"
+	"backend/internal/storage"
+	"backend/internal/webhooks"
 )
 
-func SetupRouter(engine *gin.Engine, database *gorm.DB) {
+// Workers adalah worker background yang dibuat SetupRouter. Scheduler dijalankan cmd/server setelah
+// server listen; semuanya dihentikan lewat Stop saat shutdown.
+type Workers struct {
+	Jobs     *scheduler.Scheduler
+	Webhooks *webhooks.Dispatcher
+	Messages *messaging.Service
+	Emails   *emails.Queue
+	// Certificates merender sertifikat dari generate massal
+	Certificates *certificates.Worker
+}
+
+// Stop menghentikan scheduler dan worker sertifikat lebih dulu (keduanya bisa masih mengantrikan
+// pesan / email), lalu worker pengiriman.
+// Pekerjaan yang terpotong dilanjutkan saat server start lagi.
+func (w *Workers) Stop(ctx context.Context) error {
+	var firstErr error
+	stops := []struct {
+		name string
+		stop func(context.Context) error
+	}{
+		{"scheduler", w.Jobs.Stop},
+		{"certificate", w.Certificates.Stop},
+		{"webhook", w.Webhooks.Stop},
+		{"messaging", w.Messages.Stop},
+		{"email", w.Emails.Stop},
+	}
+	for _, s := range stops {
+		if err := s.stop(ctx); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", s.name, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// SetupRouter mendaftarkan semua route dan menjalankan worker background. Worker dikembalikan
+// supaya dijalankan & dihentikan bersama lifecycle server di cmd/server.
+func SetupRouter(engine *gin.Engine, database *gorm.DB) *Workers {
 	// Initialize controllers
-	participantController := controllers.NewParticipantController(database)
+	mail := mailer.NewFromEnv()
+	store := storage.NewFromEnv()
+	// Webhook dikirim di background, delivery pending dilanjutkan saat server start
+	hooks := webhooks.NewDispatcher(database, webhooks.ConfigFromEnv())
+	hooks.Start()
+	// Antrian WhatsApp / SMS, pesan yang belum terkirim dilanjutkan saat server start
+	messages := controllers.NewMessagingService(database)
+	messages.Start()
+	// Outbox email transaksional, dikirim worker lewat MAILER_DRIVER (SMTP di production)
+	outbox := controllers.NewEmailQueue(database, mail)
+	outbox.Start()
+	// Render sertifikat, sertifikat pending yang tertinggal (mis. server restart di tengah generate
+	// massal) dilanjutkan saat server start
+	certs := controllers.NewCertificateWorker(database, store, outbox)
+	certs.Start()
+	// Job terjadwal (pengingat event, pembersihan data); Start / Stop oleh cmd/server
+	jobs := controllers.NewScheduler(database, messages, outbox)
+	participantController := controllers.NewParticipantController(database, mail, store, hooks, messages, outbox)
+	eventController := controllers.NewEventController(database, hooks, messages, outbox)
+	questionController := controllers.NewQuestionController(database)
+	fileController := controllers.NewFileController(database, store)
+	ticketController := controllers.NewTicketController(database)
+	checkInController := controllers.NewCheckInController(database, hooks)
+	sessionController := controllers.NewSessionController(database, hooks)
+	certificateController := controllers.NewCertificateController(database, store, certs)
+	statsController := controllers.NewStatsController(database, participantController)
+	masterDataController := controllers.NewMasterDataController(database)
+	portalController := controllers.NewPortalController(database, mail, messages, participantController, ticketController)
+	statusLookupController := controllers.NewStatusLookupController(database)
+	webhookController := controllers.NewWebhookController(database, hooks)
+	messageController := controllers.NewMessageController(database, messages, outbox, participantController)
+	emailController := controllers.NewEmailController(database, outbox, participantController)
+	jobController := controllers.NewJobController(database, jobs)
 	authController := controllers.NewAuthController(database)
+	registrationLimiter := controllers.RegistrationRateLimiter()
+	idempotency := middleware.IdempotencyFromEnv()
 
 	// Middleware global: set DB ke context agar bisa diakses di AuthMiddleware
 	engine.Use(func(c *gin.Context) {
@@ -19,6 +101,9 @@ func SetupRouter(engine *gin.Engine, database *gorm.DB) {
 		c.Next()
 	})
 
+	// Verifikasi sertifikat (tujuan QR yang dicetak di sertifikat, tidak perlu login)
+	engine.GET("/verify/:serial", certificateController.VerifyCertificate)
+
 	api := engine.Group("/api")
 	{
 		// Auth endpoints (tidak perlu login)
@@ -26,7 +111,56 @@ func SetupRouter(engine *gin.Engine, database *gorm.DB) {
 		api.POST("/refresh", authController.RefreshToken)
 
 		// Participants endpoints
-		api.POST("/participants", participantController.CreateParticipant) // Tidak perlu login untuk register
+		api.POST("/participants", middleware.RateLimit(registrationLimiter), idempotency, participantController.CreateParticipant) // Tidak perlu login untuk register
+		// form_token & konfigurasi anti-spam untuk form registrasi
+		api.GET("/registration-challenge", participantController.GetRegistrationChallenge)
+		api.GET("/participants/verify-email", participantController.VerifyEmail)
+		api.POST("/participants/:id/uploads", fileController.UploadFileWithToken) // Upload mandiri dengan upload_token
+
+		// Tiket pendaftar (PDF / QR PNG / QR SVG) lewat link bertanda tangan dari response registrasi
+		api.GET("/tickets/:code", ticketController.GetTicket)
+
+		// Download file lewat signed URL berumur pendek (dibuat dari endpoint admin files)
+		api.GET("/files/:id", fileController.DownloadFile)
+
+		// Autocomplete kampus & jurusan untuk form registrasi
+		api.GET("/campuses", masterDataController.SearchCampuses)
+		api.GET("/study-programs", masterDataController.SearchStudyPrograms)
+
+		// Cek status pendaftaran dengan kode registrasi + 4 digit terakhir HP (dibatasi per IP)
+		api.POST("/registrations/status", middleware.RateLimit(controllers.StatusLookupRateLimiter()), statusLookupController.LookupStatus)
+
+		// Portal pendaftar: login lewat magic link (dibatasi per IP), sesi terpisah dari JWT admin
+		api.POST("/portal/magic-link", middleware.RateLimit(controllers.MagicLinkRateLimiter()), portalController.RequestMagicLink)
+		api.POST("/portal/session", portalController.ExchangeMagicLink)
+		portal := api.Group("/portal")
+		portal.Use(middleware.ParticipantAuth())
+		{
+			portal.GET("/me", portalController.GetMe)
+			portal.PUT("/me", portalController.UpdateMe)
+			portal.PUT("/me/notifications", portalController.UpdateNotifications)
+			portal.POST("/me/withdraw", portalController.Withdraw)
+			portal.GET("/me/certificates/:serial", portalController.DownloadCertificate)
+			portal.GET("/me/ticket", portalController.GetTicket)
+			portal.POST("/logout", portalController.Logout)
+		}
+
+		// Callback WhatsApp Cloud API: status pesan & balasan STOP (diverifikasi dengan signature Meta)
+		api.GET("/messaging/whatsapp/webhook", messageController.VerifyWhatsAppWebhook)
+		api.POST("/messaging/whatsapp/webhook", messageController.WhatsAppWebhook)
+		// Laporan bounce dari server / provider email (header X-Mail-Bounce-Token)
+		api.POST("/mail/bounces", emailController.RecordBounce)
+
+		// Events endpoints (public): info event & registrasi per event
+		api.GET("/events", eventController.GetAllEvents)
+		publicEvent := api.Group("/events/:slug")
+		publicEvent.Use(middleware.EventScope())
+		{
+			publicEvent.GET("", eventController.GetEvent)
+			publicEvent.GET("/questions", questionController.GetQuestions)
+			publicEvent.GET("/sessions", sessionController.GetSessions)
+			publicEvent.POST("/participants", middleware.RateLimit(registrationLimiter), idempotency, participantController.CreateParticipant)
+		}
 
 		// Protected endpoints (perlu login)
 		protected := api.Group("/")
@@ -35,16 +169,150 @@ func SetupRouter(engine *gin.Engine, database *gorm.DB) {
 			// Auth endpoints yang perlu login
 			protected.POST("/logout", authController.Logout)
 
-			// Participants protected endpoints
-			protected.GET("/participants", participantController.GetAllParticipants)
-			protected.GET("/participants/count", participantController.CountParticipant)
-			protected.GET("/participants/:id", participantController.GetParticipant)
-			protected.PUT("/participants/:id", participantController.UpdateParticipant)
-			protected.DELETE("/participants/:id", participantController.DeleteParticipant)
+			// Participants protected endpoints (semua event)
+			registerParticipantRoutes(protected.Group("/participants"), participantController, fileController, ticketController, messageController, emailController)
+
+			// Data master kampus & jurusan
+			protected.POST("/campuses", idempotency, masterDataController.CreateCampus)
+			protected.PUT("/campuses/:id", masterDataController.UpdateCampus)
+			protected.DELETE("/campuses/:id", masterDataController.DeleteCampus)
+			protected.POST("/study-programs", idempotency, masterDataController.CreateStudyProgram)
+			protected.PUT("/study-programs/:id", masterDataController.UpdateStudyProgram)
+			protected.DELETE("/study-programs/:id", masterDataController.DeleteStudyProgram)
+			protected.GET("/master-data/unmapped/:field", masterDataController.GetUnmapped)
+			protected.POST("/master-data/map", masterDataController.MapValues)
+			protected.POST("/master-data/auto-map", masterDataController.AutoMapValues)
+
+			// Webhook untuk sistem luar (sistem kampus partner, Google Sheets)
+			protected.GET("/webhooks", webhookController.GetWebhooks)
+			protected.POST("/webhooks", idempotency, webhookController.CreateWebhook)
+			protected.GET("/webhooks/:id", webhookController.GetWebhook)
+			protected.PUT("/webhooks/:id", webhookController.UpdateWebhook)
+			protected.DELETE("/webhooks/:id", webhookController.DeleteWebhook)
+			protected.POST("/webhooks/:id/rotate-secret", webhookController.RotateWebhookSecret)
+			protected.POST("/webhooks/:id/ping", webhookController.PingWebhook)
+			protected.GET("/webhooks/:id/deliveries", webhookController.GetDeliveries)
+			protected.GET("/webhooks/:id/deliveries/:deliveryId", webhookController.GetDelivery)
+			protected.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", webhookController.RedeliverDelivery)
+
+			// Template pesan WhatsApp / SMS
+			protected.GET("/message-templates", messageController.GetTemplates)
+			protected.PUT("/message-templates/:key", messageController.UpdateTemplate)
+			protected.DELETE("/message-templates/:key", messageController.ResetTemplate)
+			protected.POST("/message-templates/:key/preview", messageController.PreviewTemplate)
+
+			// Template & outbox email transaksional
+			protected.GET("/email-templates", emailController.GetTemplates)
+			protected.PUT("/email-templates/:key/:lang", emailController.UpdateTemplate)
+			protected.DELETE("/email-templates/:key/:lang", emailController.ResetTemplate)
+			protected.POST("/email-templates/:key/:lang/preview", emailController.PreviewTemplate)
+			protected.GET("/emails", emailController.GetEmails)
+			protected.GET("/emails/:id", emailController.GetEmail)
+			protected.POST("/emails/:id/retry", emailController.RetryEmail)
+
+			// Job terjadwal & riwayat eksekusinya
+			protected.GET("/jobs", jobController.GetJobs)
+			protected.POST("/jobs", idempotency, jobController.CreateJob)
+			protected.GET("/jobs/:id", jobController.GetJob)
+			protected.PUT("/jobs/:id", jobController.UpdateJob)
+			protected.DELETE("/jobs/:id", jobController.DeleteJob)
+			protected.POST("/jobs/:id/pause", jobController.PauseJob)
+			protected.POST("/jobs/:id/resume", jobController.ResumeJob)
+			protected.POST("/jobs/:id/trigger", jobController.TriggerJob)
+			protected.GET("/jobs/:id/runs", jobController.GetJobRuns)
+
+			// Statistik dashboard (filter sama dengan list participant)
+			registerStatsRoutes(protected.Group("/stats"), statsController)
+
+			// Events protected endpoints
+			protected.POST("/events", idempotency, eventController.CreateEvent)
+			protectedEvent := protected.Group("/events/:slug")
+			protectedEvent.Use(middleware.EventScope())
+			{
+				protectedEvent.PUT("", eventController.UpdateEvent)
+				protectedEvent.DELETE("", eventController.DeleteEvent)
+				protectedEvent.POST("/eligibility/evaluate", eventController.EvaluateEligibility)
+				protectedEvent.POST("/reminders", messageController.SendEventReminder)
+				protectedEvent.GET("/jobs", jobController.GetJobs)
+				protectedEvent.POST("/jobs", idempotency, jobController.CreateJob)
+
+				// Pertanyaan registrasi tambahan per event
+				protectedEvent.POST("/questions", idempotency, questionController.CreateQuestion)
+				protectedEvent.PUT("/questions/:id", questionController.UpdateQuestion)
+				protectedEvent.DELETE("/questions/:id", questionController.DeleteQuestion)
+
+				// Check-in di lokasi (scan QR tiket / kode registrasi) dan kehadiran
+				protectedEvent.POST("/check-ins", idempotency, checkInController.CheckIn)
+				protectedEvent.GET("/check-ins", checkInController.GetCheckIns)
+				protectedEvent.POST("/check-ins/:id/undo", checkInController.UndoCheckIn)
+				protectedEvent.GET("/attendance", checkInController.GetAttendance)
+				protectedEvent.GET("/no-shows", checkInController.GetNoShows)
+
+				// Jadwal sesi dan kehadiran per sesi
+				protectedEvent.POST("/sessions", idempotency, sessionController.CreateSession)
+				protectedEvent.GET("/sessions/report", sessionController.GetSessionReport)
+				protectedEvent.PUT("/sessions/:id", sessionController.UpdateSession)
+				protectedEvent.DELETE("/sessions/:id", sessionController.DeleteSession)
+				protectedEvent.GET("/sessions/:id/attendance", sessionController.GetSessionAttendance)
+				protectedEvent.POST("/sessions/:id/attendance", sessionController.MarkSessionAttendance)
+				protectedEvent.DELETE("/sessions/:id/attendance/:participantId", sessionController.RemoveSessionAttendance)
+				protectedEvent.GET("/attendance/participants", sessionController.GetParticipantAttendanceReport)
+
+				// Sertifikat
+				protectedEvent.GET("/certificate-templates", certificateController.GetTemplates)
+				protectedEvent.POST("/certificate-templates", idempotency, certificateController.CreateTemplate)
+				protectedEvent.PUT("/certificate-templates/:id", certificateController.UpdateTemplate)
+				protectedEvent.DELETE("/certificate-templates/:id", certificateController.DeleteTemplate)
+				protectedEvent.PUT("/certificate-templates/:id/background", certificateController.UploadTemplateBackground)
+				protectedEvent.GET("/certificate-templates/:id/preview", certificateController.PreviewTemplate)
+				protectedEvent.GET("/certificate-templates/:id/eligible", certificateController.GetEligible)
+				protectedEvent.POST("/certificates/generate", idempotency, certificateController.GenerateCertificates)
+				protectedEvent.GET("/certificates", certificateController.GetCertificates)
+				protectedEvent.GET("/certificates/batches/:id", certificateController.GetBatch)
+				protectedEvent.GET("/certificates/:id/download", certificateController.DownloadCertificate)
+				protectedEvent.POST("/certificates/:id/regenerate", certificateController.RegenerateCertificate)
+				protectedEvent.POST("/certificates/:id/revoke", certificateController.RevokeCertificate)
+
+				// Participants protected endpoints, dibatasi ke satu event
+				registerParticipantRoutes(protectedEvent.Group("/participants"), participantController, fileController, ticketController, messageController, emailController)
+				registerStatsRoutes(protectedEvent.Group("/stats"), statsController)
+			}
 
 			// User endpoints
 			protected.GET("/users/:id", authController.GetUserById)
 			protected.GET("/admin/profile", authController.GetProfile)
 		}
 	}
+	return &Workers{Jobs: jobs, Webhooks: hooks, Messages: messages, Emails: outbox, Certificates: certs}
+}
+
+// registerParticipantRoutes mendaftarkan endpoint admin participant.
+// Dipakai untuk /api/participants dan /api/events/:slug/participants (scope event dari middleware.EventScope).
+func registerParticipantRoutes(group *gin.RouterGroup, participantController *controllers.ParticipantController, fileController *controllers.FileController, ticketController *controllers.TicketController, messageController *controllers.MessageController, emailController *controllers.EmailController) {
+	group.GET("", participantController.GetAllParticipants)
+	group.GET("/count", participantController.CountParticipant)
+	group.GET("/export", participantController.ExportParticipants)
+	group.GET("/duplicates", participantController.GetDuplicateReport)
+	group.POST("/duplicates/merge", participantController.MergeParticipants)
+	group.GET("/:id", participantController.GetParticipant)
+	group.PUT("/:id", participantController.UpdateParticipant)
+	group.DELETE("/:id", participantController.DeleteParticipant)
+	group.POST("/:id/resend-verification", participantController.ResendEmailVerification)
+	group.POST("/:id/status", participantController.ChangeStatus)
+	group.GET("/:id/status-history", participantController.GetStatusHistory)
+	group.GET("/:id/files", fileController.ListFiles)
+	group.POST("/:id/files", fileController.UploadFile)
+	group.DELETE("/:id/files/:fileId", fileController.DeleteFile)
+	group.GET("/:id/ticket", ticketController.GetParticipantTicket)
+	group.GET("/:id/messages", messageController.GetParticipantMessages)
+	group.GET("/:id/emails", emailController.GetParticipantEmails)
+	group.POST("/:id/opt-out", messageController.OptOutParticipant)
+	group.DELETE("/:id/opt-out", messageController.OptInParticipant)
+}
+
+// registerStatsRoutes mendaftarkan endpoint statistik untuk /api/stats dan /api/events/:slug/stats
+func registerStatsRoutes(group *gin.RouterGroup, statsController *controllers.StatsController) {
+	group.GET("/summary", statsController.GetSummary)
+	group.GET("/breakdown/:dimension", statsController.GetBreakdown)
+	group.GET("/registrations", statsController.GetRegistrations)
 }
@@ -0,0 +1,244 @@
+// Package fakesmtp adalah server SMTP minimal untuk development dan test: email yang diterima
+// disimpan di memori (dan opsional ditulis sebagai .eml), tanpa pernah diteruskan ke penerima.
+// Alamat penerima tertentu bisa ditolak untuk mensimulasikan bounce atau gangguan sementara.
+package fakesmtp
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Received adalah satu email yang diterima server
+type Received struct {
+	From       string
+	To         []string
+	Data       []byte
+	ReceivedAt time.Time
+}
+
+// Server adalah fake SMTP server. Set field konfigurasi sebelum Start.
+type Server struct {
+	// Dir (opsional) folder untuk menulis setiap email yang diterima sebagai .eml
+	Dir string
+	// Reject berisi alamat penerima (lowercase) yang ditolak permanen dengan 550 saat RCPT TO
+	Reject map[string]bool
+	// TempFail berisi alamat penerima (lowercase) yang ditolak sementara dengan 451 saat RCPT TO
+	TempFail map[string]bool
+	// Logf (opsional) untuk mencatat percakapan SMTP
+	Logf func(format string, args ...interface{})
+
+	listener net.Listener
+	mu       sync.Mutex
+	received []Received
+	conns    map[net.Conn]bool
+	wg       sync.WaitGroup
+}
+
+// Start mendengarkan di addr (mis. "127.0.0.1:0" untuk port acak) dan melayani koneksi di background
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.wg.Add(1)
+	go s.serve()
+	return nil
+}
+
+// Addr adalah alamat yang didengarkan server (host:port)
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close menghentikan server, memutus koneksi yang masih terbuka dan menunggu semuanya selesai
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// Messages mengembalikan salinan semua email yang sudah diterima
+func (s *Server) Messages() []Received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Received(nil), s.received...)
+}
+
+// Reset menghapus email yang sudah diterima
+func (s *Server) Reset() {
+	s.mu.Lock()
+	s.received = nil
+	s.mu.Unlock()
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.conns == nil {
+			s.conns = map[net.Conn]bool{}
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// handle melayani satu sesi SMTP: EHLO/HELO, MAIL, RCPT, DATA, RSET, NOOP, QUIT
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		s.logf("S: %s", line)
+		fmt.Fprintf(conn, "%s\r\n", line)
+	}
+
+	var from string
+	var to []string
+	reply("220 fakesmtp ready")
+	for {
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Minute))
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		s.logf("C: %s", line)
+		parts := strings.SplitN(line, " ", 2)
+		verb, arg := strings.ToUpper(parts[0]), ""
+		if len(parts) == 2 {
+			arg = strings.TrimSpace(parts[1])
+		}
+
+		switch verb {
+		case "EHLO":
+			reply("250-fakesmtp")
+			reply("250-8BITMIME")
+			reply("250 AUTH PLAIN")
+		case "HELO":
+			reply("250 fakesmtp")
+		case "AUTH":
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			from = extractAddress(arg)
+			to = nil
+			reply("250 2.1.0 OK")
+		case "RCPT":
+			addr := extractAddress(arg)
+			switch {
+			case s.Reject[strings.ToLower(addr)]:
+				reply("550 5.1.1 " + addr + ": user unknown")
+			case s.TempFail[strings.ToLower(addr)]:
+				reply("451 4.3.0 " + addr + ": temporarily unavailable")
+			default:
+				to = append(to, addr)
+				reply("250 2.1.5 OK")
+			}
+		case "DATA":
+			if len(to) == 0 {
+				reply("503 5.5.1 RCPT first")
+				continue
+			}
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readData(r)
+			if err != nil {
+				return
+			}
+			s.store(Received{From: from, To: to, Data: data, ReceivedAt: time.Now()})
+			from, to = "", nil
+			reply("250 2.0.0 OK queued")
+		case "RSET":
+			from, to = "", nil
+			reply("250 2.0.0 OK")
+		case "NOOP":
+			reply("250 2.0.0 OK")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Command not implemented")
+		}
+	}
+}
+
+// store menyimpan email di memori dan (jika Dir diisi) sebagai file .eml
+func (s *Server) store(msg Received) {
+	s.mu.Lock()
+	s.received = append(s.received, msg)
+	n := len(s.received)
+	s.mu.Unlock()
+
+	if s.Dir == "" {
+		return
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		log.Printf("fakesmtp: %v", err)
+		return
+	}
+	name := fmt.Sprintf("%s-%04d.eml", msg.ReceivedAt.Format("20060102T150405"), n)
+	if err := os.WriteFile(filepath.Join(s.Dir, name), msg.Data, 0o644); err != nil {
+		log.Printf("fakesmtp: %v", err)
+	}
+}
+
+// readData membaca isi DATA sampai baris "." dan membatalkan dot-stuffing
+func readData(r *bufio.Reader) ([]byte, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+		if trimmed == "." {
+			return []byte(b.String()), nil
+		}
+		if strings.HasPrefix(trimmed, "..") {
+			trimmed = trimmed[1:]
+		}
+		b.WriteString(trimmed + "\r\n")
+	}
+}
+
+// extractAddress mengambil alamat dari argumen "FROM:<a@b>" / "TO:<a@b>"
+func extractAddress(arg string) string {
+	if i := strings.Index(arg, "<"); i >= 0 {
+		if j := strings.Index(arg[i:], ">"); j > 0 {
+			return arg[i+1 : i+j]
+		}
+	}
+	if i := strings.Index(arg, ":"); i >= 0 {
+		return strings.TrimSpace(arg[i+1:])
+	}
+	return arg
+}
@@ -0,0 +1,116 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message adalah email yang akan dikirim
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// MessageID (opsional) dipakai sebagai header Message-ID, mis. untuk mencocokkan laporan bounce
+	MessageID string
+}
+
+// Mailer mengirim email. Implementasi bisa diganti (file outbox untuk lokal, SMTP, dll).
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewFromEnv membuat mailer sesuai env MAILER_DRIVER: file (default), log atau smtp
+func NewFromEnv() Mailer {
+	driver := strings.ToLower(os.Getenv("MAILER_DRIVER"))
+	switch driver {
+	case "smtp":
+		return SMTPFromEnv(getFrom())
+	case "log":
+		return LogMailer{From: getFrom()}
+	default:
+		dir := os.Getenv("MAIL_OUTBOX_DIR")
+		if dir == "" {
+			dir = filepath.Join(".", "data", "outbox")
+		}
+		return &FileMailer{Dir: dir, From: getFrom()}
+	}
+}
+
+func getFrom() string {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "Youth College <no-reply@youthcollege.local>"
+	}
+	return from
+}
+
+// FileMailer menulis setiap email sebagai file .eml di folder outbox (untuk development & test)
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// Send menulis email ke file <timestamp>-<uuid>.eml
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return fmt.Errorf("create outbox dir: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), uuid.New().String())
+	return os.WriteFile(filepath.Join(m.Dir, name), Render(m.From, msg), 0o644)
+}
+
+// LogMailer hanya mencatat email ke log
+type LogMailer struct {
+	From string
+}
+
+// Send mencatat email ke log
+func (m LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("MAIL from=%q to=%q subject=%q\n%s", m.From, msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+// Render membuat isi email format RFC 5322 (multipart/alternative jika ada HTML)
+func Render(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	if msg.MessageID != "" {
+		b.WriteString("Message-ID: <" + msg.MessageID + ">\r\n")
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	if msg.HTML == "" {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		b.WriteString(msg.Text)
+		return []byte(b.String())
+	}
+	boundary := "yc-" + uuid.New().String()
+	b.WriteString("Content-Type: multipart/alternative; boundary=\"" + boundary + "\"\r\n\r\n")
+	b.WriteString("--" + boundary + "\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n" + msg.Text + "\r\n")
+	b.WriteString("--" + boundary + "\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n" + msg.HTML + "\r\n")
+	b.WriteString("--" + boundary + "--\r\n")
+	return []byte(b.String())
+}
+
+// NewMessageID membuat nilai Message-ID unik (tanpa kurung sudut) dengan domain dari MAIL_FROM
+func NewMessageID() string {
+	domain := "youthcollege.local"
+	if addr, err := mail.ParseAddress(getFrom()); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+	return uuid.New().String() + "@" + domain
+}
@@ -0,0 +1,174 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/helpers"
+)
+
+// Mode TLS koneksi SMTP
+const (
+	TLSNone     = "none"     // tanpa enkripsi (hanya untuk server lokal / fake SMTP)
+	TLSStartTLS = "starttls" // STARTTLS jika server mendukung (port 587)
+	TLSImplicit = "tls"      // TLS sejak awal koneksi (port 465)
+)
+
+// SMTPMailer mengirim email lewat server SMTP
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      string
+	Timeout  time.Duration
+}
+
+// SMTPFromEnv membuat SMTPMailer dari SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, SMTP_TLS dan SMTP_TIMEOUT
+func SMTPFromEnv(from string) *SMTPMailer {
+	return &SMTPMailer{
+		Host:     helpers.GetEnv("SMTP_HOST", "localhost"),
+		Port:     helpers.GetEnvInt("SMTP_PORT", 587),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+		TLS:      strings.ToLower(helpers.GetEnv("SMTP_TLS", TLSStartTLS)),
+		Timeout:  helpers.GetEnvDuration("SMTP_TIMEOUT", 30*time.Second),
+	}
+}
+
+// SMTPError adalah balasan error dari server SMTP beserta tahap yang gagal
+type SMTPError struct {
+	Stage string // connect, hello, starttls, auth, mail, rcpt, data
+	Code  int
+	Msg   string
+}
+
+func (e *SMTPError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("smtp %s: %s", e.Stage, e.Msg)
+	}
+	return fmt.Sprintf("smtp %s: %d %s", e.Stage, e.Code, e.Msg)
+}
+
+// Permanent mengecek apakah server menolak secara permanen (kode 5xx); percobaan ulang tidak akan berhasil
+func (e *SMTPError) Permanent() bool { return e.Code >= 500 && e.Code < 600 }
+
+// Bounced mengecek apakah alamat penerima ditolak permanen oleh server (kode 5xx saat RCPT TO)
+func (e *SMTPError) Bounced() bool { return e.Permanent() && e.Stage == "rcpt" }
+
+// AsSMTPError mengambil SMTPError dari err (nil jika bukan error SMTP)
+func AsSMTPError(err error) *SMTPError {
+	var smtpErr *SMTPError
+	if errors.As(err, &smtpErr) {
+		return smtpErr
+	}
+	return nil
+}
+
+// smtpStageError membungkus error dari net/smtp dengan kode balasan jika ada
+func smtpStageError(stage string, err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return &SMTPError{Stage: stage, Code: protoErr.Code, Msg: protoErr.Msg}
+	}
+	return &SMTPError{Stage: stage, Msg: err.Error()}
+}
+
+// Send mengirim satu email. Error berupa *SMTPError sehingga pemanggil bisa membedakan
+// penolakan permanen (bounce) dan gangguan sementara.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("MAIL_FROM tidak valid: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return &SMTPError{Stage: "rcpt", Code: 553, Msg: "alamat penerima tidak valid: " + msg.To}
+	}
+
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	if m.TLS == TLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: m.Host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return &SMTPError{Stage: "connect", Msg: err.Error()}
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return smtpStageError("connect", err)
+	}
+	defer client.Close()
+
+	if err := client.Hello(helloName()); err != nil {
+		return smtpStageError("hello", err)
+	}
+	if m.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+				return smtpStageError("starttls", err)
+			}
+		}
+	}
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return smtpStageError("auth", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return smtpStageError("mail", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return smtpStageError("rcpt", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return smtpStageError("data", err)
+	}
+	if _, err := w.Write(Render(m.From, msg)); err != nil {
+		return smtpStageError("data", err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpStageError("data", err)
+	}
+	// Email sudah diterima server; error saat QUIT tidak membuat pengiriman gagal
+	_ = client.Quit()
+	return nil
+}
+
+// helloName adalah nama host yang dikirim saat EHLO (SMTP_HELO_NAME, default hostname mesin)
+func helloName() string {
+	if name := os.Getenv("SMTP_HELO_NAME"); name != "" {
+		return name
+	}
+	if name, err := os.Hostname(); err == nil && name != "" {
+		return name
+	}
+	return "localhost"
+}
@@ -0,0 +1,299 @@
+package masterdata
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+)
+
+var (
+	ErrUnknownField = errors.New("field harus kampus atau jurusan")
+	ErrUnknownEntry = errors.New("data master tidak ditemukan")
+)
+
+// Field menghubungkan kolom teks bebas participant dengan tabel data masternya
+type Field struct {
+	// Name adalah nama field di participant / API (kampus, jurusan)
+	Name string
+	// Column adalah kolom teks di tabel participants, IDColumn kolom relasinya
+	Column   string
+	IDColumn string
+	// Table adalah tabel data master
+	Table string
+}
+
+// Fields adalah field participant yang punya data master
+var Fields = map[string]Field{
+	"kampus":  {Name: "kampus", Column: "kampus", IDColumn: "campus_id", Table: "campuses"},
+	"jurusan": {Name: "jurusan", Column: "jurusan", IDColumn: "study_program_id", Table: "study_programs"},
+}
+
+// Kampus dan Jurusan untuk dipakai langsung tanpa lookup map
+var (
+	Kampus  = Fields["kampus"]
+	Jurusan = Fields["jurusan"]
+)
+
+// Entry adalah satu baris data master (kampus atau jurusan) dalam bentuk yang sama
+type Entry struct {
+	ID        uint     `json:"id"`
+	Name      string   `json:"name"`
+	ShortName string   `json:"short_name,omitempty"`
+	City      string   `json:"city,omitempty"`
+	Aliases   []string `json:"-"`
+}
+
+// keys mengembalikan semua penulisan entry yang sudah dinormalisasi
+func (e Entry) keys() []string {
+	keys := []string{Normalize(e.Name)}
+	if e.ShortName != "" {
+		keys = append(keys, Normalize(e.ShortName))
+	}
+	for _, a := range e.Aliases {
+		keys = append(keys, Normalize(a))
+	}
+	return keys
+}
+
+// abbreviations adalah singkatan umum yang disamakan saat pencocokan
+var abbreviations = map[string]string{
+	"univ":   "universitas",
+	"inst":   "institut",
+	"poltek": "politeknik",
+	"pend":   "pendidikan",
+	"uin":    "universitas islam negeri",
+}
+
+// Normalize menyamakan penulisan untuk pencocokan: huruf kecil, tanpa tanda baca,
+// singkatan umum diperpanjang ("Univ. Indonesia" -> "universitas indonesia")
+func Normalize(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		if full, ok := abbreviations[f]; ok {
+			fields[i] = full
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// Load mengambil semua entry data master field
+func Load(db *gorm.DB, field Field) ([]Entry, error) {
+	var entries []Entry
+	switch field.Name {
+	case Kampus.Name:
+		var campuses []models.Campus
+		if err := db.Order("name asc").Find(&campuses).Error; err != nil {
+			return nil, err
+		}
+		for _, c := range campuses {
+			entries = append(entries, Entry{ID: c.ID, Name: c.Name, ShortName: c.ShortName, City: c.City, Aliases: c.Aliases})
+		}
+	case Jurusan.Name:
+		var programs []models.StudyProgram
+		if err := db.Order("name asc").Find(&programs).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range programs {
+			entries = append(entries, Entry{ID: p.ID, Name: p.Name, Aliases: p.Aliases})
+		}
+	default:
+		return nil, ErrUnknownField
+	}
+	return entries, nil
+}
+
+// Find mengambil satu entry berdasarkan ID (ErrUnknownEntry jika tidak ada)
+func Find(db *gorm.DB, field Field, id uint) (*Entry, error) {
+	entries, err := Load(db, field)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, ErrUnknownEntry
+}
+
+// Search mencari entry untuk autocomplete: nama, singkatan atau alias yang mengandung q.
+// Entry yang diawali q ditampilkan lebih dulu.
+func Search(db *gorm.DB, field Field, q string, limit int) ([]Entry, error) {
+	entries, err := Load(db, field)
+	if err != nil {
+		return nil, err
+	}
+	key := Normalize(q)
+	type ranked struct {
+		entry Entry
+		rank  int
+	}
+	var found []ranked
+	for _, e := range entries {
+		rank := -1
+		for _, k := range e.keys() {
+			switch {
+			case key == "" || strings.HasPrefix(k, key):
+				rank = 0
+			case rank < 0 && strings.Contains(k, key):
+				rank = 1
+			}
+			if rank == 0 {
+				break
+			}
+		}
+		if rank >= 0 {
+			found = append(found, ranked{e, rank})
+		}
+	}
+	// entries sudah urut nama, sort stabil menjaga urutan itu di dalam rank yang sama
+	sort.SliceStable(found, func(i, j int) bool { return found[i].rank < found[j].rank })
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	results := make([]Entry, len(found))
+	for i, f := range found {
+		results[i] = f.entry
+	}
+	return results, nil
+}
+
+// Match mencari entry yang penulisannya sama persis (setelah Normalize) dengan teks.
+// Jika cocok ke lebih dari satu entry hasilnya nil, supaya tidak salah tautkan.
+func Match(entries []Entry, text string) *Entry {
+	key := Normalize(text)
+	if key == "" {
+		return nil
+	}
+	var found *Entry
+	for i := range entries {
+		for _, k := range entries[i].keys() {
+			if k != key {
+				continue
+			}
+			if found != nil && found.ID != entries[i].ID {
+				return nil
+			}
+			found = &entries[i]
+			break
+		}
+	}
+	return found
+}
+
+// Resolve menentukan relasi data master dan teks yang disimpan di participant.
+// ID yang dikirim harus ada (ErrUnknownEntry); tanpa ID, teks bebas dicocokkan otomatis,
+// dan jika tidak ada yang cocok disimpan apa adanya sebagai "lainnya".
+func Resolve(db *gorm.DB, field Field, id *uint, text string) (*uint, string, error) {
+	text = strings.TrimSpace(text)
+	if id != nil {
+		var names []string
+		if err := db.Table(field.Table).Where("id = ?", *id).Pluck("name", &names).Error; err != nil {
+			return nil, "", err
+		}
+		if len(names) == 0 {
+			return nil, "", ErrUnknownEntry
+		}
+		return id, names[0], nil
+	}
+	entries, err := Load(db, field)
+	if err != nil {
+		return nil, "", err
+	}
+	if entry := Match(entries, text); entry != nil {
+		entryID := entry.ID
+		return &entryID, entry.Name, nil
+	}
+	return nil, text, nil
+}
+
+// UnmappedValue adalah teks bebas participant yang belum tertaut ke data master
+type UnmappedValue struct {
+	Value string `json:"value"`
+	Total int64  `json:"total"`
+	// Suggestion adalah entry yang cocok otomatis (nil jika tidak ada / ambigu)
+	Suggestion *Entry `json:"suggestion" gorm:"-"`
+}
+
+// Unmapped mengelompokkan teks bebas yang belum tertaut (tanpa beda huruf besar/kecil), terbanyak dulu
+func Unmapped(db *gorm.DB, field Field) ([]UnmappedValue, error) {
+	rows := []UnmappedValue{}
+	if err := db.Model(&models.Participant{}).
+		Select("MIN(TRIM(" + field.Column + ")) AS value, COUNT(*) AS total").
+		Where(field.IDColumn + " IS NULL AND TRIM(" + field.Column + ") <> ''").
+		Group("LOWER(TRIM(" + field.Column + "))").
+		Order("total DESC, value ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	entries, err := Load(db, field)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].Suggestion = Match(entries, rows[i].Value)
+	}
+	return rows, nil
+}
+
+// Apply menautkan participant dengan teks bebas values (tanpa beda huruf besar/kecil) ke entry,
+// teks participant diganti nama kanonik. Mengembalikan jumlah participant yang diubah.
+func Apply(db *gorm.DB, field Field, entry Entry, values []string) (int64, error) {
+	lowered := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			lowered = append(lowered, v)
+		}
+	}
+	if len(lowered) == 0 {
+		return 0, nil
+	}
+	result := db.Model(&models.Participant{}).
+		Where(field.IDColumn+" IS NULL AND LOWER(TRIM("+field.Column+")) IN ?", lowered).
+		UpdateColumns(map[string]interface{}{field.IDColumn: entry.ID, field.Column: entry.Name})
+	return result.RowsAffected, result.Error
+}
+
+// AddAliases menambahkan values sebagai alias entry supaya pendaftaran berikutnya tertaut otomatis
+func AddAliases(db *gorm.DB, field Field, id uint, values []string) error {
+	switch field.Name {
+	case Kampus.Name:
+		var campus models.Campus
+		if err := db.First(&campus, id).Error; err != nil {
+			return err
+		}
+		campus.Aliases = mergeAliases(campus.Aliases, campus.Name, values)
+		return db.Save(&campus).Error
+	case Jurusan.Name:
+		var program models.StudyProgram
+		if err := db.First(&program, id).Error; err != nil {
+			return err
+		}
+		program.Aliases = mergeAliases(program.Aliases, program.Name, values)
+		return db.Save(&program).Error
+	}
+	return ErrUnknownField
+}
+
+// mergeAliases menambahkan values yang penulisannya belum ada di nama / alias
+func mergeAliases(aliases []string, name string, values []string) []string {
+	seen := map[string]bool{Normalize(name): true}
+	for _, a := range aliases {
+		seen[Normalize(a)] = true
+	}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if key := Normalize(v); key != "" && !seen[key] {
+			seen[key] = true
+			aliases = append(aliases, v)
+		}
+	}
+	return aliases
+}
@@ -0,0 +1,183 @@
+package masterdata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// newTestDB menyimpan kampus UI, UGM dan UIN Jakarta serta jurusan Teknik Informatika
+func newTestDB(t *testing.T) *gorm.DB {
+	db := testutil.OpenDB(t, &models.Participant{}, &models.Campus{}, &models.StudyProgram{})
+	for _, c := range []models.Campus{
+		{Name: "Universitas Indonesia", ShortName: "UI", City: "Depok", Aliases: []string{"Univ Indonesia"}},
+		{Name: "Universitas Gadjah Mada", ShortName: "UGM", City: "Yogyakarta", Aliases: []string{}},
+		{Name: "UIN Syarif Hidayatullah Jakarta", ShortName: "UIN Jakarta", City: "Jakarta", Aliases: []string{}},
+	} {
+		if err := db.Create(&c).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Create(&models.StudyProgram{Name: "Teknik Informatika", Aliases: []string{"TI", "Informatika"}})
+	return db
+}
+
+func entryNames(entries []Entry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+	return names
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"Univ. Indonesia":          "universitas indonesia",
+		"  UNIVERSITAS  Indonesia": "universitas indonesia",
+		"Poltek Negeri-Jakarta":    "politeknik negeri jakarta",
+		"UIN Jakarta":              "universitas islam negeri jakarta",
+		"S1 Pend. Matematika":      "s1 pendidikan matematika",
+		"...":                      "",
+	} {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	entries := []Entry{
+		{ID: 1, Name: "Universitas Indonesia", ShortName: "UI"},
+		{ID: 2, Name: "Institut Teknologi Bandung", ShortName: "ITB", Aliases: []string{"Inst. Tek. Bandung"}},
+		{ID: 3, Name: "Universitas Negeri Jakarta", ShortName: "UNJ", Aliases: []string{"IKIP Jakarta"}},
+		{ID: 4, Name: "Universitas Muhammadiyah Jakarta", ShortName: "UMJ", Aliases: []string{"IKIP Jakarta"}},
+	}
+	for text, want := range map[string]uint{
+		"univ. indonesia":    1, // singkatan diperpanjang
+		"ui":                 1, // nama singkat
+		"Inst Tek Bandung":   2, // alias
+		"Universitas":        0, // hanya sebagian nama
+		"IKIP Jakarta":       0, // alias dipakai dua entry: tidak ditautkan
+		"":                   0,
+		"Universitas Gadjah": 0,
+	} {
+		got := Match(entries, text)
+		if (got == nil && want != 0) || (got != nil && got.ID != want) {
+			t.Errorf("Match(%q) = %+v, want ID %d", text, got, want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	db := newTestDB(t)
+	ui := uint(1)
+
+	// ID dari autocomplete menang atas teks, nama disimpan dalam bentuk kanonik
+	id, name, err := Resolve(db, Kampus, &ui, "kampus saya")
+	if err != nil || id == nil || *id != ui || name != "Universitas Indonesia" {
+		t.Errorf("Resolve(id) = %v, %q, %v", id, name, err)
+	}
+	unknown := uint(99)
+	if _, _, err := Resolve(db, Kampus, &unknown, "UI"); !errors.Is(err, ErrUnknownEntry) {
+		t.Errorf("unknown id: err = %v", err)
+	}
+
+	// Tanpa ID teks dicocokkan otomatis; tidak cocok disimpan apa adanya sebagai "lainnya"
+	id, name, err = Resolve(db, Kampus, nil, " ugm ")
+	if err != nil || id == nil || name != "Universitas Gadjah Mada" {
+		t.Errorf("Resolve(ugm) = %v, %q, %v", id, name, err)
+	}
+	id, name, err = Resolve(db, Kampus, nil, "  Universitas Terbuka ")
+	if err != nil || id != nil || name != "Universitas Terbuka" {
+		t.Errorf("Resolve(free text) = %v, %q, %v", id, name, err)
+	}
+	id, name, err = Resolve(db, Jurusan, nil, "informatika")
+	if err != nil || id == nil || name != "Teknik Informatika" {
+		t.Errorf("Resolve(jurusan alias) = %v, %q, %v", id, name, err)
+	}
+	if _, err := Load(db, Field{Name: "fakultas"}); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Load(unknown field) = %v", err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	db := newTestDB(t)
+	for q, want := range map[string][]string{
+		"":     {"UIN Syarif Hidayatullah Jakarta", "Universitas Gadjah Mada"},
+		"u":    {"UIN Syarif Hidayatullah Jakarta", "Universitas Gadjah Mada"},
+		"jak":  {"UIN Syarif Hidayatullah Jakarta"},
+		"mada": {"Universitas Gadjah Mada"},
+		// "Univ" diperpanjang jadi "universitas": UIN Jakarta (diawali "universitas islam negeri") ikut cocok
+		"univ": {"UIN Syarif Hidayatullah Jakarta", "Universitas Gadjah Mada"},
+		"xyz":  {},
+	} {
+		got, err := Search(db, Kampus, q, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names := entryNames(got); !reflect.DeepEqual(names, want) {
+			t.Errorf("Search(%q) = %v, want %v", q, names, want)
+		}
+	}
+	// Entry yang diawali q lebih dulu dari entry yang hanya mengandung q
+	got, _ := Search(db, Kampus, "indonesia", 10)
+	if names := entryNames(got); !reflect.DeepEqual(names, []string{"Universitas Indonesia"}) {
+		t.Errorf("Search(indonesia) = %v", names)
+	}
+}
+
+func TestUnmappedApplyAndAliases(t *testing.T) {
+	db := newTestDB(t)
+	for _, kampus := range []string{"Univ. Gadjah Mada", "univ. gadjah mada ", "Kampus Merdeka", "Kampus Merdeka", "Kampus Merdeka"} {
+		testutil.CreateParticipant(t, db, "Budi", func(p *models.Participant) { p.Kampus = kampus })
+	}
+	ui := uint(1)
+	testutil.CreateParticipant(t, db, "Citra", func(p *models.Participant) {
+		p.Kampus = "Univ. Gadjah Mada"
+		p.CampusID = &ui // sudah tertaut, tidak ikut dihitung
+	})
+
+	values, err := Unmapped(db, Kampus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[0].Value != "Kampus Merdeka" || values[0].Total != 3 || values[0].Suggestion != nil {
+		t.Fatalf("values = %+v", values)
+	}
+	if values[1].Total != 2 || values[1].Suggestion == nil || values[1].Suggestion.Name != "Universitas Gadjah Mada" {
+		t.Fatalf("values[1] = %+v", values[1])
+	}
+
+	// Apply menautkan tanpa beda huruf besar/kecil dan spasi, teks diganti nama kanonik
+	ugm, _ := Find(db, Kampus, values[1].Suggestion.ID)
+	n, err := Apply(db, Kampus, *ugm, []string{"UNIV. GADJAH MADA", " "})
+	if err != nil || n != 2 {
+		t.Fatalf("Apply = %d, %v", n, err)
+	}
+	var linked int64
+	db.Model(&models.Participant{}).Where("campus_id = ? AND kampus = ?", ugm.ID, ugm.Name).Count(&linked)
+	if linked != 2 {
+		t.Errorf("linked = %d, want 2", linked)
+	}
+
+	// Alias baru membuat teks yang sama tertaut otomatis; penulisan yang sudah ada tidak digandakan
+	if err := AddAliases(db, Kampus, ugm.ID, []string{"Kampus Merdeka", "kampus merdeka!", "Univ Gadjah Mada", "UNIVERSITAS GADJAH MADA"}); err != nil {
+		t.Fatal(err)
+	}
+	var campus models.Campus
+	db.First(&campus, ugm.ID)
+	if !reflect.DeepEqual(campus.Aliases, []string{"Kampus Merdeka"}) {
+		t.Errorf("aliases = %v", campus.Aliases)
+	}
+	if id, _, _ := Resolve(db, Kampus, nil, "KAMPUS MERDEKA"); id == nil || *id != ugm.ID {
+		t.Errorf("Resolve after alias = %v", id)
+	}
+	if _, err := Find(db, Kampus, 99); !errors.Is(err, ErrUnknownEntry) {
+		t.Errorf("Find(99) = %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// Message adalah satu pesan WhatsApp / SMS yang diserahkan ke provider
+type Message struct {
+	Channel string
+	To      string // E.164, mis. +6281234567890
+	Body    string
+	// Template & Language & Params diisi jika pesan WhatsApp harus memakai template yang disetujui Meta
+	Template string
+	Language string
+	Params   []string
+}
+
+// Provider mengirim pesan ke satu channel. Implementasi bisa diganti (WhatsApp Business API,
+// SMS gateway, file / log untuk development & test). Mengembalikan id pesan dari provider.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, msg Message) (string, error)
+}
+
+// PermanentError menandai kegagalan yang tidak akan berhasil walaupun dicoba ulang
+// (mis. nomor tidak terdaftar di WhatsApp, kredensial salah)
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent membungkus err sebagai PermanentError
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent mengecek apakah err tidak perlu dicoba ulang
+func IsPermanent(err error) bool {
+	var p *PermanentError
+	return errors.As(err, &p)
+}
+
+// NewProviderFromEnv membuat provider untuk channel sesuai env WHATSAPP_PROVIDER / SMS_PROVIDER
+// (cloud / http, file atau log; default file). none = channel tidak dipakai.
+func NewProviderFromEnv(channel string) Provider {
+	outbox := helpers.GetEnv("MESSAGING_OUTBOX_DIR", filepath.Join(".", "data", "messages"))
+	switch channel {
+	case models.ChannelWhatsApp:
+		switch strings.ToLower(helpers.GetEnv("WHATSAPP_PROVIDER", "file")) {
+		case "cloud":
+			return &WhatsAppProvider{
+				BaseURL:       strings.TrimRight(helpers.GetEnv("WHATSAPP_API_URL", "https://graph.facebook.com/v19.0"), "/"),
+				PhoneNumberID: os.Getenv("WHATSAPP_PHONE_NUMBER_ID"),
+				AccessToken:   os.Getenv("WHATSAPP_ACCESS_TOKEN"),
+			}
+		case "log":
+			return LogProvider{Channel: channel}
+		case "none":
+			return nil
+		default:
+			return &FileProvider{Dir: outbox, Channel: channel}
+		}
+	case models.ChannelSMS:
+		switch strings.ToLower(helpers.GetEnv("SMS_PROVIDER", "file")) {
+		case "http":
+			return &HTTPSMSProvider{
+				URL:      os.Getenv("SMS_GATEWAY_URL"),
+				APIKey:   os.Getenv("SMS_API_KEY"),
+				SenderID: helpers.GetEnv("SMS_SENDER_ID", "YouthCollege"),
+			}
+		case "log":
+			return LogProvider{Channel: channel}
+		case "none":
+			return nil
+		default:
+			return &FileProvider{Dir: outbox, Channel: channel}
+		}
+	}
+	return nil
+}
+
+// FileProvider menulis setiap pesan sebagai file JSON di folder outbox (untuk development & test)
+type FileProvider struct {
+	Dir     string
+	Channel string
+}
+
+func (p *FileProvider) Name() string { return "file" }
+
+// Send menulis pesan ke file <timestamp>-<channel>-<uuid>.json
+func (p *FileProvider) Send(ctx context.Context, msg Message) (string, error) {
+	if err := os.MkdirAll(p.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("create message outbox dir: %w", err)
+	}
+	id := uuid.New().String()
+	body, err := json.MarshalIndent(map[string]interface{}{
+		"id":       id,
+		"channel":  msg.Channel,
+		"to":       msg.To,
+		"body":     msg.Body,
+		"template": msg.Template,
+		"params":   msg.Params,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s-%s.json", time.Now().Format("20060102T150405"), p.Channel, id)
+	return id, os.WriteFile(filepath.Join(p.Dir, name), body, 0o644)
+}
+
+// LogProvider hanya mencatat pesan ke log
+type LogProvider struct {
+	Channel string
+}
+
+func (p LogProvider) Name() string { return "log" }
+
+// Send mencatat pesan ke log
+func (p LogProvider) Send(ctx context.Context, msg Message) (string, error) {
+	id := uuid.New().String()
+	log.Printf("MESSAGE channel=%s to=%q id=%s\n%s", msg.Channel, msg.To, id, msg.Body)
+	return id, nil
+}
@@ -0,0 +1,473 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/outbox"
+)
+
+// Config mengatur channel pesan dan retry antrian
+type Config struct {
+	// Channel adalah channel utama pesan ke participant: whatsapp, sms atau none
+	Channel string
+	// SMSFallback mengirim ulang lewat SMS jika pesan WhatsApp gagal permanen (mis. nomor tidak punya WhatsApp)
+	SMSFallback bool
+	outbox.Retry
+	// SendTimeout adalah batas waktu satu pengiriman ke provider
+	SendTimeout time.Duration
+	// PollInterval adalah jeda worker mengecek pesan yang jatuh tempo
+	PollInterval time.Duration
+}
+
+// ConfigFromEnv membaca konfigurasi pesan dari environment
+func ConfigFromEnv() Config {
+	return Config{
+		Channel:     strings.ToLower(helpers.GetEnv("MESSAGING_CHANNEL", models.ChannelWhatsApp)),
+		SMSFallback: helpers.GetEnvBool("MESSAGING_SMS_FALLBACK", false),
+		Retry: outbox.Retry{
+			MaxAttempts: helpers.GetEnvInt("MESSAGING_MAX_ATTEMPTS", 5),
+			BaseBackoff: helpers.GetEnvDuration("MESSAGING_BASE_BACKOFF", time.Minute),
+			MaxBackoff:  helpers.GetEnvDuration("MESSAGING_MAX_BACKOFF", time.Hour),
+		},
+		SendTimeout:  helpers.GetEnvDuration("MESSAGING_SEND_TIMEOUT", 20*time.Second),
+		PollInterval: helpers.GetEnvDuration("MESSAGING_POLL_INTERVAL", 15*time.Second),
+	}
+}
+
+// Service mengantrikan pesan WhatsApp / SMS ke participant; worker outbox mengirimnya di background
+type Service struct {
+	DB        *gorm.DB
+	Config    Config
+	Providers map[string]Provider // channel -> provider
+	// TicketURL membuat link tiket bertanda tangan untuk placeholder {ticket_url}
+	TicketURL func(code string) string
+	// PortalURL adalah halaman portal pendaftar untuk placeholder {portal_url}
+	PortalURL string
+
+	worker *outbox.Worker[models.OutboundMessage]
+}
+
+// NewService membuat service pesan; panggil Start untuk menjalankan worker
+func NewService(db *gorm.DB, cfg Config, providers map[string]Provider) *Service {
+	s := &Service{DB: db, Config: cfg, Providers: providers}
+	s.worker = outbox.New(db, outbox.Options{
+		Name:          "Messaging worker",
+		PendingStatus: models.MessageQueued,
+		Retry:         cfg.Retry,
+		SendTimeout:   cfg.SendTimeout,
+		PollInterval:  cfg.PollInterval,
+		Permanent:     func(err error) bool { return IsPermanent(err) || errors.Is(err, errOptedOut) },
+	}, outbox.Handler[models.OutboundMessage]{
+		Attempts: func(msg *models.OutboundMessage) int { return msg.Attempts },
+		Send:     s.send,
+		Record:   s.record,
+	})
+	return s
+}
+
+// NewServiceFromEnv membuat service dengan provider WhatsApp & SMS dari environment
+func NewServiceFromEnv(db *gorm.DB) *Service {
+	providers := map[string]Provider{}
+	for _, channel := range []string{models.ChannelWhatsApp, models.ChannelSMS} {
+		if p := NewProviderFromEnv(channel); p != nil {
+			providers[channel] = p
+		}
+	}
+	return NewService(db, ConfigFromEnv(), providers)
+}
+
+// Enabled mengecek apakah channel utama punya provider aktif
+func (s *Service) Enabled() bool {
+	return s != nil && s.Providers[s.Config.Channel] != nil
+}
+
+// Start menjalankan worker pengiriman di background (sekali per proses)
+func (s *Service) Start() { s.worker.Start() }
+
+// Stop menghentikan worker setelah pesan yang sedang dikirim selesai (atau ctx habis)
+func (s *Service) Stop(ctx context.Context) error { return s.worker.Stop(ctx) }
+
+// Kick membangunkan worker tanpa menunggu PollInterval
+func (s *Service) Kick() { s.worker.Kick() }
+
+// Vars membuat nilai placeholder pesan untuk participant
+func (s *Service) Vars(p models.Participant, event *models.Event) map[string]string {
+	vars := map[string]string{
+		"name":       p.Name,
+		"event_name": "Youth College",
+		"status":     p.Status,
+		"portal_url": s.PortalURL,
+	}
+	if p.RegistrationCode != nil {
+		vars["registration_code"] = *p.RegistrationCode
+		if s.TicketURL != nil {
+			vars["ticket_url"] = s.TicketURL(*p.RegistrationCode)
+		}
+	}
+	if event != nil {
+		vars["event_name"] = event.Name
+		vars["event_location"] = event.Location
+		vars["event_date"] = event.StartDate.Format("02 January 2006")
+		if !event.EndDate.IsZero() && !event.EndDate.Equal(event.StartDate) {
+			vars["event_date"] += " - " + event.EndDate.Format("02 January 2006")
+		}
+		if event.RegistrationClosesAt != nil {
+			vars["registration_closes_at"] = event.RegistrationClosesAt.Local().Format("02 January 2006 15:04")
+		}
+	}
+	return vars
+}
+
+// Notify mengantrikan pesan jenis key untuk participant lewat channel utama.
+// Pesan yang gagal diantrikan hanya masuk log; request yang memicunya tetap berhasil.
+func (s *Service) Notify(key string, p models.Participant) {
+	if !s.Enabled() {
+		return
+	}
+	var event *models.Event
+	if p.EventID != nil {
+		event = &models.Event{}
+		if err := s.DB.First(event, *p.EventID).Error; err != nil {
+			log.Printf("ERROR: Gagal memuat event %d untuk pesan %s: %v", *p.EventID, key, err)
+			return
+		}
+	}
+	if _, err := s.queue(key, p, event); err != nil {
+		log.Printf("ERROR: Gagal mengantrikan pesan %s untuk %s: %v", key, p.ID, err)
+		return
+	}
+	s.Kick()
+}
+
+// RemindEvent mengantrikan pengingat event ke participant dengan status tertentu (default approved).
+// Mengembalikan jumlah pesan yang diantrikan, termasuk yang dilewati karena opt-out.
+func (s *Service) RemindEvent(event models.Event, statuses []string) (int, error) {
+	return s.SendToEvent(TemplateEventReminder, event, statuses)
+}
+
+// SendToEvent mengantrikan pesan jenis key ke participant event dengan status tertentu (default approved).
+// Mengembalikan jumlah pesan yang diantrikan, termasuk yang dilewati karena opt-out.
+func (s *Service) SendToEvent(key string, event models.Event, statuses []string) (int, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+	if len(statuses) == 0 {
+		statuses = []string{models.StatusApproved}
+	}
+	var participants []models.Participant
+	if err := s.DB.Where("event_id = ? AND status IN ?", event.ID, statuses).Order("created_at asc").Find(&participants).Error; err != nil {
+		return 0, err
+	}
+	queued := 0
+	for _, p := range participants {
+		msg, err := s.queue(key, p, &event)
+		if err != nil {
+			return queued, err
+		}
+		if msg != nil {
+			queued++
+		}
+	}
+	if queued > 0 {
+		s.Kick()
+	}
+	return queued, nil
+}
+
+// SendDirect merender pesan jenis key lalu mengirimnya langsung ke provider tanpa antrian, untuk
+// pesan berisi token sekali pakai (mis. magic link portal) yang tidak boleh tersimpan di log pesan.
+// extra menambah placeholder. WhatsApp yang gagal dikirim ulang lewat SMS jika SMSFallback aktif.
+func (s *Service) SendDirect(ctx context.Context, key string, p models.Participant, event *models.Event, extra map[string]string) error {
+	if !s.Enabled() {
+		return errNoProvider(s.Config.Channel)
+	}
+	if strings.TrimSpace(p.Phone) == "" {
+		return errors.New("participant tidak punya nomor HP")
+	}
+	optedOut, err := s.OptedOut(p.Phone)
+	if err != nil {
+		return err
+	}
+	if optedOut {
+		return errOptedOut
+	}
+	tpl, err := LoadTemplate(s.DB, key)
+	if err != nil {
+		return err
+	}
+	if !tpl.Active || tpl.Body == "" {
+		return fmt.Errorf("template pesan %s dinonaktifkan", key)
+	}
+	vars := s.Vars(p, event)
+	for k, v := range extra {
+		vars[k] = v
+	}
+	msg := Message{Channel: s.Config.Channel, To: p.Phone, Body: Render(tpl.Body, vars)}
+	if msg.Channel == models.ChannelWhatsApp {
+		msg.Template, msg.Language = tpl.WhatsAppTemplate, tpl.WhatsAppLanguage
+		msg.Params = make([]string, len(tpl.WhatsAppParams))
+		for i, name := range tpl.WhatsAppParams {
+			msg.Params[i] = vars[name]
+		}
+	}
+	_, err = s.Providers[msg.Channel].Send(ctx, msg)
+	if err != nil && msg.Channel == models.ChannelWhatsApp && s.Config.SMSFallback && s.Providers[models.ChannelSMS] != nil {
+		log.Printf("ERROR: Pesan %s lewat WhatsApp gagal, dicoba lewat SMS: %v", key, err)
+		_, err = s.Providers[models.ChannelSMS].Send(ctx, Message{Channel: models.ChannelSMS, To: p.Phone, Body: msg.Body})
+	}
+	return err
+}
+
+// queue merender template dan menyimpan pesan ke antrian. Nomor yang sudah opt-out tetap dicatat
+// dengan status skipped. Mengembalikan nil jika template dinonaktifkan atau participant tanpa nomor HP.
+func (s *Service) queue(key string, p models.Participant, event *models.Event) (*models.OutboundMessage, error) {
+	if strings.TrimSpace(p.Phone) == "" {
+		return nil, nil
+	}
+	tpl, err := LoadTemplate(s.DB, key)
+	if err != nil {
+		return nil, err
+	}
+	if !tpl.Active || tpl.Body == "" {
+		return nil, nil
+	}
+	vars := s.Vars(p, event)
+	params := make([]string, len(tpl.WhatsAppParams))
+	for i, name := range tpl.WhatsAppParams {
+		params[i] = vars[name]
+	}
+
+	now := time.Now()
+	msg := models.OutboundMessage{
+		ParticipantID: p.ID,
+		EventID:       p.EventID,
+		Channel:       s.Config.Channel,
+		To:            p.Phone,
+		TemplateKey:   key,
+		Body:          Render(tpl.Body, vars),
+		Params:        params,
+		Status:        models.MessageQueued,
+		NextAttemptAt: &now,
+	}
+	optedOut, err := s.OptedOut(p.Phone)
+	if err != nil {
+		return nil, err
+	}
+	if optedOut {
+		msg.Status = models.MessageSkipped
+		msg.NextAttemptAt = nil
+		msg.LastError = "Nomor HP sudah opt-out"
+	}
+	if err := s.DB.Create(&msg).Error; err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// errOptedOut menandai pesan yang nomornya opt-out setelah pesan diantrikan
+var errOptedOut = errors.New("Nomor HP sudah opt-out")
+
+// send mengirim satu pesan ke provider. ID pesan dari provider disimpan ke msg untuk record.
+func (s *Service) send(ctx context.Context, msg *models.OutboundMessage) error {
+	// Opt-out bisa terjadi setelah pesan diantrikan
+	if optedOut, err := s.OptedOut(msg.To); err == nil && optedOut {
+		return errOptedOut
+	}
+	provider := s.Providers[msg.Channel]
+	if provider == nil {
+		return Permanent(errNoProvider(msg.Channel))
+	}
+	msg.Provider = provider.Name()
+	template, language := s.whatsAppTemplate(msg)
+	providerID, err := provider.Send(ctx, Message{
+		Channel:  msg.Channel,
+		To:       msg.To,
+		Body:     msg.Body,
+		Template: template,
+		Language: language,
+		Params:   msg.Params,
+	})
+	if providerID != "" {
+		msg.ProviderMessageID = &providerID
+	}
+	return err
+}
+
+// record mencatat hasil pengiriman; WhatsApp yang gagal permanen dikirim ulang lewat SMS (jika aktif)
+func (s *Service) record(msg *models.OutboundMessage, res outbox.Result) {
+	if errors.Is(res.Err, errOptedOut) {
+		s.update(msg.ID, map[string]interface{}{"status": models.MessageSkipped, "next_attempt_at": nil, "last_error": res.Err.Error(), "updated_at": res.FinishedAt})
+		return
+	}
+	updates := map[string]interface{}{"attempts": res.Attempts, "next_attempt_at": res.NextAttemptAt, "updated_at": res.FinishedAt}
+	if msg.Provider != "" {
+		updates["provider"] = msg.Provider
+	}
+	switch {
+	case res.Err == nil:
+		updates["status"] = models.MessageSent
+		updates["sent_at"] = res.FinishedAt
+		updates["last_error"] = ""
+		if msg.ProviderMessageID != nil {
+			updates["provider_message_id"] = *msg.ProviderMessageID
+		}
+	case res.Final:
+		updates["status"] = models.MessageFailed
+		updates["last_error"] = res.Err.Error()
+	default:
+		updates["last_error"] = res.Err.Error()
+	}
+	s.update(msg.ID, updates)
+
+	if updates["status"] == models.MessageFailed {
+		log.Printf("ERROR: Pesan %s ke %s gagal: %v", msg.TemplateKey, msg.To, res.Err)
+		s.fallbackToSMS(msg)
+	}
+}
+
+func (s *Service) update(id uint, updates map[string]interface{}) {
+	if err := s.DB.Model(&models.OutboundMessage{}).Where("id = ?", id).UpdateColumns(updates).Error; err != nil {
+		log.Printf("ERROR: Gagal mencatat status pesan %d: %v", id, err)
+	}
+}
+
+// fallbackToSMS mengantrikan salinan pesan WhatsApp yang gagal sebagai SMS (jika diaktifkan)
+func (s *Service) fallbackToSMS(msg *models.OutboundMessage) {
+	if !s.Config.SMSFallback || msg.Channel != models.ChannelWhatsApp || s.Providers[models.ChannelSMS] == nil {
+		return
+	}
+	now := time.Now()
+	sms := models.OutboundMessage{
+		ParticipantID: msg.ParticipantID,
+		EventID:       msg.EventID,
+		Channel:       models.ChannelSMS,
+		To:            msg.To,
+		TemplateKey:   msg.TemplateKey,
+		Body:          msg.Body,
+		Status:        models.MessageQueued,
+		NextAttemptAt: &now,
+	}
+	if err := s.DB.Create(&sms).Error; err != nil {
+		log.Printf("ERROR: Gagal mengantrikan SMS pengganti pesan %d: %v", msg.ID, err)
+		return
+	}
+	s.Kick()
+}
+
+// whatsAppTemplate mengambil nama & bahasa template WhatsApp yang disetujui untuk jenis pesan
+// (kosong = dikirim sebagai pesan teks biasa)
+func (s *Service) whatsAppTemplate(msg *models.OutboundMessage) (string, string) {
+	if msg.Channel != models.ChannelWhatsApp {
+		return "", ""
+	}
+	tpl, err := LoadTemplate(s.DB, msg.TemplateKey)
+	if err != nil {
+		return "", ""
+	}
+	return tpl.WhatsAppTemplate, tpl.WhatsAppLanguage
+}
+
+// statusRank mengurutkan status supaya laporan provider yang datang terlambat tidak menurunkan status
+var statusRank = map[string]int{
+	models.MessageQueued:    0,
+	models.MessageSent:      1,
+	models.MessageDelivered: 2,
+	models.MessageRead:      3,
+}
+
+// ApplyStatus mencatat laporan status dari provider ke pesan yang cocok
+func (s *Service) ApplyStatus(update StatusUpdate) error {
+	var msg models.OutboundMessage
+	if err := s.DB.Where("provider_message_id = ?", update.ProviderMessageID).First(&msg).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+	updates := map[string]interface{}{"updated_at": time.Now()}
+	switch update.Status {
+	case "failed":
+		if statusRank[msg.Status] >= statusRank[models.MessageDelivered] {
+			return nil
+		}
+		updates["status"] = models.MessageFailed
+		updates["last_error"] = update.Error
+	case models.MessageDelivered, models.MessageRead:
+		rank, known := statusRank[msg.Status]
+		if !known || rank >= statusRank[update.Status] {
+			return nil
+		}
+		updates["status"] = update.Status
+		if update.Status == models.MessageRead {
+			updates["read_at"] = update.Timestamp
+		}
+		if msg.DeliveredAt == nil {
+			updates["delivered_at"] = update.Timestamp
+		}
+	default:
+		return nil
+	}
+	err := s.DB.Model(&models.OutboundMessage{}).Where("id = ?", msg.ID).UpdateColumns(updates).Error
+	if err == nil && updates["status"] == models.MessageFailed {
+		s.fallbackToSMS(&msg)
+	}
+	return err
+}
+
+// Kata kunci balasan participant untuk berhenti / kembali berlangganan
+var (
+	stopKeywords  = map[string]bool{"STOP": true, "BERHENTI": true, "UNSUBSCRIBE": true}
+	startKeywords = map[string]bool{"START": true, "MULAI": true, "SUBSCRIBE": true}
+)
+
+// HandleInbound memproses balasan participant: STOP / BERHENTI untuk opt-out, START / MULAI untuk opt-in
+func (s *Service) HandleInbound(msg InboundMessage) error {
+	keyword := strings.ToUpper(strings.TrimSpace(msg.Text))
+	phone := msg.From
+	if !strings.HasPrefix(phone, "+") {
+		phone = "+" + phone
+	}
+	switch {
+	case stopKeywords[keyword]:
+		return s.OptOut(phone, "inbound", "Membalas "+keyword, "")
+	case startKeywords[keyword]:
+		return s.OptIn(phone)
+	}
+	return nil
+}
+
+// OptedOut mengecek apakah nomor HP sudah opt-out
+func (s *Service) OptedOut(phone string) (bool, error) {
+	var count int64
+	err := s.DB.Model(&models.MessageOptOut{}).Where("phone_key = ?", helpers.PhoneKey(phone)).Count(&count).Error
+	return count > 0, err
+}
+
+// OptOut menghentikan WhatsApp / SMS ke nomor HP; pesan yang masih antri ikut dilewati
+func (s *Service) OptOut(phone, source, reason, actor string) error {
+	key := helpers.PhoneKey(phone)
+	if key == "" {
+		return nil
+	}
+	optOut := models.MessageOptOut{PhoneKey: key, Phone: phone, Source: source, Reason: reason, CreatedBy: actor, CreatedAt: time.Now()}
+	return s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&optOut).Error
+}
+
+// OptIn menghapus opt-out nomor HP
+func (s *Service) OptIn(phone string) error {
+	return s.DB.Where("phone_key = ?", helpers.PhoneKey(phone)).Delete(&models.MessageOptOut{}).Error
+}
+
+type errNoProvider string
+
+func (e errNoProvider) Error() string { return "provider untuk channel " + string(e) + " tidak aktif" }
@@ -0,0 +1,310 @@
+package messaging
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/outbox"
+	"backend/internal/testutil"
+)
+
+// fakeProvider mencatat pesan yang dikirim; err (jika diisi) dikembalikan untuk setiap pengiriman
+type fakeProvider struct {
+	name string
+	err  error
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Send(ctx context.Context, msg Message) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, msg)
+	if p.err != nil {
+		return "", p.err
+	}
+	return p.name + "-" + strconv.Itoa(len(p.sent)), nil
+}
+
+func (p *fakeProvider) messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.sent...)
+}
+
+// newTestService membuat service WhatsApp dengan provider SMS cadangan di SQLite sementara
+func newTestService(t *testing.T, smsFallback bool) (*Service, *fakeProvider, *fakeProvider) {
+	t.Helper()
+	db := testutil.OpenDB(t, &models.Event{}, &models.Participant{}, &models.OutboundMessage{},
+		&models.MessageTemplate{}, &models.MessageOptOut{})
+	wa, sms := &fakeProvider{name: "wa"}, &fakeProvider{name: "sms"}
+	cfg := Config{
+		Channel:      models.ChannelWhatsApp,
+		SMSFallback:  smsFallback,
+		Retry:        outbox.Retry{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour},
+		SendTimeout:  5 * time.Second,
+		PollInterval: time.Hour,
+	}
+	s := NewService(db, cfg, map[string]Provider{models.ChannelWhatsApp: wa, models.ChannelSMS: sms})
+	s.PortalURL = "https://yc.test/portal"
+	return s, wa, sms
+}
+
+func lastMessage(t *testing.T, s *Service, channel string) models.OutboundMessage {
+	t.Helper()
+	var msg models.OutboundMessage
+	if err := s.DB.Where("channel = ?", channel).Order("id desc").First(&msg).Error; err != nil {
+		t.Fatalf("load %s message: %v", channel, err)
+	}
+	return msg
+}
+
+func TestNotifySendsThroughProvider(t *testing.T) {
+	s, wa, _ := newTestService(t, false)
+	p := testutil.CreateParticipant(t, s.DB, "Budi")
+
+	s.Notify(TemplateRegistrationReceived, p)
+	if n, err := s.worker.ProcessDue(); n != 1 || err != nil {
+		t.Fatalf("ProcessDue = %d, %v", n, err)
+	}
+	sent := wa.messages()
+	if len(sent) != 1 || sent[0].To != "+6281234567890" || !strings.Contains(sent[0].Body, "Halo Budi") ||
+		!strings.Contains(sent[0].Body, *p.RegistrationCode) {
+		t.Fatalf("sent = %+v", sent)
+	}
+	msg := lastMessage(t, s, models.ChannelWhatsApp)
+	if msg.Status != models.MessageSent || msg.Provider != "wa" || msg.ProviderMessageID == nil || *msg.ProviderMessageID != "wa-1" || msg.SentAt == nil {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestOptOutSkipsMessages(t *testing.T) {
+	s, wa, _ := newTestService(t, false)
+	p := testutil.CreateParticipant(t, s.DB, "Budi")
+
+	// Opt-out sebelum diantrikan: pesan dicatat skipped dan tidak pernah diambil worker
+	if err := s.OptOut("0812-3456-7890", "admin", "Minta berhenti", "admin"); err != nil {
+		t.Fatal(err)
+	}
+	s.Notify(TemplateRegistrationReceived, p)
+	msg := lastMessage(t, s, models.ChannelWhatsApp)
+	if msg.Status != models.MessageSkipped || msg.NextAttemptAt != nil || msg.LastError != "Nomor HP sudah opt-out" {
+		t.Errorf("queued after opt-out = %+v", msg)
+	}
+	if n, _ := s.worker.ProcessDue(); n != 0 {
+		t.Errorf("ProcessDue picked %d skipped messages", n)
+	}
+
+	// Opt-out setelah diantrikan: dicek lagi saat kirim
+	if err := s.OptIn(p.Phone); err != nil {
+		t.Fatal(err)
+	}
+	s.Notify(TemplateRegistrationApproved, p)
+	if err := s.OptOut(p.Phone, "participant", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.worker.ProcessDue(); n != 1 || err != nil {
+		t.Fatalf("ProcessDue = %d, %v", n, err)
+	}
+	msg = lastMessage(t, s, models.ChannelWhatsApp)
+	if msg.TemplateKey != TemplateRegistrationApproved || msg.Status != models.MessageSkipped || msg.NextAttemptAt != nil {
+		t.Errorf("opted out after queue = %+v", msg)
+	}
+	if len(wa.messages()) != 0 {
+		t.Errorf("provider received %d messages", len(wa.messages()))
+	}
+}
+
+func TestHandleInboundStopAndStart(t *testing.T) {
+	s, _, _ := newTestService(t, false)
+	phone := "+6281234567890"
+
+	for _, tt := range []struct {
+		text     string
+		optedOut bool
+	}{
+		{" stop ", true},
+		{"halo", true}, // pesan lain tidak mengubah apa pun
+		{"MULAI", false},
+		{"Berhenti", true},
+		{"start", false},
+	} {
+		if err := s.HandleInbound(InboundMessage{From: "6281234567890", Text: tt.text}); err != nil {
+			t.Fatalf("%q: %v", tt.text, err)
+		}
+		if got, _ := s.OptedOut(phone); got != tt.optedOut {
+			t.Errorf("after %q opted out = %v, want %v", tt.text, got, tt.optedOut)
+		}
+	}
+
+	// Balasan tombol dari callback WhatsApp Cloud API
+	body := []byte(`{"entry":[{"changes":[{"value":{"messages":[{"from":"6281234567890","type":"button","button":{"text":"STOP"}}]}}]}]}`)
+	_, inbound, err := ParseWhatsAppCallback(body)
+	if err != nil || len(inbound) != 1 {
+		t.Fatalf("ParseWhatsAppCallback = %+v, %v", inbound, err)
+	}
+	if err := s.HandleInbound(inbound[0]); err != nil {
+		t.Fatal(err)
+	}
+	var optOut models.MessageOptOut
+	if err := s.DB.First(&optOut).Error; err != nil || optOut.Source != "inbound" || optOut.Reason != "Membalas STOP" {
+		t.Errorf("opt-out = %+v, %v", optOut, err)
+	}
+}
+
+func TestSMSFallback(t *testing.T) {
+	for _, fallback := range []bool{true, false} {
+		s, wa, sms := newTestService(t, fallback)
+		wa.err = Permanent(errors.New("nomor tidak terdaftar di WhatsApp"))
+		p := testutil.CreateParticipant(t, s.DB, "Budi")
+
+		s.Notify(TemplateRegistrationReceived, p)
+		s.worker.ProcessDue()
+		if msg := lastMessage(t, s, models.ChannelWhatsApp); msg.Status != models.MessageFailed || msg.Attempts != 1 {
+			t.Errorf("fallback %v: whatsapp = %s after %d attempts", fallback, msg.Status, msg.Attempts)
+		}
+		s.worker.ProcessDue()
+
+		var smsCount int64
+		s.DB.Model(&models.OutboundMessage{}).Where("channel = ?", models.ChannelSMS).Count(&smsCount)
+		if !fallback {
+			if smsCount != 0 || len(sms.messages()) != 0 {
+				t.Errorf("SMS sent without MESSAGING_SMS_FALLBACK")
+			}
+			continue
+		}
+		msg := lastMessage(t, s, models.ChannelSMS)
+		sent := sms.messages()
+		if msg.Status != models.MessageSent || len(sent) != 1 || sent[0].Body != lastMessage(t, s, models.ChannelWhatsApp).Body {
+			t.Errorf("sms = %+v, sent %+v", msg, sent)
+		}
+	}
+}
+
+func TestApplyStatusFallbackAndRank(t *testing.T) {
+	s, _, sms := newTestService(t, true)
+	p := testutil.CreateParticipant(t, s.DB, "Budi")
+	s.Notify(TemplateRegistrationReceived, p)
+	s.worker.ProcessDue()
+	s.Notify(TemplateRegistrationApproved, p)
+	s.worker.ProcessDue()
+
+	// Laporan terlambat tidak menurunkan status
+	for _, update := range []StatusUpdate{
+		{ProviderMessageID: "wa-1", Status: models.MessageRead, Timestamp: time.Now()},
+		{ProviderMessageID: "wa-1", Status: models.MessageDelivered, Timestamp: time.Now()},
+		{ProviderMessageID: "wa-1", Status: "failed", Error: "terlambat"},
+		{ProviderMessageID: "tidak-ada", Status: models.MessageRead},
+	} {
+		if err := s.ApplyStatus(update); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var first models.OutboundMessage
+	s.DB.Where("provider_message_id = ?", "wa-1").First(&first)
+	if first.Status != models.MessageRead || first.ReadAt == nil || first.DeliveredAt == nil {
+		t.Errorf("first = %+v", first)
+	}
+
+	// Gagal dikirim ke HP setelah diterima provider: diganti SMS
+	if err := s.ApplyStatus(StatusUpdate{ProviderMessageID: "wa-2", Status: "failed", Error: "Message undeliverable (code 131026)"}); err != nil {
+		t.Fatal(err)
+	}
+	s.worker.ProcessDue()
+	if sent := sms.messages(); len(sent) != 1 || !strings.Contains(sent[0].Body, "disetujui") {
+		t.Errorf("sms = %+v", sent)
+	}
+}
+
+func TestVerifyWhatsAppSignature(t *testing.T) {
+	body := []byte(`{"entry":[]}`)
+	m := hmac.New(sha256.New, []byte("app-secret"))
+	m.Write(body)
+	valid := "sha256=" + hex.EncodeToString(m.Sum(nil))
+
+	if !VerifyWhatsAppSignature("app-secret", body, valid) {
+		t.Error("valid signature rejected")
+	}
+	for name, tt := range map[string]struct {
+		secret string
+		body   string
+		header string
+	}{
+		"other secret":  {"secret-lain", string(body), valid},
+		"changed body":  {"app-secret", `{"entry":[{}]}`, valid},
+		"no prefix":     {"app-secret", string(body), strings.TrimPrefix(valid, "sha256=")},
+		"empty secret":  {"", string(body), valid},
+		"empty header":  {"app-secret", string(body), ""},
+		"uppercase hex": {"app-secret", string(body), "sha256=" + strings.ToUpper(strings.TrimPrefix(valid, "sha256="))},
+	} {
+		if VerifyWhatsAppSignature(tt.secret, []byte(tt.body), tt.header) {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestSendDirect(t *testing.T) {
+	s, wa, sms := newTestService(t, true)
+	p := testutil.CreateParticipant(t, s.DB, "Budi")
+	extra := map[string]string{"portal_link": "https://yc.test/portal?token=abc", "link_expires_at": "01 Dec 2026 10:00"}
+
+	if err := s.SendDirect(context.Background(), TemplatePortalLink, p, nil, extra); err != nil {
+		t.Fatal(err)
+	}
+	sent := wa.messages()
+	if len(sent) != 1 || !strings.Contains(sent[0].Body, "token=abc") || !strings.Contains(sent[0].Body, "01 Dec 2026 10:00") {
+		t.Fatalf("sent = %+v", sent)
+	}
+	// Tidak tersimpan di antrian / log pesan
+	var stored int64
+	s.DB.Model(&models.OutboundMessage{}).Count(&stored)
+	if stored != 0 {
+		t.Errorf("stored messages = %d, want 0", stored)
+	}
+
+	// WhatsApp gagal: dikirim lewat SMS
+	wa.err = errors.New("timeout")
+	if err := s.SendDirect(context.Background(), TemplatePortalLink, p, nil, extra); err != nil {
+		t.Fatalf("with fallback: %v", err)
+	}
+	if sent := sms.messages(); len(sent) != 1 || sent[0].Channel != models.ChannelSMS || !strings.Contains(sent[0].Body, "token=abc") {
+		t.Errorf("sms = %+v", sent)
+	}
+	s.Config.SMSFallback = false
+	if err := s.SendDirect(context.Background(), TemplatePortalLink, p, nil, extra); err == nil {
+		t.Error("WhatsApp error not returned without fallback")
+	}
+	wa.err = nil
+
+	// Nomor opt-out dan template dinonaktifkan tidak dikirim
+	s.OptOut(p.Phone, "participant", "", "")
+	if err := s.SendDirect(context.Background(), TemplatePortalLink, p, nil, extra); !errors.Is(err, errOptedOut) {
+		t.Errorf("opted out: %v", err)
+	}
+	s.OptIn(p.Phone)
+	s.DB.Create(&models.MessageTemplate{Key: TemplatePortalLink, Body: "x"})
+	s.DB.Model(&models.MessageTemplate{}).Where("template_key = ?", TemplatePortalLink).UpdateColumn("active", false)
+	if err := s.SendDirect(context.Background(), TemplatePortalLink, p, nil, extra); err == nil {
+		t.Error("disabled template sent")
+	}
+
+	// Channel tanpa provider
+	s.Providers = nil
+	if err := s.SendDirect(context.Background(), TemplatePortalLink, p, nil, extra); err == nil || s.Enabled() {
+		t.Error("sent without provider")
+	}
+	if got := len(wa.messages()); got != 3 {
+		t.Errorf("whatsapp attempts = %d, want 3", got)
+	}
+}
@@ -0,0 +1,70 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSMSProvider mengirim SMS lewat gateway HTTP generik: POST JSON {"to", "message", "sender"}
+// dengan header Authorization: Bearer <SMS_API_KEY>. Id pesan dibaca dari field "id" / "message_id" response.
+type HTTPSMSProvider struct {
+	URL      string
+	APIKey   string
+	SenderID string
+	Client   *http.Client
+}
+
+func (p *HTTPSMSProvider) Name() string { return "sms_http" }
+
+func (p *HTTPSMSProvider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return &http.Client{Timeout: 15 * time.Second}
+}
+
+// Send mengirim SMS teks (template WhatsApp diabaikan, SMS selalu memakai Body)
+func (p *HTTPSMSProvider) Send(ctx context.Context, msg Message) (string, error) {
+	if p.URL == "" {
+		return "", Permanent(errors.New("SMS_GATEWAY_URL belum diatur"))
+	}
+	body, err := json.Marshal(map[string]string{"to": msg.To, "message": msg.Body, "sender": p.SenderID})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", classifyHTTPError(resp.StatusCode, fmt.Errorf("SMS gateway HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(raw)))
+	}
+	var result struct {
+		ID        interface{} `json:"id"`
+		MessageID interface{} `json:"message_id"`
+	}
+	_ = json.Unmarshal(raw, &result)
+	for _, id := range []interface{}{result.ID, result.MessageID} {
+		if id != nil && fmt.Sprint(id) != "" {
+			return fmt.Sprint(id), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,76 @@
+package messaging
+
+import (
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+)
+
+// Jenis pesan ke participant
+const (
+	TemplateRegistrationReceived   = "registration_received"
+	TemplateRegistrationWaitlisted = "registration_waitlisted"
+	TemplateRegistrationApproved   = "registration_approved"
+	TemplateWaitlistPromoted       = "waitlist_promoted"
+	TemplateEventReminder          = "event_reminder"
+	TemplateRegistrationClosing    = "registration_closing"
+	// TemplatePortalLink berisi magic link portal, dikirim lewat SendDirect (tidak masuk antrian)
+	TemplatePortalLink = "portal_link"
+)
+
+// optOutHint ditambahkan di akhir teks bawaan supaya penerima tahu cara berhenti berlangganan
+const optOutHint = "\n\nBalas STOP untuk berhenti menerima pesan."
+
+// DefaultTemplates adalah teks bawaan tiap jenis pesan, bisa ditimpa admin lewat MessageTemplate
+var DefaultTemplates = map[string]string{
+	TemplateRegistrationReceived: "Halo {name}, pendaftaran kamu di {event_name} sudah kami terima. " +
+		"Kode registrasi: {registration_code}. Kami kabari lagi setelah pendaftaran diverifikasi." + optOutHint,
+	TemplateRegistrationWaitlisted: "Halo {name}, kuota {event_name} sudah penuh sehingga kamu masuk waitlist. " +
+		"Kami kabari jika ada kursi kosong. Kode registrasi: {registration_code}." + optOutHint,
+	TemplateRegistrationApproved: "Selamat {name}! Pendaftaran kamu di {event_name} sudah disetujui. " +
+		"Tunjukkan tiket ini saat check-in: {ticket_url}" + optOutHint,
+	TemplateWaitlistPromoted: "Kabar baik {name}! Ada kursi kosong di {event_name} dan pendaftaran kamu sudah " +
+		"dipindahkan dari waitlist. Kode registrasi: {registration_code}." + optOutHint,
+	TemplateEventReminder: "Halo {name}, jangan lupa {event_name} dimulai {event_date} di {event_location}. " +
+		"Tiket: {ticket_url}" + optOutHint,
+	TemplateRegistrationClosing: "Halo {name}, pendaftaran {event_name} ditutup {registration_closes_at}. " +
+		"Pastikan data pendaftaran kamu sudah lengkap lewat portal: {portal_url}" + optOutHint,
+	TemplatePortalLink: "Halo {name}, ini link masuk portal pendaftaran {event_name}: {portal_link} " +
+		"Link hanya bisa dipakai sekali dan berlaku sampai {link_expires_at}. Abaikan pesan ini jika kamu tidak memintanya.",
+}
+
+// Variables adalah placeholder yang tersedia di template pesan; portal_link & link_expires_at
+// hanya terisi di template portal_link
+var Variables = []string{"name", "event_name", "event_date", "event_location", "registration_code", "ticket_url", "status", "portal_url", "registration_closes_at", "portal_link", "link_expires_at"}
+
+// TemplateKeys mengembalikan semua jenis pesan, urut abjad
+func TemplateKeys() []string {
+	keys := make([]string, 0, len(DefaultTemplates))
+	for k := range DefaultTemplates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Render mengganti {variabel} di body dengan nilainya; placeholder yang tidak dikenal dibiarkan
+func Render(body string, vars map[string]string) string {
+	pairs := make([]string, 0, len(vars)*2)
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(body)
+}
+
+// LoadTemplate mengambil template jenis pesan: versi admin jika ada, selain itu teks bawaan
+func LoadTemplate(db *gorm.DB, key string) (models.MessageTemplate, error) {
+	var tpl models.MessageTemplate
+	err := db.Where("template_key = ?", key).First(&tpl).Error
+	if err == gorm.ErrRecordNotFound {
+		return models.MessageTemplate{Key: key, Body: DefaultTemplates[key], Active: true, WhatsAppParams: []string{}}, nil
+	}
+	return tpl, err
+}
@@ -0,0 +1,195 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WhatsAppProvider mengirim pesan lewat WhatsApp Business Cloud API
+type WhatsAppProvider struct {
+	BaseURL       string
+	PhoneNumberID string
+	AccessToken   string
+	Client        *http.Client
+}
+
+func (p *WhatsAppProvider) Name() string { return "whatsapp_cloud" }
+
+func (p *WhatsAppProvider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return &http.Client{Timeout: 15 * time.Second}
+}
+
+// Send mengirim pesan teks, atau pesan template jika msg.Template diisi
+func (p *WhatsAppProvider) Send(ctx context.Context, msg Message) (string, error) {
+	if p.PhoneNumberID == "" || p.AccessToken == "" {
+		return "", Permanent(errors.New("WHATSAPP_PHONE_NUMBER_ID / WHATSAPP_ACCESS_TOKEN belum diatur"))
+	}
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                strings.TrimPrefix(msg.To, "+"),
+	}
+	if msg.Template != "" {
+		params := make([]map[string]string, len(msg.Params))
+		for i, v := range msg.Params {
+			params[i] = map[string]string{"type": "text", "text": v}
+		}
+		language := msg.Language
+		if language == "" {
+			language = "id"
+		}
+		template := map[string]interface{}{"name": msg.Template, "language": map[string]string{"code": language}}
+		if len(params) > 0 {
+			template["components"] = []map[string]interface{}{{"type": "body", "parameters": params}}
+		}
+		payload["type"] = "template"
+		payload["template"] = template
+	} else {
+		payload["type"] = "text"
+		payload["text"] = map[string]interface{}{"body": msg.Body, "preview_url": true}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/"+p.PhoneNumberID+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.AccessToken)
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+		Error struct {
+			Message string `json:"message"`
+			Code    int    `json:"code"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("whatsapp API HTTP %d: %s (code %d)", resp.StatusCode, result.Error.Message, result.Error.Code)
+		return "", classifyHTTPError(resp.StatusCode, err)
+	}
+	if len(result.Messages) == 0 || result.Messages[0].ID == "" {
+		return "", errors.New("whatsapp API tidak mengembalikan id pesan")
+	}
+	return result.Messages[0].ID, nil
+}
+
+// classifyHTTPError: 4xx (kecuali 408 & 429) tidak akan berhasil jika diulang
+func classifyHTTPError(status int, err error) error {
+	if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
+
+// VerifyWhatsAppSignature mengecek header X-Hub-Signature-256 callback WhatsApp dengan app secret
+func VerifyWhatsAppSignature(appSecret string, body []byte, header string) bool {
+	if appSecret == "" || !strings.HasPrefix(header, "sha256=") {
+		return false
+	}
+	m := hmac.New(sha256.New, []byte(appSecret))
+	m.Write(body)
+	expected := "sha256=" + hex.EncodeToString(m.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(header))
+}
+
+// StatusUpdate adalah laporan status pesan dari provider
+type StatusUpdate struct {
+	ProviderMessageID string
+	Status            string // sent, delivered, read atau failed
+	Error             string
+	Timestamp         time.Time
+}
+
+// InboundMessage adalah pesan teks yang dikirim participant ke nomor kita
+type InboundMessage struct {
+	From string // nomor pengirim tanpa "+"
+	Text string
+}
+
+// ParseWhatsAppCallback membaca payload callback WhatsApp Cloud API (status pesan & pesan masuk)
+func ParseWhatsAppCallback(body []byte) ([]StatusUpdate, []InboundMessage, error) {
+	var payload struct {
+		Entry []struct {
+			Changes []struct {
+				Value struct {
+					Statuses []struct {
+						ID        string `json:"id"`
+						Status    string `json:"status"`
+						Timestamp string `json:"timestamp"`
+						Errors    []struct {
+							Code  int    `json:"code"`
+							Title string `json:"title"`
+						} `json:"errors"`
+					} `json:"statuses"`
+					Messages []struct {
+						From string `json:"from"`
+						Type string `json:"type"`
+						Text struct {
+							Body string `json:"body"`
+						} `json:"text"`
+						Button struct {
+							Text string `json:"text"`
+						} `json:"button"`
+					} `json:"messages"`
+				} `json:"value"`
+			} `json:"changes"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, nil, err
+	}
+
+	var statuses []StatusUpdate
+	var inbound []InboundMessage
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			for _, s := range change.Value.Statuses {
+				update := StatusUpdate{ProviderMessageID: s.ID, Status: s.Status, Timestamp: parseUnix(s.Timestamp)}
+				if len(s.Errors) > 0 {
+					update.Error = fmt.Sprintf("%s (code %d)", s.Errors[0].Title, s.Errors[0].Code)
+				}
+				statuses = append(statuses, update)
+			}
+			for _, m := range change.Value.Messages {
+				text := m.Text.Body
+				if m.Type == "button" {
+					text = m.Button.Text
+				}
+				inbound = append(inbound, InboundMessage{From: m.From, Text: text})
+			}
+		}
+	}
+	return statuses, inbound, nil
+}
+
+func parseUnix(s string) time.Time {
+	var sec int64
+	if _, err := fmt.Sscan(s, &sec); err != nil || sec <= 0 {
+		return time.Now()
+	}
+	return time.Unix(sec, 0)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// EventScope membaca event dari parameter :slug dan menyimpannya ke context ("event").
+// Handler participant memakai event ini untuk membatasi query ke satu event.
+func EventScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := c.MustGet("db").(*gorm.DB)
+		var event models.Event
+		if err := db.Where("slug = ?", c.Param("slug")).First(&event).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				helpers.ResponseNotFound(c, "Event not found")
+			} else {
+				helpers.ResponseInternalServerError(c, err.Error())
+			}
+			c.Abort()
+			return
+		}
+		c.Set("event", &event)
+		c.Next()
+	}
+}
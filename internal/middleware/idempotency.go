@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// idempotencyMaxBody membatasi body yang dibaca untuk fingerprint (endpoint create berupa JSON kecil)
+const idempotencyMaxBody = 1 << 20
+
+// idempotencyRecorder menyalin response yang ditulis handler supaya bisa disimpan
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyFromEnv membuat middleware Idempotency dengan IDEMPOTENCY_TTL (default 24 jam) dan
+// IDEMPOTENCY_LOCK_TIMEOUT (default 1 menit, setelah itu request yang tidak selesai dianggap terhenti)
+func IdempotencyFromEnv() gin.HandlerFunc {
+	return Idempotency(helpers.GetEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour), helpers.GetEnvDuration("IDEMPOTENCY_LOCK_TIMEOUT", time.Minute))
+}
+
+// Idempotency menerapkan header Idempotency-Key untuk endpoint create (POST).
+// Request pertama diproses dan response-nya (status + body) disimpan selama ttl; request dengan key sama
+// dan body sama mendapat response tersimpan (header Idempotent-Replayed: true), body berbeda ditolak 422,
+// dan request yang masih diproses ditolak 409. Response sementara (5xx, 408, 409, 429) tidak disimpan
+// sehingga request boleh dicoba ulang dengan key yang sama.
+// Tanpa header request diproses seperti biasa.
+func Idempotency(ttl, lockTimeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			helpers.ResponseBadRequest(c, "Idempotency-Key maksimal 255 karakter")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, idempotencyMaxBody+1))
+		if err != nil {
+			helpers.ResponseBadRequest(c, err.Error())
+			c.Abort()
+			return
+		}
+		if len(body) > idempotencyMaxBody {
+			helpers.ResponseError(c, http.StatusRequestEntityTooLarge, "Body terlalu besar untuk Idempotency-Key")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Key berlaku per endpoint dan per admin (request publik berbagi scope yang sama)
+		scope := c.Request.Method + " " + c.Request.URL.Path + " " + c.GetString("username")
+		keyHash := helpers.SHA256Hash(scope + "\n" + key)
+		fingerprint := helpers.SHA256Hash(string(canonicalBody(body)))
+
+		db := c.MustGet("db").(*gorm.DB)
+		now := time.Now()
+		record := models.IdempotencyKey{
+			KeyHash:     keyHash,
+			Fingerprint: fingerprint,
+			Status:      models.IdempotencyProcessing,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+		// Insert dengan unique KeyHash berfungsi sebagai kunci: hanya satu request yang berhasil
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			helpers.ResponseInternalServerError(c, result.Error.Error())
+			c.Abort()
+			return
+		}
+		if result.RowsAffected == 0 {
+			var existing models.IdempotencyKey
+			if err := db.Where("key_hash = ?", keyHash).First(&existing).Error; err != nil {
+				helpers.ResponseInternalServerError(c, err.Error())
+				c.Abort()
+				return
+			}
+			// Key kedaluwarsa atau proses sebelumnya terhenti (mis. server restart): hapus lalu proses ulang
+			abandoned := existing.Status == models.IdempotencyProcessing && now.Sub(existing.CreatedAt) > lockTimeout
+			if now.After(existing.ExpiresAt) || abandoned {
+				if err := db.Where("id = ? AND status = ?", existing.ID, existing.Status).Delete(&models.IdempotencyKey{}).Error; err != nil {
+					helpers.ResponseInternalServerError(c, err.Error())
+					c.Abort()
+					return
+				}
+				result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+				if result.Error != nil {
+					helpers.ResponseInternalServerError(c, result.Error.Error())
+					c.Abort()
+					return
+				}
+				if result.RowsAffected == 0 {
+					helpers.ResponseConflict(c, "Request dengan Idempotency-Key yang sama sedang diproses")
+					c.Abort()
+					return
+				}
+			} else {
+				replayIdempotent(c, existing, fingerprint)
+				return
+			}
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			// Handler panic / response sementara: lepas kunci supaya client bisa mencoba ulang
+			if !completed {
+				if err := db.Delete(&models.IdempotencyKey{}, record.ID).Error; err != nil {
+					log.Printf("ERROR: Gagal melepas idempotency key %d: %v", record.ID, err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if !storableStatus(status) {
+			return
+		}
+		if err := db.Model(&record).Updates(models.IdempotencyKey{
+			Status:         models.IdempotencyCompleted,
+			ResponseStatus: status,
+			ResponseType:   recorder.Header().Get("Content-Type"),
+			ResponseBody:   recorder.body.Bytes(),
+		}).Error; err != nil {
+			log.Printf("ERROR: Gagal menyimpan response idempotency key %d: %v", record.ID, err)
+			return
+		}
+		completed = true
+	}
+}
+
+// storableStatus menentukan response yang disimpan: hasil final, bukan kegagalan sementara
+func storableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return status < http.StatusInternalServerError
+}
+
+// replayIdempotent mengirim response tersimpan, atau menolak key yang masih diproses / dipakai dengan body lain
+func replayIdempotent(c *gin.Context, existing models.IdempotencyKey, fingerprint string) {
+	defer c.Abort()
+	if existing.Fingerprint != fingerprint {
+		helpers.ResponseError(c, http.StatusUnprocessableEntity, "Idempotency-Key sudah dipakai untuk request dengan body berbeda")
+		return
+	}
+	if existing.Status != models.IdempotencyCompleted {
+		c.Header("Retry-After", "1")
+		helpers.ResponseConflict(c, "Request dengan Idempotency-Key yang sama sedang diproses")
+		return
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(existing.ResponseStatus, existing.ResponseType, existing.ResponseBody)
+}
+
+// canonicalBody menghapus spasi yang tidak bermakna dari body JSON supaya format berbeda tetap dianggap sama
+func canonicalBody(body []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, body); err != nil {
+		return body
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// idempotencyServer menyiapkan endpoint POST /items dan /other di belakang middleware Idempotency.
+// Handler mengembalikan status dari header X-Status (default 201) dan berhenti di release jika X-Block diisi.
+type idempotencyServer struct {
+	engine  *gin.Engine
+	db      *gorm.DB
+	calls   atomic.Int32
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newIdempotencyServer(t *testing.T) *idempotencyServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db := testutil.OpenDB(t, &models.IdempotencyKey{})
+
+	s := &idempotencyServer{db: db, entered: make(chan struct{}, 1), release: make(chan struct{})}
+	handler := func(c *gin.Context) {
+		n := s.calls.Add(1)
+		if c.GetHeader("X-Block") != "" {
+			s.entered <- struct{}{}
+			<-s.release
+		}
+		if c.GetHeader("X-Panic") != "" {
+			panic("handler gagal")
+		}
+		status := http.StatusCreated
+		if v := c.GetHeader("X-Status"); v != "" {
+			status = map[string]int{"500": 500, "409": 409, "400": 400}[v]
+		}
+		c.JSON(status, gin.H{"call": n})
+	}
+	s.engine = gin.New()
+	s.engine.Use(gin.Recovery(), func(c *gin.Context) {
+		c.Set("db", db)
+		c.Set("username", c.GetHeader("X-User"))
+	})
+	idempotency := Idempotency(time.Hour, time.Minute)
+	s.engine.POST("/items", idempotency, handler)
+	s.engine.POST("/other", idempotency, handler)
+	return s
+}
+
+func (s *idempotencyServer) do(path, key, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysStoredResponse(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	first := s.do("/items", "abc", `{"name":"Budi","kampus":"UI"}`)
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first = %d %q", first.Code, first.Header().Get("Idempotent-Replayed"))
+	}
+	// Format JSON berbeda (spasi, baris baru) dianggap body yang sama
+	replay := s.do("/items", "abc", "{\n  \"name\": \"Budi\",\n  \"kampus\": \"UI\"\n}")
+	if replay.Code != http.StatusCreated || replay.Header().Get("Idempotent-Replayed") != "true" || replay.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %q %s, want stored %s", replay.Code, replay.Header().Get("Idempotent-Replayed"), replay.Body, first.Body)
+	}
+	if !strings.HasPrefix(replay.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("replay Content-Type = %q", replay.Header().Get("Content-Type"))
+	}
+	if s.calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", s.calls.Load())
+	}
+
+	// Response final non-2xx (mis. validasi 400) juga disimpan
+	if w := s.do("/items", "bad", `{}`, "X-Status", "400"); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid = %d", w.Code)
+	}
+	if w := s.do("/items", "bad", `{}`); w.Code != http.StatusBadRequest || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("replayed invalid = %d %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if s.calls.Load() != 2 {
+		t.Fatalf("handler called %d times, want 2", s.calls.Load())
+	}
+}
+
+func TestIdempotencyConflicts(t *testing.T) {
+	s := newIdempotencyServer(t)
+	s.do("/items", "abc", `{"name":"Budi"}`)
+
+	if w := s.do("/items", "abc", `{"name":"Siti"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("different body = %d, want 422", w.Code)
+	}
+	if w := s.do("/items", strings.Repeat("k", 256), `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("long key = %d, want 400", w.Code)
+	}
+
+	// Request kedua saat yang pertama masih diproses
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- s.do("/items", "slow", `{"name":"Budi"}`, "X-Block", "1") }()
+	<-s.entered
+	w := s.do("/items", "slow", `{"name":"Budi"}`)
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("in-flight duplicate = %d Retry-After %q, want 409", w.Code, w.Header().Get("Retry-After"))
+	}
+	close(s.release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("blocked request = %d", first.Code)
+	}
+	if w := s.do("/items", "slow", `{"name":"Budi"}`); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("after completion = %d, want replay", w.Code)
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	s := newIdempotencyServer(t)
+	s.do("/items", "abc", `{}`, "X-User", "admin1")
+
+	// Key yang sama di endpoint lain atau oleh admin lain diproses terpisah
+	for _, w := range []*httptest.ResponseRecorder{
+		s.do("/other", "abc", `{}`, "X-User", "admin1"),
+		s.do("/items", "abc", `{}`, "X-User", "admin2"),
+	} {
+		if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("other scope = %d replayed %q", w.Code, w.Header().Get("Idempotent-Replayed"))
+		}
+	}
+	// Tanpa header tidak ada deduplikasi
+	s.do("/items", "", `{}`)
+	s.do("/items", "", `{}`)
+	if s.calls.Load() != 5 {
+		t.Errorf("handler called %d times, want 5", s.calls.Load())
+	}
+}
+
+func TestIdempotencyReleasesKeyOnTransientFailure(t *testing.T) {
+	s := newIdempotencyServer(t)
+
+	for _, headers := range [][]string{{"X-Status", "500"}, {"X-Status", "409"}, {"X-Panic", "1"}} {
+		s.calls.Store(0)
+		key := "retry-" + headers[0] + headers[1]
+		if w := s.do("/items", key, `{}`, headers...); w.Code < 409 {
+			t.Fatalf("%v: first = %d", headers, w.Code)
+		}
+		var count int64
+		s.db.Model(&models.IdempotencyKey{}).Count(&count)
+		if count != 0 {
+			t.Fatalf("%v: %d keys left after transient failure", headers, count)
+		}
+		// Retry dengan key yang sama diproses ulang
+		if w := s.do("/items", key, `{}`); w.Code != http.StatusCreated || s.calls.Load() != 2 {
+			t.Fatalf("%v: retry = %d, calls %d", headers, w.Code, s.calls.Load())
+		}
+		s.db.Where("1 = 1").Delete(&models.IdempotencyKey{})
+	}
+}
+
+func TestIdempotencyReprocessesExpiredOrAbandonedKeys(t *testing.T) {
+	s := newIdempotencyServer(t)
+	keyHash := func(key string) string { return helpers.SHA256Hash("POST /items \n" + key) }
+	fingerprint := helpers.SHA256Hash("{}")
+	now := time.Now()
+
+	records := []models.IdempotencyKey{
+		// Sudah kedaluwarsa: response lama tidak dipakai lagi
+		{KeyHash: keyHash("expired"), Fingerprint: fingerprint, Status: models.IdempotencyCompleted, ResponseStatus: 201,
+			ResponseBody: []byte(`{"call":0}`), CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+		// Proses sebelumnya terhenti lebih lama dari lock timeout (mis. server restart)
+		{KeyHash: keyHash("abandoned"), Fingerprint: fingerprint, Status: models.IdempotencyProcessing,
+			CreatedAt: now.Add(-2 * time.Minute), ExpiresAt: now.Add(time.Hour)},
+		// Masih dalam lock timeout: tetap dianggap sedang diproses
+		{KeyHash: keyHash("recent"), Fingerprint: fingerprint, Status: models.IdempotencyProcessing,
+			CreatedAt: now.Add(-10 * time.Second), ExpiresAt: now.Add(time.Hour)},
+	}
+	if err := s.db.Create(&records).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"expired", "abandoned"} {
+		if w := s.do("/items", key, `{}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("%s: = %d replayed %q, want processed again", key, w.Code, w.Header().Get("Idempotent-Replayed"))
+		}
+	}
+	if w := s.do("/items", "recent", `{}`); w.Code != http.StatusConflict {
+		t.Errorf("recent: = %d, want 409", w.Code)
+	}
+	if s.calls.Load() != 2 {
+		t.Errorf("handler called %d times, want 2", s.calls.Load())
+	}
+}
+
+func TestCanonicalBodyAndStorableStatus(t *testing.T) {
+	if string(canonicalBody([]byte("{ \"a\" : [1, 2] }\n"))) != `{"a":[1,2]}` {
+		t.Errorf("canonicalBody did not compact JSON")
+	}
+	// Key tetap dibedakan: urutan field dan isi tidak dinormalisasi
+	if string(canonicalBody([]byte(`{"b":1,"a":2}`))) == string(canonicalBody([]byte(`{"a":2,"b":1}`))) {
+		t.Errorf("canonicalBody reordered fields")
+	}
+	if got := string(canonicalBody([]byte("bukan json"))); got != "bukan json" {
+		t.Errorf("canonicalBody(non-JSON) = %q", got)
+	}
+
+	for status, want := range map[int]bool{
+		200: true, 201: true, 400: true, 404: true, 422: true,
+		408: false, 409: false, 429: false, 500: false, 503: false,
+	} {
+		if storableStatus(status) != want {
+			t.Errorf("storableStatus(%d) = %v, want %v", status, !want, want)
+		}
+	}
+}
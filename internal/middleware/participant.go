@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// ParticipantAuth memvalidasi sesi portal pendaftar (dari magic link) dan menyimpan
+// "participant_id" ke context. Token admin tidak diterima di sini, begitu juga sebaliknya.
+func ParticipantAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := ""
+		if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+			tokenString = strings.TrimPrefix(authHeader, "Bearer ")
+		} else if cookieToken, err := c.Cookie("participant_token"); err == nil {
+			tokenString = cookieToken
+		}
+		if tokenString == "" {
+			helpers.ResponseUnauthorized(c, "Session token required (Authorization header or cookie)")
+			c.Abort()
+			return
+		}
+
+		db := c.MustGet("db").(*gorm.DB)
+		var session models.ParticipantSession
+		if err := db.Where("token_hash = ? AND expires_at > ?", helpers.SHA256Hash(tokenString), time.Now()).
+			First(&session).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				helpers.ResponseUnauthorized(c, "Invalid or expired session")
+			} else {
+				helpers.ResponseInternalServerError(c, err.Error())
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("participant_id", session.ParticipantID)
+		c.Set("participant_session_id", session.ID)
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/helpers"
+	"backend/internal/ratelimit"
+)
+
+// RateLimit membatasi jumlah request per IP client. Route yang memakai limiter yang sama berbagi kuota.
+// IP diambil dari c.ClientIP(), yang hanya mempercayai X-Forwarded-For dari proxy di TRUSTED_PROXIES.
+// Hitungan disimpan per proses: dengan beberapa instance, batas efektif dikali jumlah instance.
+func RateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, retryAfter := limiter.Allow(c.ClientIP()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			helpers.ResponseError(c, http.StatusTooManyRequests, "Terlalu banyak permintaan, coba lagi nanti")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status sertifikat
+const (
+	CertificatePending   = "pending"   // menunggu dirender worker
+	CertificateRendering = "rendering" // sedang dirender worker sampai LeaseUntil
+	CertificateGenerated = "generated" // PDF tersimpan di storage
+	CertificateFailed    = "failed"    // render gagal, lihat Error
+)
+
+// CertificateField adalah satu teks di sertifikat. Text boleh berisi placeholder seperti {name},
+// {kampus}, {event_name}, {serial}; posisi dalam milimeter dari pojok kiri atas halaman.
+type CertificateField struct {
+	Text     string  `json:"text"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`     // 0 = selebar halaman dikurangi X
+	Font     string  `json:"font"`      // Helvetica (default), Times atau Courier
+	Style    string  `json:"style"`     // "", "B", "I" atau "BI"
+	FontSize float64 `json:"font_size"` // point, default 12
+	Align    string  `json:"align"`     // L, C (default) atau R
+	Color    string  `json:"color"`     // #RRGGBB, default hitam
+}
+
+// CertificateRules menentukan participant yang berhak mendapat sertifikat
+type CertificateRules struct {
+	// Statuses adalah status pendaftaran yang boleh mendapat sertifikat, default ["attended"]
+	Statuses []string `json:"statuses"`
+	// MinAttendance adalah persentase minimal kehadiran sesi (0 = tidak dicek)
+	MinAttendance float64 `json:"min_attendance"`
+}
+
+// CertificateTemplate adalah desain sertifikat per event: gambar latar + teks yang diposisikan + QR verifikasi
+type CertificateTemplate struct {
+	ID            uint               `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID       uint               `json:"event_id" gorm:"index;not null"`
+	Name          string             `json:"name" gorm:"type:varchar(255);not null"`
+	Orientation   string             `json:"orientation" gorm:"type:varchar(1);not null;default:L"` // L (landscape) atau P
+	PageSize      string             `json:"page_size" gorm:"type:varchar(10);not null;default:A4"`
+	BackgroundKey *string            `json:"-" gorm:"type:varchar(255)"`
+	Fields        []CertificateField `json:"fields" gorm:"type:text;serializer:json"`
+	QRX           float64            `json:"qr_x"`
+	QRY           float64            `json:"qr_y"`
+	QRSize        float64            `json:"qr_size"` // mm, 0 = default 25
+	Rules         CertificateRules   `json:"rules" gorm:"type:text;serializer:json"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	// HasBackground diisi untuk response, tidak disimpan
+	HasBackground bool `json:"has_background" gorm:"-"`
+}
+
+// AfterFind mengisi HasBackground
+func (t *CertificateTemplate) AfterFind(tx *gorm.DB) error {
+	t.HasBackground = t.BackgroundKey != nil
+	return nil
+}
+
+func (CertificateTemplate) TableName() string { return "certificate_templates" }
+
+// Certificate adalah sertifikat yang diterbitkan untuk satu participant dari satu template
+type Certificate struct {
+	ID            string     `json:"id" gorm:"type:varchar(36);primaryKey"`
+	Serial        string     `json:"serial" gorm:"type:varchar(32);not null;uniqueIndex"`
+	EventID       uint       `json:"event_id" gorm:"index;not null"`
+	TemplateID    uint       `json:"template_id" gorm:"not null;uniqueIndex:idx_certificates_template_participant"`
+	ParticipantID string     `json:"participant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_certificates_template_participant;index"`
+	BatchID       *uint      `json:"batch_id" gorm:"index"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	StorageKey    *string    `json:"-" gorm:"type:varchar(255)"`
+	Error         string     `json:"error,omitempty" gorm:"type:text"`
+	LeaseUntil    *time.Time `json:"-"`
+	IssuedAt      time.Time  `json:"issued_at"`
+	GeneratedAt   *time.Time `json:"generated_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	RevokedReason string     `json:"revoked_reason,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// Participant diisi untuk response list, tidak disimpan
+	Participant *Participant `json:"participant,omitempty" gorm:"-"`
+}
+
+// BeforeCreate hook untuk generate UUID
+func (c *Certificate) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (Certificate) TableName() string { return "certificates" }
+
+// CertificateBatch mencatat progres generate sertifikat massal di background
+type CertificateBatch struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID    uint       `json:"event_id" gorm:"index;not null"`
+	TemplateID uint       `json:"template_id" gorm:"not null"`
+	Total      int        `json:"total"`
+	CreatedBy  string     `json:"created_by" gorm:"type:varchar(255)"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+func (CertificateBatch) TableName() string { return "certificate_batches" }
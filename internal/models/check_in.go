@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Cara participant diidentifikasi saat check-in
+const (
+	CheckInMethodQR   = "qr"   // scan QR tiket (signature diverifikasi)
+	CheckInMethodCode = "code" // kode registrasi diketik manual oleh petugas
+	CheckInMethodBulk = "bulk" // ditandai hadir oleh admin lewat daftar
+)
+
+// CheckIn mencatat kedatangan participant di lokasi event.
+// Check-in yang dibatalkan tidak dihapus, hanya ditandai UndoneAt supaya jejaknya tetap ada.
+type CheckIn struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	ParticipantID string     `json:"participant_id" gorm:"type:varchar(36);index;not null"`
+	EventID       *uint      `json:"event_id" gorm:"index"`
+	Gate          string     `json:"gate" gorm:"type:varchar(100)"`
+	Method        string     `json:"method" gorm:"type:varchar(10);not null"`
+	CheckedInBy   string     `json:"checked_in_by" gorm:"type:varchar(255);not null"` // Username petugas
+	CheckedInAt   time.Time  `json:"checked_in_at" gorm:"index"`
+	UndoneAt      *time.Time `json:"undone_at"`
+	UndoneBy      string     `json:"undone_by,omitempty" gorm:"type:varchar(255)"`
+	UndoReason    string     `json:"undo_reason,omitempty" gorm:"type:text"`
+	// Participant diisi untuk response list / scan, tidak disimpan
+	Participant *Participant `json:"participant,omitempty" gorm:"-"`
+}
+
+func (CheckIn) TableName() string { return "check_ins" }
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Status email transaksional di outbox
+const (
+	EmailQueued  = "queued"  // menunggu dikirim / dicoba ulang
+	EmailSent    = "sent"    // diterima server SMTP
+	EmailFailed  = "failed"  // gagal permanen atau sampai batas percobaan
+	EmailBounced = "bounced" // ditolak server penerima (saat kirim atau laporan bounce setelahnya)
+	// EmailSuppressed tidak dikirim karena alamat penerima pernah hard bounce
+	EmailSuppressed = "suppressed"
+)
+
+// Jenis bounce
+const (
+	BounceHard = "hard" // alamat tidak ada / ditolak permanen
+	BounceSoft = "soft" // kotak surat penuh, sementara tidak bisa menerima
+)
+
+// OutboundEmail adalah email transaksional ke participant, disimpan sebagai outbox yang dikirim worker
+type OutboundEmail struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ParticipantID *string    `json:"participant_id" gorm:"type:varchar(36);index"`
+	EventID       *uint      `json:"event_id" gorm:"index"`
+	TemplateKey   string     `json:"template_key" gorm:"type:varchar(50);not null;index"`
+	Language      string     `json:"language" gorm:"type:varchar(5);not null"`
+	To            string     `json:"to" gorm:"column:recipient;type:varchar(255);not null;index"`
+	Subject       string     `json:"subject" gorm:"type:varchar(998);not null"`
+	TextBody      string     `json:"text_body" gorm:"type:text;not null"`
+	HTMLBody      string     `json:"html_body" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;index:idx_outbound_emails_due,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt *time.Time `json:"next_attempt_at" gorm:"index:idx_outbound_emails_due,priority:2"`
+	// MessageID adalah header Message-ID, dipakai mencocokkan laporan bounce
+	MessageID string `json:"message_id" gorm:"type:varchar(255);not null;uniqueIndex"`
+	// SMTPCode adalah kode balasan server SMTP untuk error terakhir (0 jika bukan error SMTP)
+	SMTPCode   int        `json:"smtp_code,omitempty"`
+	LastError  string     `json:"last_error,omitempty" gorm:"type:text"`
+	BounceType string     `json:"bounce_type,omitempty" gorm:"type:varchar(10)"`
+	SentAt     *time.Time `json:"sent_at"`
+	BouncedAt  *time.Time `json:"bounced_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (OutboundEmail) TableName() string { return "outbound_emails" }
+
+// EmailTemplate menimpa teks bawaan satu jenis email untuk satu bahasa. Subject, Text dan HTML
+// berisi placeholder seperti {name}; nilai placeholder di HTML di-escape otomatis.
+type EmailTemplate struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Key       string    `json:"key" gorm:"column:template_key;type:varchar(50);not null;uniqueIndex:idx_email_templates_key_language"`
+	Language  string    `json:"language" gorm:"type:varchar(5);not null;uniqueIndex:idx_email_templates_key_language"`
+	Subject   string    `json:"subject" gorm:"type:varchar(998);not null"`
+	Text      string    `json:"text" gorm:"type:text;not null"`
+	HTML      string    `json:"html" gorm:"type:text"`
+	Active    bool      `json:"active" gorm:"not null;default:true"`
+	UpdatedBy string    `json:"updated_by" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (EmailTemplate) TableName() string { return "email_templates" }
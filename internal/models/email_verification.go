@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailVerification menyimpan token verifikasi email participant (hanya hash yang disimpan)
+type EmailVerification struct {
+	ID            uint      `gorm:"primaryKey"`
+	ParticipantID string    `gorm:"type:varchar(36);index;not null"`
+	Email         string    `gorm:"type:varchar(255);not null"`
+	TokenHash     string    `gorm:"uniqueIndex;not null;size:64"` // SHA256 hash dari token
+	ExpiresAt     time.Time `gorm:"not null"`
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func (EmailVerification) TableName() string { return "email_verifications" }
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Event adalah satu penyelenggaraan / angkatan Youth College
+type Event struct {
+	ID                   uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name                 string     `json:"name" gorm:"type:varchar(255);not null"`
+	Slug                 string     `json:"slug" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Description          string     `json:"description" gorm:"type:text"`
+	Location             string     `json:"location" gorm:"type:varchar(255)"`
+	StartDate            time.Time  `json:"start_date" gorm:"type:date;not null"`
+	EndDate              time.Time  `json:"end_date" gorm:"type:date;not null"`
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt *time.Time `json:"registration_closes_at"`
+	Capacity             *int       `json:"capacity"` // NULL = tanpa batas; jika penuh pendaftar baru otomatis waitlisted
+	// Eligibility adalah syarat pendaftar, dicek saat registrasi dan saat admin mengevaluasi ulang
+	Eligibility EligibilityRules `json:"eligibility" gorm:"type:text;serializer:json"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// EligibilityRules adalah syarat pendaftaran event. Field kosong berarti tidak ada batasan.
+type EligibilityRules struct {
+	// MinAge & MaxAge adalah batas umur (tahun penuh) pada tanggal mulai event
+	MinAge *int `json:"min_age,omitempty"`
+	MaxAge *int `json:"max_age,omitempty"`
+	// Angkatan adalah tahun angkatan yang boleh mendaftar, mis. ["2022", "2023"]
+	Angkatan []string `json:"angkatan,omitempty"`
+	// CampusIDs membatasi pendaftar ke kampus data master tertentu (kampus "lainnya" tidak lolos)
+	CampusIDs []uint `json:"campus_ids,omitempty"`
+	// RequiredAnswers adalah key pertanyaan registrasi yang wajib dijawab, termasuk pertanyaan tipe file
+	// dan dokumen "ktm" / "photo". File baru dicek saat evaluasi ulang karena diupload setelah registrasi.
+	RequiredAnswers []string `json:"required_answers,omitempty"`
+}
+
+// Empty mengecek apakah event tidak punya syarat pendaftaran
+func (r EligibilityRules) Empty() bool {
+	return r.MinAge == nil && r.MaxAge == nil && len(r.Angkatan) == 0 && len(r.CampusIDs) == 0 && len(r.RequiredAnswers) == 0
+}
+
+// BeforeCreate hook untuk set timestamps
+func (e *Event) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	e.CreatedAt = now
+	e.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate hook untuk update timestamp
+func (e *Event) BeforeUpdate(tx *gorm.DB) error {
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+// RegistrationOpen mengecek apakah pendaftaran event terbuka pada waktu t
+func (e Event) RegistrationOpen(t time.Time) bool {
+	if e.RegistrationOpensAt != nil && t.Before(*e.RegistrationOpensAt) {
+		return false
+	}
+	if e.RegistrationClosesAt != nil && !t.Before(*e.RegistrationClosesAt) {
+		return false
+	}
+	return true
+}
+
+func (Event) TableName() string { return "events" }
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Status proses idempotency key
+const (
+	IdempotencyProcessing = "processing"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyKey menyimpan response pertama untuk header Idempotency-Key, supaya request ulang
+// (mis. retry dari jaringan HP yang putus-putus) mendapat response yang sama tanpa membuat data ganda
+type IdempotencyKey struct {
+	ID uint `gorm:"primaryKey"`
+	// KeyHash adalah SHA256 dari scope (method, path, user) + key dari client
+	KeyHash string `gorm:"uniqueIndex;not null;size:64"`
+	// Fingerprint adalah SHA256 dari body request; key yang dipakai ulang dengan body lain ditolak
+	Fingerprint    string `gorm:"not null;size:64"`
+	Status         string `gorm:"type:varchar(20);not null"`
+	ResponseStatus int
+	ResponseType   string `gorm:"type:varchar(100)"`
+	ResponseBody   []byte
+	CreatedAt      time.Time
+	ExpiresAt      time.Time `gorm:"not null;index"`
+}
+
+func (IdempotencyKey) TableName() string { return "idempotency_keys" }
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Jenis jadwal job
+const (
+	TriggerCron  = "cron"  // berulang sesuai ekspresi cron
+	TriggerEvent = "event" // sekali, relatif terhadap tanggal event (mis. 1 hari sebelum mulai)
+)
+
+// Status satu kali eksekusi job
+const (
+	JobRunRunning = "running"
+	JobRunSuccess = "success"
+	JobRunFailed  = "failed"
+	JobRunSkipped = "skipped" // jadwal terlewat terlalu lama (server mati), tidak dijalankan
+)
+
+// Sumber eksekusi job
+const (
+	RunBySchedule = "schedule"
+	RunByManual   = "manual"
+)
+
+// JobParams adalah parameter job pengiriman notifikasi event
+type JobParams struct {
+	// Template adalah jenis pesan / email, mis. event_reminder atau registration_closing
+	Template string `json:"template,omitempty"`
+	// Statuses adalah status pendaftaran penerima (kosong = bawaan template)
+	Statuses []string `json:"statuses,omitempty"`
+	// Channels adalah channel pengiriman: message (WhatsApp / SMS) dan / atau email (kosong = keduanya)
+	Channels []string `json:"channels,omitempty"`
+}
+
+// ScheduledJob adalah job terjadwal yang disimpan di database. Hanya satu instance server yang
+// menjalankan job pada satu waktu (lease LockedBy / LockedUntil).
+type ScheduledJob struct {
+	ID   uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name string `json:"name" gorm:"type:varchar(100);not null"`
+	Type string `json:"type" gorm:"type:varchar(50);not null;index"`
+	// SystemKey diisi untuk job bawaan server (tidak bisa dihapus), unik supaya tidak dibuat dobel oleh replika lain
+	SystemKey *string `json:"system_key,omitempty" gorm:"type:varchar(50);uniqueIndex"`
+	EventID   *uint   `json:"event_id" gorm:"index"`
+	Trigger   string  `json:"trigger" gorm:"column:trigger_type;type:varchar(10);not null"`
+	// Cron berisi ekspresi 5 field (menit jam tanggal bulan hari) atau @daily / @hourly dst, untuk trigger cron
+	Cron string `json:"cron,omitempty" gorm:"type:varchar(100)"`
+	// Anchor, Offset & TimeOfDay untuk trigger event: waktu jalan = anchor + offset, jam diganti TimeOfDay jika diisi
+	Anchor    string    `json:"anchor,omitempty" gorm:"type:varchar(30)"`
+	Offset    string    `json:"offset,omitempty" gorm:"column:anchor_offset;type:varchar(20)"`
+	TimeOfDay string    `json:"time_of_day,omitempty" gorm:"type:varchar(5)"`
+	Params    JobParams `json:"params" gorm:"type:text;serializer:json"`
+	Paused    bool      `json:"paused" gorm:"not null;default:false"`
+	// NextRunAt NULL jika job event sudah selesai atau waktu acuan event belum diisi
+	NextRunAt *time.Time `json:"next_run_at" gorm:"index"`
+	// CompletedAt diisi setelah job event (sekali jalan) dieksekusi sesuai jadwal
+	CompletedAt *time.Time `json:"completed_at"`
+	LastRunAt   *time.Time `json:"last_run_at"`
+	LastStatus  string     `json:"last_status,omitempty" gorm:"type:varchar(20)"`
+	LastError   string     `json:"last_error,omitempty" gorm:"type:text"`
+	LockedBy    string     `json:"locked_by,omitempty" gorm:"type:varchar(100)"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (ScheduledJob) TableName() string { return "scheduled_jobs" }
+
+// JobRun adalah riwayat satu kali eksekusi job
+type JobRun struct {
+	ID    uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	JobID uint   `json:"job_id" gorm:"not null;index"`
+	RunBy string `json:"run_by" gorm:"type:varchar(10);not null"` // schedule atau manual
+	// Actor adalah username admin untuk eksekusi manual
+	Actor        string     `json:"actor,omitempty" gorm:"type:varchar(255)"`
+	Status       string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	ScheduledFor *time.Time `json:"scheduled_for"`
+	StartedAt    time.Time  `json:"started_at" gorm:"index"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	DurationMs   int64      `json:"duration_ms"`
+	Output       string     `json:"output,omitempty" gorm:"type:text"`
+	Error        string     `json:"error,omitempty" gorm:"type:text"`
+	// Instance adalah server yang menjalankan job (hostname-pid)
+	Instance string `json:"instance" gorm:"type:varchar(100)"`
+}
+
+func (JobRun) TableName() string { return "job_runs" }
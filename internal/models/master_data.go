@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Campus adalah data master kampus untuk autocomplete dan laporan
+type Campus struct {
+	ID        uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string `json:"name" gorm:"type:varchar(255);not null;uniqueIndex"`
+	ShortName string `json:"short_name" gorm:"type:varchar(50)"`
+	City      string `json:"city" gorm:"type:varchar(100)"`
+	// Aliases adalah penulisan lain yang sering dipakai pendaftar (mis. "Univ. Indonesia"),
+	// dipakai untuk pencocokan otomatis teks bebas
+	Aliases   []string  `json:"aliases" gorm:"type:text;serializer:json"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Campus) TableName() string { return "campuses" }
+
+// StudyProgram adalah data master jurusan / program studi (tidak terikat ke kampus tertentu)
+type StudyProgram struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null;uniqueIndex"`
+	Aliases   []string  `json:"aliases" gorm:"type:text;serializer:json"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (StudyProgram) TableName() string { return "study_programs" }
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Channel pesan ke nomor HP participant
+const (
+	ChannelWhatsApp = "whatsapp"
+	ChannelSMS      = "sms"
+)
+
+// Status pesan WhatsApp / SMS
+const (
+	MessageQueued    = "queued"    // menunggu dikirim / dicoba ulang
+	MessageSent      = "sent"      // diterima provider
+	MessageDelivered = "delivered" // sampai di HP penerima (laporan provider)
+	MessageRead      = "read"      // dibaca penerima (khusus WhatsApp)
+	MessageFailed    = "failed"    // gagal permanen atau sampai batas percobaan
+	MessageSkipped   = "skipped"   // tidak dikirim karena nomor sudah opt-out
+)
+
+// OutboundMessage adalah pesan WhatsApp / SMS untuk participant, disimpan sebagai antrian persisten
+type OutboundMessage struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ParticipantID string     `json:"participant_id" gorm:"type:varchar(36);not null;index"`
+	EventID       *uint      `json:"event_id" gorm:"index"`
+	Channel       string     `json:"channel" gorm:"type:varchar(20);not null"`
+	To            string     `json:"to" gorm:"column:recipient;type:varchar(20);not null"` // E.164
+	TemplateKey   string     `json:"template_key" gorm:"type:varchar(50);not null;index"`
+	Body          string     `json:"body" gorm:"type:text;not null"`
+	Params        []string   `json:"-" gorm:"type:text;serializer:json"` // parameter template WhatsApp, urut
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;index:idx_outbound_messages_due,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt *time.Time `json:"next_attempt_at" gorm:"index:idx_outbound_messages_due,priority:2"`
+	Provider      string     `json:"provider" gorm:"type:varchar(20)"`
+	// ProviderMessageID dipakai mencocokkan laporan status dari provider (mis. wamid WhatsApp)
+	ProviderMessageID *string    `json:"provider_message_id" gorm:"type:varchar(255);uniqueIndex"`
+	LastError         string     `json:"last_error,omitempty" gorm:"type:text"`
+	SentAt            *time.Time `json:"sent_at"`
+	DeliveredAt       *time.Time `json:"delivered_at"`
+	ReadAt            *time.Time `json:"read_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func (OutboundMessage) TableName() string { return "outbound_messages" }
+
+// MessageTemplate menimpa teks bawaan satu jenis pesan. Body berisi placeholder seperti {name},
+// {event_name}, {registration_code}; WhatsAppTemplate dipakai untuk pesan di luar jendela 24 jam
+// WhatsApp (template yang sudah disetujui Meta, parameter body diisi sesuai WhatsAppParams).
+type MessageTemplate struct {
+	ID               uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Key              string    `json:"key" gorm:"column:template_key;type:varchar(50);not null;uniqueIndex"`
+	Body             string    `json:"body" gorm:"type:text;not null"`
+	WhatsAppTemplate string    `json:"whatsapp_template" gorm:"type:varchar(255)"`
+	WhatsAppLanguage string    `json:"whatsapp_language" gorm:"type:varchar(10)"`
+	WhatsAppParams   []string  `json:"whatsapp_params" gorm:"type:text;serializer:json"`
+	Active           bool      `json:"active" gorm:"not null;default:true"`
+	UpdatedBy        string    `json:"updated_by" gorm:"type:varchar(255)"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (MessageTemplate) TableName() string { return "message_templates" }
+
+// MessageOptOut mencatat nomor HP yang tidak mau menerima WhatsApp / SMS. Dicocokkan lewat
+// PhoneKey sehingga berlaku untuk semua pendaftaran dengan nomor yang sama.
+type MessageOptOut struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	PhoneKey  string    `json:"-" gorm:"type:varchar(20);not null;uniqueIndex"`
+	Phone     string    `json:"phone" gorm:"type:varchar(20);not null"`
+	Source    string    `json:"source" gorm:"type:varchar(20);not null"` // participant, admin atau inbound (balasan STOP)
+	Reason    string    `json:"reason,omitempty" gorm:"type:text"`
+	CreatedBy string    `json:"created_by,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (MessageOptOut) TableName() string { return "message_opt_outs" }
@@ -1,23 +1,56 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"backend/internal/helpers"
 )
 
 type Participant struct {
 	ID        string    `json:"id" gorm:"type:varchar(36);primaryKey"`
+	EventID   *uint     `json:"event_id" gorm:"index"` // NULL untuk pendaftaran sebelum ada events
 	Name      string    `json:"name" gorm:"type:varchar(255);not null" binding:"required,min=2,max=100"`
 	Place     string    `json:"place" gorm:"type:varchar(255);not null" binding:"required,min=2,max=255"`
 	BirthDate time.Time `json:"birth_date" gorm:"type:date;not null" binding:"required"`
 	Kampus    string    `json:"kampus" gorm:"type:varchar(255);not null" binding:"required"`
 	Jurusan   string    `json:"jurusan" gorm:"type:varchar(255);not null" binding:"required,min=2,max=255"`
 	Angkatan  string    `json:"angkatan" gorm:"type:varchar(255);not null" binding:"required,min=2,max=255"`
-	Phone     string    `json:"phone" gorm:"type:varchar(20);not null" binding:"required,min=8,max=20"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Phone     string    `json:"phone" gorm:"type:varchar(20);not null" binding:"required,min=8,max=20"` // Format E.164 (+628...)
+	// PhoneInput adalah nomor HP persis seperti yang diketik pendaftar, untuk ditampilkan
+	PhoneInput string `json:"phone_input" gorm:"type:varchar(30)"`
+	// Email opsional (bisa diwajibkan lewat EMAIL_REQUIRED), NULL jika tidak diisi
+	Email           *string    `json:"email" gorm:"type:varchar(255);index"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// Language adalah bahasa email ke pendaftar (id / en), dari pilihan form atau Accept-Language saat daftar
+	Language string `json:"language" gorm:"type:varchar(5);not null;default:id"`
+	// Status pendaftaran, hanya berubah lewat workflow.Transition
+	Status string `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	// CampusID & StudyProgramID menautkan ke data master; NULL jika kampus / jurusan diisi teks bebas ("lainnya").
+	// Kampus & Jurusan tetap diisi (nama kanonik jika tertaut) untuk tampilan, pencarian dan laporan.
+	CampusID       *uint `json:"campus_id" gorm:"index"`
+	StudyProgramID *uint `json:"study_program_id" gorm:"index"`
+	// IneligibleAt diisi jika participant tidak memenuhi syarat event saat evaluasi ulang terakhir,
+	// alasannya di EligibilityIssues (NULL / kosong jika memenuhi syarat)
+	IneligibleAt      *time.Time         `json:"ineligible_at" gorm:"index"`
+	EligibilityIssues []EligibilityIssue `json:"eligibility_issues,omitempty" gorm:"type:text;serializer:json"`
+	// RegistrationCode adalah kode pendek untuk pendaftar (XXXX-XXXX), dipakai di tiket dan saat check-in
+	RegistrationCode *string `json:"registration_code" gorm:"type:varchar(9);uniqueIndex"`
+	// PhoneKey adalah nomor HP yang dinormalisasi untuk deteksi duplikat
+	PhoneKey string `json:"-" gorm:"type:varchar(20);index"`
+	// PossibleDuplicateOf berisi ID participant lain jika pendaftaran ini ditandai sebagai kemungkinan duplikat
+	PossibleDuplicateOf *string   `json:"possible_duplicate_of" gorm:"type:varchar(36);index"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	// Answers adalah jawaban pertanyaan registrasi event (key -> nilai), diisi lewat questions.Attach
+	Answers map[string]interface{} `json:"answers,omitempty" gorm:"-"`
+	// UploadToken hanya dikirim di response registrasi, dipakai pendaftar untuk upload file mandiri
+	UploadToken string `json:"upload_token,omitempty" gorm:"-"`
+	// TicketURL hanya dikirim di response registrasi, link tiket PDF yang ditandatangani
+	TicketURL string `json:"ticket_url,omitempty" gorm:"-"`
 }
 
 // BeforeCreate hook untuk generate UUID dan set timestamps
@@ -25,16 +58,59 @@ func (p *Participant) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == "" {
 		p.ID = uuid.New().String()
 	}
+	if p.RegistrationCode == nil {
+		code, err := UniqueRegistrationCode(tx)
+		if err != nil {
+			return err
+		}
+		p.RegistrationCode = &code
+	}
+	if p.Status == "" {
+		p.Status = StatusPending
+	}
 	now := time.Now()
 	p.CreatedAt = now
 	p.UpdatedAt = now
 	return nil
 }
 
+// BeforeSave hook untuk menjaga PhoneKey tetap sinkron dengan Phone
+func (p *Participant) BeforeSave(tx *gorm.DB) error {
+	p.PhoneKey = helpers.PhoneKey(p.Phone)
+	return nil
+}
+
 // BeforeUpdate hook untuk update timestamp
 func (p *Participant) BeforeUpdate(tx *gorm.DB) error {
 	p.UpdatedAt = time.Now()
 	return nil
 }
 
+// UniqueRegistrationCode membuat kode registrasi yang belum dipakai participant lain.
+// Tabrakan sangat jarang (ruang kode ±8,5e11), pengecekan ini hanya pengaman.
+func UniqueRegistrationCode(tx *gorm.DB) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := helpers.GenerateRegistrationCode()
+		if err != nil {
+			return "", err
+		}
+		var count int64
+		if err := tx.Session(&gorm.Session{NewDB: true}).Model(&Participant{}).
+			Where("registration_code = ?", code).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return code, nil
+		}
+	}
+	return "", errors.New("gagal membuat kode registrasi unik")
+}
+
 func (Participant) TableName() string { return "participants" }
+
+// EligibilityIssue adalah satu syarat event yang tidak dipenuhi participant
+type EligibilityIssue struct {
+	Rule    string `json:"rule"`    // min_age, max_age, angkatan, campus, required_answer, required_file
+	Field   string `json:"field"`   // field form yang bermasalah, mis. birth_date atau answers.ukuran_kaos
+	Message string `json:"message"` // pesan sesuai bahasa pendaftar
+}
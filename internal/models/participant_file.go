@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ParticipantFile adalah file yang diupload untuk participant (foto KTM, pas foto, atau jawaban pertanyaan tipe file)
+type ParticipantFile struct {
+	ID            string `json:"id" gorm:"type:varchar(36);primaryKey"`
+	ParticipantID string `json:"participant_id" gorm:"type:varchar(36);not null;index"`
+	// Kind adalah jenis file: ktm, photo, atau key pertanyaan registrasi tipe file
+	Kind         string `json:"kind" gorm:"type:varchar(100);not null"`
+	OriginalName string `json:"original_name" gorm:"type:varchar(255)"`
+	ContentType  string `json:"content_type" gorm:"type:varchar(100);not null"` // Hasil content sniffing, bukan dari header request
+	Size         int64  `json:"size" gorm:"not null"`
+	Checksum     string `json:"checksum" gorm:"type:varchar(64);not null"` // SHA256 hex
+	StorageKey   string `json:"-" gorm:"type:varchar(255);not null"`
+	// ThumbnailKey hanya ada untuk gambar
+	ThumbnailKey *string   `json:"-" gorm:"type:varchar(255)"`
+	UploadedBy   string    `json:"uploaded_by" gorm:"type:varchar(255);not null"` // Username admin, atau "participant" untuk upload mandiri
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook untuk generate UUID dan set timestamp
+func (f *ParticipantFile) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	f.CreatedAt = time.Now()
+	return nil
+}
+
+func (ParticipantFile) TableName() string { return "participant_files" }
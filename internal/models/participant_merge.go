@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// ParticipantMerge mencatat audit penggabungan dua data participant
+type ParticipantMerge struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PrimaryID string    `json:"primary_id" gorm:"type:varchar(36);index;not null"`
+	MergedID  string    `json:"merged_id" gorm:"type:varchar(36);index;not null"`
+	Fields    string    `json:"fields" gorm:"type:text"`                     // Field yang diambil dari data duplikat (dipisah koma)
+	Snapshot  string    `json:"snapshot" gorm:"type:text;not null"`          // JSON data duplikat sebelum dihapus
+	MergedBy  string    `json:"merged_by" gorm:"type:varchar(255);not null"` // Username admin yang melakukan merge
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (ParticipantMerge) TableName() string { return "participant_merges" }
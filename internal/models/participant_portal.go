@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// ParticipantMagicLink menyimpan token sekali pakai yang dikirim ke pendaftar untuk masuk ke portal
+// (hanya hash yang disimpan)
+type ParticipantMagicLink struct {
+	ID            uint      `gorm:"primaryKey"`
+	ParticipantID string    `gorm:"type:varchar(36);index;not null"`
+	TokenHash     string    `gorm:"uniqueIndex;not null;size:64"` // SHA256 hash dari token
+	ExpiresAt     time.Time `gorm:"not null"`
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+func (ParticipantMagicLink) TableName() string { return "participant_magic_links" }
+
+// ParticipantSession adalah sesi portal pendaftar hasil menukar magic link.
+// Terpisah dari JWT admin: token acak (bukan JWT) sehingga tidak pernah diterima AuthMiddleware.
+type ParticipantSession struct {
+	ID            uint      `gorm:"primaryKey"`
+	ParticipantID string    `gorm:"type:varchar(36);index;not null"`
+	TokenHash     string    `gorm:"uniqueIndex;not null;size:64"` // SHA256 hash dari token
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time
+}
+
+func (ParticipantSession) TableName() string { return "participant_sessions" }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// Status pendaftaran participant
+const (
+	StatusPending    = "pending"
+	StatusApproved   = "approved"
+	StatusRejected   = "rejected"
+	StatusWaitlisted = "waitlisted"
+	StatusWithdrawn  = "withdrawn"
+	StatusAttended   = "attended"
+)
+
+// ParticipantStatuses adalah semua status yang valid, sesuai urutan funnel
+var ParticipantStatuses = []string{StatusPending, StatusWaitlisted, StatusApproved, StatusRejected, StatusWithdrawn, StatusAttended}
+
+// IsValidParticipantStatus mengecek apakah status dikenal
+func IsValidParticipantStatus(status string) bool {
+	for _, s := range ParticipantStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ParticipantStatusHistory mencatat setiap perubahan status participant
+type ParticipantStatusHistory struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ParticipantID string    `json:"participant_id" gorm:"type:varchar(36);index;not null"`
+	FromStatus    string    `json:"from_status" gorm:"type:varchar(20);not null"`
+	ToStatus      string    `json:"to_status" gorm:"type:varchar(20);not null"`
+	Reason        string    `json:"reason" gorm:"type:text"`
+	Actor         string    `json:"actor" gorm:"type:varchar(255);not null"` // Username admin atau "system"
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (ParticipantStatusHistory) TableName() string { return "participant_status_histories" }
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipe pertanyaan registrasi
+const (
+	QuestionText        = "text"
+	QuestionNumber      = "number"
+	QuestionSelect      = "select"
+	QuestionMultiSelect = "multi_select"
+	QuestionDate        = "date"
+	QuestionBoolean     = "boolean"
+	QuestionFile        = "file"
+)
+
+// QuestionTypes adalah semua tipe pertanyaan yang didukung
+var QuestionTypes = []string{
+	QuestionText, QuestionNumber, QuestionSelect, QuestionMultiSelect, QuestionDate, QuestionBoolean, QuestionFile,
+}
+
+// QuestionRules adalah aturan validasi tambahan, hanya field yang relevan dengan tipe pertanyaan yang dipakai
+type QuestionRules struct {
+	MinLength     *int     `json:"min_length,omitempty"`     // text
+	MaxLength     *int     `json:"max_length,omitempty"`     // text
+	Pattern       string   `json:"pattern,omitempty"`        // text, regex Go
+	Min           *float64 `json:"min,omitempty"`            // number
+	Max           *float64 `json:"max,omitempty"`            // number
+	Integer       bool     `json:"integer,omitempty"`        // number, hanya bilangan bulat
+	MinSelections *int     `json:"min_selections,omitempty"` // multi_select
+	MaxSelections *int     `json:"max_selections,omitempty"` // multi_select
+	MinDate       string   `json:"min_date,omitempty"`       // date, YYYY-MM-DD
+	MaxDate       string   `json:"max_date,omitempty"`       // date, YYYY-MM-DD
+}
+
+// RegistrationQuestion adalah pertanyaan tambahan di form registrasi sebuah event
+// (mis. ukuran kaos, motivasi, kebutuhan makanan)
+type RegistrationQuestion struct {
+	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID  uint   `json:"event_id" gorm:"not null;uniqueIndex:idx_registration_questions_event_key"`
+	Key      string `json:"key" gorm:"column:question_key;type:varchar(100);not null;uniqueIndex:idx_registration_questions_event_key"` // Nama field di body answers
+	Label    string `json:"label" gorm:"type:varchar(255);not null"`
+	HelpText string `json:"help_text" gorm:"type:text"`
+	Type     string `json:"type" gorm:"type:varchar(20);not null"`
+	Required bool   `json:"required" gorm:"not null"`
+	// Options berisi pilihan untuk select / multi_select
+	Options   []string      `json:"options" gorm:"type:text;serializer:json"`
+	Rules     QuestionRules `json:"rules" gorm:"type:text;serializer:json"`
+	Position  int           `json:"position" gorm:"not null"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// BeforeCreate hook untuk set timestamps
+func (q *RegistrationQuestion) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	q.CreatedAt = now
+	q.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate hook untuk update timestamp
+func (q *RegistrationQuestion) BeforeUpdate(tx *gorm.DB) error {
+	q.UpdatedAt = time.Now()
+	return nil
+}
+
+func (RegistrationQuestion) TableName() string { return "registration_questions" }
+
+// ParticipantAnswer adalah jawaban participant untuk satu pertanyaan registrasi.
+// Value disimpan dalam bentuk kanonik: angka tanpa format, tanggal YYYY-MM-DD, boolean "true"/"false",
+// multi_select sebagai JSON array.
+type ParticipantAnswer struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ParticipantID string    `json:"participant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_participant_answers_participant_question"`
+	QuestionID    uint      `json:"question_id" gorm:"not null;index;uniqueIndex:idx_participant_answers_participant_question"`
+	Value         string    `json:"value" gorm:"type:text;not null"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (ParticipantAnswer) TableName() string { return "participant_answers" }
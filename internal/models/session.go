@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session adalah satu sesi di jadwal event (materi, pembicara, ruangan)
+type Session struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID   uint      `json:"event_id" gorm:"index;not null"`
+	Title     string    `json:"title" gorm:"type:varchar(255);not null"`
+	Speaker   string    `json:"speaker" gorm:"type:varchar(255)"`
+	Room      string    `json:"room" gorm:"type:varchar(100)"`
+	StartsAt  time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt    time.Time `json:"ends_at" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook untuk set timestamps
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate hook untuk update timestamp
+func (s *Session) BeforeUpdate(tx *gorm.DB) error {
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+func (Session) TableName() string { return "sessions" }
+
+// SessionAttendance mencatat participant hadir di satu sesi. Tidak ada baris = tidak hadir.
+type SessionAttendance struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	SessionID     uint      `json:"session_id" gorm:"not null;uniqueIndex:idx_session_attendances_session_participant"`
+	ParticipantID string    `json:"participant_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_session_attendances_session_participant;index"`
+	Method        string    `json:"method" gorm:"type:varchar(10);not null"` // qr, code atau bulk
+	RecordedBy    string    `json:"recorded_by" gorm:"type:varchar(255);not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (SessionAttendance) TableName() string { return "session_attendances" }
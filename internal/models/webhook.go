@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending = "pending" // menunggu dikirim / dicoba ulang
+	WebhookDeliverySuccess = "success" // penerima membalas 2xx
+	WebhookDeliveryFailed  = "failed"  // gagal sampai batas percobaan
+)
+
+// Webhook adalah langganan sistem luar (sistem kampus partner, Google Sheets, dll) terhadap event pendaftaran
+type Webhook struct {
+	ID         uint     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name       string   `json:"name" gorm:"type:varchar(255);not null"`
+	URL        string   `json:"url" gorm:"type:varchar(2048);not null"`
+	Secret     string   `json:"-" gorm:"type:varchar(255);not null"`
+	EventTypes []string `json:"event_types" gorm:"type:text;serializer:json"` // "*" = semua tipe
+	EventID    *uint    `json:"event_id" gorm:"index"`                        // nil = semua event
+	Active     bool     `json:"active" gorm:"not null;default:true"`
+	// ConsecutiveFailures menghitung percobaan gagal berturut-turut, reset saat ada yang berhasil
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"not null;default:0"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	DisabledReason      string     `json:"disabled_reason,omitempty" gorm:"type:varchar(255)"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	LastFailureAt       *time.Time `json:"last_failure_at"`
+	CreatedBy           string     `json:"created_by" gorm:"type:varchar(255)"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func (Webhook) TableName() string { return "webhooks" }
+
+// Subscribes mengecek apakah webhook berlangganan tipe event ini untuk event (acara) tertentu
+func (w Webhook) Subscribes(eventType string, eventID *uint) bool {
+	if w.EventID != nil && (eventID == nil || *eventID != *w.EventID) {
+		return false
+	}
+	for _, t := range w.EventTypes {
+		if t == "*" || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery adalah satu payload yang dikirim ke satu webhook, dicoba ulang dengan backoff sampai berhasil
+type WebhookDelivery struct {
+	ID        uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	WebhookID uint   `json:"webhook_id" gorm:"not null;index"`
+	EventType string `json:"event_type" gorm:"type:varchar(50);not null;index"`
+	// MessageID sama untuk redelivery, dipakai penerima untuk membuang pesan ganda
+	MessageID     string     `json:"message_id" gorm:"type:varchar(36);not null;index"`
+	Payload       string     `json:"payload,omitempty" gorm:"type:text;not null"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt *time.Time `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	LastStatus    int        `json:"last_status_code"`
+	LastError     string     `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	RedeliveryOf  *uint      `json:"redelivery_of"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	// AttemptLog diisi untuk response detail, tidak disimpan
+	AttemptLog []WebhookAttempt `json:"attempt_log,omitempty" gorm:"-"`
+}
+
+func (WebhookDelivery) TableName() string { return "webhook_deliveries" }
+
+// WebhookAttempt mencatat satu percobaan HTTP untuk sebuah delivery
+type WebhookAttempt struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	DeliveryID uint      `json:"delivery_id" gorm:"not null;index"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty" gorm:"type:text"`
+	Response   string    `json:"response,omitempty" gorm:"type:text"` // dipotong maksimal 2 KB
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (WebhookAttempt) TableName() string { return "webhook_attempts" }
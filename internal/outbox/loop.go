@@ -0,0 +1,99 @@
+// Package outbox menjalankan antrian pengiriman yang disimpan di database (email, WhatsApp / SMS,
+// webhook): worker background yang mengambil baris jatuh tempo dengan lease, mencoba ulang dengan
+// backoff eksponensial, dan bisa dihentikan dengan rapi saat server shutdown.
+package outbox
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Loop menjalankan satu putaran kerja berulang di background: langsung lagi selama putaran
+// memproses batch penuh, selain itu menunggu PollInterval atau Kick.
+type Loop struct {
+	// Name dipakai di log, mis. "Email worker"
+	Name string
+	// BatchSize adalah jumlah baris per putaran; putaran yang mengambil kurang dari ini berarti antrian habis
+	BatchSize int
+	// PollInterval adalah jeda worker mengecek antrian jika tidak di-Kick
+	PollInterval time.Duration
+	// Round memproses satu batch dan mengembalikan jumlah baris yang diambil. ctx dibatalkan saat
+	// Stop; Round sebaiknya berhenti mengambil baris baru tapi menyelesaikan yang sedang dikerjakan.
+	Round func(ctx context.Context) (int, error)
+
+	kick    chan struct{}
+	mu      sync.Mutex
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewLoop membuat loop; panggil Start untuk menjalankannya
+func NewLoop(name string, batchSize int, pollInterval time.Duration, round func(ctx context.Context) (int, error)) *Loop {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Loop{
+		Name: name, BatchSize: batchSize, PollInterval: pollInterval, Round: round,
+		kick: make(chan struct{}, 1), ctx: ctx, cancel: cancel, done: make(chan struct{}),
+	}
+}
+
+// Start menjalankan loop di background (sekali per proses)
+func (l *Loop) Start() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.started {
+		return
+	}
+	l.started = true
+	go l.run()
+}
+
+// Stop menghentikan loop setelah putaran yang sedang berjalan selesai (atau ctx habis).
+// Baris yang belum diproses tetap di database dan dilanjutkan saat server start lagi.
+func (l *Loop) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	started := l.started
+	l.mu.Unlock()
+	l.cancel()
+	if !started {
+		return nil
+	}
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Kick membangunkan loop tanpa menunggu PollInterval
+func (l *Loop) Kick() {
+	select {
+	case l.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (l *Loop) run() {
+	defer close(l.done)
+	for {
+		for l.ctx.Err() == nil {
+			n, err := l.Round(l.ctx)
+			if err != nil {
+				log.Printf("ERROR: %s: %v", l.Name, err)
+			}
+			if n < l.BatchSize || err != nil {
+				break
+			}
+		}
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-l.kick:
+		case <-time.After(l.PollInterval):
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultBatchSize adalah jumlah baris yang diambil worker per putaran
+const DefaultBatchSize = 20
+
+// ErrSkip dikembalikan Send jika baris yang sudah diambil belum boleh dikirim sekarang (mis. webhook
+// dinonaktifkan di tengah putaran). Lease dilepas tanpa menghitung percobaan dan Record tidak dipanggil.
+var ErrSkip = errors.New("outbox: pengiriman ditunda")
+
+// Retry mengatur percobaan ulang pengiriman
+type Retry struct {
+	// MaxAttempts adalah jumlah percobaan per baris sebelum dianggap gagal
+	MaxAttempts int
+	// BaseBackoff & MaxBackoff: jeda sebelum percobaan ke-n adalah BaseBackoff * 2^(n-1), maksimal MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Backoff menghitung jeda sebelum percobaan berikutnya setelah attempts kali gagal
+func (r Retry) Backoff(attempts int) time.Duration {
+	delay := r.BaseBackoff
+	for i := 1; i < attempts && delay < r.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+	return delay
+}
+
+// Options mengatur antrian yang dikerjakan Worker. Tabel antrian harus punya kolom status,
+// attempts dan next_attempt_at.
+type Options struct {
+	// Name dipakai di log, mis. "Email worker"
+	Name string
+	// PendingStatus adalah nilai kolom status untuk baris yang menunggu dikirim
+	PendingStatus string
+	Retry         Retry
+	// SendTimeout adalah batas waktu satu pengiriman; lease baris = SendTimeout + 1 menit
+	SendTimeout time.Duration
+	// PollInterval adalah jeda worker mengecek baris yang jatuh tempo
+	PollInterval time.Duration
+	// BatchSize default DefaultBatchSize
+	BatchSize int
+	// Scope (opsional) menambah filter ke query baris jatuh tempo
+	Scope func(*gorm.DB) *gorm.DB
+	// Permanent (opsional) menandai error yang tidak perlu dicoba ulang
+	Permanent func(error) bool
+}
+
+// Result adalah hasil satu percobaan pengiriman yang diteruskan ke Record
+type Result struct {
+	// Err adalah error pengiriman (nil = terkirim)
+	Err error
+	// Attempts adalah jumlah percobaan termasuk yang ini
+	Attempts int
+	// Final true jika baris tidak dicoba lagi: terkirim, error permanen atau percobaan habis
+	Final bool
+	// NextAttemptAt adalah jadwal percobaan berikutnya (nil jika Final)
+	NextAttemptAt *time.Time
+	// StartedAt & FinishedAt adalah waktu pengiriman dimulai dan selesai
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Handler adalah bagian yang berbeda per antrian: cara mengirim dan cara mencatat hasilnya
+type Handler[T any] struct {
+	// Attempts membaca jumlah percobaan sebelumnya dari baris
+	Attempts func(row *T) int
+	// Send mengirim satu baris yang sudah diambil. ctx dibatasi SendTimeout dan tidak ikut
+	// dibatalkan oleh Stop, supaya pengiriman yang sedang berjalan selesai.
+	Send func(ctx context.Context, row *T) error
+	// Record mencatat hasil percobaan: status, attempts, next_attempt_at dan kolom lain milik antrian
+	Record func(row *T, res Result)
+}
+
+// Worker mengirim baris antrian bertipe T yang jatuh tempo di background. Baris diambil dengan
+// update bersyarat (lease) sehingga aman dijalankan di beberapa instance sekaligus; jika proses
+// mati di tengah pengiriman, baris dicoba lagi setelah lease habis.
+type Worker[T any] struct {
+	*Loop
+	DB      *gorm.DB
+	Options Options
+	Handler Handler[T]
+}
+
+// New membuat worker; panggil Start untuk menjalankannya
+func New[T any](db *gorm.DB, opts Options, handler Handler[T]) *Worker[T] {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	w := &Worker[T]{DB: db, Options: opts, Handler: handler}
+	w.Loop = NewLoop(opts.Name, opts.BatchSize, opts.PollInterval, w.processDue)
+	return w
+}
+
+// ProcessDue mengirim satu putaran baris yang jatuh tempo sekarang, mengembalikan jumlah yang diambil
+func (w *Worker[T]) ProcessDue() (int, error) {
+	return w.processDue(context.Background())
+}
+
+func (w *Worker[T]) processDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	query := w.DB.Where("status = ? AND next_attempt_at <= ?", w.Options.PendingStatus, now)
+	if w.Options.Scope != nil {
+		query = w.Options.Scope(query)
+	}
+	var due []T
+	if err := query.Order("next_attempt_at asc, id asc").Limit(w.Options.BatchSize).Find(&due).Error; err != nil {
+		return 0, err
+	}
+	for i := range due {
+		// Saat Stop, baris yang belum diambil ditinggal untuk putaran berikutnya / instance lain
+		if ctx.Err() != nil {
+			break
+		}
+		if w.claim(&due[i], now) {
+			w.deliver(&due[i], now)
+		}
+	}
+	return len(due), nil
+}
+
+// claim menggeser next_attempt_at ke akhir lease sebelum dikirim supaya instance lain (atau
+// putaran berikutnya) tidak mengirim baris yang sama
+func (w *Worker[T]) claim(row *T, now time.Time) bool {
+	result := w.DB.Model(row).
+		Where("status = ? AND next_attempt_at <= ?", w.Options.PendingStatus, now).
+		UpdateColumn("next_attempt_at", now.Add(w.Options.SendTimeout+time.Minute))
+	if result.Error != nil {
+		log.Printf("ERROR: %s: gagal mengambil baris: %v", w.Options.Name, result.Error)
+		return false
+	}
+	return result.RowsAffected == 1
+}
+
+// deliver mengirim satu baris yang sudah diambil lalu meneruskan hasilnya ke Record
+func (w *Worker[T]) deliver(row *T, claimedAt time.Time) {
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), w.Options.SendTimeout)
+	err := w.Handler.Send(ctx, row)
+	cancel()
+	finished := time.Now()
+
+	if errors.Is(err, ErrSkip) {
+		if err := w.DB.Model(row).UpdateColumn("next_attempt_at", claimedAt).Error; err != nil {
+			log.Printf("ERROR: %s: gagal melepas baris: %v", w.Options.Name, err)
+		}
+		return
+	}
+
+	res := Result{Err: err, Attempts: w.Handler.Attempts(row) + 1, StartedAt: started, FinishedAt: finished}
+	permanent := err != nil && w.Options.Permanent != nil && w.Options.Permanent(err)
+	if err == nil || permanent || res.Attempts >= w.Options.Retry.MaxAttempts {
+		res.Final = true
+	} else {
+		next := finished.Add(w.Options.Retry.Backoff(res.Attempts))
+		res.NextAttemptAt = &next
+	}
+	w.Handler.Record(row, res)
+}
@@ -0,0 +1,225 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/testutil"
+)
+
+// testRow adalah tabel antrian minimal untuk menguji worker
+type testRow struct {
+	ID            uint `gorm:"primaryKey;autoIncrement"`
+	Kind          string
+	Status        string
+	Attempts      int
+	NextAttemptAt *time.Time
+	LastError     string
+}
+
+func (testRow) TableName() string { return "test_rows" }
+
+var errPermanent = errors.New("ditolak permanen")
+
+// newTestWorker membuat worker yang gagal sementara untuk Kind "flaky", gagal permanen untuk
+// "reject", menunda "later" dan berhasil untuk selain itu
+func newTestWorker(t *testing.T, db *gorm.DB) (*Worker[testRow], *atomic.Int32) {
+	t.Helper()
+	sends := &atomic.Int32{}
+	var w *Worker[testRow]
+	w = New(db, Options{
+		Name: "Test worker", PendingStatus: "queued", SendTimeout: time.Second, PollInterval: time.Hour, BatchSize: 5,
+		Retry:     Retry{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour},
+		Permanent: func(err error) bool { return errors.Is(err, errPermanent) },
+	}, Handler[testRow]{
+		Attempts: func(row *testRow) int { return row.Attempts },
+		Send: func(ctx context.Context, row *testRow) error {
+			switch row.Kind {
+			case "later":
+				return ErrSkip
+			case "flaky":
+				sends.Add(1)
+				return errors.New("timeout")
+			case "reject":
+				sends.Add(1)
+				return errPermanent
+			}
+			sends.Add(1)
+			time.Sleep(5 * time.Millisecond) // beri kesempatan worker lain berebut baris yang sama
+			return nil
+		},
+		Record: func(row *testRow, res Result) {
+			updates := map[string]interface{}{"attempts": res.Attempts, "next_attempt_at": res.NextAttemptAt}
+			switch {
+			case res.Err == nil:
+				updates["status"] = "sent"
+			case res.Final:
+				updates["status"], updates["last_error"] = "failed", res.Err.Error()
+			default:
+				updates["last_error"] = res.Err.Error()
+			}
+			if err := w.DB.Model(row).UpdateColumns(updates).Error; err != nil {
+				t.Errorf("record: %v", err)
+			}
+		},
+	})
+	return w, sends
+}
+
+func enqueue(t *testing.T, db *gorm.DB, kind string) testRow {
+	t.Helper()
+	now := time.Now().Add(-time.Second)
+	row := testRow{Kind: kind, Status: "queued", NextAttemptAt: &now}
+	if err := db.Create(&row).Error; err != nil {
+		t.Fatal(err)
+	}
+	return row
+}
+
+func reload(t *testing.T, db *gorm.DB, id uint) testRow {
+	t.Helper()
+	var row testRow
+	if err := db.First(&row, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return row
+}
+
+func TestRetryBackoff(t *testing.T) {
+	r := Retry{BaseBackoff: 30 * time.Second, MaxBackoff: 10 * time.Minute}
+	for attempts, want := range map[int]time.Duration{
+		0: 30 * time.Second, 1: 30 * time.Second, 2: time.Minute, 3: 2 * time.Minute,
+		5: 8 * time.Minute, 6: 10 * time.Minute, 50: 10 * time.Minute,
+	} {
+		if got := r.Backoff(attempts); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+	if got := (Retry{BaseBackoff: time.Hour, MaxBackoff: time.Minute}).Backoff(1); got != time.Minute {
+		t.Errorf("Backoff with base > max = %s", got)
+	}
+}
+
+func TestWorkerRetriesUntilFinal(t *testing.T) {
+	db := testutil.OpenDB(t, &testRow{})
+	w, sends := newTestWorker(t, db)
+	flaky := enqueue(t, db, "flaky")
+	reject := enqueue(t, db, "reject")
+	ok := enqueue(t, db, "ok")
+
+	if n, err := w.ProcessDue(); err != nil || n != 3 {
+		t.Fatalf("ProcessDue = %d, %v; want 3", n, err)
+	}
+	if row := reload(t, db, ok.ID); row.Status != "sent" || row.Attempts != 1 || row.NextAttemptAt != nil {
+		t.Errorf("ok = %+v", row)
+	}
+	// Error permanen tidak dicoba lagi walaupun percobaan belum habis
+	if row := reload(t, db, reject.ID); row.Status != "failed" || row.Attempts != 1 || row.LastError != errPermanent.Error() {
+		t.Errorf("reject = %+v", row)
+	}
+	row := reload(t, db, flaky.ID)
+	if row.Status != "queued" || row.Attempts != 1 || row.NextAttemptAt == nil {
+		t.Fatalf("flaky after first attempt = %+v", row)
+	}
+	if wait := time.Until(*row.NextAttemptAt); wait < 59*time.Second || wait > time.Minute {
+		t.Errorf("next attempt in %s, want 1m backoff", wait)
+	}
+
+	// Belum jatuh tempo: tidak diambil
+	if n, _ := w.ProcessDue(); n != 0 {
+		t.Fatalf("ProcessDue before backoff = %d", n)
+	}
+	for attempt := 2; attempt <= 3; attempt++ {
+		db.Model(&row).UpdateColumn("next_attempt_at", time.Now().Add(-time.Second))
+		w.ProcessDue()
+		row = reload(t, db, flaky.ID)
+	}
+	if row.Status != "failed" || row.Attempts != 3 || row.NextAttemptAt != nil {
+		t.Errorf("flaky after MaxAttempts = %+v", row)
+	}
+	if sends.Load() != 5 {
+		t.Errorf("sends = %d, want 5", sends.Load())
+	}
+}
+
+func TestWorkerSkipReleasesLease(t *testing.T) {
+	db := testutil.OpenDB(t, &testRow{})
+	w, _ := newTestWorker(t, db)
+	later := enqueue(t, db, "later")
+
+	w.ProcessDue()
+	row := reload(t, db, later.ID)
+	if row.Status != "queued" || row.Attempts != 0 || row.NextAttemptAt == nil || row.NextAttemptAt.After(time.Now()) {
+		t.Errorf("skipped row = %+v, want still due without attempt", row)
+	}
+}
+
+func TestConcurrentWorkersSendOnce(t *testing.T) {
+	db := testutil.OpenDB(t, &testRow{})
+	// Dua instance server berbagi database yang sama
+	a, sendsA := newTestWorker(t, db)
+	b, sendsB := newTestWorker(t, db)
+	for i := 0; i < 10; i++ {
+		enqueue(t, db, "ok")
+	}
+
+	var wg sync.WaitGroup
+	for _, w := range []*Worker[testRow]{a, b} {
+		wg.Add(1)
+		go func(w *Worker[testRow]) {
+			defer wg.Done()
+			for {
+				n, err := w.ProcessDue()
+				if err != nil {
+					t.Errorf("ProcessDue: %v", err)
+				}
+				if n == 0 || err != nil {
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var sent int64
+	db.Model(&testRow{}).Where("status = ? AND attempts = 1", "sent").Count(&sent)
+	if total := sendsA.Load() + sendsB.Load(); total != 10 || sent != 10 {
+		t.Errorf("sends = %d (a %d, b %d), sent rows = %d; want each row sent once", total, sendsA.Load(), sendsB.Load(), sent)
+	}
+}
+
+func TestWorkerStop(t *testing.T) {
+	db := testutil.OpenDB(t, &testRow{})
+	w, sends := newTestWorker(t, db)
+	row := enqueue(t, db, "ok")
+
+	// Stop sebelum Start tidak menunggu apa pun
+	idle, _ := newTestWorker(t, db)
+	if err := idle.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop before Start: %v", err)
+	}
+
+	w.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for reload(t, db, row.ID).Status != "sent" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Stop(ctx); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	// Setelah Stop baris baru tidak dikirim lagi walaupun di-Kick
+	later := enqueue(t, db, "ok")
+	w.Kick()
+	time.Sleep(50 * time.Millisecond)
+	if reload(t, db, later.ID).Status != "queued" || sends.Load() != 1 {
+		t.Errorf("worker sent after Stop: sends %d", sends.Load())
+	}
+}
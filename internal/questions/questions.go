@@ -0,0 +1,474 @@
+package questions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+)
+
+// Error validasi definisi pertanyaan dan filter jawaban
+var (
+	ErrUnknownQuestion   = errors.New("pertanyaan tidak dikenal")
+	ErrNotFilterable     = errors.New("jawaban tipe file tidak bisa difilter")
+	ErrInvalidFilterText = errors.New("nilai filter tidak valid")
+)
+
+// keyPattern adalah format key pertanyaan (dipakai sebagai nama field di body answers dan header export)
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var keyInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// KeyFromLabel membuat key dari label, mis. "Ukuran Kaos" -> "ukuran_kaos"
+func KeyFromLabel(label string) string {
+	key := strings.Trim(keyInvalidChars.ReplaceAllString(strings.ToLower(label), "_"), "_")
+	if key != "" && (key[0] < 'a' || key[0] > 'z') {
+		key = "q_" + key
+	}
+	if len(key) > 100 {
+		key = strings.TrimRight(key[:100], "_")
+	}
+	return key
+}
+
+// Load mengambil pertanyaan registrasi event sesuai urutan tampil
+func Load(db *gorm.DB, eventID uint) ([]models.RegistrationQuestion, error) {
+	var list []models.RegistrationQuestion
+	err := db.Where("event_id = ?", eventID).Order("position asc, id asc").Find(&list).Error
+	return list, err
+}
+
+// CheckDefinition memvalidasi definisi pertanyaan yang dibuat admin
+func CheckDefinition(q models.RegistrationQuestion) error {
+	if !keyPattern.MatchString(q.Key) {
+		return errors.New("key hanya boleh huruf kecil, angka dan _, diawali huruf")
+	}
+	if !isValidType(q.Type) {
+		return fmt.Errorf("tipe pertanyaan %q tidak didukung", q.Type)
+	}
+
+	hasOptions := q.Type == models.QuestionSelect || q.Type == models.QuestionMultiSelect
+	if hasOptions && len(q.Options) == 0 {
+		return errors.New("options wajib diisi untuk tipe select dan multi_select")
+	}
+	if !hasOptions && len(q.Options) > 0 {
+		return errors.New("options hanya untuk tipe select dan multi_select")
+	}
+	seen := make(map[string]bool, len(q.Options))
+	for _, opt := range q.Options {
+		if strings.TrimSpace(opt) == "" {
+			return errors.New("options tidak boleh kosong")
+		}
+		if seen[opt] {
+			return fmt.Errorf("option %q duplikat", opt)
+		}
+		seen[opt] = true
+	}
+
+	r := q.Rules
+	if r.MinLength != nil && r.MaxLength != nil && *r.MinLength > *r.MaxLength {
+		return errors.New("min_length tidak boleh lebih besar dari max_length")
+	}
+	if r.Pattern != "" {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("pattern tidak valid: %v", err)
+		}
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return errors.New("min tidak boleh lebih besar dari max")
+	}
+	if r.MinSelections != nil && r.MaxSelections != nil && *r.MinSelections > *r.MaxSelections {
+		return errors.New("min_selections tidak boleh lebih besar dari max_selections")
+	}
+	if r.MaxSelections != nil && *r.MaxSelections > len(q.Options) && q.Type == models.QuestionMultiSelect {
+		return errors.New("max_selections melebihi jumlah options")
+	}
+	for _, d := range []string{r.MinDate, r.MaxDate} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return errors.New("min_date / max_date harus format YYYY-MM-DD")
+		}
+	}
+	if r.MinDate != "" && r.MaxDate != "" && r.MinDate > r.MaxDate {
+		return errors.New("min_date tidak boleh setelah max_date")
+	}
+	return nil
+}
+
+func isValidType(t string) bool {
+	for _, qt := range models.QuestionTypes {
+		if qt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate memvalidasi jawaban (dari body JSON) terhadap pertanyaan event.
+// Mengembalikan jawaban dalam bentuk kanonik (ParticipantID belum diisi) dan pesan error per key pertanyaan.
+func Validate(list []models.RegistrationQuestion, answers map[string]interface{}) ([]models.ParticipantAnswer, map[string]string) {
+	errs := make(map[string]string)
+	known := make(map[string]bool, len(list))
+	var result []models.ParticipantAnswer
+
+	for _, q := range list {
+		known[q.Key] = true
+		if q.Type == models.QuestionFile {
+			// Jawaban file diisi lewat endpoint upload, nilai di body diabaikan
+			continue
+		}
+		value, err := canonical(q, answers[q.Key])
+		if err != nil {
+			errs[q.Key] = err.Error()
+			continue
+		}
+		if value == "" {
+			if q.Required {
+				errs[q.Key] = "wajib diisi"
+			}
+			continue
+		}
+		result = append(result, models.ParticipantAnswer{QuestionID: q.ID, Value: value})
+	}
+	for key := range answers {
+		if !known[key] {
+			errs[key] = ErrUnknownQuestion.Error()
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return result, nil
+}
+
+// canonical mengubah satu jawaban ke bentuk simpan. String kosong berarti tidak dijawab.
+func canonical(q models.RegistrationQuestion, raw interface{}) (string, error) {
+	if raw == nil {
+		return "", nil
+	}
+	r := q.Rules
+
+	switch q.Type {
+	case models.QuestionText:
+		s, ok := raw.(string)
+		if !ok {
+			return "", errors.New("harus berupa teks")
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return "", nil
+		}
+		n := utf8.RuneCountInString(s)
+		if r.MinLength != nil && n < *r.MinLength {
+			return "", fmt.Errorf("minimal %d karakter", *r.MinLength)
+		}
+		if r.MaxLength != nil && n > *r.MaxLength {
+			return "", fmt.Errorf("maksimal %d karakter", *r.MaxLength)
+		}
+		if r.Pattern != "" {
+			if re, err := regexp.Compile(`^(?:` + r.Pattern + `)$`); err == nil && !re.MatchString(s) {
+				return "", errors.New("format tidak sesuai")
+			}
+		}
+		return s, nil
+
+	case models.QuestionNumber:
+		var f float64
+		switch v := raw.(type) {
+		case float64:
+			f = v
+		case string:
+			v = strings.TrimSpace(v)
+			if v == "" {
+				return "", nil
+			}
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return "", errors.New("harus berupa angka")
+			}
+			f = parsed
+		default:
+			return "", errors.New("harus berupa angka")
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", errors.New("harus berupa angka")
+		}
+		if r.Integer && f != math.Trunc(f) {
+			return "", errors.New("harus bilangan bulat")
+		}
+		if r.Min != nil && f < *r.Min {
+			return "", fmt.Errorf("minimal %s", formatNumber(*r.Min))
+		}
+		if r.Max != nil && f > *r.Max {
+			return "", fmt.Errorf("maksimal %s", formatNumber(*r.Max))
+		}
+		return formatNumber(f), nil
+
+	case models.QuestionSelect:
+		s, ok := raw.(string)
+		if !ok {
+			return "", errors.New("harus berupa teks")
+		}
+		if s == "" {
+			return "", nil
+		}
+		if !containsOption(q.Options, s) {
+			return "", errors.New("pilihan tidak valid")
+		}
+		return s, nil
+
+	case models.QuestionMultiSelect:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return "", errors.New("harus berupa daftar pilihan")
+		}
+		if len(items) == 0 {
+			return "", nil
+		}
+		chosen := make(map[string]bool, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok || !containsOption(q.Options, s) {
+				return "", errors.New("pilihan tidak valid")
+			}
+			chosen[s] = true
+		}
+		if r.MinSelections != nil && len(chosen) < *r.MinSelections {
+			return "", fmt.Errorf("pilih minimal %d", *r.MinSelections)
+		}
+		if r.MaxSelections != nil && len(chosen) > *r.MaxSelections {
+			return "", fmt.Errorf("pilih maksimal %d", *r.MaxSelections)
+		}
+		// Disimpan mengikuti urutan options agar nilai kanonik stabil
+		ordered := make([]string, 0, len(chosen))
+		for _, opt := range q.Options {
+			if chosen[opt] {
+				ordered = append(ordered, opt)
+			}
+		}
+		encoded, _ := json.Marshal(ordered)
+		return string(encoded), nil
+
+	case models.QuestionDate:
+		s, ok := raw.(string)
+		if !ok {
+			return "", errors.New("harus berupa tanggal YYYY-MM-DD")
+		}
+		if s = strings.TrimSpace(s); s == "" {
+			return "", nil
+		}
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return "", errors.New("harus berupa tanggal YYYY-MM-DD")
+		}
+		s = d.Format("2006-01-02")
+		if r.MinDate != "" && s < r.MinDate {
+			return "", fmt.Errorf("tidak boleh sebelum %s", r.MinDate)
+		}
+		if r.MaxDate != "" && s > r.MaxDate {
+			return "", fmt.Errorf("tidak boleh setelah %s", r.MaxDate)
+		}
+		return s, nil
+
+	case models.QuestionBoolean:
+		switch v := raw.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			if v == "" {
+				return "", nil
+			}
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return "", errors.New("harus true atau false")
+			}
+			return strconv.FormatBool(b), nil
+		}
+		return "", errors.New("harus true atau false")
+	}
+	return "", fmt.Errorf("tipe pertanyaan %q tidak didukung", q.Type)
+}
+
+func containsOption(options []string, s string) bool {
+	for _, opt := range options {
+		if opt == s {
+			return true
+		}
+	}
+	return false
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Decode mengubah nilai kanonik kembali ke tipe JSON yang sesuai untuk response
+func Decode(q models.RegistrationQuestion, value string) interface{} {
+	switch q.Type {
+	case models.QuestionNumber:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case models.QuestionBoolean:
+		return value == "true"
+	case models.QuestionMultiSelect:
+		var items []string
+		if err := json.Unmarshal([]byte(value), &items); err == nil {
+			return items
+		}
+	}
+	return value
+}
+
+// Display mengubah nilai kanonik menjadi teks untuk export
+func Display(q models.RegistrationQuestion, value string) string {
+	switch q.Type {
+	case models.QuestionMultiSelect:
+		if items, ok := Decode(q, value).([]string); ok {
+			return strings.Join(items, "; ")
+		}
+	case models.QuestionBoolean:
+		if value == "true" {
+			return "ya"
+		}
+		return "tidak"
+	}
+	return value
+}
+
+// AnswerMap membuat map key pertanyaan -> jawaban bertipe untuk response
+func AnswerMap(list []models.RegistrationQuestion, answers []models.ParticipantAnswer) map[string]interface{} {
+	byID := make(map[uint]models.RegistrationQuestion, len(list))
+	for _, q := range list {
+		byID[q.ID] = q
+	}
+	result := make(map[string]interface{}, len(answers))
+	for _, a := range answers {
+		if q, ok := byID[a.QuestionID]; ok {
+			result[q.Key] = Decode(q, a.Value)
+		}
+	}
+	return result
+}
+
+// Merge menimpa jawaban lama dengan jawaban baru; nilai null menghapus jawaban
+func Merge(existing map[string]interface{}, updates map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(existing)+len(updates))
+	for k, v := range existing {
+		// Jawaban lama disimpan ulang lewat Validate, jadi slice string perlu diubah ke bentuk JSON body
+		if items, ok := v.([]string); ok {
+			list := make([]interface{}, len(items))
+			for i, item := range items {
+				list[i] = item
+			}
+			v = list
+		}
+		merged[k] = v
+	}
+	for k, v := range updates {
+		merged[k] = v
+	}
+	return merged
+}
+
+// SaveAnswers mengganti seluruh jawaban participant dengan jawaban baru.
+// Jawaban pertanyaan tipe file tidak disentuh karena diatur lewat upload (lihat SetFileAnswer).
+func SaveAnswers(tx *gorm.DB, participantID string, answers []models.ParticipantAnswer) error {
+	if err := tx.Where("participant_id = ? AND question_id NOT IN (?)", participantID,
+		tx.Model(&models.RegistrationQuestion{}).Select("id").Where("type = ?", models.QuestionFile),
+	).Delete(&models.ParticipantAnswer{}).Error; err != nil {
+		return err
+	}
+	if len(answers) == 0 {
+		return nil
+	}
+	for i := range answers {
+		answers[i].ID = 0
+		answers[i].ParticipantID = participantID
+	}
+	return tx.Create(&answers).Error
+}
+
+// SetFileAnswer menjadikan file upload sebagai jawaban pertanyaan tipe file
+func SetFileAnswer(tx *gorm.DB, participantID string, questionID uint, fileID string) error {
+	if err := tx.Where("participant_id = ? AND question_id = ?", participantID, questionID).Delete(&models.ParticipantAnswer{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.ParticipantAnswer{ParticipantID: participantID, QuestionID: questionID, Value: fileID}).Error
+}
+
+// Attach mengisi field Answers pada participants (pertanyaan dari event masing-masing)
+func Attach(db *gorm.DB, participants []models.Participant) error {
+	if len(participants) == 0 {
+		return nil
+	}
+	ids := make([]string, len(participants))
+	for i, p := range participants {
+		ids[i] = p.ID
+	}
+
+	var answers []models.ParticipantAnswer
+	if err := db.Where("participant_id IN ?", ids).Find(&answers).Error; err != nil {
+		return err
+	}
+	if len(answers) == 0 {
+		return nil
+	}
+
+	questionIDs := make([]uint, 0, len(answers))
+	byParticipant := make(map[string][]models.ParticipantAnswer)
+	for _, a := range answers {
+		questionIDs = append(questionIDs, a.QuestionID)
+		byParticipant[a.ParticipantID] = append(byParticipant[a.ParticipantID], a)
+	}
+	var list []models.RegistrationQuestion
+	if err := db.Where("id IN ?", questionIDs).Find(&list).Error; err != nil {
+		return err
+	}
+
+	for i := range participants {
+		if own := byParticipant[participants[i].ID]; len(own) > 0 {
+			participants[i].Answers = AnswerMap(list, own)
+		}
+	}
+	return nil
+}
+
+// Filter menambahkan filter "participant menjawab pertanyaan q dengan nilai raw" ke query.
+// Teks dicocokkan sebagian (LIKE), multi_select cocok jika salah satu pilihan sama, lainnya harus sama persis.
+func Filter(query *gorm.DB, q models.RegistrationQuestion, raw string) (*gorm.DB, error) {
+	const exists = "EXISTS (SELECT 1 FROM participant_answers pa WHERE pa.participant_id = participants.id AND pa.question_id = ? AND "
+
+	switch q.Type {
+	case models.QuestionFile:
+		return nil, ErrNotFilterable
+	case models.QuestionText:
+		return query.Where(exists+"LOWER(pa.value) LIKE ?)", q.ID, "%"+strings.ToLower(raw)+"%"), nil
+	case models.QuestionMultiSelect:
+		if !containsOption(q.Options, raw) {
+			return nil, ErrInvalidFilterText
+		}
+		encoded, _ := json.Marshal(raw)
+		return query.Where(exists+"pa.value LIKE ?)", q.ID, "%"+string(encoded)+"%"), nil
+	}
+
+	// Tipe lain: samakan dulu ke bentuk kanonik (mis. "1" -> "true" untuk boolean)
+	var input interface{} = raw
+	value, err := canonical(models.RegistrationQuestion{Type: q.Type, Options: q.Options}, input)
+	if err != nil || value == "" {
+		return nil, ErrInvalidFilterText
+	}
+	return query.Where(exists+"pa.value = ?)", q.ID, value), nil
+}
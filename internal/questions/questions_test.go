@@ -0,0 +1,199 @@
+package questions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func intPtr(n int) *int { return &n }
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestKeyFromLabel(t *testing.T) {
+	for label, want := range map[string]string{
+		"Ukuran Kaos":           "ukuran_kaos",
+		"  Alergi / Pantangan?": "alergi_pantangan",
+		"2 Pilihan Sesi":        "q_2_pilihan_sesi",
+		"???":                   "",
+	} {
+		if got := KeyFromLabel(label); got != want {
+			t.Errorf("KeyFromLabel(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
+
+func TestCheckDefinition(t *testing.T) {
+	valid := []models.RegistrationQuestion{
+		{Key: "motivasi", Type: models.QuestionText, Rules: models.QuestionRules{MinLength: intPtr(10), Pattern: `[a-z ]+`}},
+		{Key: "ukuran_kaos", Type: models.QuestionSelect, Options: []string{"S", "M", "L"}},
+		{Key: "sesi", Type: models.QuestionMultiSelect, Options: []string{"A", "B"}, Rules: models.QuestionRules{MaxSelections: intPtr(2)}},
+		{Key: "ktm", Type: models.QuestionFile},
+	}
+	for _, q := range valid {
+		if err := CheckDefinition(q); err != nil {
+			t.Errorf("%s: %v", q.Key, err)
+		}
+	}
+
+	invalid := map[string]models.RegistrationQuestion{
+		"key uppercase":       {Key: "Ukuran", Type: models.QuestionText},
+		"key starts with num": {Key: "1_kaos", Type: models.QuestionText},
+		"unknown type":        {Key: "kaos", Type: "color"},
+		"select no options":   {Key: "kaos", Type: models.QuestionSelect},
+		"text with options":   {Key: "kaos", Type: models.QuestionText, Options: []string{"S"}},
+		"duplicate option":    {Key: "kaos", Type: models.QuestionSelect, Options: []string{"S", "S"}},
+		"blank option":        {Key: "kaos", Type: models.QuestionSelect, Options: []string{" "}},
+		"min over max length": {Key: "kaos", Type: models.QuestionText, Rules: models.QuestionRules{MinLength: intPtr(5), MaxLength: intPtr(2)}},
+		"bad pattern":         {Key: "kaos", Type: models.QuestionText, Rules: models.QuestionRules{Pattern: "("}},
+		"min over max":        {Key: "umur", Type: models.QuestionNumber, Rules: models.QuestionRules{Min: floatPtr(10), Max: floatPtr(1)}},
+		"selections > opts":   {Key: "sesi", Type: models.QuestionMultiSelect, Options: []string{"A"}, Rules: models.QuestionRules{MaxSelections: intPtr(2)}},
+		"bad date rule":       {Key: "tgl", Type: models.QuestionDate, Rules: models.QuestionRules{MinDate: "01-01-2026"}},
+		"min date after max":  {Key: "tgl", Type: models.QuestionDate, Rules: models.QuestionRules{MinDate: "2026-02-01", MaxDate: "2026-01-01"}},
+	}
+	for name, q := range invalid {
+		if err := CheckDefinition(q); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
+
+// eventQuestions adalah satu pertanyaan untuk setiap tipe
+func eventQuestions() []models.RegistrationQuestion {
+	return []models.RegistrationQuestion{
+		{ID: 1, Key: "motivasi", Type: models.QuestionText, Required: true, Rules: models.QuestionRules{MaxLength: intPtr(20)}},
+		{ID: 2, Key: "umur", Type: models.QuestionNumber, Rules: models.QuestionRules{Integer: true, Min: floatPtr(17)}},
+		{ID: 3, Key: "ukuran_kaos", Type: models.QuestionSelect, Options: []string{"S", "M", "L"}},
+		{ID: 4, Key: "sesi", Type: models.QuestionMultiSelect, Options: []string{"Pagi", "Siang", "Malam"}, Rules: models.QuestionRules{MaxSelections: intPtr(2)}},
+		{ID: 5, Key: "tiba", Type: models.QuestionDate, Rules: models.QuestionRules{MinDate: "2026-12-01"}},
+		{ID: 6, Key: "vegetarian", Type: models.QuestionBoolean},
+		{ID: 7, Key: "ktm", Type: models.QuestionFile, Required: true},
+	}
+}
+
+func TestValidateCanonicalizesAnswers(t *testing.T) {
+	answers, errs := Validate(eventQuestions(), map[string]interface{}{
+		"motivasi":    "  Belajar  ",
+		"umur":        "20",
+		"ukuran_kaos": "M",
+		"sesi":        []interface{}{"Malam", "Pagi", "Malam"},
+		"tiba":        "2026-12-01",
+		"vegetarian":  "1",
+		"ktm":         "diabaikan",
+	})
+	if errs != nil {
+		t.Fatalf("errs = %v", errs)
+	}
+	got := make(map[uint]string, len(answers))
+	for _, a := range answers {
+		got[a.QuestionID] = a.Value
+	}
+	// Multi select disimpan urut sesuai options, file tidak diambil dari body
+	want := map[uint]string{1: "Belajar", 2: "20", 3: "M", 4: `["Pagi","Malam"]`, 5: "2026-12-01", 6: "true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("answers = %v, want %v", got, want)
+	}
+
+	if _, errs := Validate(eventQuestions(), map[string]interface{}{"umur": 20.0}); errs["motivasi"] != "wajib diisi" || len(errs) != 1 {
+		t.Errorf("missing required: %v", errs)
+	}
+}
+
+func TestValidateRejectsInvalidAnswers(t *testing.T) {
+	for key, value := range map[string]interface{}{
+		"motivasi":    "Saya ingin belajar banyak hal",
+		"umur":        17.5,
+		"ukuran_kaos": "XL",
+		"sesi":        []interface{}{"Pagi", "Siang", "Malam"},
+		"tiba":        "2026-11-30",
+		"vegetarian":  "mungkin",
+		"tidak_ada":   "x",
+	} {
+		answers := map[string]interface{}{"motivasi": "Belajar", key: value}
+		if _, errs := Validate(eventQuestions(), answers); errs[key] == "" {
+			t.Errorf("%s = %v: errs %v", key, value, errs)
+		}
+	}
+	_, errs := Validate(eventQuestions(), map[string]interface{}{"motivasi": "Belajar", "umur": "16"})
+	if errs["umur"] != "minimal 17" {
+		t.Errorf("umur: %q", errs["umur"])
+	}
+}
+
+func TestDecodeAndDisplay(t *testing.T) {
+	list := eventQuestions()
+	stored := []models.ParticipantAnswer{{QuestionID: 2, Value: "20"}, {QuestionID: 4, Value: `["Pagi","Malam"]`}, {QuestionID: 6, Value: "false"}, {QuestionID: 99, Value: "x"}}
+	want := map[string]interface{}{"umur": 20.0, "sesi": []string{"Pagi", "Malam"}, "vegetarian": false}
+	if got := AnswerMap(list, stored); !reflect.DeepEqual(got, want) {
+		t.Errorf("AnswerMap = %v, want %v", got, want)
+	}
+	if got := Display(list[3], `["Pagi","Malam"]`); got != "Pagi; Malam" {
+		t.Errorf("Display multi_select = %q", got)
+	}
+	if got := Display(list[5], "true"); got != "ya" {
+		t.Errorf("Display boolean = %q", got)
+	}
+
+	// Merge: nilai null menghapus jawaban, slice lama diubah ke bentuk body JSON
+	merged := Merge(want, map[string]interface{}{"umur": nil, "motivasi": "Baru"})
+	if _, errs := Validate(list, merged); errs != nil {
+		t.Errorf("merged answers invalid: %v", errs)
+	}
+	if merged["umur"] != nil || !reflect.DeepEqual(merged["sesi"], []interface{}{"Pagi", "Malam"}) {
+		t.Errorf("merged = %v", merged)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	db := testutil.OpenDB(t, &models.Participant{}, &models.ParticipantAnswer{})
+	list := eventQuestions()
+	answer := func(name string, values map[uint]string) {
+		p := testutil.CreateParticipant(t, db, name)
+		for id, v := range values {
+			db.Create(&models.ParticipantAnswer{ParticipantID: p.ID, QuestionID: id, Value: v})
+		}
+	}
+	answer("Andi", map[uint]string{1: "Ingin Belajar", 4: `["Pagi","Malam"]`, 6: "true"})
+	answer("Budi", map[uint]string{1: "Mencari teman", 4: `["Siang"]`, 6: "false"})
+
+	for _, tc := range []struct {
+		question int
+		value    string
+		want     []string
+	}{
+		{0, "belajar", []string{"Andi"}},
+		{3, "Malam", []string{"Andi"}},
+		{3, "Siang", []string{"Budi"}},
+		{5, "1", []string{"Andi"}},
+		{5, "false", []string{"Budi"}},
+	} {
+		q := list[tc.question]
+		query, err := Filter(db.Model(&models.Participant{}), q, tc.value)
+		if err != nil {
+			t.Fatalf("%s=%s: %v", q.Key, tc.value, err)
+		}
+		var names []string
+		query.Order("name").Pluck("name", &names)
+		if !reflect.DeepEqual(names, tc.want) {
+			t.Errorf("%s=%s: %v, want %v", q.Key, tc.value, names, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		question int
+		value    string
+		want     error
+	}{
+		{6, "x", ErrNotFilterable},
+		{3, "Subuh", ErrInvalidFilterText},
+		{5, "mungkin", ErrInvalidFilterText},
+		{1, "", ErrInvalidFilterText},
+	} {
+		if _, err := Filter(db, list[tc.question], tc.value); !errors.Is(err, tc.want) {
+			t.Errorf("%s=%q: err = %v, want %v", list[tc.question].Key, tc.value, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter membatasi jumlah kejadian per key dalam satu jendela waktu (fixed window).
+// Penyimpanan per proses, tidak dibagi antar instance.
+type Limiter struct {
+	Limit  int
+	Window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*window
+	swept   time.Time
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// New membuat limiter: maksimal limit kejadian per key setiap period (limit <= 0 = tanpa batas)
+func New(limit int, period time.Duration) *Limiter {
+	return &Limiter{Limit: limit, Window: period, entries: map[string]*window{}}
+}
+
+// Allow mencatat satu kejadian untuk key. Jika batas sudah terlampaui hasilnya false
+// beserta sisa waktu sampai jendela berikutnya.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l.Limit <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w := l.current(key, time.Now())
+	if w.count >= l.Limit {
+		return false, time.Until(w.start.Add(l.Window))
+	}
+	w.count++
+	return true, 0
+}
+
+// Blocked mengecek apakah key sudah mencapai batas tanpa mencatat kejadian baru
+func (l *Limiter) Blocked(key string) (bool, time.Duration) {
+	if l.Limit <= 0 {
+		return false, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w := l.current(key, time.Now())
+	if w.count >= l.Limit {
+		return true, time.Until(w.start.Add(l.Window))
+	}
+	return false, 0
+}
+
+// Reset menghapus hitungan key (mis. setelah percobaan berhasil)
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+// current mengambil jendela aktif key dan sesekali membuang jendela yang sudah lewat.
+// Harus dipanggil dengan mu terkunci.
+func (l *Limiter) current(key string, now time.Time) *window {
+	if now.Sub(l.swept) > l.Window {
+		for k, w := range l.entries {
+			if now.Sub(w.start) >= l.Window {
+				delete(l.entries, k)
+			}
+		}
+		l.swept = now
+	}
+	w, ok := l.entries[key]
+	if !ok || now.Sub(w.start) >= l.Window {
+		w = &window{start: now}
+		l.entries[key] = w
+	}
+	return w
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"abc * * * *",
+		"* * * FOO *",
+		"@every 5m",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.ParseInLocation("2006-01-02 15:04:05", s, wib)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		expr string
+		from string
+		want string
+	}{
+		{"*/15 * * * *", "2026-10-16 10:07:00", "2026-10-16 10:15:00"},
+		{"*/15 * * * *", "2026-10-16 10:45:30", "2026-10-16 11:00:00"},
+		{"5/20 * * * *", "2026-10-16 10:26:00", "2026-10-16 10:45:00"},
+		{"30 3 * * *", "2026-10-16 03:30:00", "2026-10-17 03:30:00"}, // selalu setelah t, bukan sama dengan t
+		{"30 3 * * *", "2026-10-16 03:29:59", "2026-10-16 03:30:00"},
+		{"0 8-18/2 * * *", "2026-10-16 13:00:00", "2026-10-16 14:00:00"},
+		{"0 9 * * MON-FRI", "2026-10-16 10:00:00", "2026-10-19 09:00:00"}, // Jumat -> Senin
+		{"0 0 * * 7", "2026-10-16 00:00:00", "2026-10-18 00:00:00"},       // 7 = Minggu
+		{"0 0 1,15 * *", "2026-10-16 00:00:00", "2026-11-01 00:00:00"},
+		{"0 0 1 * MON", "2026-10-18 00:00:00", "2026-10-19 00:00:00"}, // tanggal & hari dibatasi: salah satu cocok
+		{"0 0 1 * SUN", "2026-10-19 00:00:00", "2026-10-25 00:00:00"},
+		{"0 0 29 2 *", "2026-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"0 12 * JUN *", "2026-10-16 00:00:00", "2027-06-01 12:00:00"},
+		{"@yearly", "2026-12-31 23:59:00", "2027-01-01 00:00:00"},
+		{"@hourly", "2026-10-16 10:59:30", "2026-10-16 11:00:00"},
+		{"@DAILY", "2026-10-16 10:00:00", "2026-10-17 00:00:00"},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		got := cron.Next(at(tt.from))
+		if want := at(tt.want); !got.Equal(want) || got.Location() != wib {
+			t.Errorf("%q.Next(%s) = %s, want %s", tt.expr, tt.from, got, want)
+		}
+	}
+}
+
+func TestCronNextNeverDue(t *testing.T) {
+	cron, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	if next := cron.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero() {
+		t.Errorf("Next = %s, want zero time for 30 February", next)
+	}
+}
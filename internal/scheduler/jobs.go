@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/emails"
+	"backend/internal/messaging"
+	"backend/internal/models"
+)
+
+// Jenis job bawaan
+const (
+	// JobEventNotification mengirim pesan WhatsApp / SMS dan / atau email ke participant event
+	JobEventNotification = "event_notification"
+	// JobPurgeExpired menghapus token, sesi dan data sementara lain yang sudah kedaluwarsa
+	JobPurgeExpired = "purge_expired"
+)
+
+// SystemPurgeKey adalah SystemKey job bawaan pembersihan data kedaluwarsa
+const SystemPurgeKey = "purge_expired"
+
+// Channel pengiriman job notifikasi
+const (
+	ChannelMessage = "message"
+	ChannelEmail   = "email"
+)
+
+// NotificationTemplates adalah template yang bisa dikirim job notifikasi beserta status
+// penerima bawaannya
+var NotificationTemplates = map[string][]string{
+	messaging.TemplateEventReminder:       {models.StatusApproved},
+	messaging.TemplateRegistrationClosing: {models.StatusPending},
+}
+
+// NotificationTemplateKeys mengembalikan template job notifikasi, urut abjad
+func NotificationTemplateKeys() []string {
+	keys := make([]string, 0, len(NotificationTemplates))
+	for k := range NotificationTemplates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateNotification mengecek parameter job notifikasi
+func validateNotification(job *models.ScheduledJob) error {
+	if job.EventID == nil {
+		return errors.New("job event_notification membutuhkan event_id")
+	}
+	if job.Params.Template == "" {
+		job.Params.Template = messaging.TemplateEventReminder
+	}
+	if _, ok := NotificationTemplates[job.Params.Template]; !ok {
+		return fmt.Errorf("template %q tidak bisa dijadwalkan, pilih salah satu dari %s", job.Params.Template, strings.Join(NotificationTemplateKeys(), ", "))
+	}
+	return nil
+}
+
+// EventNotification mengantrikan template job (default event_reminder) ke participant event lewat
+// channel yang dipilih. Pesan & email dikirim worker masing-masing, job hanya mengantrikan.
+func EventNotification(db *gorm.DB, messages *messaging.Service, outbox *emails.Queue) Handler {
+	return func(ctx context.Context, job models.ScheduledJob) (string, error) {
+		if job.EventID == nil {
+			return "", errors.New("job tidak terhubung ke event")
+		}
+		var event models.Event
+		if err := db.WithContext(ctx).First(&event, *job.EventID).Error; err != nil {
+			return "", fmt.Errorf("gagal memuat event %d: %w", *job.EventID, err)
+		}
+
+		key := job.Params.Template
+		if key == "" {
+			key = messaging.TemplateEventReminder
+		}
+		statuses := job.Params.Statuses
+		if len(statuses) == 0 {
+			statuses = NotificationTemplates[key]
+		}
+		channels := job.Params.Channels
+		if len(channels) == 0 {
+			channels = []string{ChannelMessage, ChannelEmail}
+		}
+
+		var results []string
+		for _, channel := range channels {
+			switch channel {
+			case ChannelMessage:
+				if !messages.Enabled() {
+					results = append(results, "pesan nonaktif")
+					continue
+				}
+				n, err := messages.SendToEvent(key, event, statuses)
+				if err != nil {
+					return strings.Join(results, ", "), fmt.Errorf("gagal mengantrikan pesan: %w", err)
+				}
+				results = append(results, fmt.Sprintf("%d pesan", n))
+			case ChannelEmail:
+				if !outbox.Enabled() {
+					results = append(results, "email nonaktif")
+					continue
+				}
+				n, err := outbox.SendToEvent(key, event, statuses)
+				if err != nil {
+					return strings.Join(results, ", "), fmt.Errorf("gagal mengantrikan email: %w", err)
+				}
+				results = append(results, fmt.Sprintf("%d email", n))
+			}
+		}
+		return fmt.Sprintf("%s untuk %s (%s): %s", key, event.Name, strings.Join(statuses, ", "), strings.Join(results, ", ")), nil
+	}
+}
+
+// purgeTarget adalah tabel yang dibersihkan job purge_expired
+type purgeTarget struct {
+	name   string
+	model  interface{}
+	where  string
+	before time.Time
+}
+
+// PurgeExpired menghapus data yang sudah kedaluwarsa: idempotency key, token logout, refresh token,
+// link & sesi portal, token verifikasi email, serta riwayat eksekusi job yang lebih tua dari retention
+func PurgeExpired(db *gorm.DB, retention time.Duration) Handler {
+	return func(ctx context.Context, job models.ScheduledJob) (string, error) {
+		now := time.Now()
+		targets := []purgeTarget{
+			{"idempotency_keys", &models.IdempotencyKey{}, "expires_at < ?", now},
+			{"blacklisted_tokens", &models.BlacklistedToken{}, "expires_at < ?", now},
+			{"refresh_tokens", &models.RefreshToken{}, "expires_at < ?", now},
+			{"participant_magic_links", &models.ParticipantMagicLink{}, "expires_at < ?", now},
+			{"participant_sessions", &models.ParticipantSession{}, "expires_at < ?", now},
+			{"email_verifications", &models.EmailVerification{}, "expires_at < ?", now},
+		}
+		if retention > 0 {
+			targets = append(targets, purgeTarget{"job_runs", &models.JobRun{}, "started_at < ? AND status <> '" + models.JobRunRunning + "'", now.Add(-retention)})
+		}
+
+		results := make([]string, 0, len(targets))
+		for _, t := range targets {
+			res := db.WithContext(ctx).Where(t.where, t.before).Delete(t.model)
+			if res.Error != nil {
+				return strings.Join(results, ", "), fmt.Errorf("gagal menghapus %s: %w", t.name, res.Error)
+			}
+			results = append(results, fmt.Sprintf("%s=%d", t.name, res.RowsAffected))
+		}
+		return "dihapus: " + strings.Join(results, ", "), nil
+	}
+}
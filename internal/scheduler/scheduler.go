@@ -0,0 +1,495 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// batchSize adalah jumlah job jatuh tempo yang diambil per putaran
+const batchSize = 20
+
+// ErrLocked dikembalikan saat job sedang dijalankan (oleh instance ini atau replika lain)
+var ErrLocked = errors.New("job sedang berjalan")
+
+// Handler menjalankan satu job dan mengembalikan ringkasan hasil untuk riwayat eksekusi.
+// ctx dibatalkan saat server berhenti.
+type Handler func(ctx context.Context, job models.ScheduledJob) (string, error)
+
+// Config mengatur worker scheduler
+type Config struct {
+	// Enabled menjalankan job terjadwal di instance ini; jika false job hanya bisa dijalankan manual
+	Enabled bool
+	// PollInterval adalah jeda worker mengecek job yang jatuh tempo
+	PollInterval time.Duration
+	// LockTTL adalah lama lease job; diperpanjang selama job berjalan. Jika instance mati,
+	// replika lain bisa mengambil alih job setelah lease habis.
+	LockTTL time.Duration
+	// MisfireGrace adalah batas keterlambatan jadwal (mis. server mati); lebih dari itu eksekusi dilewati
+	MisfireGrace time.Duration
+	// PurgeCron adalah jadwal job bawaan pembersihan data kedaluwarsa (kosong = tidak dibuat)
+	PurgeCron string
+	// RunRetention adalah lama riwayat eksekusi job disimpan sebelum dihapus job pembersihan
+	RunRetention time.Duration
+}
+
+// ConfigFromEnv membaca konfigurasi scheduler dari environment
+func ConfigFromEnv() Config {
+	return Config{
+		Enabled:      helpers.GetEnvBool("SCHEDULER_ENABLED", true),
+		PollInterval: helpers.GetEnvDuration("SCHEDULER_POLL_INTERVAL", 30*time.Second),
+		LockTTL:      helpers.GetEnvDuration("SCHEDULER_LOCK_TTL", 5*time.Minute),
+		MisfireGrace: helpers.GetEnvDuration("SCHEDULER_MISFIRE_GRACE", 6*time.Hour),
+		PurgeCron:    helpers.GetEnv("SCHEDULER_PURGE_CRON", "30 3 * * *"),
+		RunRetention: helpers.GetEnvDuration("SCHEDULER_RUN_RETENTION", 90*24*time.Hour),
+	}
+}
+
+// Scheduler menjalankan job yang disimpan di tabel scheduled_jobs. Setiap replika server menjalankan
+// worker sendiri; lease di baris job memastikan satu job hanya dijalankan satu instance.
+type Scheduler struct {
+	DB     *gorm.DB
+	Config Config
+	// Instance adalah identitas instance ini di lease & riwayat eksekusi (hostname-pid)
+	Instance string
+
+	handlers map[string]Handler
+	ctx      context.Context
+	cancel   context.CancelFunc
+	kick     chan struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+	started  bool
+	mu       sync.Mutex
+}
+
+// New membuat scheduler; jenis job didaftarkan lewat Register sebelum Start
+func New(db *gorm.DB, cfg Config) *Scheduler {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "server"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		DB:       db,
+		Config:   cfg,
+		Instance: fmt.Sprintf("%s-%d", host, os.Getpid()),
+		handlers: map[string]Handler{},
+		ctx:      ctx,
+		cancel:   cancel,
+		kick:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register mendaftarkan handler untuk jenis job
+func (s *Scheduler) Register(jobType string, handler Handler) {
+	s.handlers[jobType] = handler
+}
+
+// Types mengembalikan jenis job yang terdaftar, urut abjad
+func (s *Scheduler) Types() []string {
+	types := make([]string, 0, len(s.handlers))
+	for t := range s.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// EnsureSystemJob membuat job bawaan jika belum ada. Job yang sudah ada (termasuk jadwal yang
+// diubah admin) tidak disentuh.
+func (s *Scheduler) EnsureSystemJob(key, name, jobType, cron string) error {
+	job := models.ScheduledJob{
+		Name:      name,
+		Type:      jobType,
+		SystemKey: &key,
+		Trigger:   models.TriggerCron,
+		Cron:      cron,
+		Params:    models.JobParams{},
+	}
+	if err := s.Validate(&job); err != nil {
+		return err
+	}
+	if err := s.Schedule(&job, nil); err != nil {
+		return err
+	}
+	return s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&job).Error
+}
+
+// Validate mengecek jenis job, trigger dan parameternya
+func (s *Scheduler) Validate(job *models.ScheduledJob) error {
+	if _, ok := s.handlers[job.Type]; !ok {
+		return fmt.Errorf("jenis job %q tidak dikenal", job.Type)
+	}
+	switch job.Trigger {
+	case models.TriggerCron:
+		cron, err := ParseCron(job.Cron)
+		if err != nil {
+			return err
+		}
+		if cron.Next(time.Now().In(time.Local)).IsZero() {
+			return fmt.Errorf("ekspresi cron %q tidak pernah jatuh tempo", job.Cron)
+		}
+	case models.TriggerEvent:
+		if job.EventID == nil {
+			return errors.New("trigger event membutuhkan event_id")
+		}
+		if !contains(Anchors, job.Anchor) {
+			return fmt.Errorf("anchor harus salah satu dari %v", Anchors)
+		}
+		if _, err := ParseOffset(job.Offset); err != nil {
+			return err
+		}
+		if job.TimeOfDay != "" {
+			if _, _, err := ParseTimeOfDay(job.TimeOfDay); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("trigger harus %s atau %s", models.TriggerCron, models.TriggerEvent)
+	}
+	if job.Type == JobEventNotification {
+		return validateNotification(job)
+	}
+	return nil
+}
+
+// Schedule menghitung NextRunAt job. event boleh nil; untuk trigger event, event dimuat dari database.
+func (s *Scheduler) Schedule(job *models.ScheduledJob, event *models.Event) error {
+	job.NextRunAt = nil
+	switch job.Trigger {
+	case models.TriggerCron:
+		cron, err := ParseCron(job.Cron)
+		if err != nil {
+			return err
+		}
+		if next := cron.Next(time.Now().In(time.Local)); !next.IsZero() {
+			job.NextRunAt = &next
+		}
+	case models.TriggerEvent:
+		if job.CompletedAt != nil || job.EventID == nil {
+			return nil
+		}
+		if event == nil {
+			event = &models.Event{}
+			if err := s.DB.First(event, *job.EventID).Error; err != nil {
+				return err
+			}
+		}
+		next, err := EventTime(*job, *event)
+		if err != nil {
+			return err
+		}
+		job.NextRunAt = next
+	}
+	return nil
+}
+
+// Start menjalankan worker di background (jika diaktifkan)
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || !s.Config.Enabled {
+		return
+	}
+	s.started = true
+	go s.run()
+}
+
+// Kick membangunkan worker, mis. setelah job dibuat atau diubah
+func (s *Scheduler) Kick() {
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Stop menghentikan worker dan menunggu job yang sedang berjalan selesai (atau ctx habis).
+// Job yang terpotong dilepas lease-nya tanpa memajukan jadwal, sehingga dijalankan lagi nanti.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	s.cancel()
+	if started {
+		<-s.done
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	log.Printf("Scheduler job berjalan sebagai %s", s.Instance)
+	for {
+		s.refreshEventJobs()
+		for s.ctx.Err() == nil {
+			n, err := s.runDue()
+			if err != nil {
+				log.Printf("ERROR: Scheduler gagal mengambil job: %v", err)
+				break
+			}
+			if n < batchSize {
+				break
+			}
+		}
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.kick:
+		case <-time.After(s.Config.PollInterval):
+		}
+	}
+}
+
+// refreshEventJobs menghitung ulang jadwal job trigger event yang belum dijalankan, supaya
+// perubahan tanggal event ikut terbawa
+func (s *Scheduler) refreshEventJobs() {
+	var jobs []models.ScheduledJob
+	now := time.Now()
+	if err := s.DB.Where("trigger_type = ? AND completed_at IS NULL AND (locked_until IS NULL OR locked_until < ?)", models.TriggerEvent, now).
+		Find(&jobs).Error; err != nil {
+		log.Printf("ERROR: Scheduler gagal memuat job event: %v", err)
+		return
+	}
+	events := map[uint]*models.Event{}
+	for _, job := range jobs {
+		if job.EventID == nil {
+			continue
+		}
+		event, ok := events[*job.EventID]
+		if !ok {
+			event = &models.Event{}
+			if err := s.DB.First(event, *job.EventID).Error; err != nil {
+				event = nil
+			}
+			events[*job.EventID] = event
+		}
+		if event == nil {
+			continue
+		}
+		next, err := EventTime(job, *event)
+		if err != nil || sameTime(next, job.NextRunAt) {
+			continue
+		}
+		s.DB.Model(&models.ScheduledJob{}).Where("id = ? AND completed_at IS NULL", job.ID).Update("next_run_at", next)
+	}
+}
+
+// runDue menjalankan job yang jatuh tempo. Mengembalikan jumlah job yang diambil.
+func (s *Scheduler) runDue() (int, error) {
+	now := time.Now()
+	var jobs []models.ScheduledJob
+	if err := s.DB.Where("paused = ? AND next_run_at IS NOT NULL AND next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?)", false, now, now).
+		Order("next_run_at asc").Limit(batchSize).Find(&jobs).Error; err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		if s.ctx.Err() != nil {
+			break
+		}
+		if !s.claim(job.ID, now, true) {
+			continue
+		}
+		run, err := s.startRun(job, models.RunBySchedule, "", job.NextRunAt)
+		if err != nil {
+			log.Printf("ERROR: Scheduler gagal mencatat eksekusi job %d: %v", job.ID, err)
+			s.release(job.ID)
+			continue
+		}
+		s.wg.Add(1)
+		go s.execute(job, run)
+	}
+	return len(jobs), nil
+}
+
+// Trigger menjalankan job sekarang di luar jadwal (tanpa mengubah jadwal berikutnya).
+// Mengembalikan ErrLocked jika job sedang berjalan.
+func (s *Scheduler) Trigger(job models.ScheduledJob, actor string) (*models.JobRun, error) {
+	if s.ctx.Err() != nil {
+		return nil, errors.New("scheduler sedang berhenti")
+	}
+	if !s.claim(job.ID, time.Now(), false) {
+		return nil, ErrLocked
+	}
+	run, err := s.startRun(job, models.RunByManual, actor, nil)
+	if err != nil {
+		s.release(job.ID)
+		return nil, err
+	}
+	s.wg.Add(1)
+	go s.execute(job, run)
+	return run, nil
+}
+
+// claim mengambil lease job. due=true hanya berhasil jika job masih aktif dan jatuh tempo.
+func (s *Scheduler) claim(id uint, now time.Time, due bool) bool {
+	query := s.DB.Model(&models.ScheduledJob{}).Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", id, now)
+	if due {
+		query = query.Where("paused = ? AND next_run_at <= ?", false, now)
+	}
+	res := query.Updates(map[string]interface{}{"locked_by": s.Instance, "locked_until": now.Add(s.Config.LockTTL)})
+	if res.Error != nil || res.RowsAffected != 1 {
+		return false
+	}
+	// Eksekusi yang masih "running" berarti instance sebelumnya mati sebelum selesai
+	s.DB.Model(&models.JobRun{}).Where("job_id = ? AND status = ?", id, models.JobRunRunning).Updates(map[string]interface{}{
+		"status":      models.JobRunFailed,
+		"error":       "instance berhenti sebelum job selesai (lease kedaluwarsa)",
+		"finished_at": now,
+	})
+	return true
+}
+
+// release melepas lease job tanpa mengubah jadwal
+func (s *Scheduler) release(id uint) {
+	s.DB.Model(&models.ScheduledJob{}).Where("id = ? AND locked_by = ?", id, s.Instance).
+		Updates(map[string]interface{}{"locked_by": "", "locked_until": nil})
+}
+
+func (s *Scheduler) startRun(job models.ScheduledJob, runBy, actor string, scheduledFor *time.Time) (*models.JobRun, error) {
+	run := &models.JobRun{
+		JobID:        job.ID,
+		RunBy:        runBy,
+		Actor:        actor,
+		Status:       models.JobRunRunning,
+		ScheduledFor: scheduledFor,
+		StartedAt:    time.Now(),
+		Instance:     s.Instance,
+	}
+	return run, s.DB.Create(run).Error
+}
+
+// heartbeat memperpanjang lease selama job berjalan
+func (s *Scheduler) heartbeat(ctx context.Context, id uint) {
+	ticker := time.NewTicker(s.Config.LockTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			res := s.DB.Model(&models.ScheduledJob{}).Where("id = ? AND locked_by = ?", id, s.Instance).
+				Update("locked_until", time.Now().Add(s.Config.LockTTL))
+			if res.Error == nil && res.RowsAffected == 0 {
+				log.Printf("WARNING: Lease job %d sudah diambil instance lain", id)
+			}
+		}
+	}
+}
+
+// call menjalankan handler; panic dicatat sebagai error supaya worker tetap hidup
+func (s *Scheduler) call(ctx context.Context, handler Handler, job models.ScheduledJob) (output string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// execute menjalankan job yang lease-nya sudah diambil, mencatat hasilnya dan menghitung jadwal berikutnya
+func (s *Scheduler) execute(job models.ScheduledJob, run *models.JobRun) {
+	defer s.wg.Done()
+
+	var output string
+	var err error
+	status := models.JobRunSuccess
+	if run.RunBy == models.RunBySchedule && run.ScheduledFor != nil && run.StartedAt.Sub(*run.ScheduledFor) > s.Config.MisfireGrace {
+		status = models.JobRunSkipped
+		output = fmt.Sprintf("jadwal %s terlewat lebih dari %s", run.ScheduledFor.In(time.Local).Format("2006-01-02 15:04"), s.Config.MisfireGrace)
+	} else if handler, ok := s.handlers[job.Type]; !ok {
+		err = fmt.Errorf("jenis job %q tidak dikenal", job.Type)
+	} else {
+		ctx, cancel := context.WithCancel(s.ctx)
+		go s.heartbeat(ctx, job.ID)
+		output, err = s.call(ctx, handler, job)
+		cancel()
+	}
+	if err != nil {
+		status = models.JobRunFailed
+	}
+	interrupted := s.ctx.Err() != nil && err != nil
+
+	finished := time.Now()
+	runUpdates := map[string]interface{}{
+		"status":      status,
+		"output":      output,
+		"finished_at": finished,
+		"duration_ms": finished.Sub(run.StartedAt).Milliseconds(),
+	}
+	if err != nil {
+		runUpdates["error"] = err.Error()
+	}
+	if e := s.DB.Model(run).Updates(runUpdates).Error; e != nil {
+		log.Printf("ERROR: Gagal menyimpan hasil job %d: %v", job.ID, e)
+	}
+
+	jobUpdates := map[string]interface{}{
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_run_at":  run.StartedAt,
+		"last_status":  status,
+		"last_error":   "",
+	}
+	if err != nil {
+		jobUpdates["last_error"] = err.Error()
+	}
+	// Job yang terpotong karena server berhenti tidak dimajukan jadwalnya supaya dijalankan lagi
+	if run.RunBy == models.RunBySchedule && !interrupted {
+		var current models.ScheduledJob
+		if e := s.DB.First(&current, job.ID).Error; e != nil {
+			return
+		}
+		if current.Trigger == models.TriggerEvent {
+			jobUpdates["completed_at"] = finished
+			jobUpdates["next_run_at"] = nil
+		} else if e := s.Schedule(&current, nil); e == nil {
+			jobUpdates["next_run_at"] = current.NextRunAt
+		}
+	}
+	if e := s.DB.Model(&models.ScheduledJob{}).Where("id = ? AND locked_by = ?", job.ID, s.Instance).Updates(jobUpdates).Error; e != nil {
+		log.Printf("ERROR: Gagal memperbarui job %d: %v", job.ID, e)
+	}
+	if err != nil {
+		log.Printf("ERROR: Job %d (%s) gagal: %v", job.ID, job.Name, err)
+	}
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
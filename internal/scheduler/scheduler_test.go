@@ -0,0 +1,183 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// newTestScheduler membuat scheduler di SQLite sementara dengan handler "count" (menghitung
+// eksekusi) dan "fail" (selalu error). Worker tidak dijalankan; test memanggil runDue langsung.
+func newTestScheduler(t *testing.T) (*Scheduler, *atomic.Int32) {
+	t.Helper()
+	db := testutil.OpenDB(t, &models.Event{}, &models.ScheduledJob{}, &models.JobRun{})
+	s := New(db, Config{Enabled: true, PollInterval: time.Hour, LockTTL: time.Minute, MisfireGrace: time.Hour})
+	calls := &atomic.Int32{}
+	s.Register("count", func(ctx context.Context, job models.ScheduledJob) (string, error) {
+		calls.Add(1)
+		return "ok", nil
+	})
+	s.Register("fail", func(ctx context.Context, job models.ScheduledJob) (string, error) {
+		return "", errors.New("gagal total")
+	})
+	return s, calls
+}
+
+func createJob(t *testing.T, s *Scheduler, jobType string, nextRunAt time.Time, mutate func(*models.ScheduledJob)) models.ScheduledJob {
+	t.Helper()
+	job := models.ScheduledJob{Name: jobType, Type: jobType, Trigger: models.TriggerCron, Cron: "0 * * * *", NextRunAt: &nextRunAt}
+	if mutate != nil {
+		mutate(&job)
+	}
+	if err := s.DB.Create(&job).Error; err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	return job
+}
+
+// runOnce menjalankan satu putaran worker dan menunggu eksekusi selesai
+func runOnce(t *testing.T, s *Scheduler) int {
+	t.Helper()
+	n, err := s.runDue()
+	if err != nil {
+		t.Fatalf("runDue: %v", err)
+	}
+	s.wg.Wait()
+	return n
+}
+
+func lastRun(t *testing.T, s *Scheduler, jobID uint) models.JobRun {
+	t.Helper()
+	var run models.JobRun
+	if err := s.DB.Where("job_id = ?", jobID).Order("id desc").First(&run).Error; err != nil {
+		t.Fatalf("load run for job %d: %v", jobID, err)
+	}
+	return run
+}
+
+func reload(t *testing.T, s *Scheduler, id uint) models.ScheduledJob {
+	t.Helper()
+	var job models.ScheduledJob
+	if err := s.DB.First(&job, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestRunDueExecutesAndReschedules(t *testing.T) {
+	s, calls := newTestScheduler(t)
+	job := createJob(t, s, "count", time.Now().Add(-time.Minute), nil)
+
+	if n := runOnce(t, s); n != 1 {
+		t.Fatalf("runDue picked %d jobs, want 1", n)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1", calls.Load())
+	}
+	run := lastRun(t, s, job.ID)
+	if run.Status != models.JobRunSuccess || run.Output != "ok" || run.RunBy != models.RunBySchedule || run.FinishedAt == nil {
+		t.Errorf("run = %+v", run)
+	}
+	job = reload(t, s, job.ID)
+	if job.LastStatus != models.JobRunSuccess || job.LockedBy != "" || job.LockedUntil != nil {
+		t.Errorf("job after run: status %s, locked by %q until %v", job.LastStatus, job.LockedBy, job.LockedUntil)
+	}
+	if job.NextRunAt == nil || !job.NextRunAt.After(time.Now()) || job.NextRunAt.Minute() != 0 {
+		t.Errorf("next_run_at = %v, want next full hour", job.NextRunAt)
+	}
+
+	// Jadwal berikutnya belum jatuh tempo
+	if n := runOnce(t, s); n != 0 || calls.Load() != 1 {
+		t.Errorf("second runDue picked %d jobs, handler calls %d", n, calls.Load())
+	}
+}
+
+func TestRunDueSkipsMisfiredSchedule(t *testing.T) {
+	s, calls := newTestScheduler(t)
+	scheduledFor := time.Now().Add(-2 * time.Hour) // lebih lama dari MisfireGrace 1 jam
+	late := createJob(t, s, "count", scheduledFor, nil)
+	withinGrace := createJob(t, s, "count", time.Now().Add(-30*time.Minute), nil)
+
+	if n := runOnce(t, s); n != 2 {
+		t.Fatalf("runDue picked %d jobs, want 2", n)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler called %d times, want 1 (only the job within grace)", calls.Load())
+	}
+
+	run := lastRun(t, s, late.ID)
+	if run.Status != models.JobRunSkipped || !strings.Contains(run.Output, "terlewat") || run.Error != "" {
+		t.Errorf("late run = status %s output %q error %q, want skipped", run.Status, run.Output, run.Error)
+	}
+	if run.ScheduledFor == nil || !run.ScheduledFor.Equal(scheduledFor) {
+		t.Errorf("scheduled_for = %v, want %v", run.ScheduledFor, scheduledFor)
+	}
+	// Job yang dilewati tetap dimajukan ke jadwal berikutnya, bukan dijalankan berulang kali
+	late = reload(t, s, late.ID)
+	if late.LastStatus != models.JobRunSkipped || late.NextRunAt == nil || !late.NextRunAt.After(time.Now()) {
+		t.Errorf("late job = last %s next %v", late.LastStatus, late.NextRunAt)
+	}
+	if run := lastRun(t, s, withinGrace.ID); run.Status != models.JobRunSuccess {
+		t.Errorf("job within grace = %s, want success", run.Status)
+	}
+
+	// Eksekusi manual tidak pernah dianggap terlambat
+	late.NextRunAt = &scheduledFor
+	s.DB.Model(&late).Update("next_run_at", scheduledFor)
+	run2, err := s.Trigger(late, "admin")
+	if err != nil {
+		t.Fatalf("Trigger: %v", err)
+	}
+	s.wg.Wait()
+	if run := lastRun(t, s, late.ID); run.ID != run2.ID || run.Status != models.JobRunSuccess || run.Actor != "admin" {
+		t.Errorf("manual run = %+v", run)
+	}
+	if job := reload(t, s, late.ID); job.NextRunAt == nil || !job.NextRunAt.Equal(scheduledFor) {
+		t.Errorf("manual run moved next_run_at to %v", job.NextRunAt)
+	}
+}
+
+func TestRunDueRecordsFailureAndSkipsLockedOrPaused(t *testing.T) {
+	s, calls := newTestScheduler(t)
+	due := time.Now().Add(-time.Minute)
+	failing := createJob(t, s, "fail", due, nil)
+	locked := createJob(t, s, "count", due, func(j *models.ScheduledJob) {
+		until := time.Now().Add(time.Minute)
+		j.LockedBy, j.LockedUntil = "replika-lain", &until
+	})
+	createJob(t, s, "count", due, func(j *models.ScheduledJob) { j.Paused = true })
+
+	if n := runOnce(t, s); n != 1 {
+		t.Fatalf("runDue picked %d jobs, want only the failing one", n)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("locked or paused job executed")
+	}
+	if run := lastRun(t, s, failing.ID); run.Status != models.JobRunFailed || run.Error != "gagal total" {
+		t.Errorf("failed run = status %s error %q", run.Status, run.Error)
+	}
+	if job := reload(t, s, failing.ID); job.LastError != "gagal total" || job.NextRunAt == nil || !job.NextRunAt.After(time.Now()) {
+		t.Errorf("failing job = last error %q next %v", job.LastError, job.NextRunAt)
+	}
+	if _, err := s.Trigger(locked, "admin"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Trigger locked job = %v, want ErrLocked", err)
+	}
+
+	// Lease kedaluwarsa (instance lain mati): job diambil alih dan eksekusi lamanya ditandai gagal
+	expired := time.Now().Add(-time.Second)
+	s.DB.Model(&locked).Update("locked_until", expired)
+	stale := models.JobRun{JobID: locked.ID, RunBy: models.RunBySchedule, Status: models.JobRunRunning, StartedAt: time.Now().Add(-time.Hour)}
+	s.DB.Create(&stale)
+	if n := runOnce(t, s); n != 1 || calls.Load() != 1 {
+		t.Fatalf("after lease expiry: picked %d, calls %d", n, calls.Load())
+	}
+	if err := s.DB.First(&stale, stale.ID).Error; err != nil || stale.Status != models.JobRunFailed {
+		t.Errorf("stale run = %s, %v; want failed", stale.Status, err)
+	}
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+)
+
+// Anchor adalah titik acuan trigger event
+const (
+	AnchorEventStart         = "event_start"
+	AnchorEventEnd           = "event_end"
+	AnchorRegistrationOpens  = "registration_opens"
+	AnchorRegistrationCloses = "registration_closes"
+)
+
+// Anchors adalah semua titik acuan trigger event yang dikenal
+var Anchors = []string{AnchorEventStart, AnchorEventEnd, AnchorRegistrationOpens, AnchorRegistrationCloses}
+
+// Offset adalah selisih dari titik acuan, mis. "-3d", "-1d12h", "2h30m". Hari dihitung sebagai
+// tanggal kalender supaya jam tetap sama walau ada pergantian DST.
+type Offset struct {
+	Days     int
+	Duration time.Duration
+}
+
+var offsetPattern = regexp.MustCompile(`^([+-])?(?:(\d+)d)?(.*)$`)
+
+// ParseOffset mem-parse offset; string kosong berarti tepat di titik acuan
+func ParseOffset(s string) (Offset, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Offset{}, nil
+	}
+	m := offsetPattern.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return Offset{}, fmt.Errorf("offset %q tidak valid, contoh: -3d, -1d12h, 2h", s)
+	}
+	var off Offset
+	if m[2] != "" {
+		off.Days, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		d, err := time.ParseDuration(m[3])
+		if err != nil || d < 0 {
+			return Offset{}, fmt.Errorf("offset %q tidak valid, contoh: -3d, -1d12h, 2h", s)
+		}
+		off.Duration = d
+	}
+	if m[1] == "-" {
+		off.Days, off.Duration = -off.Days, -off.Duration
+	}
+	return off, nil
+}
+
+// Apply menambahkan offset ke t
+func (o Offset) Apply(t time.Time) time.Time {
+	return t.AddDate(0, 0, o.Days).Add(o.Duration)
+}
+
+// ParseTimeOfDay mem-parse jam "HH:MM"
+func ParseTimeOfDay(s string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("time_of_day %q tidak valid, gunakan format HH:MM", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// errNoAnchor dipakai saat titik acuan belum diisi di event (mis. registration_closes_at kosong)
+var errNoAnchor = errors.New("titik acuan belum diisi di event")
+
+// anchorTime mengambil waktu titik acuan dari event. Tanggal mulai / selesai event adalah tanggal
+// kalender, dibaca sebagai pukul 00:00 waktu lokal server.
+func anchorTime(anchor string, event models.Event) (time.Time, error) {
+	date := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	}
+	switch anchor {
+	case AnchorEventStart:
+		return date(event.StartDate), nil
+	case AnchorEventEnd:
+		return date(event.EndDate), nil
+	case AnchorRegistrationOpens:
+		if event.RegistrationOpensAt == nil {
+			return time.Time{}, errNoAnchor
+		}
+		return event.RegistrationOpensAt.In(time.Local), nil
+	case AnchorRegistrationCloses:
+		if event.RegistrationClosesAt == nil {
+			return time.Time{}, errNoAnchor
+		}
+		return event.RegistrationClosesAt.In(time.Local), nil
+	}
+	return time.Time{}, fmt.Errorf("anchor %q tidak dikenal", anchor)
+}
+
+// EventTime menghitung waktu jalan job trigger event: anchor + offset, lalu jam diganti TimeOfDay
+// jika diisi. Mengembalikan nil jika titik acuan belum diisi di event.
+func EventTime(job models.ScheduledJob, event models.Event) (*time.Time, error) {
+	at, err := anchorTime(job.Anchor, event)
+	if err == errNoAnchor {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	off, err := ParseOffset(job.Offset)
+	if err != nil {
+		return nil, err
+	}
+	at = off.Apply(at)
+	if job.TimeOfDay != "" {
+		hour, minute, err := ParseTimeOfDay(job.TimeOfDay)
+		if err != nil {
+			return nil, err
+		}
+		at = time.Date(at.Year(), at.Month(), at.Day(), hour, minute, 0, 0, time.Local)
+	}
+	return &at, nil
+}
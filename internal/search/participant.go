@@ -0,0 +1,283 @@
+package search
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Nama dialect sesuai gorm Dialector.Name()
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// participantColumns adalah kolom yang ikut di-index untuk full-text search
+var participantColumns = []string{"name", "kampus", "jurusan", "place", "phone"}
+
+// columnWeights adalah bobot relevansi per kolom: name paling relevan, lalu kampus/jurusan, lalu place/phone
+var columnWeights = map[string]int{"name": 8, "kampus": 4, "jurusan": 4, "place": 2, "phone": 2}
+
+// ParticipantIndex membungkus full-text search participant sesuai driver database.
+// Jika fasilitas native tidak tersedia, pencarian fallback ke LIKE di semua kolom.
+type ParticipantIndex struct {
+	DB      *gorm.DB
+	dialect string
+	native  bool
+}
+
+// NewParticipantIndex membuat index baru untuk tabel participants. Struktur native dibuat oleh Migrate
+// saat startup; di sini hanya dicek apakah sudah tersedia.
+func NewParticipantIndex(db *gorm.DB) *ParticipantIndex {
+	pi := &ParticipantIndex{DB: db, dialect: db.Dialector.Name()}
+	pi.native = nativeReady(db, pi.dialect)
+	if pi.native {
+		log.Printf("Full-text search ready (%s)", pi.dialect)
+	} else {
+		log.Printf("Warning: native full-text search unavailable (%s), falling back to LIKE", pi.dialect)
+	}
+	return pi
+}
+
+// Native menandakan apakah full-text search native aktif
+func (pi *ParticipantIndex) Native() bool {
+	return pi.native
+}
+
+// Migrate membuat struktur full-text search native untuk tabel participants (idempotent), dijalankan
+// bersama AutoMigrate. Jika gagal, pencarian tetap jalan dengan fallback LIKE.
+func Migrate(db *gorm.DB) error {
+	switch dialect := db.Dialector.Name(); dialect {
+	case DialectMySQL:
+		return migrateMySQL(db)
+	case DialectPostgres:
+		return migratePostgres(db)
+	case DialectSQLite:
+		return migrateSQLite(db)
+	default:
+		return fmt.Errorf("full-text search not supported for dialect %q", dialect)
+	}
+}
+
+// nativeReady mengecek apakah struktur yang dibuat Migrate ada dan bisa dipakai
+func nativeReady(db *gorm.DB, dialect string) bool {
+	var count int64
+	switch dialect {
+	case DialectMySQL:
+		if err := db.Raw(
+			"SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?",
+			"participants", "ft_participants_search",
+		).Scan(&count).Error; err != nil {
+			return false
+		}
+	case DialectPostgres:
+		if err := db.Raw(
+			"SELECT COUNT(*) FROM pg_indexes WHERE schemaname = current_schema() AND tablename = ? AND indexname = ?",
+			"participants", "idx_participants_search",
+		).Scan(&count).Error; err != nil {
+			return false
+		}
+	case DialectSQLite:
+		// Tabel FTS5 dari build sebelumnya tidak bisa dibaca jika binary ini tanpa modul fts5
+		var rowid []int64
+		if err := db.Raw("SELECT rowid FROM participants_fts LIMIT 0").Scan(&rowid).Error; err != nil {
+			return false
+		}
+		count = 1
+	}
+	return count > 0
+}
+
+func migrateMySQL(db *gorm.DB) error {
+	if nativeReady(db, DialectMySQL) {
+		return nil
+	}
+	return db.Exec("CREATE FULLTEXT INDEX ft_participants_search ON participants (" + strings.Join(participantColumns, ", ") + ")").Error
+}
+
+func migratePostgres(db *gorm.DB) error {
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_participants_search ON participants USING GIN (" + postgresVector() + ")").Error
+}
+
+func migrateSQLite(db *gorm.DB) error {
+	columns := strings.Join(participantColumns, ", ")
+	newValues := "new." + strings.Join(participantColumns, ", new.")
+	oldValues := "old." + strings.Join(participantColumns, ", old.")
+
+	statements := []string{
+		"CREATE VIRTUAL TABLE IF NOT EXISTS participants_fts USING fts5(" + columns + ", content='participants')",
+		"CREATE TRIGGER IF NOT EXISTS participants_fts_ai AFTER INSERT ON participants BEGIN " +
+			"INSERT INTO participants_fts(rowid, " + columns + ") VALUES (new.rowid, " + newValues + "); END",
+		"CREATE TRIGGER IF NOT EXISTS participants_fts_ad AFTER DELETE ON participants BEGIN " +
+			"INSERT INTO participants_fts(participants_fts, rowid, " + columns + ") VALUES ('delete', old.rowid, " + oldValues + "); END",
+		"CREATE TRIGGER IF NOT EXISTS participants_fts_au AFTER UPDATE ON participants BEGIN " +
+			"INSERT INTO participants_fts(participants_fts, rowid, " + columns + ") VALUES ('delete', old.rowid, " + oldValues + "); " +
+			"INSERT INTO participants_fts(rowid, " + columns + ") VALUES (new.rowid, " + newValues + "); END",
+		// Sinkronkan ulang index dengan isi tabel (aman dijalankan setiap startup)
+		"INSERT INTO participants_fts(participants_fts) VALUES ('rebuild')",
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postgresVector adalah ekspresi tsvector yang sama persis dengan index GIN
+func postgresVector() string {
+	parts := make([]string, len(participantColumns))
+	for i, col := range participantColumns {
+		parts[i] = "coalesce(" + col + ", '')"
+	}
+	return "to_tsvector('simple', " + strings.Join(parts, " || ' ' || ") + ")"
+}
+
+// Apply menambahkan filter pencarian ke query
+func (pi *ParticipantIndex) Apply(query *gorm.DB, term string) *gorm.DB {
+	tokens := tokenize(term)
+	if len(tokens) == 0 {
+		return query
+	}
+
+	if !pi.native {
+		conditions := make([]string, len(participantColumns))
+		vars := make([]interface{}, len(participantColumns))
+		for i, col := range participantColumns {
+			conditions[i] = col + " LIKE ?"
+			vars[i] = "%" + strings.TrimSpace(term) + "%"
+		}
+		if digits := phoneDigits(term); digits != "" {
+			conditions = append(conditions, "phone LIKE ?")
+			vars = append(vars, "%"+digits+"%")
+		}
+		return query.Where("("+strings.Join(conditions, " OR ")+")", vars...)
+	}
+
+	var match, expr string
+	switch pi.dialect {
+	case DialectMySQL:
+		match = "MATCH(" + strings.Join(participantColumns, ", ") + ") AGAINST(? IN BOOLEAN MODE)"
+		expr = mysqlQuery(tokens)
+	case DialectPostgres:
+		match = postgresVector() + " @@ to_tsquery('simple', ?)"
+		expr = postgresQuery(tokens)
+	case DialectSQLite:
+		match = "participants.rowid IN (SELECT rowid FROM participants_fts WHERE participants_fts MATCH ?)"
+		expr = sqliteQuery(tokens)
+	}
+
+	// Fragmen nomor HP (mis. "3456") tidak bisa ditemukan lewat token full-text,
+	// jadi kolom phone juga dicocokkan dengan LIKE khusus untuk term berbentuk angka.
+	if digits := phoneDigits(term); digits != "" {
+		return query.Where("("+match+" OR phone LIKE ?)", expr, "%"+digits+"%")
+	}
+	return query.Where(match, expr)
+}
+
+// OrderByRelevance mengurutkan hasil berdasarkan relevansi terhadap term,
+// lalu berdasarkan tieBreaker (mis. "created_at desc") untuk hasil dengan skor sama.
+func (pi *ParticipantIndex) OrderByRelevance(query *gorm.DB, term string, tieBreaker string) *gorm.DB {
+	tokens := tokenize(term)
+	if len(tokens) == 0 {
+		return query.Order(tieBreaker)
+	}
+
+	var rank clause.Expr
+	switch {
+	case !pi.native:
+		scores := make([]string, len(participantColumns))
+		for i, col := range participantColumns {
+			scores[i] = fmt.Sprintf("CASE WHEN %s LIKE ? THEN %d ELSE 0 END", col, columnWeights[col])
+			rank.Vars = append(rank.Vars, "%"+strings.TrimSpace(term)+"%")
+		}
+		rank.SQL = "(" + strings.Join(scores, " + ") + ") DESC"
+	case pi.dialect == DialectMySQL:
+		rank.SQL = "MATCH(" + strings.Join(participantColumns, ", ") + ") AGAINST(? IN BOOLEAN MODE) DESC"
+		rank.Vars = []interface{}{mysqlQuery(tokens)}
+	case pi.dialect == DialectPostgres:
+		rank.SQL = "ts_rank(" + postgresVector() + ", to_tsquery('simple', ?)) DESC"
+		rank.Vars = []interface{}{postgresQuery(tokens)}
+	case pi.dialect == DialectSQLite:
+		// bm25 bernilai negatif, makin kecil makin relevan; baris yang hanya cocok lewat phone ditaruh di akhir
+		weights := make([]string, len(participantColumns))
+		for i, col := range participantColumns {
+			weights[i] = strconv.Itoa(columnWeights[col])
+		}
+		rank.SQL = "COALESCE((SELECT bm25(participants_fts, " + strings.Join(weights, ", ") + ") FROM participants_fts WHERE participants_fts MATCH ? AND participants_fts.rowid = participants.rowid), 0) ASC"
+		rank.Vars = []interface{}{sqliteQuery(tokens)}
+	}
+
+	// clause.OrderBy dengan Expression mengabaikan kolom lain, jadi tie breaker digabung ke ekspresi yang sama
+	if tieBreaker != "" {
+		rank.SQL += ", " + tieBreaker
+	}
+	return query.Order(clause.OrderBy{Expression: rank})
+}
+
+// tokenize memecah term menjadi token alfanumerik (karakter operator full-text dibuang)
+func tokenize(term string) []string {
+	return strings.FieldsFunc(strings.ToLower(term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// fullPhoneDigits adalah jumlah digit minimal term yang dianggap nomor HP lengkap (62 + 8xx...)
+const fullPhoneDigits = 10
+
+// phoneDigits mengembalikan digit dari term jika term terlihat seperti nomor HP. Kolom phone tersimpan
+// dalam format E.164 (+628...), jadi prefix lokal 0 dan prefix +62 dibuang. Tanpa "+", 62 hanya
+// dibuang dari nomor lengkap supaya fragmen seperti "6234" tetap dicari apa adanya.
+func phoneDigits(term string) string {
+	term = strings.TrimSpace(term)
+	var b strings.Builder
+	for _, r := range term {
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r != '+' && r != '-' && r != ' ' && r != '.' && r != '(' && r != ')':
+			return ""
+		}
+	}
+	digits := b.String()
+	switch {
+	case strings.HasPrefix(digits, "0"):
+		digits = digits[1:]
+	case strings.HasPrefix(digits, "62") && (strings.HasPrefix(term, "+") || len(digits) >= fullPhoneDigits):
+		digits = digits[2:]
+	}
+	if len(digits) < 3 {
+		return ""
+	}
+	return digits
+}
+
+func mysqlQuery(tokens []string) string {
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		parts[i] = "+" + t + "*"
+	}
+	return strings.Join(parts, " ")
+}
+
+func postgresQuery(tokens []string) string {
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		parts[i] = t + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+func sqliteQuery(tokens []string) string {
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		parts[i] = `"` + t + `"*`
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,51 @@
+//go:build sqlite_fts5
+
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Dijalankan dengan: go test -tags sqlite_fts5 ./internal/search/
+// Tanpa tag, TestSQLiteSearch hanya menguji fallback LIKE.
+
+func TestSQLiteFTS5Search(t *testing.T) {
+	db := openTestDB(t)
+	createParticipant(t, db, "Budi Santoso", "Universitas Indonesia", "+6281262340000")
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate: %v (binary harus di-build dengan -tags sqlite_fts5)", err)
+	}
+	pi := NewParticipantIndex(db)
+	if !pi.Native() {
+		t.Fatal("FTS5 index not used")
+	}
+	createParticipant(t, db, "Siti Budiarti", "Institut Teknologi Bandung", "+6285711112222")
+	createParticipant(t, db, "Andi", "Universitas Budi Luhur", "+6289900001111")
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"santoso", []string{"Budi Santoso"}},
+		{"budi santo", []string{"Budi Santoso"}},
+		{"bandung", []string{"Siti Budiarti"}},
+		// Fragmen nomor HP dicocokkan lewat LIKE di samping MATCH
+		{"6234", []string{"Budi Santoso"}},
+		{"0812-6234", []string{"Budi Santoso"}},
+		{"+62 857 1111", []string{"Siti Budiarti"}},
+		{"6289900001111", []string{"Andi"}},
+		// Karakter operator FTS5 tidak merusak query
+		{`"budi" (luhur*`, []string{"Andi"}},
+	}
+	for _, tt := range tests {
+		if got := search(t, pi, tt.term); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("search %q = %q, want %q", tt.term, got, tt.want)
+		}
+	}
+
+	// Nama lebih relevan daripada kampus (bobot bm25 per kolom)
+	if got := search(t, pi, "budi"); len(got) != 3 || got[2] != "Andi" {
+		t.Errorf("search budi = %q, want Andi (kampus match) last", got)
+	}
+}
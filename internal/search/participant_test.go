@@ -0,0 +1,206 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := map[string][]string{
+		"Budi Santoso":          {"budi", "santoso"},
+		`  "budi" -santoso* `:   {"budi", "santoso"},
+		"Teknik (Informatika)+": {"teknik", "informatika"},
+		"Universitas Indonesia": {"universitas", "indonesia"},
+		"+62 812-3456":          {"62", "812", "3456"},
+		"   ":                   nil,
+		`*"()+-`:                nil,
+	}
+	for in, want := range tests {
+		if got := tokenize(in); !reflect.DeepEqual(got, want) && !(len(got) == 0 && len(want) == 0) {
+			t.Errorf("tokenize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPhoneDigits(t *testing.T) {
+	tests := map[string]string{
+		"4567":              "4567",
+		"0812-3456-7890":    "81234567890",
+		"+62 812 3456 7890": "81234567890",
+		"(0812) 3456.7890":  "81234567890",
+		"6281234567890":     "81234567890",
+		"12":                "", // terlalu pendek
+		"0 1":               "",
+		"budi 0812":         "", // bukan nomor HP
+		"Angkatan 2020":     "",
+		"08123456789a":      "",
+		"   +62-812   ":     "812",
+		"6234":              "6234", // fragmen, bukan prefix negara
+		"62812":             "62812",
+		"+62 1":             "",
+		"(0)8123":           "8123",
+	}
+	for in, want := range tests {
+		if got := phoneDigits(in); got != want {
+			t.Errorf("phoneDigits(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDialectQueryBuilders(t *testing.T) {
+	tokens := []string{"budi", "teknik"}
+	if got, want := mysqlQuery(tokens), "+budi* +teknik*"; got != want {
+		t.Errorf("mysqlQuery = %q, want %q", got, want)
+	}
+	if got, want := postgresQuery(tokens), "budi:* & teknik:*"; got != want {
+		t.Errorf("postgresQuery = %q, want %q", got, want)
+	}
+	if got, want := sqliteQuery(tokens), `"budi"* "teknik"*`; got != want {
+		t.Errorf("sqliteQuery = %q, want %q", got, want)
+	}
+	want := "to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(kampus, '') || ' ' || coalesce(jurusan, '') || ' ' || coalesce(place, '') || ' ' || coalesce(phone, ''))"
+	if got := postgresVector(); got != want {
+		t.Errorf("postgresVector = %q, want %q", got, want)
+	}
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	return testutil.OpenDB(t, &models.Participant{})
+}
+
+// dryRun merender query Apply + OrderByRelevance tanpa menjalankannya
+func dryRun(db *gorm.DB, pi *ParticipantIndex, term string) (string, []interface{}) {
+	query := db.Session(&gorm.Session{DryRun: true}).Model(&models.Participant{})
+	query = pi.OrderByRelevance(pi.Apply(query, term), term, "created_at desc")
+	stmt := query.Find(&[]models.Participant{}).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestApplyPerDialect(t *testing.T) {
+	db := openTestDB(t)
+	tests := []struct {
+		dialect  string
+		native   bool
+		term     string
+		contains []string
+		vars     []interface{}
+	}{
+		{
+			DialectMySQL, true, "budi 0812",
+			[]string{"MATCH(name, kampus, jurusan, place, phone) AGAINST(? IN BOOLEAN MODE)", "ORDER BY MATCH("},
+			[]interface{}{"+budi* +0812*", "+budi* +0812*"},
+		},
+		{
+			DialectMySQL, true, "0812-3456",
+			[]string{"AGAINST(? IN BOOLEAN MODE) OR phone LIKE ?)"},
+			[]interface{}{"+0812* +3456*", "%8123456%", "+0812* +3456*"},
+		},
+		{
+			DialectPostgres, true, "Budi Teknik",
+			[]string{"@@ to_tsquery('simple', ?)", "ORDER BY ts_rank("},
+			[]interface{}{"budi:* & teknik:*", "budi:* & teknik:*"},
+		},
+		{
+			DialectSQLite, true, "budi",
+			[]string{"participants.rowid IN (SELECT rowid FROM participants_fts WHERE participants_fts MATCH ?)", "bm25(participants_fts, 8, 4, 4, 2, 2)", ", created_at desc"},
+			[]interface{}{`"budi"*`, `"budi"*`},
+		},
+		{
+			DialectSQLite, false, "budi",
+			[]string{"(name LIKE ? OR kampus LIKE ? OR jurusan LIKE ? OR place LIKE ? OR phone LIKE ?)", "CASE WHEN name LIKE ? THEN 8"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		pi := &ParticipantIndex{DB: db, dialect: tt.dialect, native: tt.native}
+		sql, vars := dryRun(db, pi, tt.term)
+		for _, fragment := range tt.contains {
+			if !strings.Contains(sql, fragment) {
+				t.Errorf("%s native=%v %q: SQL %s\nmissing %s", tt.dialect, tt.native, tt.term, sql, fragment)
+			}
+		}
+		if tt.vars != nil && !reflect.DeepEqual(vars, tt.vars) {
+			t.Errorf("%s native=%v %q: vars %q, want %q", tt.dialect, tt.native, tt.term, vars, tt.vars)
+		}
+	}
+
+	// Term tanpa token tidak menambah filter, hanya tie breaker
+	pi := &ParticipantIndex{DB: db, dialect: DialectMySQL, native: true}
+	if sql, _ := dryRun(db, pi, ` "*" `); strings.Contains(sql, "WHERE") || !strings.Contains(sql, "ORDER BY created_at desc") {
+		t.Errorf("empty term: SQL %s", sql)
+	}
+}
+
+func createParticipant(t *testing.T, db *gorm.DB, name, kampus, phone string) models.Participant {
+	t.Helper()
+	return testutil.CreateParticipant(t, db, name, func(p *models.Participant) {
+		p.Kampus, p.Jurusan, p.Phone = kampus, "Teknik Informatika", phone
+	})
+}
+
+func search(t *testing.T, pi *ParticipantIndex, term string) []string {
+	t.Helper()
+	var found []models.Participant
+	query := pi.OrderByRelevance(pi.Apply(pi.DB.Model(&models.Participant{}), term), term, "created_at asc")
+	if err := query.Find(&found).Error; err != nil {
+		t.Fatalf("search %q: %v", term, err)
+	}
+	names := make([]string, len(found))
+	for i, p := range found {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	db := openTestDB(t)
+	existing := createParticipant(t, db, "Budi Santoso", "Universitas Indonesia", "+6281234567890")
+
+	native := Migrate(db) == nil
+	pi := NewParticipantIndex(db)
+	if pi.Native() != native {
+		t.Fatalf("Native = %v after Migrate succeeded = %v", pi.Native(), native)
+	}
+	if native {
+		// Migrate idempotent dan rebuild mengisi index untuk baris yang sudah ada
+		if err := Migrate(db); err != nil {
+			t.Fatalf("second Migrate: %v", err)
+		}
+	} else {
+		t.Log("binary tanpa tag sqlite_fts5, yang diuji fallback LIKE")
+	}
+
+	createParticipant(t, db, "Siti Budiarti", "Institut Teknologi Bandung", "+6285711112222")
+	createParticipant(t, db, "Andi", "Universitas Budi Luhur", "+6289900001111")
+
+	if got := search(t, pi, "santoso"); !reflect.DeepEqual(got, []string{"Budi Santoso"}) {
+		t.Errorf("search santoso = %q", got)
+	}
+	if got := search(t, pi, "0812-3456"); !reflect.DeepEqual(got, []string{"Budi Santoso"}) {
+		t.Errorf("search phone fragment = %q", got)
+	}
+	if got := search(t, pi, "budi"); len(got) != 3 || got[0] == "Andi" {
+		// Nama lebih relevan daripada kampus
+		t.Errorf("search budi = %q, want all three with a name match first", got)
+	}
+
+	// Trigger menjaga index tetap sinkron setelah update & delete
+	if err := db.Model(&existing).Update("name", "Bambang Santoso").Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := search(t, pi, "bambang"); !reflect.DeepEqual(got, []string{"Bambang Santoso"}) {
+		t.Errorf("search after update = %q", got)
+	}
+	if err := db.Delete(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := search(t, pi, "santoso"); len(got) != 0 {
+		t.Errorf("search after delete = %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package seeders
+
+import (
+	"embed"
+	"encoding/json"
+	"log"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+)
+
+// Dataset awal kampus & jurusan yang ikut dibundel di binary
+//
+//go:embed data/campuses.json data/study_programs.json
+var masterData embed.FS
+
+// SeedMasterData mengisi tabel campuses dan study_programs dari dataset bawaan.
+// Tabel yang sudah berisi tidak disentuh supaya perubahan admin tidak tertimpa.
+func SeedMasterData(db *gorm.DB) error {
+	var campuses []models.Campus
+	if err := seedTable(db, "data/campuses.json", &models.Campus{}, &campuses); err != nil {
+		return err
+	}
+	var programs []models.StudyProgram
+	return seedTable(db, "data/study_programs.json", &models.StudyProgram{}, &programs)
+}
+
+// seedTable meng-insert isi file JSON ke tabel model jika tabel masih kosong
+func seedTable(db *gorm.DB, file string, model interface{}, rows interface{}) error {
+	var count int64
+	if err := db.Model(model).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	raw, err := masterData.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, rows); err != nil {
+		return err
+	}
+	result := db.CreateInBatches(rows, 100)
+	if result.Error != nil {
+		return result.Error
+	}
+	log.Printf("Seeded %d rows from %s", result.RowsAffected, file)
+	return nil
+}
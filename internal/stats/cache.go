@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache menyimpan hasil statistik sebentar supaya dashboard yang sering refresh tidak
+// menjalankan agregasi berulang. Cache per proses, tidak dibagi antar instance.
+type Cache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value     interface{}
+	createdAt time.Time
+}
+
+// NewCache membuat cache dengan TTL tertentu (0 = cache nonaktif)
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{TTL: ttl, entries: map[string]cacheEntry{}}
+}
+
+// Get mengembalikan nilai yang masih berlaku beserta waktu dibuatnya
+func (c *Cache) Get(key string) (interface{}, time.Time, bool) {
+	if c.TTL <= 0 {
+		return nil, time.Time{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.createdAt) > c.TTL {
+		return nil, time.Time{}, false
+	}
+	return entry.value, entry.createdAt, true
+}
+
+// Set menyimpan nilai dan membuang entry yang sudah kedaluwarsa
+func (c *Cache) Set(key string, value interface{}) time.Time {
+	now := time.Now()
+	if c.TTL <= 0 {
+		return now
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, entry := range c.entries {
+		if now.Sub(entry.createdAt) > c.TTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, createdAt: now}
+	return now
+}
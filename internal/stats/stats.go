@@ -0,0 +1,253 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/attendance"
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// Interval time series registrasi
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// Batas jumlah titik time series supaya query tidak terlalu besar
+const (
+	maxDays  = 366
+	maxWeeks = 104
+)
+
+// Dimensions adalah kolom participant yang bisa dipecah (GET /stats/breakdown/:dimension)
+var Dimensions = map[string]string{
+	"kampus":   "kampus",
+	"jurusan":  "jurusan",
+	"angkatan": "angkatan",
+	"place":    "place",
+}
+
+// DefaultAgeEdges adalah batas bawah kelompok umur default: <18, 18-20, 21-23, 24-26, 27-29, 30+
+var DefaultAgeEdges = []int{18, 21, 24, 27, 30}
+
+var (
+	ErrUnknownDimension = errors.New("dimensi tidak dikenal")
+	ErrInvalidInterval  = errors.New("interval harus day atau week")
+	ErrInvalidRange     = errors.New("rentang tanggal tidak valid")
+)
+
+// Count adalah jumlah participant untuk satu nilai / kelompok
+type Count struct {
+	Label string `json:"label"`
+	Total int64  `json:"total"`
+}
+
+// Breakdown menghitung participant per nilai kolom. Nilai dibandingkan tanpa beda huruf besar/kecil
+// dan spasi di ujung ("ITB" dan "itb " dihitung sama). Hanya limit nilai terbanyak yang dikembalikan,
+// sisanya dijumlah ke others.
+func Breakdown(query *gorm.DB, dimension string, limit int) ([]Count, int64, error) {
+	column, ok := Dimensions[dimension]
+	if !ok {
+		return nil, 0, ErrUnknownDimension
+	}
+	var rows []Count
+	err := query.Session(&gorm.Session{}).
+		Select(fmt.Sprintf("MIN(TRIM(%s)) AS label, COUNT(*) AS total", column)).
+		Group(fmt.Sprintf("LOWER(TRIM(%s))", column)).
+		Order("total DESC, label ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 || len(rows) <= limit {
+		return rows, 0, nil
+	}
+	var others int64
+	for _, r := range rows[limit:] {
+		others += r.Total
+	}
+	return rows[:limit], others, nil
+}
+
+// ParseAgeEdges membaca batas kelompok umur dari query, mis. "18,21,25"
+func ParseAgeEdges(raw string) ([]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return DefaultAgeEdges, nil
+	}
+	var edges []int
+	for _, part := range strings.Split(raw, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n <= 0 || n > 150 {
+			return nil, fmt.Errorf("age_brackets: %q bukan umur yang valid", part)
+		}
+		if len(edges) > 0 && n <= edges[len(edges)-1] {
+			return nil, errors.New("age_brackets harus urut naik")
+		}
+		edges = append(edges, n)
+	}
+	if len(edges) > 20 {
+		return nil, errors.New("age_brackets maksimal 20 batas")
+	}
+	return edges, nil
+}
+
+// AgeBrackets mengelompokkan participant berdasarkan umur pada tanggal ref.
+// Query dikelompokkan per tanggal lahir di SQL, pembagian kelompok dilakukan di sini
+// karena perhitungan umur berbeda di tiap driver.
+func AgeBrackets(query *gorm.DB, ref time.Time, edges []int) ([]Count, error) {
+	var rows []struct {
+		BirthDate string
+		Total     int64
+	}
+	if err := query.Session(&gorm.Session{}).Select("birth_date, COUNT(*) AS total").
+		Group("birth_date").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	labels := ageLabels(edges)
+	counts := make([]Count, len(labels))
+	for i, l := range labels {
+		counts[i].Label = l
+	}
+	for _, r := range rows {
+		// Tiap driver mengembalikan format berbeda ("2000-01-31", "2000-01-31T00:00:00Z", ...)
+		if len(r.BirthDate) < 10 {
+			continue
+		}
+		birth, err := time.Parse("2006-01-02", r.BirthDate[:10])
+		if err != nil {
+			continue
+		}
+		age := helpers.Age(birth, ref)
+		idx := sort.Search(len(edges), func(i int) bool { return edges[i] > age })
+		counts[idx].Total += r.Total
+	}
+	return counts, nil
+}
+
+// ageLabels membuat label kelompok umur dari batas bawahnya
+func ageLabels(edges []int) []string {
+	labels := make([]string, 0, len(edges)+1)
+	labels = append(labels, "<"+strconv.Itoa(edges[0]))
+	for i := 0; i < len(edges)-1; i++ {
+		if edges[i+1]-1 == edges[i] {
+			labels = append(labels, strconv.Itoa(edges[i]))
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%d-%d", edges[i], edges[i+1]-1))
+	}
+	return append(labels, strconv.Itoa(edges[len(edges)-1])+"+")
+}
+
+// Point adalah jumlah registrasi dalam satu periode (tanggal awal hari / minggu, Senin)
+type Point struct {
+	Period     string `json:"period"`
+	Total      int64  `json:"total"`
+	Cumulative int64  `json:"cumulative"`
+}
+
+// PeriodStart mengembalikan awal periode (pukul 00:00 di loc) yang memuat t
+func PeriodStart(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	if interval == IntervalWeek {
+		offset := (int(day.Weekday()) + 6) % 7 // Senin = 0
+		day = day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// DefaultRange adalah rentang default time series: 30 hari atau 12 minggu terakhir
+func DefaultRange(now time.Time, interval string, loc *time.Location) (time.Time, time.Time) {
+	to := PeriodStart(now, interval, loc)
+	if interval == IntervalWeek {
+		return to.AddDate(0, 0, -7*11), to
+	}
+	return to.AddDate(0, 0, -29), to
+}
+
+// TimeSeries menghitung registrasi per hari / minggu (menurut zona waktu loc) dari periode from sampai
+// to (inklusif). Periode tanpa registrasi tetap dikembalikan dengan total 0; cumulative ikut menghitung
+// registrasi sebelum from.
+//
+// Pengelompokan dilakukan di Go, bukan dengan fungsi tanggal SQL, karena tiap driver menyimpan dan
+// mengembalikan created_at dengan zona waktu berbeda (SQLite menyimpan offset lokal sebagai teks,
+// MySQL tanpa zona waktu). Batas query diperlebar satu hari lalu disaring ulang di sini.
+func TimeSeries(query *gorm.DB, interval string, from, to time.Time, loc *time.Location) ([]Point, error) {
+	if interval != IntervalDay && interval != IntervalWeek {
+		return nil, ErrInvalidInterval
+	}
+	from, to = PeriodStart(from, interval, loc), PeriodStart(to, interval, loc)
+	step := 1
+	limit := maxDays
+	if interval == IntervalWeek {
+		step, limit = 7, maxWeeks
+	}
+	if to.Before(from) || int(to.Sub(from).Hours()/24)/step >= limit {
+		return nil, ErrInvalidRange
+	}
+	end := to.AddDate(0, 0, step)
+
+	var before int64
+	if err := query.Session(&gorm.Session{}).Where("created_at < ?", from.AddDate(0, 0, -1)).Count(&before).Error; err != nil {
+		return nil, err
+	}
+	var created []time.Time
+	if err := query.Session(&gorm.Session{}).
+		Where("created_at >= ? AND created_at < ?", from.AddDate(0, 0, -1), end.AddDate(0, 0, 1)).
+		Pluck("created_at", &created).Error; err != nil {
+		return nil, err
+	}
+	byPeriod := map[string]int64{}
+	for _, t := range created {
+		switch {
+		case t.Before(from):
+			before++
+		case t.Before(end):
+			byPeriod[PeriodStart(t, interval, loc).Format("2006-01-02")]++
+		}
+	}
+
+	var points []Point
+	cumulative := before
+	for d := from; d.Before(end); d = d.AddDate(0, 0, step) {
+		key := d.Format("2006-01-02")
+		cumulative += byPeriod[key]
+		points = append(points, Point{Period: key, Total: byPeriod[key], Cumulative: cumulative})
+	}
+	return points, nil
+}
+
+// Stage adalah satu tahap funnel pendaftaran
+type Stage struct {
+	Stage string `json:"stage"`
+	Total int64  `json:"total"`
+	// Rate adalah persentase terhadap tahap pertama (registered)
+	Rate float64 `json:"rate"`
+}
+
+// Funnel menghitung tahapan pendaftaran dari jumlah per status:
+// registered (semua) -> approved (approved + attended) -> attended
+func Funnel(byStatus map[string]int64) []Stage {
+	var registered int64
+	for _, n := range byStatus {
+		registered += n
+	}
+	stages := []Stage{
+		{Stage: "registered", Total: registered},
+		{Stage: "approved", Total: byStatus[models.StatusApproved] + byStatus[models.StatusAttended]},
+		{Stage: models.StatusAttended, Total: byStatus[models.StatusAttended]},
+	}
+	for i := range stages {
+		stages[i].Rate = attendance.Percentage(stages[i].Total, registered)
+	}
+	return stages
+}
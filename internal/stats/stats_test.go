@@ -0,0 +1,169 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+var wib = time.FixedZone("WIB", 7*3600)
+
+// register menyimpan participant lalu mengganti waktu registrasinya (BeforeCreate selalu memakai time.Now)
+func register(t *testing.T, db *gorm.DB, name string, created time.Time) {
+	t.Helper()
+	p := testutil.CreateParticipant(t, db, name)
+	if err := db.Model(&p).UpdateColumn("created_at", created).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTimeSeriesBucketsInLocation(t *testing.T) {
+	db := testutil.OpenDB(t, &models.Participant{})
+	// Disimpan dengan offset berbeda-beda, seperti data dari server dengan TZ berbeda
+	register(t, db, "Sebelum", time.Date(2026, 9, 28, 10, 0, 0, 0, time.UTC))
+	register(t, db, "Tengah malam WIB", time.Date(2026, 10, 4, 17, 30, 0, 0, time.UTC)) // 5 Okt 00:30 WIB
+	register(t, db, "Pagi WIB", time.Date(2026, 10, 5, 8, 0, 0, 0, wib))
+	register(t, db, "Malam UTC", time.Date(2026, 10, 5, 23, 0, 0, 0, time.UTC)) // 6 Okt 06:00 WIB
+	register(t, db, "Minggu", time.Date(2026, 10, 11, 20, 0, 0, 0, wib))
+	register(t, db, "Sesudah", time.Date(2026, 10, 12, 1, 0, 0, 0, wib))
+	query := db.Model(&models.Participant{})
+
+	from, to := time.Date(2026, 10, 4, 0, 0, 0, 0, wib), time.Date(2026, 10, 6, 0, 0, 0, 0, wib)
+	days, err := TimeSeries(query, IntervalDay, from, to, wib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Point{
+		{Period: "2026-10-04", Total: 0, Cumulative: 1},
+		{Period: "2026-10-05", Total: 2, Cumulative: 3},
+		{Period: "2026-10-06", Total: 1, Cumulative: 4},
+	}
+	if !reflect.DeepEqual(days, want) {
+		t.Errorf("days = %+v, want %+v", days, want)
+	}
+
+	// Minggu mulai Senin 5 Okt; registrasi Minggu 11 Okt 20:00 WIB masih minggu yang sama
+	weeks, err := TimeSeries(query, IntervalWeek, time.Date(2026, 10, 7, 0, 0, 0, 0, wib), time.Date(2026, 10, 12, 0, 0, 0, 0, wib), wib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Point{
+		{Period: "2026-10-05", Total: 4, Cumulative: 5},
+		{Period: "2026-10-12", Total: 1, Cumulative: 6},
+	}
+	if !reflect.DeepEqual(weeks, want) {
+		t.Errorf("weeks = %+v, want %+v", weeks, want)
+	}
+
+	// Zona waktu lain menghasilkan pembagian hari yang berbeda
+	utcDays, err := TimeSeries(query, IntervalDay, time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if utcDays[0].Total != 1 || utcDays[1].Total != 2 {
+		t.Errorf("UTC days = %+v", utcDays)
+	}
+}
+
+func TestTimeSeriesValidation(t *testing.T) {
+	db := testutil.OpenDB(t, &models.Participant{})
+	query := db.Model(&models.Participant{})
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, wib)
+	if _, err := TimeSeries(query, "month", day, day, wib); err != ErrInvalidInterval {
+		t.Errorf("interval month: %v", err)
+	}
+	if _, err := TimeSeries(query, IntervalDay, day, day.AddDate(0, 0, -1), wib); err != ErrInvalidRange {
+		t.Errorf("to before from: %v", err)
+	}
+	if _, err := TimeSeries(query, IntervalDay, day, day.AddDate(0, 0, maxDays), wib); err != ErrInvalidRange {
+		t.Errorf("range too long: %v", err)
+	}
+	if points, err := TimeSeries(query, IntervalDay, day, day.AddDate(0, 0, maxDays-1), wib); err != nil || len(points) != maxDays {
+		t.Errorf("max range = %d points, %v", len(points), err)
+	}
+}
+
+func TestPeriodStartAndDefaultRange(t *testing.T) {
+	// Rabu 7 Okt 2026 01:00 WIB = Selasa 6 Okt 18:00 UTC
+	now := time.Date(2026, 10, 6, 18, 0, 0, 0, time.UTC)
+	if got := PeriodStart(now, IntervalDay, wib); !got.Equal(time.Date(2026, 10, 7, 0, 0, 0, 0, wib)) {
+		t.Errorf("day start = %s", got)
+	}
+	if got := PeriodStart(now, IntervalWeek, wib); !got.Equal(time.Date(2026, 10, 5, 0, 0, 0, 0, wib)) {
+		t.Errorf("week start = %s", got)
+	}
+	from, to := DefaultRange(now, IntervalDay, wib)
+	if from.Format("2006-01-02") != "2026-09-08" || to.Format("2006-01-02") != "2026-10-07" {
+		t.Errorf("default day range = %s - %s", from, to)
+	}
+	from, to = DefaultRange(now, IntervalWeek, wib)
+	if from.Format("2006-01-02") != "2026-07-20" || to.Format("2006-01-02") != "2026-10-05" {
+		t.Errorf("default week range = %s - %s", from, to)
+	}
+}
+
+func TestBreakdownIgnoresCaseAndLimits(t *testing.T) {
+	db := testutil.OpenDB(t, &models.Participant{})
+	for i, kampus := range []string{"ITB", "itb ", "ITB", "UI", "UI", "UGM"} {
+		testutil.CreateParticipant(t, db, "P"+string(rune('A'+i)), func(p *models.Participant) { p.Kampus = kampus })
+	}
+	items, others, err := Breakdown(db.Model(&models.Participant{}), "kampus", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Count{{Label: "ITB", Total: 3}, {Label: "UI", Total: 2}}
+	if !reflect.DeepEqual(items, want) || others != 1 {
+		t.Errorf("items = %+v, others = %d", items, others)
+	}
+	if _, _, err := Breakdown(db.Model(&models.Participant{}), "phone", 0); err != ErrUnknownDimension {
+		t.Errorf("dimension phone: %v", err)
+	}
+}
+
+func TestAgeBrackets(t *testing.T) {
+	db := testutil.OpenDB(t, &models.Participant{})
+	for i, birth := range []string{"2009-06-01", "2008-01-01", "2005-03-10", "1990-12-31"} {
+		testutil.CreateParticipant(t, db, "P"+string(rune('A'+i)), func(p *models.Participant) { p.BirthDate = testutil.Date(birth) })
+	}
+	edges, err := ParseAgeEdges("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := AgeBrackets(db.Model(&models.Participant{}), testutil.Date("2026-03-10"), edges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Count{{"<18", 1}, {"18-20", 1}, {"21-23", 1}, {"24-26", 0}, {"27-29", 0}, {"30+", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("brackets = %+v", got)
+	}
+}
+
+func TestParseAgeEdges(t *testing.T) {
+	if edges, err := ParseAgeEdges("17, 18,25"); err != nil || !reflect.DeepEqual(edges, []int{17, 18, 25}) {
+		t.Errorf("edges = %v, %v", edges, err)
+	}
+	if labels := ageLabels([]int{17, 18, 25}); !reflect.DeepEqual(labels, []string{"<17", "17", "18-24", "25+"}) {
+		t.Errorf("labels = %v", labels)
+	}
+	for _, bad := range []string{"18,a", "21,18", "0", "200"} {
+		if _, err := ParseAgeEdges(bad); err == nil {
+			t.Errorf("ParseAgeEdges(%q) accepted", bad)
+		}
+	}
+}
+
+func TestFunnel(t *testing.T) {
+	stages := Funnel(map[string]int64{
+		models.StatusPending: 4, models.StatusApproved: 3, models.StatusAttended: 2, models.StatusRejected: 1,
+	})
+	want := []Stage{{"registered", 10, 100}, {"approved", 5, 50}, {"attended", 2, 20}}
+	if !reflect.DeepEqual(stages, want) {
+		t.Errorf("funnel = %+v", stages)
+	}
+}
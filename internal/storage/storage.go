@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"backend/internal/helpers"
+)
+
+// ErrNotFound dikembalikan jika object tidak ada di storage
+var ErrNotFound = errors.New("object not found")
+
+// Storage menyimpan file upload. Implementasi bisa diganti (folder lokal, S3 / MinIO, dll).
+// Key memakai pemisah "/" (mis. "participants/<id>/<file>.jpg").
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewFromEnv membuat storage sesuai env STORAGE_DRIVER (default: local)
+func NewFromEnv() Storage {
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "s3":
+		return &S3Storage{
+			Endpoint:  helpers.GetEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:    helpers.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: helpers.GetEnvBool("S3_PATH_STYLE", true),
+		}
+	default:
+		return &LocalStorage{Dir: helpers.GetEnv("STORAGE_LOCAL_DIR", filepath.Join(".", "data", "uploads"))}
+	}
+}
+
+// validKey menolak key kosong, absolut atau yang keluar dari root storage ("..")
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+// Package testutil berisi fixture yang dipakai bersama oleh test di beberapa package: database
+// SQLite sementara serta event dan participant dengan nilai default yang lolos validasi.
+package testutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"backend/internal/models"
+)
+
+var seq atomic.Int64
+
+// OpenDB membuka database SQLite di direktori sementara test lalu memigrasi tabel yang diberikan.
+// Opsi koneksi sama dengan db.Connect (busy timeout + BEGIN IMMEDIATE) supaya test yang menulis
+// bersamaan berperilaku seperti server.
+func OpenDB(t testing.TB, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if len(tables) > 0 {
+		if err := db.AutoMigrate(tables...); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// Date mem-parse tanggal format "2006-01-02" (UTC)
+func Date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// CreateEvent menyimpan event 1-2 Desember 2026 dengan slug unik. mutate dijalankan sebelum disimpan.
+func CreateEvent(t testing.TB, db *gorm.DB, mutate ...func(*models.Event)) models.Event {
+	t.Helper()
+	event := models.Event{
+		Name:      "Youth Camp",
+		Slug:      fmt.Sprintf("youth-camp-%d", seq.Add(1)),
+		StartDate: Date("2026-12-01"),
+		EndDate:   Date("2026-12-02"),
+	}
+	for _, fn := range mutate {
+		fn(&event)
+	}
+	if err := db.Create(&event).Error; err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	return event
+}
+
+// Participant mengembalikan participant dengan semua field wajib terisi (belum disimpan)
+func Participant(name string, mutate ...func(*models.Participant)) models.Participant {
+	p := models.Participant{
+		Name:      name,
+		Place:     "Jakarta",
+		BirthDate: Date("2000-01-01"),
+		Kampus:    "UI",
+		Jurusan:   "Teknik",
+		Angkatan:  "2020",
+		Phone:     "+6281234567890",
+	}
+	for _, fn := range mutate {
+		fn(&p)
+	}
+	return p
+}
+
+// CreateParticipant menyimpan Participant(name, mutate...) langsung tanpa alur registrasi
+func CreateParticipant(t testing.TB, db *gorm.DB, name string, mutate ...func(*models.Participant)) models.Participant {
+	t.Helper()
+	p := Participant(name, mutate...)
+	if err := db.Create(&p).Error; err != nil {
+		t.Fatalf("create participant %s: %v", name, err)
+	}
+	return p
+}
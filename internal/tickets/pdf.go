@@ -0,0 +1,99 @@
+package tickets
+
+import (
+	"bytes"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Ticket adalah data yang dicetak di tiket PDF
+type Ticket struct {
+	Code      string
+	Payload   string // isi QR (Signer.Payload)
+	Name      string
+	Kampus    string
+	Status    string
+	EventName string
+	EventDate string // sudah diformat, kosong jika participant tanpa event
+	Location  string
+}
+
+// PDF membuat tiket printable ukuran A6 berisi QR, kode registrasi dan data pendaftar
+func PDF(t Ticket) ([]byte, error) {
+	qr, err := QRPNG(t.Payload, 512)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A6", "")
+	pdf.SetTitle("Tiket "+t.Code, true)
+	pdf.SetCreator("Youth College", true)
+	pdf.SetMargins(8, 8, 8)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+	// Font bawaan PDF hanya cp1252, karakter non-latin diterjemahkan semampunya
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageW, _ := pdf.GetPageSize()
+	contentW := pageW - 16
+
+	// Header
+	pdf.SetFillColor(33, 37, 41)
+	pdf.Rect(0, 0, pageW, 20, "F")
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.SetXY(8, 5)
+	pdf.CellFormat(contentW, 6, "YOUTH COLLEGE", "", 2, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(contentW, 5, "Tiket Registrasi", "", 2, "C", false, 0, "")
+
+	// Event
+	pdf.SetTextColor(33, 37, 41)
+	pdf.SetXY(8, 24)
+	if t.EventName != "" {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.MultiCell(contentW, 5, tr(t.EventName), "", "C", false)
+		pdf.SetFont("Helvetica", "", 8)
+		if t.EventDate != "" {
+			pdf.CellFormat(contentW, 4, tr(t.EventDate), "", 2, "C", false, 0, "")
+		}
+		if t.Location != "" {
+			pdf.MultiCell(contentW, 4, tr(t.Location), "", "C", false)
+		}
+	}
+
+	// QR
+	const qrSize = 56.0
+	qrY := pdf.GetY() + 2
+	opts := gofpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("qr", opts, bytes.NewReader(qr))
+	pdf.ImageOptions("qr", (pageW-qrSize)/2, qrY, qrSize, qrSize, false, opts, 0, "")
+
+	// Kode registrasi dan data pendaftar
+	pdf.SetXY(8, qrY+qrSize+1)
+	pdf.SetFont("Courier", "B", 20)
+	pdf.CellFormat(contentW, 9, t.Code, "", 2, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.MultiCell(contentW, 5, tr(t.Name), "", "C", false)
+	pdf.SetFont("Helvetica", "", 9)
+	if t.Kampus != "" {
+		pdf.MultiCell(contentW, 4.5, tr(t.Kampus), "", "C", false)
+	}
+	if t.Status != "" {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(contentW, 6, "Status: "+tr(t.Status), "", 2, "C", false, 0, "")
+	}
+
+	// Footer
+	_, pageH := pdf.GetPageSize()
+	pdf.SetXY(8, pageH-14)
+	pdf.SetFont("Helvetica", "I", 7)
+	pdf.SetTextColor(108, 117, 125)
+	pdf.MultiCell(contentW, 3.5, "Tunjukkan tiket ini (cetak atau dari HP) saat registrasi ulang di lokasi. "+
+		"Jangan bagikan QR tiket ke orang lain.", "", "C", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,46 @@
+package tickets
+
+import (
+	"bytes"
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRPNG membuat gambar QR (PNG persegi, sisi size piksel) dari isi tiket
+func QRPNG(payload string, size int) ([]byte, error) {
+	qr, err := qrcode.New(payload, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	return qr.PNG(size)
+}
+
+// QRSVG membuat QR dalam format SVG. Satu modul = satu unit viewBox sehingga tajam di ukuran berapa pun.
+func QRSVG(payload string) ([]byte, error) {
+	qr, err := qrcode.New(payload, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := qr.Bitmap() // sudah termasuk quiet zone
+	n := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// Gabungkan modul hitam yang berurutan dalam satu baris menjadi satu persegi panjang
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buf, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package tickets
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+)
+
+// payloadPrefix menandai isi QR tiket (versi format ikut disimpan agar bisa diganti kelak)
+const payloadPrefix = "YC1"
+
+// ErrInvalidTicket dikembalikan jika isi QR / signature tiket tidak valid
+var ErrInvalidTicket = errors.New("tiket tidak valid")
+
+// Signer menandatangani kode registrasi dengan HMAC-SHA256 sehingga QR tiket tidak bisa dipalsukan
+// hanya dengan menebak kode. Signature tidak punya masa berlaku; tiket berlaku selama pendaftarannya ada.
+type Signer struct {
+	Secret []byte
+}
+
+// Sign mengembalikan signature pendek (16 karakter base32) untuk kode registrasi.
+// Huruf besar + angka saja agar QR bisa memakai mode alfanumerik yang lebih ringkas.
+func (s Signer) Sign(code string) string {
+	m := hmac.New(sha256.New, s.Secret)
+	m.Write([]byte("ticket\n" + code))
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(m.Sum(nil)[:10])
+}
+
+// Verify mengecek signature untuk kode registrasi
+func (s Signer) Verify(code, signature string) bool {
+	return hmac.Equal([]byte(strings.ToUpper(signature)), []byte(s.Sign(code)))
+}
+
+// Payload adalah isi QR tiket, format "YC1:<kode>:<signature>"
+func (s Signer) Payload(code string) string {
+	return payloadPrefix + ":" + code + ":" + s.Sign(code)
+}
+
+// ParsePayload memvalidasi isi QR hasil scan dan mengembalikan kode registrasinya
+func (s Signer) ParsePayload(payload string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(payload), ":")
+	if len(parts) != 3 || !strings.EqualFold(parts[0], payloadPrefix) {
+		return "", ErrInvalidTicket
+	}
+	code := helpers.NormalizeRegistrationCode(parts[1])
+	if code == "" || !s.Verify(code, parts[2]) {
+		return "", ErrInvalidTicket
+	}
+	return code, nil
+}
+
+// BackfillCodes memberi kode registrasi ke participant lama yang dibuat sebelum kolom ini ada
+func BackfillCodes(db *gorm.DB) (int, error) {
+	var participants []models.Participant
+	if err := db.Select("id").Where("registration_code IS NULL OR registration_code = ''").Find(&participants).Error; err != nil {
+		return 0, err
+	}
+	for _, p := range participants {
+		code, err := models.UniqueRegistrationCode(db)
+		if err != nil {
+			return 0, err
+		}
+		if err := db.Model(&models.Participant{}).Where("id = ?", p.ID).
+			UpdateColumn("registration_code", code).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(participants), nil
+}
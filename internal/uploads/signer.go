@@ -0,0 +1,83 @@
+package uploads
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error verifikasi URL / token
+var (
+	ErrInvalidSignature = errors.New("signature tidak valid")
+	ErrExpired          = errors.New("link sudah kedaluwarsa")
+)
+
+// Signer membuat dan memverifikasi URL download berumur pendek dan token upload mandiri.
+// Keduanya berupa HMAC-SHA256 sehingga tidak perlu disimpan di database.
+type Signer struct {
+	Secret []byte
+}
+
+func (s Signer) mac(parts ...string) string {
+	m := hmac.New(sha256.New, s.Secret)
+	m.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// SignDownload mengembalikan query string (expires & signature) untuk download file.
+// Variant kosong untuk file asli, "thumb" untuk thumbnail.
+func (s Signer) SignDownload(fileID, variant string, expires time.Time) url.Values {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{}
+	if variant != "" {
+		q.Set("variant", variant)
+	}
+	q.Set("expires", exp)
+	q.Set("signature", s.mac("download", fileID, variant, exp))
+	return q
+}
+
+// VerifyDownload memvalidasi query string hasil SignDownload
+func (s Signer) VerifyDownload(fileID, variant, expires, signature string, now time.Time) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.mac("download", fileID, variant, expires))) {
+		return ErrInvalidSignature
+	}
+	if now.Unix() > exp {
+		return ErrExpired
+	}
+	return nil
+}
+
+// UploadToken membuat token upload mandiri untuk participant, format "<expires>.<signature>"
+func (s Signer) UploadToken(participantID string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + s.mac("upload", participantID, exp)
+}
+
+// VerifyUploadToken memvalidasi token hasil UploadToken
+func (s Signer) VerifyUploadToken(participantID, token string, now time.Time) error {
+	exp, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.mac("upload", participantID, exp))) {
+		return ErrInvalidSignature
+	}
+	if now.Unix() > expires {
+		return ErrExpired
+	}
+	return nil
+}
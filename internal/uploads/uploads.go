@@ -0,0 +1,149 @@
+package uploads
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // registrasi decoder PNG untuk image.Decode
+	"net/http"
+	"strings"
+)
+
+// Jenis file bawaan participant. Selain ini, kind boleh berupa key pertanyaan registrasi tipe file.
+const (
+	KindKTM   = "ktm"
+	KindPhoto = "photo"
+)
+
+// Content type yang diterima (hasil sniffing)
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypePDF  = "application/pdf"
+)
+
+// Batas thumbnail dan ukuran gambar yang mau di-decode
+const (
+	ThumbnailSize = 320
+	maxPixels     = 40_000_000 // Mencegah decompression bomb (gambar kecil dengan dimensi raksasa)
+)
+
+// Error validasi upload
+var (
+	ErrUnsupportedType = errors.New("tipe file tidak didukung, gunakan JPEG, PNG atau PDF")
+	ErrImageRequired   = errors.New("file harus berupa gambar JPEG atau PNG")
+	ErrImageTooLarge   = errors.New("dimensi gambar terlalu besar")
+)
+
+// Sniff menentukan content type dari isi file (bukan dari nama file atau header request)
+func Sniff(data []byte) string {
+	ct := http.DetectContentType(data)
+	if i := strings.Index(ct, ";"); i >= 0 {
+		ct = ct[:i]
+	}
+	return strings.TrimSpace(ct)
+}
+
+// IsImage mengecek apakah content type adalah gambar yang bisa dibuat thumbnail
+func IsImage(contentType string) bool {
+	return contentType == TypeJPEG || contentType == TypePNG
+}
+
+// Check memvalidasi content type untuk jenis file tertentu. Pas foto wajib gambar, lainnya boleh PDF.
+func Check(kind, contentType string) error {
+	switch contentType {
+	case TypeJPEG, TypePNG:
+		return nil
+	case TypePDF:
+		if kind == KindPhoto {
+			return ErrImageRequired
+		}
+		return nil
+	}
+	if kind == KindPhoto {
+		return ErrImageRequired
+	}
+	return ErrUnsupportedType
+}
+
+// Extension mengembalikan ekstensi file untuk content type yang diterima
+func Extension(contentType string) string {
+	switch contentType {
+	case TypeJPEG:
+		return ".jpg"
+	case TypePNG:
+		return ".png"
+	case TypePDF:
+		return ".pdf"
+	}
+	return ""
+}
+
+// Thumbnail membuat thumbnail JPEG dengan sisi terpanjang ThumbnailSize piksel.
+// Gambar yang lebih kecil dari ukuran thumbnail tetap di-encode ulang (metadata EXIF ikut terbuang).
+func Thumbnail(data []byte) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scale(src, ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scale memperkecil gambar dengan rata-rata area (box filter) dan meratakan transparansi ke latar putih
+func scale(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	nw, nh := w, h
+	if w >= h && w > maxSide {
+		nw, nh = maxSide, h*maxSide/w
+	} else if h > w && h > maxSide {
+		nw, nh = w*maxSide/h, maxSide
+	}
+	if nw < 1 {
+		nw = 1
+	}
+	if nh < 1 {
+		nh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		sy0, sy1 := b.Min.Y+y*h/nh, b.Min.Y+(y+1)*h/nh
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < nw; x++ {
+			sx0, sx1 := b.Min.X+x*w/nw, b.Min.X+(x+1)*w/nw
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+			var r, g, bl, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					// Warna sudah premultiplied, tambahkan putih sebanyak bagian yang transparan
+					r += uint64(cr + 0xffff - ca)
+					g += uint64(cg + 0xffff - ca)
+					bl += uint64(cb + 0xffff - ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: 0xffff})
+		}
+	}
+	return dst
+}
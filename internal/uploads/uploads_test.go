@@ -0,0 +1,139 @@
+package uploads
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"jpeg", encodeJPEG(t, img), TypeJPEG},
+		{"png", encodePNG(t, img), TypePNG},
+		{"pdf", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), TypePDF},
+		{"html disguised", []byte("<html><script>alert(1)</script></html>"), "text/html"},
+		{"text", []byte("halo"), "text/plain"},
+	}
+	for _, tt := range tests {
+		if got := Sniff(tt.data); got != tt.want {
+			t.Errorf("%s: Sniff = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		kind, contentType string
+		want              error
+	}{
+		{KindPhoto, TypeJPEG, nil},
+		{KindPhoto, TypePNG, nil},
+		{KindPhoto, TypePDF, ErrImageRequired},
+		{KindPhoto, "text/html", ErrImageRequired},
+		{KindKTM, TypePDF, nil},
+		{KindKTM, TypeJPEG, nil},
+		{KindKTM, "text/html", ErrUnsupportedType},
+		{"surat_rekomendasi", TypePDF, nil},
+		{"surat_rekomendasi", "application/zip", ErrUnsupportedType},
+	}
+	for _, tt := range tests {
+		if got := Check(tt.kind, tt.contentType); got != tt.want {
+			t.Errorf("Check(%q, %q) = %v, want %v", tt.kind, tt.contentType, got, tt.want)
+		}
+	}
+	if Extension(TypeJPEG) != ".jpg" || Extension(TypePNG) != ".png" || Extension(TypePDF) != ".pdf" || Extension("text/html") != "" {
+		t.Error("Extension returned unexpected value")
+	}
+}
+
+func TestThumbnailScalesLongestSide(t *testing.T) {
+	tests := []struct {
+		w, h, wantW, wantH int
+	}{
+		{1280, 640, ThumbnailSize, 160},
+		{600, 1200, 160, ThumbnailSize},
+		{100, 50, 100, 50}, // lebih kecil dari thumbnail: ukuran tetap
+		{5000, 2, ThumbnailSize, 1},
+	}
+	for _, tt := range tests {
+		thumb, err := Thumbnail(encodePNG(t, image.NewRGBA(image.Rect(0, 0, tt.w, tt.h))))
+		if err != nil {
+			t.Fatalf("%dx%d: %v", tt.w, tt.h, err)
+		}
+		if Sniff(thumb) != TypeJPEG {
+			t.Fatalf("%dx%d: thumbnail is %s, want JPEG", tt.w, tt.h, Sniff(thumb))
+		}
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+			t.Errorf("%dx%d: thumbnail %dx%d, want %dx%d", tt.w, tt.h, cfg.Width, cfg.Height, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestThumbnailFlattensTransparencyToWhite(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			img.Set(x, y, color.NRGBA{}) // transparan penuh
+		}
+	}
+	thumb, err := Thumbnail(encodePNG(t, img))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := jpeg.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, g, b, _ := decoded.At(5, 5).RGBA()
+	if r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("transparent pixel became (%d, %d, %d), want white", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestThumbnailRejectsInvalidImages(t *testing.T) {
+	if _, err := Thumbnail([]byte("%PDF-1.4")); err == nil {
+		t.Error("Thumbnail of PDF succeeded")
+	}
+
+	// Header PNG dengan dimensi raksasa: ditolak sebelum piksel di-decode
+	huge := encodePNG(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+	ihdr := bytes.Index(huge, []byte("IHDR"))
+	copy(huge[ihdr+4:], []byte{0, 0, 0x4e, 0x20, 0, 0, 0x4e, 0x20}) // 20000 x 20000
+	binary.BigEndian.PutUint32(huge[ihdr+17:], crc32.ChecksumIEEE(huge[ihdr:ihdr+17]))
+	if _, err := Thumbnail(huge); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Thumbnail of 20000x20000 image = %v, want ErrImageTooLarge", err)
+	}
+}
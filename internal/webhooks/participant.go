@@ -0,0 +1,69 @@
+package webhooks
+
+import (
+	"log"
+
+	"backend/internal/models"
+)
+
+// PublishParticipant mengirim event siklus pendaftaran participant ke webhook yang berlangganan.
+// Data berisi participant, ringkasan event dan field tambahan (mis. from_status / to_status).
+func (d *Dispatcher) PublishParticipant(eventType string, participant models.Participant, extra map[string]interface{}) {
+	if d == nil {
+		return
+	}
+	// Token upload & link tiket adalah akses milik pendaftar, tidak ikut dikirim ke sistem luar
+	participant.UploadToken = ""
+	participant.TicketURL = ""
+
+	data := map[string]interface{}{"participant": participant, "event": nil}
+	if participant.EventID != nil {
+		var event models.Event
+		if err := d.DB.Select("id", "slug", "name").First(&event, *participant.EventID).Error; err != nil {
+			log.Printf("ERROR: Gagal memuat event %d untuk webhook %s: %v", *participant.EventID, eventType, err)
+		} else {
+			data["event"] = map[string]interface{}{"id": event.ID, "slug": event.Slug, "name": event.Name}
+		}
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	d.Publish(eventType, participant.EventID, data)
+}
+
+// PublishStatusChange mengirim participant.status_changed, ditambah participant.approved jika status baru approved
+func (d *Dispatcher) PublishStatusChange(participant models.Participant, fromStatus, reason, actor string) {
+	extra := map[string]interface{}{
+		"from_status": fromStatus,
+		"to_status":   participant.Status,
+		"reason":      reason,
+		"actor":       actor,
+	}
+	d.PublishParticipant(ParticipantStatusChanged, participant, extra)
+	if participant.Status == models.StatusApproved {
+		d.PublishParticipant(ParticipantApproved, participant, extra)
+	}
+}
+
+// PublishCheckIn mengirim participant.checked_in dan participant.status_changed (approved -> attended)
+// setelah workflow.CheckIn berhasil
+func (d *Dispatcher) PublishCheckIn(participant models.Participant, checkIn *models.CheckIn) {
+	d.PublishParticipant(ParticipantCheckedIn, participant, map[string]interface{}{"check_in": checkIn})
+	reason := "Check-in"
+	if checkIn.Gate != "" {
+		reason += " di gate " + checkIn.Gate
+	}
+	d.PublishStatusChange(participant, models.StatusApproved, reason, checkIn.CheckedInBy)
+}
+
+// PublishCheckInUndone mengirim participant.status_changed (attended -> approved) setelah
+// workflow.UndoCheckIn berhasil. participant.approved tidak dikirim karena pendaftaran tidak disetujui ulang.
+func (d *Dispatcher) PublishCheckInUndone(participant models.Participant, checkIn *models.CheckIn) {
+	d.PublishParticipant(ParticipantStatusChanged, participant, map[string]interface{}{
+		"from_status": models.StatusAttended,
+		"to_status":   participant.Status,
+		"reason":      checkIn.UndoReason,
+		"actor":       checkIn.UndoneBy,
+		"check_in":    checkIn,
+	})
+}
@@ -0,0 +1,324 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"backend/internal/helpers"
+	"backend/internal/models"
+	"backend/internal/outbox"
+)
+
+// Tipe event yang dikirim ke webhook
+const (
+	ParticipantRegistered    = "participant.registered"     // pendaftaran baru (termasuk yang masuk waitlist)
+	ParticipantApproved      = "participant.approved"       // status berubah menjadi approved
+	ParticipantStatusChanged = "participant.status_changed" // semua perubahan status, termasuk promosi dari waitlist
+	ParticipantCheckedIn     = "participant.checked_in"     // check-in di lokasi event
+	Ping                     = "ping"                       // test manual dari admin, selalu dikirim tanpa melihat filter
+)
+
+// EventTypes adalah tipe event yang boleh dipilih di filter webhook
+var EventTypes = []string{ParticipantRegistered, ParticipantApproved, ParticipantStatusChanged, ParticipantCheckedIn}
+
+// IsValidEventType mengecek tipe event untuk filter webhook ("*" = semua)
+func IsValidEventType(t string) bool {
+	if t == "*" {
+		return true
+	}
+	for _, e := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// maxResponseLog adalah panjang maksimal body response penerima yang disimpan di log percobaan
+const maxResponseLog = 2048
+
+// Config mengatur retry dan worker pengiriman
+type Config struct {
+	outbox.Retry
+	// DisableAfter menonaktifkan webhook setelah sekian percobaan gagal berturut-turut (0 = tidak pernah)
+	DisableAfter int
+	// Timeout adalah batas waktu satu request HTTP ke penerima
+	Timeout time.Duration
+	// PollInterval adalah jeda worker mengecek delivery yang jatuh tempo
+	PollInterval time.Duration
+}
+
+// ConfigFromEnv membaca konfigurasi webhook dari environment
+func ConfigFromEnv() Config {
+	return Config{
+		Retry: outbox.Retry{
+			MaxAttempts: helpers.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 10),
+			BaseBackoff: helpers.GetEnvDuration("WEBHOOK_BASE_BACKOFF", 30*time.Second),
+			MaxBackoff:  helpers.GetEnvDuration("WEBHOOK_MAX_BACKOFF", 6*time.Hour),
+		},
+		DisableAfter: helpers.GetEnvInt("WEBHOOK_DISABLE_AFTER", 20),
+		Timeout:      helpers.GetEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		PollInterval: helpers.GetEnvDuration("WEBHOOK_POLL_INTERVAL", 15*time.Second),
+	}
+}
+
+// Envelope adalah body JSON yang dikirim ke penerima
+type Envelope struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Sign membuat signature HMAC-SHA256 dari "timestamp.body" dengan secret webhook
+func Sign(secret, timestamp string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(timestamp + "."))
+	m.Write(body)
+	return "sha256=" + hex.EncodeToString(m.Sum(nil))
+}
+
+// Dispatcher menyimpan delivery untuk webhook yang berlangganan; worker outbox mengirimnya di background
+type Dispatcher struct {
+	DB     *gorm.DB
+	Config Config
+	Client *http.Client
+
+	worker *outbox.Worker[models.WebhookDelivery]
+}
+
+// NewDispatcher membuat dispatcher; panggil Start untuk menjalankan worker
+func NewDispatcher(db *gorm.DB, cfg Config) *Dispatcher {
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+		// Redirect tidak diikuti supaya payload bertanda tangan tidak dikirim ke alamat lain
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	d := &Dispatcher{DB: db, Config: cfg, Client: client}
+	d.worker = outbox.New(db, outbox.Options{
+		Name:          "Webhook worker",
+		PendingStatus: models.WebhookDeliveryPending,
+		Retry:         cfg.Retry,
+		SendTimeout:   cfg.Timeout,
+		PollInterval:  cfg.PollInterval,
+		// Delivery milik webhook nonaktif menunggu sampai webhook diaktifkan lagi
+		Scope: func(q *gorm.DB) *gorm.DB {
+			return q.Where("webhook_id IN (SELECT id FROM webhooks WHERE active = ?)", true)
+		},
+	}, outbox.Handler[models.WebhookDelivery]{
+		Attempts: func(delivery *models.WebhookDelivery) int { return delivery.Attempts },
+		Send:     d.send,
+		Record:   d.record,
+	})
+	return d
+}
+
+// Start menjalankan worker pengiriman di background (sekali per proses)
+func (d *Dispatcher) Start() { d.worker.Start() }
+
+// Stop menghentikan worker setelah request yang sedang berjalan selesai (atau ctx habis)
+func (d *Dispatcher) Stop(ctx context.Context) error { return d.worker.Stop(ctx) }
+
+// Kick membangunkan worker tanpa menunggu PollInterval
+func (d *Dispatcher) Kick() { d.worker.Kick() }
+
+// Publish membuat delivery untuk semua webhook aktif yang berlangganan tipe event ini.
+// Penerima webhook tidak boleh mempengaruhi request admin / pendaftar, jadi error cukup di log.
+func (d *Dispatcher) Publish(eventType string, eventID *uint, data interface{}) {
+	if d == nil {
+		return
+	}
+	var hooks []models.Webhook
+	if err := d.DB.Where("active = ?", true).Find(&hooks).Error; err != nil {
+		log.Printf("ERROR: Gagal memuat webhook untuk %s: %v", eventType, err)
+		return
+	}
+	queued := 0
+	for _, hook := range hooks {
+		if !hook.Subscribes(eventType, eventID) {
+			continue
+		}
+		if _, err := d.enqueue(hook.ID, eventType, data); err != nil {
+			log.Printf("ERROR: Gagal menyimpan delivery webhook %d (%s): %v", hook.ID, eventType, err)
+			continue
+		}
+		queued++
+	}
+	if queued > 0 {
+		d.Kick()
+	}
+}
+
+// SendPing membuat delivery ping untuk satu webhook (tanpa melihat filter tipe event)
+func (d *Dispatcher) SendPing(hook models.Webhook) (*models.WebhookDelivery, error) {
+	delivery, err := d.enqueue(hook.ID, Ping, map[string]interface{}{
+		"webhook_id": hook.ID,
+		"name":       hook.Name,
+		"message":    "Webhook Youth College terhubung",
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.Kick()
+	return delivery, nil
+}
+
+func (d *Dispatcher) enqueue(webhookID uint, eventType string, data interface{}) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	envelope := Envelope{ID: uuid.New().String(), Type: eventType, CreatedAt: now.UTC(), Data: data}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventType:     eventType,
+		MessageID:     envelope.ID,
+		Payload:       string(payload),
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}
+	if err := d.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// Redeliver membuat delivery baru dengan payload dan message id yang sama
+func (d *Dispatcher) Redeliver(original models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		EventType:     original.EventType,
+		MessageID:     original.MessageID,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}
+	if err := d.DB.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	d.Kick()
+	return &delivery, nil
+}
+
+// record mencatat percobaan (di AttemptLog hasil send), status delivery dan kesehatan webhook
+func (d *Dispatcher) record(delivery *models.WebhookDelivery, res outbox.Result) {
+	attempt := models.WebhookAttempt{DeliveryID: delivery.ID}
+	if n := len(delivery.AttemptLog); n > 0 {
+		attempt = delivery.AttemptLog[n-1]
+	}
+	attempt.Attempt = res.Attempts
+	attempt.DurationMs = res.FinishedAt.Sub(res.StartedAt).Milliseconds()
+	attempt.CreatedAt = res.FinishedAt
+	if res.Err != nil {
+		attempt.Error = res.Err.Error()
+	}
+
+	updates := map[string]interface{}{
+		"attempts":        res.Attempts,
+		"last_status":     attempt.StatusCode,
+		"last_error":      attempt.Error,
+		"next_attempt_at": res.NextAttemptAt,
+		"updated_at":      res.FinishedAt,
+	}
+	switch {
+	case res.Err == nil:
+		updates["status"] = models.WebhookDeliverySuccess
+		updates["delivered_at"] = res.FinishedAt
+	case res.Final:
+		updates["status"] = models.WebhookDeliveryFailed
+	}
+
+	if txErr := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		return d.recordHealth(tx, delivery.WebhookID, res.Err == nil, res.FinishedAt)
+	}); txErr != nil {
+		log.Printf("ERROR: Gagal mencatat hasil delivery webhook %d: %v", delivery.ID, txErr)
+	}
+}
+
+// recordHealth memperbarui hitungan gagal berturut-turut dan menonaktifkan webhook yang terus gagal
+func (d *Dispatcher) recordHealth(tx *gorm.DB, webhookID uint, ok bool, now time.Time) error {
+	query := tx.Model(&models.Webhook{}).Where("id = ?", webhookID)
+	if ok {
+		return query.UpdateColumns(map[string]interface{}{"consecutive_failures": 0, "last_success_at": now}).Error
+	}
+	if err := query.UpdateColumns(map[string]interface{}{
+		"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+		"last_failure_at":      now,
+	}).Error; err != nil {
+		return err
+	}
+	if d.Config.DisableAfter <= 0 {
+		return nil
+	}
+	reason := "Dinonaktifkan otomatis setelah " + strconv.Itoa(d.Config.DisableAfter) + " kali gagal berturut-turut"
+	result := tx.Model(&models.Webhook{}).Where("id = ? AND active = ? AND consecutive_failures >= ?", webhookID, true, d.Config.DisableAfter).
+		UpdateColumns(map[string]interface{}{"active": false, "disabled_at": now, "disabled_reason": reason})
+	if result.Error == nil && result.RowsAffected > 0 {
+		log.Printf("Webhook %d disabled after %d consecutive failures", webhookID, d.Config.DisableAfter)
+	}
+	return result.Error
+}
+
+// send melakukan request HTTP POST bertanda tangan ke URL webhook. Status dan body response
+// disimpan ke delivery.AttemptLog untuk record; response selain 2xx dianggap gagal.
+func (d *Dispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) error {
+	var hook models.Webhook
+	if err := d.DB.First(&hook, delivery.WebhookID).Error; err != nil {
+		return err
+	}
+	// Webhook bisa dinonaktifkan di tengah putaran (mis. oleh delivery sebelumnya di batch yang sama);
+	// delivery dikirim setelah webhook aktif lagi
+	if !hook.Active {
+		return outbox.ErrSkip
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "YouthCollege-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", delivery.MessageID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseLog))
+	delivery.AttemptLog = append(delivery.AttemptLog, models.WebhookAttempt{
+		DeliveryID: delivery.ID, StatusCode: resp.StatusCode, Response: string(response),
+	})
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("penerima membalas HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
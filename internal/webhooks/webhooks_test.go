@@ -0,0 +1,322 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/outbox"
+	"backend/internal/testutil"
+)
+
+func TestSign(t *testing.T) {
+	// Vektor dihitung terpisah: HMAC-SHA256("whsec_test", "1700000000." + body)
+	body := []byte(`{"id":"evt_1","type":"ping"}`)
+	want := "sha256=33ff6664879612e191c4e01184f3da32597a3c9b33ca2655fac317d01c5abc3f"
+	if got := Sign("whsec_test", "1700000000", body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if Sign("whsec_test", "1700000001", body) == want || Sign("whsec_other", "1700000000", body) == want {
+		t.Error("signature does not depend on timestamp and secret")
+	}
+}
+
+func TestIsValidEventType(t *testing.T) {
+	for _, ok := range append([]string{"*"}, EventTypes...) {
+		if !IsValidEventType(ok) {
+			t.Errorf("IsValidEventType(%q) = false", ok)
+		}
+	}
+	for _, bad := range []string{"", Ping, "participant.*", "participant.deleted"} {
+		if IsValidEventType(bad) {
+			t.Errorf("IsValidEventType(%q) = true", bad)
+		}
+	}
+}
+
+// receiver adalah penerima webhook palsu yang memverifikasi signature seperti integrasi sungguhan
+type receiver struct {
+	t      *testing.T
+	secret string
+	mu     sync.Mutex
+	status int
+	got    []Envelope
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	timestamp := req.Header.Get("X-Webhook-Timestamp")
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		r.t.Errorf("X-Webhook-Timestamp = %q", timestamp)
+	}
+	if sig := req.Header.Get("X-Webhook-Signature"); sig != Sign(r.secret, timestamp, body) {
+		r.t.Errorf("X-Webhook-Signature = %q does not verify", sig)
+	}
+	var env Envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		r.t.Errorf("body is not an envelope: %v", err)
+	}
+	if req.Header.Get("X-Webhook-Id") != env.ID || req.Header.Get("X-Webhook-Event") != env.Type {
+		r.t.Errorf("headers id %q event %q do not match envelope %+v", req.Header.Get("X-Webhook-Id"), req.Header.Get("X-Webhook-Event"), env)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, env)
+	if r.status == http.StatusFound {
+		w.Header().Set("Location", "http://example.com/elsewhere")
+	}
+	w.WriteHeader(r.status)
+	w.Write([]byte("diterima"))
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mu.Lock()
+	r.status = status
+	r.mu.Unlock()
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.got)
+}
+
+func newTestDispatcher(t *testing.T) (*Dispatcher, *receiver, *httptest.Server) {
+	t.Helper()
+	db := testutil.OpenDB(t, &models.Webhook{}, &models.WebhookDelivery{}, &models.WebhookAttempt{})
+	recv := &receiver{t: t, secret: "whsec_test", status: http.StatusOK}
+	server := httptest.NewServer(recv)
+	t.Cleanup(server.Close)
+
+	d := NewDispatcher(db, Config{
+		Retry:        outbox.Retry{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour},
+		DisableAfter: 5,
+		Timeout:      5 * time.Second, PollInterval: time.Hour,
+	})
+	return d, recv, server
+}
+
+func createHook(t *testing.T, d *Dispatcher, url string, eventTypes []string, eventID *uint) models.Webhook {
+	t.Helper()
+	hook := models.Webhook{Name: "CRM", URL: url, Secret: "whsec_test", EventTypes: eventTypes, EventID: eventID, Active: true}
+	if err := d.DB.Create(&hook).Error; err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	return hook
+}
+
+func deliveriesFor(t *testing.T, d *Dispatcher, hookID uint) []models.WebhookDelivery {
+	t.Helper()
+	var list []models.WebhookDelivery
+	if err := d.DB.Where("webhook_id = ?", hookID).Order("id asc").Find(&list).Error; err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestPublishDeliversSignedPayload(t *testing.T) {
+	d, recv, server := newTestDispatcher(t)
+	event := uint(7)
+	all := createHook(t, d, server.URL, []string{"*"}, nil)
+	approvedOnly := createHook(t, d, server.URL, []string{ParticipantApproved}, nil)
+	otherEvent := createHook(t, d, server.URL, []string{"*"}, func() *uint { v := uint(8); return &v }())
+
+	d.Publish(ParticipantRegistered, &event, map[string]string{"participant_id": "p-1"})
+	if len(deliveriesFor(t, d, all.ID)) != 1 || len(deliveriesFor(t, d, approvedOnly.ID)) != 0 || len(deliveriesFor(t, d, otherEvent.ID)) != 0 {
+		t.Fatal("Publish did not respect webhook filters")
+	}
+
+	if n, err := d.worker.ProcessDue(); err != nil || n != 1 {
+		t.Fatalf("ProcessDue = %d, %v", n, err)
+	}
+	if recv.count() != 1 || recv.got[0].Type != ParticipantRegistered {
+		t.Fatalf("receiver got %+v", recv.got)
+	}
+	delivery := deliveriesFor(t, d, all.ID)[0]
+	if delivery.Status != models.WebhookDeliverySuccess || delivery.Attempts != 1 || delivery.LastStatus != 200 ||
+		delivery.DeliveredAt == nil || delivery.NextAttemptAt != nil {
+		t.Errorf("delivery = %+v", delivery)
+	}
+	var attempt models.WebhookAttempt
+	if err := d.DB.Where("delivery_id = ?", delivery.ID).First(&attempt).Error; err != nil || attempt.Response != "diterima" {
+		t.Errorf("attempt log = %+v, %v", attempt, err)
+	}
+
+	// Redelivery memakai message id yang sama supaya penerima bisa membuang duplikat
+	redelivery, err := d.Redeliver(delivery)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	d.worker.ProcessDue()
+	if recv.count() != 2 || recv.got[1].ID != recv.got[0].ID || *redelivery.RedeliveryOf != delivery.ID {
+		t.Errorf("redelivery ids = %s / %s", recv.got[0].ID, recv.got[1].ID)
+	}
+}
+
+func TestCheckInPublishesStatusChange(t *testing.T) {
+	d, _, server := newTestDispatcher(t)
+	hook := createHook(t, d, server.URL, []string{ParticipantStatusChanged, ParticipantApproved, ParticipantCheckedIn}, nil)
+	participant := testutil.Participant("Budi", func(p *models.Participant) {
+		p.ID = "p-1"
+		p.Status = models.StatusAttended
+	})
+	checkIn := &models.CheckIn{ID: 1, ParticipantID: participant.ID, Gate: "A", CheckedInBy: "petugas"}
+
+	d.PublishCheckIn(participant, checkIn)
+	// Undo: status kembali approved tanpa participant.approved
+	participant.Status = models.StatusApproved
+	checkIn.UndoneBy, checkIn.UndoReason = "koordinator", "Salah scan"
+	d.PublishCheckInUndone(participant, checkIn)
+
+	want := []struct{ eventType, from, to, reason, actor string }{
+		{ParticipantCheckedIn, "", "", "", ""},
+		{ParticipantStatusChanged, models.StatusApproved, models.StatusAttended, "Check-in di gate A", "petugas"},
+		{ParticipantStatusChanged, models.StatusAttended, models.StatusApproved, "Salah scan", "koordinator"},
+	}
+	deliveries := deliveriesFor(t, d, hook.ID)
+	if len(deliveries) != len(want) {
+		t.Fatalf("deliveries = %d, want %d", len(deliveries), len(want))
+	}
+	for i, w := range want {
+		var env struct {
+			Type string `json:"type"`
+			Data struct {
+				FromStatus string `json:"from_status"`
+				ToStatus   string `json:"to_status"`
+				Reason     string `json:"reason"`
+				Actor      string `json:"actor"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(deliveries[i].Payload), &env); err != nil {
+			t.Fatal(err)
+		}
+		if deliveries[i].EventType != w.eventType || env.Data.FromStatus != w.from || env.Data.ToStatus != w.to ||
+			env.Data.Reason != w.reason || env.Data.Actor != w.actor {
+			t.Errorf("delivery %d = %s %+v, want %+v", i, deliveries[i].EventType, env.Data, w)
+		}
+	}
+}
+
+func TestFailedDeliveryBacksOffThenFails(t *testing.T) {
+	d, recv, server := newTestDispatcher(t)
+	hook := createHook(t, d, server.URL, []string{"*"}, nil)
+	// Redirect tidak diikuti dan dihitung gagal
+	recv.setStatus(http.StatusFound)
+	if _, err := d.SendPing(hook); err != nil {
+		t.Fatalf("SendPing: %v", err)
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		before := time.Now()
+		if n, err := d.worker.ProcessDue(); err != nil || n != 1 {
+			t.Fatalf("attempt %d: ProcessDue = %d, %v", attempt, n, err)
+		}
+		delivery := deliveriesFor(t, d, hook.ID)[0]
+		if delivery.Attempts != attempt || delivery.LastStatus != http.StatusFound || delivery.LastError == "" {
+			t.Fatalf("attempt %d: delivery = %+v", attempt, delivery)
+		}
+		if attempt == 3 {
+			if delivery.Status != models.WebhookDeliveryFailed || delivery.NextAttemptAt != nil {
+				t.Fatalf("after MaxAttempts: status %s next %v, want failed", delivery.Status, delivery.NextAttemptAt)
+			}
+			break
+		}
+		wait := d.Config.Backoff(attempt)
+		if delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt == nil ||
+			delivery.NextAttemptAt.Before(before.Add(wait)) || delivery.NextAttemptAt.After(time.Now().Add(wait)) {
+			t.Fatalf("attempt %d: status %s next %v, want pending after %s", attempt, delivery.Status, delivery.NextAttemptAt, wait)
+		}
+		// Belum jatuh tempo: tidak dikirim lagi
+		if n, _ := d.worker.ProcessDue(); n != 0 {
+			t.Fatalf("attempt %d: delivery resent before backoff elapsed", attempt)
+		}
+		d.DB.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).UpdateColumn("next_attempt_at", time.Now().Add(-time.Second))
+	}
+
+	var stored models.Webhook
+	d.DB.First(&stored, hook.ID)
+	if stored.ConsecutiveFailures != 3 || stored.LastFailureAt == nil || !stored.Active {
+		t.Errorf("webhook = failures %d last failure %v active %v", stored.ConsecutiveFailures, stored.LastFailureAt, stored.Active)
+	}
+	if recv.count() != 3 {
+		t.Errorf("receiver got %d requests, want 3", recv.count())
+	}
+}
+
+func TestWebhookDisabledAfterConsecutiveFailures(t *testing.T) {
+	d, recv, server := newTestDispatcher(t)
+	d.Config.DisableAfter = 2
+	hook := createHook(t, d, server.URL, []string{"*"}, nil)
+	recv.setStatus(http.StatusInternalServerError)
+
+	d.Publish(ParticipantCheckedIn, nil, nil)
+	d.Publish(ParticipantCheckedIn, nil, nil)
+	d.Publish(ParticipantCheckedIn, nil, nil)
+	if n, err := d.worker.ProcessDue(); err != nil || n != 3 {
+		t.Fatalf("ProcessDue = %d, %v", n, err)
+	}
+
+	var stored models.Webhook
+	d.DB.First(&stored, hook.ID)
+	if stored.Active || stored.DisabledAt == nil || stored.DisabledReason == "" {
+		t.Fatalf("webhook = active %v disabled %v reason %q, want disabled", stored.Active, stored.DisabledAt, stored.DisabledReason)
+	}
+	// Delivery milik webhook nonaktif tetap tersimpan tapi tidak dikirim
+	if recv.count() != 2 {
+		t.Errorf("receiver got %d requests, want 2 before disabling", recv.count())
+	}
+	pending := 0
+	for _, delivery := range deliveriesFor(t, d, hook.ID) {
+		if delivery.Status == models.WebhookDeliveryPending {
+			pending++
+		}
+	}
+	if pending != 3 {
+		t.Errorf("%d pending deliveries, want 3", pending)
+	}
+
+	// Satu keberhasilan mereset hitungan gagal
+	d.DB.Model(&stored).UpdateColumns(map[string]interface{}{"active": true, "consecutive_failures": 1})
+	d.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID).UpdateColumn("next_attempt_at", time.Now().Add(-time.Second))
+	recv.setStatus(http.StatusNoContent)
+	d.worker.ProcessDue()
+	d.DB.First(&stored, hook.ID)
+	if stored.ConsecutiveFailures != 0 || stored.LastSuccessAt == nil {
+		t.Errorf("after success: failures %d last success %v", stored.ConsecutiveFailures, stored.LastSuccessAt)
+	}
+}
+
+func TestDispatcherStop(t *testing.T) {
+	d, recv, server := newTestDispatcher(t)
+	createHook(t, d, server.URL, []string{"*"}, nil)
+
+	d.Start()
+	d.Start()
+	d.Publish(ParticipantRegistered, nil, nil)
+	deadline := time.Now().Add(5 * time.Second)
+	for recv.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("Stop = %v", err)
+	}
+	if recv.count() != 1 {
+		t.Fatalf("receiver got %d requests, want 1", recv.count())
+	}
+	// Setelah Stop, delivery baru menunggu start berikutnya
+	d.Publish(ParticipantRegistered, nil, nil)
+	time.Sleep(50 * time.Millisecond)
+	if recv.count() != 1 {
+		t.Errorf("delivery sent after Stop")
+	}
+}
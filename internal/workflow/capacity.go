@@ -0,0 +1,169 @@
+package workflow
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend/internal/models"
+)
+
+// ErrEventFull dikembalikan jika participant tidak bisa menempati kursi karena kapasitas event penuh
+var ErrEventFull = errors.New("event capacity is full")
+
+// seatStatuses adalah status yang menempati kursi event
+var seatStatuses = []string{models.StatusPending, models.StatusApproved, models.StatusAttended}
+
+// HoldsSeat mengecek apakah participant dengan status ini menempati kursi event
+func HoldsSeat(status string) bool {
+	for _, s := range seatStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// lockEvent mengunci baris event sampai transaksi selesai, sehingga keputusan kursi
+// untuk satu event diproses bergantian (row lock di MySQL/Postgres, write lock di SQLite).
+// Event terbaru (termasuk capacity) dibaca ulang setelah lock.
+func lockEvent(tx *gorm.DB, eventID uint) (*models.Event, error) {
+	if err := tx.Exec("UPDATE events SET id = id WHERE id = ?", eventID).Error; err != nil {
+		return nil, err
+	}
+	var event models.Event
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", eventID).First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// LockEvent mengunci baris event sampai transaksi selesai untuk pengecekan lain yang harus
+// bergantian dengan pendaftaran ke event yang sama (mis. email unik per event)
+func LockEvent(tx *gorm.DB, eventID uint) error {
+	_, err := lockEvent(tx, eventID)
+	return err
+}
+
+// SeatsTaken menghitung jumlah kursi yang sudah terisi di event
+func SeatsTaken(db *gorm.DB, eventID uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.Participant{}).Where("event_id = ? AND status IN ?", eventID, seatStatuses).Count(&count).Error
+	return count, err
+}
+
+// lockedSeatsTaken sama dengan SeatsTaken tapi membaca data terbaru di dalam transaksi.
+// MySQL (REPEATABLE READ) membaca snapshot lama tanpa locking read; Postgres (READ COMMITTED)
+// dan SQLite (write lock satu database) sudah melihat data terbaru setelah lockEvent.
+func lockedSeatsTaken(tx *gorm.DB, eventID uint) (int64, error) {
+	if tx.Dialector.Name() == "mysql" {
+		tx = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	return SeatsTaken(tx, eventID)
+}
+
+// hasFreeSeat mengecek ketersediaan kursi; event tanpa capacity tidak pernah penuh
+func hasFreeSeat(tx *gorm.DB, event *models.Event) (bool, error) {
+	if event.Capacity == nil {
+		return true, nil
+	}
+	taken, err := lockedSeatsTaken(tx, event.ID)
+	if err != nil {
+		return false, err
+	}
+	return taken < int64(*event.Capacity), nil
+}
+
+// Register menyimpan participant baru. Jika participant terdaftar di event yang sudah penuh,
+// statusnya otomatis waitlisted. Harus dipanggil di dalam transaksi.
+func Register(tx *gorm.DB, participant *models.Participant) error {
+	participant.Status = models.StatusPending
+	if participant.EventID == nil {
+		return tx.Create(participant).Error
+	}
+
+	event, err := lockEvent(tx, *participant.EventID)
+	if err != nil {
+		return err
+	}
+	free, err := hasFreeSeat(tx, event)
+	if err != nil {
+		return err
+	}
+	if free {
+		return tx.Create(participant).Error
+	}
+
+	participant.Status = models.StatusWaitlisted
+	if err := tx.Create(participant).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.ParticipantStatusHistory{
+		ParticipantID: participant.ID,
+		FromStatus:    models.StatusPending,
+		ToStatus:      models.StatusWaitlisted,
+		Reason:        "Kapasitas event penuh",
+		Actor:         ActorSystem,
+		CreatedAt:     time.Now(),
+	}).Error
+}
+
+// FillOpenSeats mempromosikan participant waitlisted (yang mendaftar paling awal) ke pending
+// selama masih ada kursi kosong. Dipanggil setelah kursi dilepas (withdraw, reject, hapus)
+// atau kapasitas dinaikkan. Mengembalikan participant yang dipromosikan.
+func FillOpenSeats(db *gorm.DB, eventID uint) ([]models.Participant, error) {
+	var promoted []models.Participant
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		promoted, err = fillOpenSeats(tx, eventID)
+		return err
+	})
+	return promoted, err
+}
+
+func fillOpenSeats(tx *gorm.DB, eventID uint) ([]models.Participant, error) {
+	event, err := lockEvent(tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Capacity == nil {
+		return nil, nil
+	}
+	taken, err := lockedSeatsTaken(tx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	free := int64(*event.Capacity) - taken
+	if free <= 0 {
+		return nil, nil
+	}
+
+	var waitlisted []models.Participant
+	if err := tx.Where("event_id = ? AND status = ?", event.ID, models.StatusWaitlisted).
+		Order("created_at asc, id asc").Limit(int(free)).Find(&waitlisted).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range waitlisted {
+		if err := tx.Model(&models.Participant{}).Where("id = ?", waitlisted[i].ID).
+			UpdateColumns(map[string]interface{}{"status": models.StatusPending, "updated_at": now}).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Create(&models.ParticipantStatusHistory{
+			ParticipantID: waitlisted[i].ID,
+			FromStatus:    models.StatusWaitlisted,
+			ToStatus:      models.StatusPending,
+			Reason:        "Dipromosikan dari waitlist karena ada kursi kosong",
+			Actor:         ActorSystem,
+			CreatedAt:     now,
+		}).Error; err != nil {
+			return nil, err
+		}
+		waitlisted[i].Status = models.StatusPending
+		waitlisted[i].UpdatedAt = now
+	}
+	return waitlisted, nil
+}
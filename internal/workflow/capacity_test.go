@@ -0,0 +1,145 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func TestHoldsSeat(t *testing.T) {
+	want := map[string]bool{
+		models.StatusPending: true, models.StatusApproved: true, models.StatusAttended: true,
+		models.StatusWaitlisted: false, models.StatusRejected: false, models.StatusWithdrawn: false,
+	}
+	for status, holds := range want {
+		if HoldsSeat(status) != holds {
+			t.Errorf("HoldsSeat(%s) = %v, want %v", status, !holds, holds)
+		}
+	}
+}
+
+func TestRegisterWaitlistsWhenFull(t *testing.T) {
+	db := openTestDB(t)
+	capacity := 2
+	event := createEvent(t, db, &capacity)
+
+	a := register(t, db, &event.ID, "A")
+	b := register(t, db, &event.ID, "B")
+	c := register(t, db, &event.ID, "C")
+	if a.Status != models.StatusPending || b.Status != models.StatusPending || c.Status != models.StatusWaitlisted {
+		t.Fatalf("statuses = %s, %s, %s; want pending, pending, waitlisted", a.Status, b.Status, c.Status)
+	}
+	var history models.ParticipantStatusHistory
+	if err := db.Where("participant_id = ?", c.ID).First(&history).Error; err != nil || history.ToStatus != models.StatusWaitlisted || history.Actor != ActorSystem {
+		t.Errorf("waitlist history = %+v, %v", history, err)
+	}
+
+	// Event tanpa capacity dan participant tanpa event tidak pernah di-waitlist
+	unlimited := createEvent(t, db, nil)
+	for i := 0; i < 3; i++ {
+		if p := register(t, db, &unlimited.ID, "U"); p.Status != models.StatusPending {
+			t.Errorf("unlimited event: status = %s, want pending", p.Status)
+		}
+	}
+	if p := register(t, db, nil, "Tanpa event"); p.Status != models.StatusPending {
+		t.Errorf("no event: status = %s, want pending", p.Status)
+	}
+	if taken, _ := SeatsTaken(db, event.ID); taken != 2 {
+		t.Errorf("SeatsTaken = %d, want 2", taken)
+	}
+}
+
+func TestReleasingSeatPromotesEarliestWaitlisted(t *testing.T) {
+	db := openTestDB(t)
+	capacity := 1
+	event := createEvent(t, db, &capacity)
+	a := register(t, db, &event.ID, "A")
+	first := register(t, db, &event.ID, "Waitlist 1")
+	second := register(t, db, &event.ID, "Waitlist 2")
+
+	// Waitlisted tidak bisa mengambil kursi selama event penuh
+	if _, err := Transition(db, first.ID, models.StatusApproved, "", "admin"); !errors.Is(err, ErrEventFull) {
+		t.Fatalf("approve while full: err = %v, want ErrEventFull", err)
+	}
+
+	res, err := Transition(db, a.ID, models.StatusWithdrawn, "Batal ikut", ActorParticipant)
+	if err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if len(res.Promoted) != 1 || res.Promoted[0].ID != first.ID || res.Promoted[0].Status != models.StatusPending {
+		t.Fatalf("promoted = %+v, want only the earliest waitlisted", res.Promoted)
+	}
+	if got := statusOf(t, db, second.ID); got != models.StatusWaitlisted {
+		t.Errorf("second waitlisted = %s, want still waitlisted", got)
+	}
+
+	// Transisi antar status pemegang kursi tidak mempromosikan siapa pun
+	res, err = Transition(db, first.ID, models.StatusApproved, "", "admin")
+	if err != nil || len(res.Promoted) != 0 {
+		t.Fatalf("approve promoted: %+v, %v", res, err)
+	}
+}
+
+func TestFillOpenSeatsAfterCapacityIncrease(t *testing.T) {
+	db := openTestDB(t)
+	capacity := 1
+	event := createEvent(t, db, &capacity)
+	register(t, db, &event.ID, "A")
+	w1 := register(t, db, &event.ID, "W1")
+	w2 := register(t, db, &event.ID, "W2")
+	w3 := register(t, db, &event.ID, "W3")
+
+	// Masih penuh: tidak ada yang dipromosikan
+	if promoted, err := FillOpenSeats(db, event.ID); err != nil || len(promoted) != 0 {
+		t.Fatalf("FillOpenSeats while full = %v, %v", promoted, err)
+	}
+
+	if err := db.Model(&event).Update("capacity", 3).Error; err != nil {
+		t.Fatal(err)
+	}
+	promoted, err := FillOpenSeats(db, event.ID)
+	if err != nil {
+		t.Fatalf("FillOpenSeats: %v", err)
+	}
+	if len(promoted) != 2 || promoted[0].ID != w1.ID || promoted[1].ID != w2.ID {
+		t.Fatalf("promoted = %+v, want W1 and W2 in registration order", promoted)
+	}
+	if got := statusOf(t, db, w3.ID); got != models.StatusWaitlisted {
+		t.Errorf("W3 = %s, want waitlisted", got)
+	}
+	if taken, _ := SeatsTaken(db, event.ID); taken != 3 {
+		t.Errorf("SeatsTaken = %d, want 3", taken)
+	}
+}
+
+func TestConcurrentRegisterDoesNotOverbook(t *testing.T) {
+	db := openTestDB(t)
+	capacity := 3
+	event := createEvent(t, db, &capacity)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := testutil.Participant(fmt.Sprintf("P%d", i), func(p *models.Participant) { p.EventID = &event.ID })
+			if err := db.Transaction(func(tx *gorm.DB) error { return Register(tx, &p) }); err != nil {
+				t.Errorf("register: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var pending, waitlisted int64
+	db.Model(&models.Participant{}).Where("status = ?", models.StatusPending).Count(&pending)
+	db.Model(&models.Participant{}).Where("status = ?", models.StatusWaitlisted).Count(&waitlisted)
+	if pending != 3 || waitlisted != 9 {
+		t.Fatalf("pending %d, waitlisted %d; want 3 and 9", pending, waitlisted)
+	}
+}
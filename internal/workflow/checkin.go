@@ -0,0 +1,112 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+)
+
+var (
+	// ErrAlreadyCheckedIn dikembalikan jika participant sudah check-in (scan ganda)
+	ErrAlreadyCheckedIn = errors.New("participant already checked in")
+	// ErrNotCheckedIn dikembalikan saat undo jika check-in tidak ada atau sudah dibatalkan
+	ErrNotCheckedIn = errors.New("check-in not found or already undone")
+	// ErrCheckInNotAllowed dikembalikan jika status pendaftaran tidak boleh check-in (mis. belum disetujui)
+	ErrCheckInNotAllowed = errors.New("participant is not approved for check-in")
+)
+
+// CheckIn mencatat kedatangan participant dan mengubah statusnya approved -> attended.
+// Jika participant sudah check-in, mengembalikan check-in yang aktif (boleh nil jika status
+// diubah manual) bersama ErrAlreadyCheckedIn. Status lama dipakai sebagai kunci sehingga dua
+// scan bersamaan hanya menghasilkan satu check-in.
+func CheckIn(db *gorm.DB, participantID, gate, method, actor string) (*models.CheckIn, error) {
+	var checkIn models.CheckIn
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var participant models.Participant
+		if err := tx.Where("id = ?", participantID).First(&participant).Error; err != nil {
+			return err
+		}
+		if participant.Status == models.StatusAttended {
+			return ErrAlreadyCheckedIn
+		}
+		if participant.Status != models.StatusApproved {
+			return fmt.Errorf("%w (status: %s)", ErrCheckInNotAllowed, participant.Status)
+		}
+
+		reason := "Check-in"
+		if gate != "" {
+			reason += " di gate " + gate
+		}
+		if _, err := Transition(tx, participant.ID, models.StatusAttended, reason, actor); err != nil {
+			return err
+		}
+
+		checkIn = models.CheckIn{
+			ParticipantID: participant.ID,
+			EventID:       participant.EventID,
+			Gate:          gate,
+			Method:        method,
+			CheckedInBy:   actor,
+			CheckedInAt:   time.Now(),
+		}
+		return tx.Create(&checkIn).Error
+	})
+	if errors.Is(err, ErrAlreadyCheckedIn) || errors.Is(err, ErrStatusChanged) {
+		// Kalah balapan dengan scan lain: laporkan sebagai check-in ganda beserta data check-in pertama
+		existing, findErr := ActiveCheckIn(db, participantID)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if errors.Is(err, ErrStatusChanged) && existing == nil {
+			return nil, err
+		}
+		return existing, ErrAlreadyCheckedIn
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// ActiveCheckIn mengambil check-in participant yang belum dibatalkan, nil jika tidak ada
+func ActiveCheckIn(db *gorm.DB, participantID string) (*models.CheckIn, error) {
+	var checkIn models.CheckIn
+	err := db.Where("participant_id = ? AND undone_at IS NULL", participantID).
+		Order("checked_in_at desc").First(&checkIn).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// UndoCheckIn membatalkan check-in (mis. salah scan) dan mengembalikan status ke approved.
+// Record check-in ditandai undone oleh Transition, bukan dihapus.
+func UndoCheckIn(db *gorm.DB, checkInID uint, reason, actor string) (*models.CheckIn, error) {
+	var checkIn models.CheckIn
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND undone_at IS NULL", checkInID).First(&checkIn).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotCheckedIn
+			}
+			return err
+		}
+		if reason == "" {
+			reason = "Check-in dibatalkan"
+		}
+		if _, err := Transition(tx, checkIn.ParticipantID, models.StatusApproved, reason, actor); err != nil {
+			return err
+		}
+		return tx.First(&checkIn, checkIn.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
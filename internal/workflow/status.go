@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+)
+
+// ActorSystem dipakai untuk transisi otomatis (bukan oleh admin)
+const ActorSystem = "system"
+
+// ActorParticipant dipakai untuk transisi oleh pendaftar sendiri lewat portal
+const ActorParticipant = "participant"
+
+var (
+	// ErrInvalidStatus dikembalikan jika status tujuan tidak dikenal
+	ErrInvalidStatus = errors.New("invalid status")
+	// ErrInvalidTransition dikembalikan jika transisi tidak diizinkan state machine
+	ErrInvalidTransition = errors.New("status transition not allowed")
+	// ErrStatusChanged dikembalikan jika status berubah oleh request lain di tengah transisi
+	ErrStatusChanged = errors.New("status was changed by another request")
+)
+
+// transitions adalah state machine status pendaftaran: status asal -> status tujuan yang diizinkan
+var transitions = map[string][]string{
+	models.StatusPending:    {models.StatusApproved, models.StatusRejected, models.StatusWaitlisted, models.StatusWithdrawn},
+	models.StatusWaitlisted: {models.StatusPending, models.StatusApproved, models.StatusRejected, models.StatusWithdrawn},
+	models.StatusApproved:   {models.StatusAttended, models.StatusWithdrawn},
+	models.StatusRejected:   {models.StatusPending},
+	models.StatusWithdrawn:  {},
+	models.StatusAttended:   {models.StatusApproved}, // Koreksi / undo check-in
+}
+
+// CanTransition mengecek apakah status from boleh berubah ke to
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions mengembalikan status tujuan yang diizinkan dari status from
+func AllowedTransitions(from string) []string {
+	allowed := transitions[from]
+	if allowed == nil {
+		return []string{}
+	}
+	return allowed
+}
+
+// Result adalah hasil Transition
+type Result struct {
+	Participant *models.Participant
+	History     *models.ParticipantStatusHistory
+	// Promoted berisi participant waitlisted yang otomatis naik karena kursi dilepas
+	Promoted []models.Participant
+}
+
+// Transition mengubah status participant sesuai state machine dan mencatat history.
+// Untuk participant di event berkapasitas, transisi yang mengambil kursi ditolak jika event penuh
+// (ErrEventFull) dan transisi yang melepas kursi otomatis mempromosikan waitlist.
+// db boleh berupa transaksi yang sedang berjalan (transisi akan memakai savepoint).
+func Transition(db *gorm.DB, participantID string, to string, reason string, actor string) (*Result, error) {
+	if !models.IsValidParticipantStatus(to) {
+		return nil, ErrInvalidStatus
+	}
+
+	var participant models.Participant
+	var history models.ParticipantStatusHistory
+	var promoted []models.Participant
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", participantID).First(&participant).Error; err != nil {
+			return err
+		}
+		from := participant.Status
+		if !CanTransition(from, to) {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+		}
+
+		// Mengambil kursi: cek kapasitas dengan event terkunci supaya tidak overbook
+		if participant.EventID != nil && !HoldsSeat(from) && HoldsSeat(to) {
+			event, err := lockEvent(tx, *participant.EventID)
+			if err != nil {
+				return err
+			}
+			free, err := hasFreeSeat(tx, event)
+			if err != nil {
+				return err
+			}
+			if !free {
+				return ErrEventFull
+			}
+		}
+
+		// Update bersyarat pada status lama supaya dua admin tidak menimpa transisi satu sama lain
+		now := time.Now()
+		result := tx.Model(&models.Participant{}).
+			Where("id = ? AND status = ?", participant.ID, from).
+			UpdateColumns(map[string]interface{}{"status": to, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+		participant.Status = to
+		participant.UpdatedAt = now
+
+		history = models.ParticipantStatusHistory{
+			ParticipantID: participant.ID,
+			FromStatus:    from,
+			ToStatus:      to,
+			Reason:        reason,
+			Actor:         actor,
+			CreatedAt:     now,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+
+		// Kembali dari attended berarti check-in dibatalkan, tandai check-in yang masih aktif
+		if from == models.StatusAttended {
+			if err := tx.Model(&models.CheckIn{}).
+				Where("participant_id = ? AND undone_at IS NULL", participant.ID).
+				UpdateColumns(map[string]interface{}{"undone_at": now, "undone_by": actor, "undo_reason": reason}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Melepas kursi: naikkan waitlist berikutnya di transaksi yang sama
+		if participant.EventID != nil && HoldsSeat(from) && !HoldsSeat(to) {
+			var err error
+			if promoted, err = fillOpenSeats(tx, *participant.EventID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Participant: &participant, History: &history, Promoted: promoted}, nil
+}
@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func TestCanTransition(t *testing.T) {
+	all := []string{
+		models.StatusPending, models.StatusApproved, models.StatusRejected,
+		models.StatusWaitlisted, models.StatusWithdrawn, models.StatusAttended,
+	}
+	allowed := map[string]bool{
+		"pending->approved":     true,
+		"pending->rejected":     true,
+		"pending->waitlisted":   true,
+		"pending->withdrawn":    true,
+		"waitlisted->pending":   true,
+		"waitlisted->approved":  true,
+		"waitlisted->rejected":  true,
+		"waitlisted->withdrawn": true,
+		"approved->attended":    true,
+		"approved->withdrawn":   true,
+		"rejected->pending":     true,
+		"attended->approved":    true,
+	}
+	// Semua pasangan status diperiksa, termasuk transisi ke status yang sama
+	for _, from := range all {
+		for _, to := range all {
+			want := allowed[from+"->"+to]
+			if got := CanTransition(from, to); got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+	if CanTransition("unknown", models.StatusPending) || CanTransition(models.StatusPending, "unknown") {
+		t.Error("unknown status allowed")
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	if got := AllowedTransitions(models.StatusWithdrawn); got == nil || len(got) != 0 {
+		t.Errorf("AllowedTransitions(withdrawn) = %#v, want empty slice", got)
+	}
+	if got := AllowedTransitions("unknown"); got == nil || len(got) != 0 {
+		t.Errorf("AllowedTransitions(unknown) = %#v, want empty slice", got)
+	}
+	for _, to := range AllowedTransitions(models.StatusPending) {
+		if !CanTransition(models.StatusPending, to) {
+			t.Errorf("AllowedTransitions(pending) lists %s but CanTransition rejects it", to)
+		}
+	}
+}
+
+func TestTransitionRecordsHistory(t *testing.T) {
+	db := openTestDB(t)
+	p := register(t, db, nil, "Budi")
+
+	res, err := Transition(db, p.ID, models.StatusApproved, "Berkas lengkap", "admin")
+	if err != nil {
+		t.Fatalf("Transition: %v", err)
+	}
+	if res.Participant.Status != models.StatusApproved || res.History.FromStatus != models.StatusPending ||
+		res.History.ToStatus != models.StatusApproved || res.History.Actor != "admin" {
+		t.Fatalf("result = %+v / %+v", res.Participant, res.History)
+	}
+
+	if _, err := Transition(db, p.ID, models.StatusPending, "", "admin"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("approved -> pending: err = %v, want ErrInvalidTransition", err)
+	}
+	if _, err := Transition(db, p.ID, "lulus", "", "admin"); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("unknown status: err = %v, want ErrInvalidStatus", err)
+	}
+	if got := statusOf(t, db, p.ID); got != models.StatusApproved {
+		t.Errorf("status after rejected transitions = %s, want approved", got)
+	}
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	return testutil.OpenDB(t, &models.Event{}, &models.Participant{}, &models.ParticipantStatusHistory{}, &models.CheckIn{})
+}
+
+func createEvent(t *testing.T, db *gorm.DB, capacity *int) models.Event {
+	t.Helper()
+	return testutil.CreateEvent(t, db, func(e *models.Event) { e.Capacity = capacity })
+}
+
+// register mendaftarkan participant lewat Register (termasuk waitlist) seperti handler registrasi
+func register(t *testing.T, db *gorm.DB, eventID *uint, name string) models.Participant {
+	t.Helper()
+	p := testutil.Participant(name, func(p *models.Participant) { p.EventID = eventID })
+	if err := db.Transaction(func(tx *gorm.DB) error { return Register(tx, &p) }); err != nil {
+		t.Fatalf("register %s: %v", name, err)
+	}
+	// created_at berbeda supaya urutan waitlist deterministik
+	time.Sleep(2 * time.Millisecond)
+	return p
+}
+
+func statusOf(t *testing.T, db *gorm.DB, id string) string {
+	t.Helper()
+	var p models.Participant
+	if err := db.Select("status").Where("id = ?", id).First(&p).Error; err != nil {
+		t.Fatal(err)
+	}
+	return p.Status
+}